@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/orchestrator"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// AddBackupCommand defines the backup command and adds it to the root command.
+func AddBackupCommand(rootCmd *cobra.Command) {
+	var output string
+
+	var backupCmd = &cobra.Command{
+		Use:   "backup",
+		Short: "Archive the entire Reflow configuration",
+		Long: `Writes a tar.gz snapshot of the Reflow configuration: the global config.yaml and
+plugins.json, each project's config.yaml, state.json and deployments.log, and all
+Nginx config files. A manifest records each project's repository URL (or local source
+directory) and active commits. Restore it with 'reflow restore'.
+
+Repositories are not archived; they are cloned again on restore. Env files, secrets,
+git credentials, deploy keys, plugin files and data are not archived either; use
+'reflow project backup' to archive a project's env files. The global config may hold
+credentials, so keep the archive somewhere safe.
+
+The archive is written to reflow-backup-<timestamp>.tar.gz in the current directory
+unless --output is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			outputPath := output
+			if outputPath == "" {
+				outputPath = fmt.Sprintf("reflow-backup-%s.tar.gz", time.Now().Format("20060102-150405"))
+			}
+			return orchestrator.BackupAll(context.Background(), reflowBasePath, outputPath)
+		},
+	}
+
+	backupCmd.Flags().StringVarP(&output, "output", "o", "", "Path of the archive to write (default reflow-backup-<timestamp>.tar.gz)")
+
+	rootCmd.AddCommand(backupCmd)
+}
+
+// AddRestoreCommand defines the restore command and adds it to the root command.
+func AddRestoreCommand(rootCmd *cobra.Command) {
+	var yes bool
+
+	var restoreCmd = &cobra.Command{
+		Use:   "restore <archive-path>",
+		Short: "Restore the Reflow configuration from a 'reflow backup' archive",
+		Long: `Restores an archive written by 'reflow backup' into the Reflow base directory.
+
+You are asked before the global config.yaml or plugins.json is overwritten, before
+each project is restored, and before the archived Nginx config files are added; --yes
+answers yes to every question. Projects that already exist are skipped. Restored
+projects get their config, state and deployment log as archived, and their repository
+is cloned again (or copied from the local source directory).
+
+Nothing is deployed and Nginx is not reloaded. Nginx files that already exist, or
+that belong to a project that was not restored, are left alone. Afterwards, run
+'reflow reconcile' to compare the restored state with the running containers and
+deploy the recorded commits where needed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+			return orchestrator.RestoreAll(context.Background(), reflowBasePath, args[0], yes)
+		},
+	}
+
+	restoreCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Answer yes to every question")
+
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -0,0 +1,40 @@
+// Package cmdutil holds helpers shared by the reflow CLI command packages.
+package cmdutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// ResolveBasePath returns the absolute Reflow base directory for a command: the value of the
+// root --config flag, or ./reflow in the current working directory when it is unset.
+func ResolveBasePath(cobraCmd *cobra.Command) (string, error) {
+	configFlag, err := cobraCmd.Root().PersistentFlags().GetString("config")
+	if err != nil {
+		return "", fmt.Errorf("failed to read --config flag: %w", err)
+	}
+	return resolveBasePath(configFlag)
+}
+
+func resolveBasePath(configFlag string) (string, error) {
+	var reflowBasePath string
+	if configFlag == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current working directory: %w", err)
+		}
+		reflowBasePath = filepath.Join(cwd, "reflow")
+	} else {
+		absPath, err := filepath.Abs(configFlag)
+		if err != nil {
+			return "", fmt.Errorf("failed to get absolute path for --config flag: %w", err)
+		}
+		reflowBasePath = absPath
+	}
+	util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
+	return reflowBasePath, nil
+}
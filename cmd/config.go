@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"reflow/cmd/config_ops"
+)
+
+// configCmd represents the base command for global Reflow settings
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage global Reflow settings",
+	Long:  `Provides subcommands to manage settings that apply to all projects, such as stored git credentials.`,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+
+	config_ops.AddGitCredentialsCommand(configCmd)
+}
@@ -0,0 +1,135 @@
+package config_ops
+
+import (
+	"fmt"
+	"os"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// AddGitCredentialsCommand defines the 'git-credentials' command and its subcommands.
+func AddGitCredentialsCommand(parentCmd *cobra.Command) {
+	var username string
+	var token string
+
+	// --- Parent 'git-credentials' Command ---
+	var gitCredentialsCmd = &cobra.Command{
+		Use:   "git-credentials",
+		Short: "Manage access tokens for cloning private repositories over HTTPS",
+		Long: `Stores username/token pairs per git host (e.g. github.com) in
+reflow/git-credentials.yaml (0600). They are used to clone and fetch HTTPS
+repository URLs on that host, unless the project has its own token from
+'reflow project create --git-token'. Tokens are redacted from log output.`,
+	}
+
+	// --- 'git-credentials set' Subcommand ---
+	var setCmd = &cobra.Command{
+		Use:   "set <host>",
+		Short: "Store the access token for a git host",
+		Long: `Stores the access token for a git host. Without --token, the token is read
+from a prompt (or the first line of stdin), which keeps it out of the shell history.
+
+Example:
+  reflow config git-credentials set github.com --username my-user
+  echo "$GITHUB_TOKEN" | reflow config git-credentials set github.com`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			host := args[0]
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			if token == "" {
+				value, err := util.ReadSecretInput(fmt.Sprintf("Access token for %s: ", host))
+				if err != nil {
+					return err
+				}
+				token = value
+			}
+			if token == "" {
+				return fmt.Errorf("access token must not be empty")
+			}
+			util.RegisterSecret(token)
+
+			if err := config.SetGitCredential(reflowBasePath, config.GitCredential{Host: host, Username: username, Token: token}); err != nil {
+				return err
+			}
+			util.Log.Infof("Stored git credentials for '%s' in %s.", host, config.GetGitCredentialsPath(reflowBasePath))
+			return nil
+		},
+	}
+	setCmd.Flags().StringVar(&username, "username", "", "Username sent with the token (default: git)")
+	setCmd.Flags().StringVar(&token, "token", "", "Access token (prompted for when omitted)")
+
+	// --- 'git-credentials unset' Subcommand ---
+	var unsetCmd = &cobra.Command{
+		Use:   "unset <host>",
+		Short: "Remove the stored access token for a git host",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			host := args[0]
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			removed, err := config.UnsetGitCredential(reflowBasePath, host)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				util.Log.Warnf("No git credentials stored for '%s'.", host)
+				return nil
+			}
+			util.Log.Infof("Removed git credentials for '%s'.", host)
+			return nil
+		},
+	}
+
+	// --- 'git-credentials list' Subcommand ---
+	var listCmd = &cobra.Command{
+		Use:     "list",
+		Short:   "List the hosts with stored access tokens",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			creds, err := config.LoadGitCredentials(reflowBasePath)
+			if err != nil {
+				return err
+			}
+			if len(creds) == 0 {
+				fmt.Println("No git credentials stored.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			_, _ = fmt.Fprintln(w, "HOST\tUSERNAME\tTOKEN")
+			for _, cred := range creds {
+				credUsername := cred.Username
+				if credUsername == "" {
+					credUsername = "(default)"
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", cred.Host, credUsername, config.MaskSecretValue(cred.Token))
+			}
+			return w.Flush()
+		},
+	}
+
+	gitCredentialsCmd.AddCommand(setCmd)
+	gitCredentialsCmd.AddCommand(unsetCmd)
+	gitCredentialsCmd.AddCommand(listCmd)
+
+	parentCmd.AddCommand(gitCredentialsCmd)
+}
@@ -2,9 +2,7 @@ package deploy
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/orchestrator"
 	"reflow/internal/util"
 
@@ -13,36 +11,40 @@ import (
 
 // AddApproveCommand defines the approval command and adds it to the root command.
 func AddApproveCommand(rootCmd *cobra.Command) {
+	var dryRun bool
+
 	var approveCmd = &cobra.Command{
-		Use:   "approve <project-name>",
+		Use:   "approve <project-name> [commit-ish]",
 		Short: "Promotes the current 'test' deployment to 'production'",
 		Long: `Takes the currently active commit in the 'test' environment for the specified project,
 deploys the corresponding Docker image to the inactive 'prod' environment slot (blue/green),
-waits for it to become healthy, and then switches live production traffic by updating Nginx.`,
-		Args: cobra.ExactArgs(1),
+waits for it to become healthy, and then switches live production traffic by updating Nginx.
+
+If a commit-ish is given, the approval is refused unless it matches the commit
+currently active in 'test'. Use it to make sure the build you verified is the one
+that reaches prod, even if someone deployed to test in the meantime.
+
+With --dry-run, the Nginx configuration that would be used is printed and the planned
+changes are logged, but no containers are started and no files are written.`,
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			projectName := args[0]
+			commitIsh := ""
+			if len(args) > 1 {
+				commitIsh = args[1]
+			}
 			ctx := context.Background()
+			if dryRun {
+				ctx = orchestrator.WithDryRun(ctx)
+			}
 
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var err error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, err = filepath.Abs(configFlag)
-				if err != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", err)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
 			// --- Call Orchestration Logic ---
-			err = orchestrator.ApproveProd(ctx, reflowBasePath, projectName)
+			err = orchestrator.ApproveProd(ctx, reflowBasePath, projectName, commitIsh)
 			if err != nil {
 				util.Log.Errorf("Approval process failed: %v", err)
 				return err
@@ -52,5 +54,7 @@ waits for it to become healthy, and then switches live production traffic by upd
 		},
 	}
 
+	approveCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the generated Nginx config and the planned changes without deploying")
+
 	rootCmd.AddCommand(approveCmd)
 }
@@ -1,24 +1,60 @@
 package deploy
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/git"
 	"reflow/internal/orchestrator"
 	"reflow/internal/util"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 // AddDeployCommand defines the deploy command and adds it to the root command.
 func AddDeployCommand(rootCmd *cobra.Command) {
+	var env string
+	var iKnowWhatImDoing bool
+	var skipTestCheck bool
+	var dryRun bool
+	var noCache bool
+	var push bool
+	var branch string
+
 	var deployCmd = &cobra.Command{
 		Use:   "deploy <project-name> [commit-ish]",
 		Short: "Deploys a project version to the 'test' environment",
 		Long: `Builds the specified commit (or the latest if none provided) for the given project,
 deploys it to the inactive 'test' environment slot (blue/green), waits for it
-to become healthy, and then switches live traffic by updating the Nginx configuration.`,
+to become healthy, and then switches live traffic by updating the Nginx configuration.
+
+Production is normally updated with 'reflow approve'. To hotfix prod directly with a
+specific commit, use --env prod together with --i-know-what-im-doing. The commit must
+have been deployed to test successfully before, unless --skip-test-check is also set.
+
+A commit-ish may be a commit hash, tag or branch name. Branches are fetched from the
+remote first, including ones created since the project was cloned. Use --branch to
+deploy the head of a remote branch even if a tag has the same name.
+
+Use --no-cache to build the image from scratch instead of reusing cached layers, e.g.
+when a cached dependency install has gone stale.
+
+Use --push to also push the built image to the registry set in the global config's
+'registry' section, as <registry>/<project>:<commit>. 'reflow approve' pulls the image
+from there when it is not present locally, e.g. on another host or after a rebuild.
+Set 'registry.push' to push after every build without the flag.
+
+With --dry-run, the Dockerfile and Nginx configuration that would be used are printed
+and the planned changes are logged, but nothing is built, started or written.
+
+Example:
+  reflow deploy my-app
+  reflow deploy my-app 4f2a9c1 --env prod --i-know-what-im-doing
+  reflow deploy my-app --branch feature-x
+  reflow deploy my-app --dry-run`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			projectName := args[0]
@@ -26,28 +62,63 @@ to become healthy, and then switches live traffic by updating the Nginx configur
 			if len(args) > 1 {
 				commitIsh = args[1]
 			}
+			if branch != "" {
+				if commitIsh != "" {
+					return fmt.Errorf("specify either a commit-ish or --branch, not both")
+				}
+				commitIsh = git.RemoteBranchRevision(branch)
+			}
 
-			ctx := context.Background()
-
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var err error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
+			switch env {
+			case "test":
+				if iKnowWhatImDoing || skipTestCheck {
+					return fmt.Errorf("--i-know-what-im-doing and --skip-test-check only apply to --env prod")
 				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, err = filepath.Abs(configFlag)
-				if err != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", err)
+			case "prod":
+				if !iKnowWhatImDoing {
+					return fmt.Errorf("deploying directly to prod bypasses 'reflow approve'; pass --i-know-what-im-doing to confirm")
+				}
+				if commitIsh == "" {
+					return fmt.Errorf("a commit-ish is required when deploying directly to prod")
 				}
+			default:
+				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+			}
+
+			ctx := context.Background()
+			if dryRun {
+				ctx = orchestrator.WithDryRun(ctx)
+			}
+			if noCache {
+				ctx = orchestrator.WithNoCache(ctx)
+			}
+			if push {
+				ctx = orchestrator.WithPush(ctx)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
 			// --- Call Orchestration Logic ---
-			err = orchestrator.DeployTest(ctx, reflowBasePath, projectName, commitIsh)
+			if env == "prod" && !dryRun {
+				util.Log.Warnf("You are about to deploy '%s' of project '%s' directly to PROD, bypassing test approval.", commitIsh, projectName)
+				fmt.Printf("Proceed with the prod deployment? (Type 'yes' to confirm): ")
+				input, readErr := bufio.NewReader(os.Stdin).ReadString('\n')
+				if readErr != nil {
+					return fmt.Errorf("failed to read confirmation: %w", readErr)
+				}
+				if strings.TrimSpace(strings.ToLower(input)) != "yes" {
+					util.Log.Info("Prod deployment cancelled by user.")
+					return nil
+				}
+				_, err = orchestrator.DeployProd(ctx, reflowBasePath, projectName, commitIsh, skipTestCheck)
+			} else if env == "prod" {
+				_, err = orchestrator.DeployProd(ctx, reflowBasePath, projectName, commitIsh, skipTestCheck)
+			} else {
+				_, err = orchestrator.DeployTest(ctx, reflowBasePath, projectName, commitIsh)
+			}
 			if err != nil {
 				util.Log.Errorf("Deployment failed: %v", err)
 				return err
@@ -57,5 +128,14 @@ to become healthy, and then switches live traffic by updating the Nginx configur
 		},
 	}
 
+	deployCmd.Flags().StringVar(&env, "env", "test", "Environment to deploy to ('test', or 'prod' with --i-know-what-im-doing)")
+	deployCmd.Flags().BoolVar(&iKnowWhatImDoing, "i-know-what-im-doing", false, "Allow deploying directly to prod without going through test approval")
+	deployCmd.Flags().BoolVar(&skipTestCheck, "skip-test-check", false, "With --env prod, do not require the commit to have been deployed to test before")
+
+	deployCmd.Flags().StringVar(&branch, "branch", "", "Deploy the head of this remote branch")
+	deployCmd.Flags().BoolVar(&noCache, "no-cache", false, "Build the image without using cached layers from earlier builds")
+	deployCmd.Flags().BoolVar(&push, "push", false, "Push the built image to the registry from the global config")
+	deployCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the generated Dockerfile and Nginx config and the planned changes without deploying")
+
 	rootCmd.AddCommand(deployCmd)
 }
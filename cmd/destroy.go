@@ -3,10 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/orchestrator"
-	"reflow/internal/util"
 
 	"github.com/spf13/cobra"
 )
@@ -14,6 +12,8 @@ import (
 // AddDestroyCommand defines the destroy command and adds it to the root command.
 func AddDestroyCommand(rootCmd *cobra.Command) {
 	var force bool
+	var keepData bool
+	var removeData bool
 
 	var destroyCmd = &cobra.Command{
 		Use:   "destroy",
@@ -25,29 +25,21 @@ reflow-nginx container), removes the reflow-network Docker network, and then
 completely deletes the Reflow base directory (usually './reflow/'), including all
 configurations, state files, cloned repositories, logs, and any other associated data.
 
+Persistent data is kept by default: Reflow managed Docker volumes, project data
+directories ('apps/<name>/data/') and plugin data ('plugin-data/'). Pass
+--remove-data to delete it as well.
+
 Use with extreme caution. Requires confirmation unless '--force' is used.`,
 		Args: cobra.NoArgs,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var pathErr error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, pathErr = filepath.Abs(configFlag)
-				if pathErr != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", pathErr)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path for destruction: %s", reflowBasePath)
 
 			ctx := context.Background()
 
-			err := orchestrator.DestroyReflow(ctx, reflowBasePath, force)
+			err = orchestrator.DestroyReflow(ctx, reflowBasePath, force, removeData || !keepData)
 			if err != nil {
 				return fmt.Errorf("destruction process failed")
 			}
@@ -58,5 +50,9 @@ Use with extreme caution. Requires confirmation unless '--force' is used.`,
 
 	destroyCmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt (use with extreme caution)")
 
+	destroyCmd.Flags().BoolVar(&keepData, "keep-data", true, "Keep managed volumes and data directories (default)")
+	destroyCmd.Flags().BoolVar(&removeData, "remove-data", false, "Also delete managed volumes and data directories")
+	destroyCmd.MarkFlagsMutuallyExclusive("keep-data", "remove-data")
+
 	rootCmd.AddCommand(destroyCmd)
 }
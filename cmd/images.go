@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"reflow/cmd/images_ops"
+)
+
+// imagesCmd represents the base command for managing the Docker images Reflow builds
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Inspect and prune the Docker images built for projects",
+	Long:  `Provides subcommands to list the images Reflow has built for its projects and to remove old ones.`,
+}
+
+func init() {
+	rootCmd.AddCommand(imagesCmd)
+
+	images_ops.AddListCommand(imagesCmd)
+	images_ops.AddPruneCommand(imagesCmd)
+}
@@ -0,0 +1,97 @@
+package images_ops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/docker"
+	"reflow/internal/project"
+	"reflow/internal/util"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// AddListCommand defines the list command and adds it to the parent command.
+func AddListCommand(parentCmd *cobra.Command) {
+	var jsonOutput bool
+
+	var listCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the Docker images built for Reflow projects",
+		Long: `Lists the local images tagged <project>:<commit> for every configured project,
+most recently built first, with their size, build time and the environments whose
+containers use them. An image used only by containers outside the project is shown
+as in use without an environment. Use --json for machine-readable output.`,
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			summaries, err := project.ListProjects(reflowBasePath)
+			if err != nil {
+				return fmt.Errorf("failed to list projects: %w", err)
+			}
+
+			images := []docker.ProjectImage{}
+			for _, s := range summaries {
+				projectImages, err := docker.ListProjectImages(ctx, s.Name)
+				if err != nil {
+					return fmt.Errorf("failed to list images of project '%s': %w", s.Name, err)
+				}
+				images = append(images, projectImages...)
+			}
+
+			if jsonOutput {
+				encoded, err := json.MarshalIndent(images, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode images as JSON: %w", err)
+				}
+				fmt.Println(string(encoded))
+				return nil
+			}
+
+			if len(images) == 0 {
+				util.Log.Info("No project images found.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "PROJECT\tCOMMIT\tIMAGE ID\tSIZE\tCREATED\tIN USE BY")
+			fmt.Fprintln(w, "-------\t------\t--------\t----\t-------\t---------")
+			var totalSize int64
+			for _, img := range images {
+				inUseBy := "-"
+				if len(img.InUseBy) > 0 {
+					inUseBy = strings.Join(img.InUseBy, ", ")
+				} else if img.InUse {
+					inUseBy = "(other container)"
+				}
+				commit := img.Commit
+				if len(commit) > 12 {
+					commit = commit[:12]
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%.1fMiB\t%s\t%s\n", img.Project, commit, strings.TrimPrefix(img.ID, "sha256:")[:12], float64(img.Size)/(1024*1024), img.Created.Local().Format("2006-01-02 15:04"), inUseBy)
+				totalSize += img.Size
+			}
+			if err := w.Flush(); err != nil {
+				util.Log.Errorf("Failed to flush tabwriter: %v", err)
+				return err
+			}
+			fmt.Printf("\n%d image(s), %.1fMiB in total (layers shared between images are counted for each).\n", len(images), float64(totalSize)/(1024*1024))
+			return nil
+		},
+	}
+
+	listCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output images as JSON")
+
+	parentCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,47 @@
+package images_ops
+
+import (
+	"context"
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+// AddPruneCommand defines the prune command and adds it to the parent command.
+func AddPruneCommand(parentCmd *cobra.Command) {
+	var keepLast int
+
+	var pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old Docker images of every project",
+		Long: `Removes, for every project with an active deployment, the images tagged
+<project>:<commit> other than the --keep-last most recently built ones, the images
+of the commits active in 'test' or 'prod', and images a container still uses.
+Without --keep-last each project's 'keepImages' setting (default 3) applies.
+
+Projects with nothing deployed are skipped. The reclaimed disk space is reported.`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if !cobraCmd.Flags().Changed("keep-last") {
+				keepLast = -1
+			} else if keepLast < 0 {
+				return fmt.Errorf("invalid value for --keep-last: %d. Must be 0 or more", keepLast)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			result, err := orchestrator.PruneAllProjectImages(context.Background(), reflowBasePath, keepLast)
+			fmt.Printf("Pruned %d image(s) across %d project(s), reclaiming about %.1fMiB.\n", result.PrunedImages, result.Projects, float64(result.ReclaimedBytes)/(1024*1024))
+			return err
+		},
+	}
+
+	pruneCmd.Flags().IntVar(&keepLast, "keep-last", 0, "Number of most recent images to keep per project (default: the project's keepImages setting)")
+
+	parentCmd.AddCommand(pruneCmd)
+}
@@ -12,6 +12,7 @@ import (
 
 	"reflow/internal/config"
 	"reflow/internal/docker"
+	"reflow/internal/git"
 	"reflow/internal/util"
 
 	"github.com/docker/docker/api/types/container"
@@ -24,13 +25,24 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+var initSSHKeyPath string
+var initEncryptSecrets bool
+
 // initCmd represents the init command
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize the Reflow environment in the target directory",
 	Long: `Creates the necessary configuration files, directories, Docker network,
 and starts the Nginx reverse proxy container. This command should be run
-once on a new VPS or in the desired base directory.`,
+once on a new VPS or in the desired base directory.
+
+Use --ssh-key to configure a private key for cloning private repositories
+on servers without an SSH agent. Encrypted keys can be unlocked by setting
+REFLOW_SSH_PASSPHRASE.
+
+Use --encrypt-secrets to store project secrets encrypted at rest. A key is
+generated at reflow/.secret.key unless REFLOW_SECRET_KEY is set, and existing
+secrets files are encrypted with it.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		basePath := GetReflowBasePath()
 		util.Log.Infof("Initializing Reflow environment at: %s", basePath)
@@ -54,6 +66,16 @@ once on a new VPS or in the desired base directory.`,
 		if err := createDefaultGlobalConfig(basePath); err != nil {
 			return err
 		}
+		if initSSHKeyPath != "" {
+			if err := configureSSHKey(basePath, initSSHKeyPath); err != nil {
+				return err
+			}
+		}
+		if initEncryptSecrets {
+			if err := configureSecretsEncryption(basePath); err != nil {
+				return err
+			}
+		}
 
 		// --- 3. Initialize Docker Client ---
 		util.Log.Info("Checking Docker connectivity...")
@@ -92,7 +114,10 @@ func createRequiredDirs(basePath string) error {
 	dirs := []string{
 		filepath.Join(basePath, config.AppsDirName),
 		filepath.Join(basePath, config.NginxDirName, config.NginxConfDirName),
+		filepath.Join(basePath, config.NginxDirName, config.NginxConfDirName, config.NginxLimitsDirName),
 		filepath.Join(basePath, config.NginxDirName, config.NginxLogDirName),
+		filepath.Join(basePath, config.NginxDirName, config.NginxCertsDirName),
+		filepath.Join(basePath, config.NginxDirName, config.NginxAcmeDirName),
 	}
 
 	for _, dir := range dirs {
@@ -137,6 +162,64 @@ func createDefaultGlobalConfig(basePath string) error {
 	return nil
 }
 
+func configureSSHKey(basePath, keyPath string) error {
+	absKeyPath, err := filepath.Abs(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for --ssh-key: %w", err)
+	}
+
+	globalCfg, err := config.LoadGlobalConfig(basePath)
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	passphrase := git.AuthConfigFromGlobal(globalCfg).SSHPassphrase
+	if _, err := git.NewSSHAuthFromKey(absKeyPath, passphrase); err != nil {
+		if _, statErr := os.Stat(absKeyPath); statErr != nil {
+			return fmt.Errorf("invalid --ssh-key: %w", err)
+		}
+		util.Log.Warnf("SSH key '%s' could not be loaded yet: %v", absKeyPath, err)
+	}
+
+	globalCfg.SSHKeyPath = absKeyPath
+	if err := config.SaveGlobalConfig(basePath, globalCfg); err != nil {
+		return err
+	}
+	util.Log.Infof("✅ Configured SSH key for git authentication: %s", absKeyPath)
+	return nil
+}
+
+func configureSecretsEncryption(basePath string) error {
+	if os.Getenv(config.SecretKeyEnvVar) != "" {
+		util.Log.Infof("Using secrets encryption key from %s.", config.SecretKeyEnvVar)
+	} else {
+		created, err := config.GenerateSecretKey(basePath)
+		if err != nil {
+			return err
+		}
+		keyPath := config.GetSecretKeyPath(basePath)
+		if created {
+			util.Log.Infof("Generated secrets encryption key: %s", keyPath)
+			util.Log.Warn("Back up this key: encrypted secrets cannot be recovered without it.")
+		} else {
+			util.Log.Infof("Using existing secrets encryption key: %s", keyPath)
+		}
+	}
+
+	if _, err := config.LoadSecretKey(basePath); err != nil {
+		return err
+	}
+	encrypted, err := config.EncryptSecretsFiles(basePath)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt existing secrets files: %w", err)
+	}
+	for _, path := range encrypted {
+		util.Log.Infof("Encrypted secrets file: %s", path)
+	}
+	util.Log.Info("✅ Secrets encryption enabled.")
+	return nil
+}
+
 func createReflowNetwork(ctx context.Context, cli *dockerClient.Client) error {
 	networks, err := cli.NetworkList(ctx, network.ListOptions{})
 	if err != nil {
@@ -168,18 +251,31 @@ func createReflowNetwork(ctx context.Context, cli *dockerClient.Client) error {
 	return nil
 }
 
+var nginxLimitsInclude = "include " + config.NginxLimitsContainerPath + "/*.conf;"
+
 func createNginxDefaultConf(basePath string) error {
 	confDir := filepath.Join(basePath, config.NginxDirName, config.NginxConfDirName)
 	defaultConfPath := filepath.Join(confDir, "00-default.conf")
 
-	if _, err := os.Stat(defaultConfPath); err == nil {
+	if existing, err := os.ReadFile(defaultConfPath); err == nil {
 		util.Log.Warnf("Nginx default config already exists at %s, skipping creation.", defaultConfPath)
+		// Configs created before rate limiting was supported lack the limits include.
+		if !strings.Contains(string(existing), nginxLimitsInclude) {
+			upgraded := nginxLimitsInclude + "\n" + string(existing)
+			if err := os.WriteFile(defaultConfPath, []byte(upgraded), 0644); err != nil {
+				return fmt.Errorf("failed to add rate limit include to nginx default config %s: %w", defaultConfPath, err)
+			}
+			util.Log.Infof("Added rate limit include to Nginx default config: %s", defaultConfPath)
+		}
 		return nil
 	} else if !os.IsNotExist(err) {
 		return fmt.Errorf("failed to check for nginx default config %s: %w", defaultConfPath, err)
 	}
 
 	defaultContent := `
+# Project rate limit zones (limit_req_zone must be defined at the http level)
+` + nginxLimitsInclude + `
+
 server {
     listen 80 default_server;
     listen [::]:80 default_server;
@@ -243,12 +339,14 @@ func setupNginxContainer(ctx context.Context, cli *dockerClient.Client, basePath
 	// --- Prepare Container Configuration ---
 	nginxConfDir := filepath.Join(basePath, config.NginxDirName, config.NginxConfDirName)
 	nginxLogDir := filepath.Join(basePath, config.NginxDirName, config.NginxLogDirName)
+	nginxLimitsDir := filepath.Join(nginxConfDir, config.NginxLimitsDirName)
+	nginxCertsDir := filepath.Join(basePath, config.NginxDirName, config.NginxCertsDirName)
+	nginxAcmeDir := filepath.Join(basePath, config.NginxDirName, config.NginxAcmeDirName)
 
-	if err := os.MkdirAll(nginxConfDir, 0755); err != nil {
-		return fmt.Errorf("failed to ensure nginx conf dir %s: %w", nginxConfDir, err)
-	}
-	if err := os.MkdirAll(nginxLogDir, 0755); err != nil {
-		return fmt.Errorf("failed to ensure nginx log dir %s: %w", nginxLogDir, err)
+	for _, dir := range []string{nginxConfDir, nginxLimitsDir, nginxLogDir, nginxCertsDir, nginxAcmeDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to ensure nginx dir %s: %w", dir, err)
+		}
 	}
 
 	containerConfig := &container.Config{
@@ -271,11 +369,29 @@ func setupNginxContainer(ctx context.Context, cli *dockerClient.Client, basePath
 				Target:   "/etc/nginx/conf.d",
 				ReadOnly: true,
 			},
+			{
+				Type:     mount.TypeBind,
+				Source:   nginxLimitsDir,
+				Target:   config.NginxLimitsContainerPath,
+				ReadOnly: true,
+			},
 			{
 				Type:   mount.TypeBind,
 				Source: nginxLogDir,
 				Target: "/var/log/nginx",
 			},
+			{
+				Type:     mount.TypeBind,
+				Source:   nginxCertsDir,
+				Target:   config.NginxCertsContainerPath,
+				ReadOnly: true,
+			},
+			{
+				Type:     mount.TypeBind,
+				Source:   nginxAcmeDir,
+				Target:   config.NginxAcmeWebrootContainerPath,
+				ReadOnly: true,
+			},
 		},
 		RestartPolicy: container.RestartPolicy{
 			Name: "unless-stopped",
@@ -323,5 +439,7 @@ func setupNginxContainer(ctx context.Context, cli *dockerClient.Client, basePath
 }
 
 func init() {
+	initCmd.Flags().StringVar(&initSSHKeyPath, "ssh-key", "", "Path to a private SSH key used to clone and fetch private repositories")
+	initCmd.Flags().BoolVar(&initEncryptSecrets, "encrypt-secrets", false, "Encrypt project secrets at rest with a generated key (or REFLOW_SECRET_KEY)")
 	rootCmd.AddCommand(initCmd)
 }
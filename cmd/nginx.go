@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"reflow/cmd/nginx_ops"
+)
+
+// nginxCmd represents the base command for Nginx maintenance operations
+var nginxCmd = &cobra.Command{
+	Use:   "nginx",
+	Short: "Maintain the Reflow Nginx configuration",
+	Long:  `Provides subcommands to inspect and clean up the Nginx configs Reflow generates, and to view the Nginx logs.`,
+}
+
+func init() {
+	rootCmd.AddCommand(nginxCmd)
+
+	nginx_ops.AddPruneCommand(nginxCmd)
+	nginx_ops.AddLogsCommand(nginxCmd)
+}
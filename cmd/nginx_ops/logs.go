@@ -0,0 +1,73 @@
+package nginx_ops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/config"
+	"reflow/internal/nginx"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// AddLogsCommand defines the logs command and adds it to the parent command.
+func AddLogsCommand(parentCmd *cobra.Command) {
+	var projectName, env, pluginName, logType string
+	var tail int
+	var follow bool
+
+	var logsCmd = &cobra.Command{
+		Use:   "logs",
+		Short: "Show the Nginx access and error logs",
+		Long: `Shows the last lines of the Nginx logs that Reflow's Nginx container writes to the
+log directory of the base path ('nginx/logs/'):
+
+  --project <name> [--env test|prod]   <project>.<env>.access.log / .error.log
+  --plugin <name>                      plugin.<name>.access.log / .error.log
+  (neither)                            default.access.log / .error.log
+
+Both the access and the error log are shown unless --type is given. With several
+files, each line is prefixed with its file name. Use -f to keep following the logs;
+rotated or truncated files are followed from their start.
+
+Example:
+  reflow nginx logs --project my-app --env prod --type error -f`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+			if projectName != "" {
+				if _, err := config.LoadProjectConfig(reflowBasePath, projectName); err != nil {
+					return fmt.Errorf("failed to load project '%s': %w", projectName, err)
+				}
+			} else if cobraCmd.Flags().Changed("env") {
+				return fmt.Errorf("--env requires --project")
+			}
+
+			paths, err := nginx.LogFilePaths(reflowBasePath, projectName, env, pluginName, logType)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			return nginx.TailLogFiles(ctx, paths, tail, follow, os.Stdout)
+		},
+	}
+
+	logsCmd.Flags().StringVar(&projectName, "project", "", "Show the logs of this project")
+	logsCmd.Flags().StringVar(&env, "env", "test", "Environment of the project ('test' or 'prod')")
+	logsCmd.Flags().StringVar(&pluginName, "plugin", "", "Show the logs of this plugin")
+	logsCmd.Flags().StringVar(&logType, "type", nginx.LogTypeAll, "Log to show ('access', 'error' or 'all')")
+	logsCmd.Flags().IntVar(&tail, "tail", 100, "Number of lines to show from the end of each log")
+	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Follow log output")
+	logsCmd.MarkFlagsMutuallyExclusive("project", "plugin")
+
+	parentCmd.AddCommand(logsCmd)
+}
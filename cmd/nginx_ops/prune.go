@@ -0,0 +1,61 @@
+package nginx_ops
+
+import (
+	"context"
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/nginx"
+	"reflow/internal/util"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// AddPruneCommand defines the prune command and adds it to the parent command.
+func AddPruneCommand(parentCmd *cobra.Command) {
+	var dryRun bool
+
+	var pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove Nginx configs whose upstream containers no longer exist",
+		Long: `Scans the Nginx conf.d directory for configs generated by Reflow and removes
+those whose upstream containers are all gone, e.g. after a failed deploy or a
+manually removed container, then reloads Nginx. Stale configs make Nginx log
+upstream resolution failures and can make reloads fail.
+
+Stopped containers count as present, so configs of stopped projects are kept.
+Use --dry-run to list what would be removed without changing anything.`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			orphaned, err := nginx.PruneOrphanedConfigs(ctx, reflowBasePath, "", dryRun)
+			if err != nil {
+				return err
+			}
+
+			if len(orphaned) == 0 {
+				util.Log.Info("No orphaned Nginx configs found.")
+				return nil
+			}
+			if dryRun {
+				fmt.Printf("Would remove %d orphaned Nginx config(s):\n", len(orphaned))
+				for _, conf := range orphaned {
+					fmt.Printf("  %s (upstreams: %s)\n", conf.FileName, strings.Join(conf.Upstreams, ", "))
+				}
+				return nil
+			}
+			util.Log.Infof("✅ Removed %d orphaned Nginx config(s).", len(orphaned))
+			return nil
+		},
+	}
+
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "List orphaned configs without removing them")
+
+	parentCmd.AddCommand(pruneCmd)
+}
@@ -22,4 +22,8 @@ func init() {
 	plugin_ops.AddConfigCommand(pluginCmd)
 	plugin_ops.AddEnableCommand(pluginCmd)
 	plugin_ops.AddDisableCommand(pluginCmd)
+	plugin_ops.AddDoctorCommand(pluginCmd)
+	plugin_ops.AddLogsCommand(pluginCmd)
+	plugin_ops.AddRestartCommand(pluginCmd)
+	plugin_ops.AddStatusCommand(pluginCmd)
 }
@@ -3,7 +3,7 @@ package plugin_ops
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/config"
 	"reflow/internal/util"
 
@@ -24,7 +24,10 @@ func AddConfigCommand(parentCmd *cobra.Command) {
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			pluginName := args[0]
-			reflowBasePath := getBasePathFromFlags(cobraCmd)
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
 
 			pluginState, err := config.LoadGlobalPluginState(reflowBasePath)
 			if err != nil {
@@ -59,7 +62,10 @@ func AddConfigCommand(parentCmd *cobra.Command) {
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			pluginName := args[0]
-			reflowBasePath := getBasePathFromFlags(cobraCmd)
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
 
 			pluginState, err := config.LoadGlobalPluginState(reflowBasePath)
 			if err != nil {
@@ -108,26 +114,3 @@ func AddConfigCommand(parentCmd *cobra.Command) {
 	configCmd.AddCommand(editCmd)
 	parentCmd.AddCommand(configCmd)
 }
-
-// getBasePathFromFlags retrieves the base path for Reflow from the command line flags. (maybe combine with one in root cmd)
-func getBasePathFromFlags(cobraCmd *cobra.Command) string {
-	configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-	var reflowBasePath string
-	if configFlag == "" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			util.Log.Warnf("Failed to get CWD: %v. Defaulting to './reflow'", err)
-			return "reflow"
-		}
-		reflowBasePath = filepath.Join(cwd, "reflow")
-	} else {
-		absPath, err := filepath.Abs(configFlag)
-		if err != nil {
-			util.Log.Warnf("Failed to get absolute path for config flag '%s': %v. Using as is.", configFlag, err)
-			return configFlag
-		}
-		reflowBasePath = absPath
-	}
-	util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
-	return reflowBasePath
-}
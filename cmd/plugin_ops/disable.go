@@ -1,6 +1,7 @@
 package plugin_ops
 
 import (
+	"reflow/cmd/cmdutil"
 	"reflow/internal/plugin"
 	"reflow/internal/util"
 
@@ -17,11 +18,14 @@ this will attempt to stop its Docker container and remove its Nginx configuratio
 		Args: cobra.ExactArgs(1),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			pluginName := args[0]
-			reflowBasePath := getBasePathFromFlags(cobraCmd)
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
 
 			util.Log.Debugf("Attempting to disable plugin '%s' in base path '%s'", pluginName, reflowBasePath)
 
-			err := plugin.DisablePlugin(reflowBasePath, pluginName)
+			err = plugin.DisablePlugin(reflowBasePath, pluginName)
 			if err != nil {
 				return err
 			}
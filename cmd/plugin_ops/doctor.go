@@ -0,0 +1,66 @@
+package plugin_ops
+
+import (
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/plugin"
+	"reflow/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// AddDoctorCommand defines the doctor command for plugins.
+func AddDoctorCommand(parentCmd *cobra.Command) {
+	var fix bool
+
+	var doctorCmd = &cobra.Command{
+		Use:   "doctor <plugin-name>",
+		Short: "Diagnose problems with an installed plugin",
+		Long: `Checks an installed plugin for inconsistent state: install directory, metadata,
+config file, container status (for enabled container plugins) and Nginx config.
+Each failing check is reported with a suggested remediation.
+
+Use --fix to restore a missing config file, restart or recreate the plugin
+container, and regenerate its Nginx config.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			pluginName := args[0]
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			util.Log.Debugf("Running doctor for plugin '%s' in base path '%s' (fix: %v)", pluginName, reflowBasePath, fix)
+
+			checks, err := plugin.DiagnosePlugin(reflowBasePath, pluginName, fix)
+			if err != nil {
+				return err
+			}
+
+			problems := 0
+			for _, check := range checks {
+				switch {
+				case check.Fixed:
+					fmt.Printf("🔧 %-18s %s (fixed)\n", check.Name+":", check.Message)
+				case check.OK:
+					fmt.Printf("✅ %-18s %s\n", check.Name+":", check.Message)
+				default:
+					problems++
+					fmt.Printf("❌ %-18s %s\n", check.Name+":", check.Message)
+					if check.Remediation != "" {
+						fmt.Printf("   → %s\n", check.Remediation)
+					}
+				}
+			}
+
+			if problems > 0 {
+				return fmt.Errorf("plugin '%s' has %d problem(s)", pluginName, problems)
+			}
+			util.Log.Infof("Plugin '%s' looks healthy.", pluginName)
+			return nil
+		},
+	}
+
+	doctorCmd.Flags().BoolVar(&fix, "fix", false, "Attempt to repair detected problems")
+	parentCmd.AddCommand(doctorCmd)
+}
@@ -1,6 +1,7 @@
 package plugin_ops
 
 import (
+	"reflow/cmd/cmdutil"
 	"reflow/internal/plugin"
 	"reflow/internal/util"
 
@@ -17,11 +18,14 @@ this will attempt to start its Docker container and configure Nginx.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			pluginName := args[0]
-			reflowBasePath := getBasePathFromFlags(cobraCmd)
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
 
 			util.Log.Debugf("Attempting to enable plugin '%s' in base path '%s'", pluginName, reflowBasePath)
 
-			err := plugin.EnablePlugin(reflowBasePath, pluginName)
+			err = plugin.EnablePlugin(reflowBasePath, pluginName)
 			if err != nil {
 				// Specific error logged in EnablePlugin, return directly for Cobra
 				return err
@@ -1,9 +1,7 @@
 package plugin_ops
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/plugin"
 	"reflow/internal/util"
 
@@ -12,33 +10,40 @@ import (
 
 // AddInstallCommand defines the install command for plugins.
 func AddInstallCommand(parentCmd *cobra.Command) {
+	var withDeps, nonInteractive bool
+	var setValues []string
+	var valuesFile string
+
 	var installCmd = &cobra.Command{
 		Use:   "install <git-repo-url>",
 		Short: "Install a new plugin from a Git repository",
 		Long: `Clones the specified Git repository, parses the plugin metadata (reflow-plugin.yaml),
-runs any defined setup prompts, and registers the plugin with Reflow.`,
+runs any defined setup prompts, and registers the plugin with Reflow.
+
+Plugins listed under 'dependencies' in the metadata must already be installed (at
+the required minimum version). Use --with-deps to install missing ones first, from
+the 'repo' each dependency declares.
+
+Setup prompts can be answered up front with --values-file (a YAML file of
+'key: value' pairs) and --set key=value, which takes precedence. Prompts covered
+by a provided value are not asked. With --non-interactive, nothing is read from
+stdin: remaining prompts use their defaults, and installation fails if a required
+prompt has neither a provided value nor a default.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			repoURL := args[0]
 
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var pathErr error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, pathErr = filepath.Abs(configFlag)
-				if pathErr != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", pathErr)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			opts := plugin.InstallOptions{WithDeps: withDeps, NonInteractive: nonInteractive}
+			if opts.Values, err = plugin.SetupValues(valuesFile, setValues); err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
-			err := plugin.InstallPlugin(reflowBasePath, repoURL)
+			err = plugin.InstallPlugin(reflowBasePath, repoURL, opts)
 			if err != nil {
 				util.Log.Errorf("Plugin installation failed: %v", err)
 				return err
@@ -48,5 +53,9 @@ runs any defined setup prompts, and registers the plugin with Reflow.`,
 		},
 	}
 
+	installCmd.Flags().BoolVar(&withDeps, "with-deps", false, "Install missing plugin dependencies first")
+	installCmd.Flags().StringArrayVar(&setValues, "set", nil, "Answer a setup prompt (key=value); can be repeated")
+	installCmd.Flags().StringVar(&valuesFile, "values-file", "", "YAML file with answers to setup prompts")
+	installCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Do not prompt; fail if a required value is missing")
 	parentCmd.AddCommand(installCmd)
 }
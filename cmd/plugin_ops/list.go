@@ -3,7 +3,7 @@ package plugin_ops
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/plugin"
 	"reflow/internal/util"
 	"text/tabwriter"
@@ -20,22 +20,10 @@ func AddListCommand(parentCmd *cobra.Command) {
 		Aliases: []string{"ls"},
 		Args:    cobra.NoArgs,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var pathErr error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, pathErr = filepath.Abs(configFlag)
-				if pathErr != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", pathErr)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
 			plugins, err := plugin.ListInstalledPlugins(reflowBasePath)
 			if err != nil {
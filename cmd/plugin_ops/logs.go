@@ -0,0 +1,44 @@
+package plugin_ops
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/plugin"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// AddLogsCommand defines the logs command for plugins.
+func AddLogsCommand(parentCmd *cobra.Command) {
+	var follow bool
+	var tail string
+
+	var logsCmd = &cobra.Command{
+		Use:   "logs <plugin-name>",
+		Short: "Show logs for a container plugin",
+		Long: `Displays the logs of an enabled container plugin's Docker container. Allows
+following logs in real-time and specifying the number of tail lines. When the
+container is stopped its last logs are shown; it cannot be followed until it runs again.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			pluginName := args[0]
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			return plugin.StreamPluginLogs(ctx, reflowBasePath, pluginName, follow, tail, os.Stdout)
+		},
+	}
+
+	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Follow log output")
+	logsCmd.Flags().StringVar(&tail, "tail", "100", "Number of lines to show from the end of the logs")
+
+	parentCmd.AddCommand(logsCmd)
+}
@@ -0,0 +1,32 @@
+package plugin_ops
+
+import (
+	"reflow/cmd/cmdutil"
+	"reflow/internal/plugin"
+	"reflow/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// AddRestartCommand defines the restart command for plugins.
+func AddRestartCommand(parentCmd *cobra.Command) {
+	var restartCmd = &cobra.Command{
+		Use:   "restart <plugin-name>",
+		Short: "Restart a container plugin",
+		Long: `Stops and starts an enabled container plugin's Docker container. If the
+container no longer exists, it is recreated from the plugin's saved config values
+and its new container ID is recorded in plugins.json.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			pluginName := args[0]
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			util.Log.Debugf("Attempting to restart plugin '%s' in base path '%s'", pluginName, reflowBasePath)
+			return plugin.RestartPlugin(reflowBasePath, pluginName)
+		},
+	}
+	parentCmd.AddCommand(restartCmd)
+}
@@ -0,0 +1,118 @@
+package plugin_ops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/config"
+	"reflow/internal/plugin"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// AddStatusCommand defines the status command for plugins.
+func AddStatusCommand(parentCmd *cobra.Command) {
+	var jsonOutput bool
+
+	var statusCmd = &cobra.Command{
+		Use:   "status <plugin-name>",
+		Short: "Show the live status of an installed plugin",
+		Long: `Displays an installed plugin's metadata, enabled flag, container state, uptime
+and resource usage (for container plugins) or install path and registered commands
+(for CLI plugins), Nginx config file, effective domain and config values. Values
+of config keys containing "token", "secret" or "password" are masked.
+
+A container that was removed outside Reflow is reported as missing rather than
+failing the command; 'reflow plugin restart' recreates it. Use --json for
+machine-readable output.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			pluginName := args[0]
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			status, err := plugin.GetPluginStatus(context.Background(), reflowBasePath, pluginName)
+			if err != nil {
+				return fmt.Errorf("failed to get status for plugin '%s': %w", pluginName, err)
+			}
+
+			if jsonOutput {
+				encoded, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode status as JSON: %w", err)
+				}
+				fmt.Println(string(encoded))
+				return nil
+			}
+
+			// --- Print Status ---
+			fmt.Printf("Plugin Status: %s\n", status.Name)
+			fmt.Printf("  Display Name: %s\n", status.DisplayName)
+			fmt.Printf("  Version:      %s\n", status.Version)
+			fmt.Printf("  Type:         %s\n", status.Type)
+			fmt.Printf("  Enabled:      %v\n", status.Enabled)
+
+			if status.Type == config.PluginTypeContainer {
+				fmt.Println("---")
+				fmt.Printf("  Container:    %s\n", status.ContainerName)
+				if status.ContainerID != "" {
+					fmt.Printf("  Container ID: %s\n", status.ContainerID)
+				}
+				fmt.Printf("  State:        %s\n", status.ContainerStatus)
+				if status.Uptime != "" {
+					fmt.Printf("  Uptime:       %s\n", status.Uptime)
+					fmt.Printf("  Usage:        %.1f%% CPU, %.1f MB memory\n", status.CPUPercent, status.MemoryMB)
+				}
+
+				nginxConf := "missing"
+				if status.NginxConfigFile {
+					nginxConf = fmt.Sprintf("present (modified %s)", status.NginxConfigTime.Local().Format("2006-01-02 15:04:05"))
+				}
+				if status.NginxConfigOk && !status.NginxConfigFile {
+					nginxConf += " (expected based on state!)"
+				}
+				fmt.Printf("  Nginx Config: %s, %s\n", status.NginxConfigPath, nginxConf)
+				if status.EffectiveDomain != "" {
+					fmt.Printf("  Domain:       %s\n", status.EffectiveDomain)
+				}
+				if status.AccessURL != "" {
+					fmt.Printf("  Access URL:   %s\n", status.AccessURL)
+				}
+			}
+
+			if status.Type == config.PluginTypeCLI {
+				fmt.Println("---")
+				fmt.Printf("  Install Path: %s\n", status.InstallPath)
+				commands := "(none)"
+				if len(status.Commands) > 0 {
+					commands = strings.Join(status.Commands, ", ")
+				}
+				fmt.Printf("  Commands:     %s\n", commands)
+			}
+
+			fmt.Println("---")
+			if len(status.ConfigValues) == 0 {
+				fmt.Println("  Config:       (none)")
+				return nil
+			}
+			fmt.Println("  Config:")
+			keys := make([]string, 0, len(status.ConfigValues))
+			for key := range status.ConfigValues {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fmt.Printf("    %s: %s\n", key, status.ConfigValues[key])
+			}
+			return nil
+		},
+	}
+
+	statusCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output status as JSON")
+	parentCmd.AddCommand(statusCmd)
+}
@@ -1,9 +1,7 @@
 package plugin_ops
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/plugin"
 	"reflow/internal/util"
 
@@ -12,34 +10,28 @@ import (
 
 // AddUninstallCommand defines the uninstall command for plugins.
 func AddUninstallCommand(parentCmd *cobra.Command) {
+	var keepData bool
+	var removeData bool
+
 	var uninstallCmd = &cobra.Command{
 		Use:   "uninstall <plugin-name>",
 		Short: "Uninstall a Reflow plugin",
 		Long: `Removes the specified plugin from Reflow. This includes stopping and removing
 any associated Docker containers, removing Nginx configurations, deleting the
-plugin's files, and updating the Reflow state.`,
+plugin's files, and updating the Reflow state.
+
+Persistent data (the plugin's named volumes and its directory under 'plugin-data/')
+is kept by default so a reinstall picks it up again. Pass --remove-data to delete it.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			pluginName := args[0]
 
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var pathErr error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, pathErr = filepath.Abs(configFlag)
-				if pathErr != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", pathErr)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
-			err := plugin.UninstallPlugin(reflowBasePath, pluginName)
+			err = plugin.UninstallPlugin(reflowBasePath, pluginName, removeData || !keepData)
 			if err != nil {
 				util.Log.Errorf("Plugin uninstallation failed: %v", err)
 				return err
@@ -49,5 +41,9 @@ plugin's files, and updating the Reflow state.`,
 		},
 	}
 
+	uninstallCmd.Flags().BoolVar(&keepData, "keep-data", true, "Keep the plugin's volumes and data directory (default)")
+	uninstallCmd.Flags().BoolVar(&removeData, "remove-data", false, "Delete the plugin's volumes and data directory")
+	uninstallCmd.MarkFlagsMutuallyExclusive("keep-data", "remove-data")
+
 	parentCmd.AddCommand(uninstallCmd)
 }
@@ -21,6 +21,21 @@ func init() {
 	project_ops.AddStopCommand(projectCmd)
 	project_ops.AddStartCommand(projectCmd)
 	project_ops.AddLogsCommand(projectCmd)
+	project_ops.AddExecCommand(projectCmd)
+	project_ops.AddStatsCommand(projectCmd)
 	project_ops.AddCleanupCommand(projectCmd)
 	project_ops.AddConfigCommand(projectCmd)
+	project_ops.AddSecretsCommand(projectCmd)
+	project_ops.AddRenameCommand(projectCmd)
+	project_ops.AddHistoryCommand(projectCmd)
+	project_ops.AddTailDeployCommand(projectCmd)
+	project_ops.AddMaintenanceCommand(projectCmd)
+	project_ops.AddEnvDiffCommand(projectCmd)
+	project_ops.AddEnvCommand(projectCmd)
+	project_ops.AddImportCommand(projectCmd)
+	project_ops.AddGenerateKeyCommand(projectCmd)
+	project_ops.AddScaleCommand(projectCmd)
+	project_ops.AddDuplicateCommand(projectCmd)
+	project_ops.AddBackupCommand(projectCmd)
+	project_ops.AddRestoreCommand(projectCmd)
 }
@@ -0,0 +1,89 @@
+package project_ops
+
+import (
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/project"
+	"reflow/internal/util"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// AddBackupCommand defines the backup command and adds it to the parent command.
+func AddBackupCommand(parentCmd *cobra.Command) {
+	var output string
+
+	var backupCmd = &cobra.Command{
+		Use:   "backup <project-name>",
+		Short: "Archives a project's config, state and env files",
+		Long: `Writes a tar.gz archive with the project's config.yaml, state.json, deployments.log
+and the env files of both environments, plus a manifest recording the repository URL
+(or local source directory) and the active commits. Restore it with
+'reflow project restore'.
+
+The repository is not archived; it is cloned again on restore. Secrets, the project's
+git credential and its deploy key are not archived either, so add them again after
+restoring if the project uses them. The archive contains the env files as they are,
+so keep it somewhere safe.
+
+The archive is written to <project-name>-backup-<timestamp>.tar.gz in the current
+directory unless --output is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			outputPath := output
+			if outputPath == "" {
+				outputPath = fmt.Sprintf("%s-backup-%s.tar.gz", projectName, time.Now().Format("20060102-150405"))
+			}
+			return project.BackupProject(reflowBasePath, projectName, outputPath)
+		},
+	}
+
+	backupCmd.Flags().StringVarP(&output, "output", "o", "", "Path of the archive to write (default <project-name>-backup-<timestamp>.tar.gz)")
+
+	parentCmd.AddCommand(backupCmd)
+}
+
+// AddRestoreCommand defines the restore command and adds it to the parent command.
+func AddRestoreCommand(parentCmd *cobra.Command) {
+	var restoreCmd = &cobra.Command{
+		Use:   "restore <archive-path>",
+		Short: "Recreates a project from a backup archive",
+		Long: `Recreates a project from an archive written by 'reflow project backup'. The project
+must not exist yet. Its config, state and deployment log are restored as archived, the
+repository is cloned again (or copied from the local source directory) using the
+global git authentication, and the env files are written into it.
+
+No containers are started. If the archived deployments are not running on this host,
+deploy the recorded commits again; 'reflow reconcile <project-name>' shows how the
+restored state compares with the running containers.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			manifest, err := project.RestoreProject(reflowBasePath, args[0])
+			if err != nil {
+				return err
+			}
+			if manifest.TestCommit != "" {
+				util.Log.Infof("Archived active test commit: %s", manifest.TestCommit)
+			}
+			if manifest.ProdCommit != "" {
+				util.Log.Infof("Archived active prod commit: %s", manifest.ProdCommit)
+			}
+			util.Log.Infof("Next step: Check the project with 'reflow reconcile %s'.", manifest.ProjectName)
+			return nil
+		},
+	}
+
+	parentCmd.AddCommand(restoreCmd)
+}
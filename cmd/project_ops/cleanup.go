@@ -3,11 +3,12 @@ package project_ops
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/nginx"
 	"reflow/internal/orchestrator"
 	"reflow/internal/util"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -16,37 +17,52 @@ import (
 func AddCleanupCommand(parentCmd *cobra.Command) {
 	var env string
 	var pruneImages bool
+	var stopTimeout int
+	var allProjects bool
 
 	var cleanupCmd = &cobra.Command{
-		Use:   "cleanup <project-name>",
+		Use:   "cleanup [project-name]",
 		Short: "Removes inactive containers and optionally images for a project",
 		Long: `Cleans up resources associated with inactive deployments for a project.
 Specifically, it finds and removes Docker containers that do not correspond
 to the currently active deployment slot and commit hash in the specified environment(s).
 
 Use the --prune-images flag cautiously to also remove Docker images associated
-with commits that are no longer active in either 'test' or 'prod' for this project.`,
-		Args: cobra.ExactArgs(1),
+with commits that are no longer active in either 'test' or 'prod' for this project.
+The project's 'keepImages' most recently built images (default 3) are kept for rollbacks.
+
+Inactive containers are sent SIGTERM and given a grace period (--stop-timeout, or the
+project's 'stopTimeout' setting, default 10s) before being killed with SIGKILL.
+Forced kills are recorded in the project's deployment history.
+
+The project's Nginx configs are also checked, and any whose upstream containers no
+longer exist (see 'reflow nginx prune') are removed.
+
+With --all-projects, no project name is given: the images of every project's commits
+that are no longer active in either 'test' or 'prod' are removed (except each project's
+'keepImages' most recent ones, see 'reflow images prune'), without asking for
+confirmation, and the reclaimed disk space is reported. Containers and Nginx configs
+are left alone in this mode.`,
+		Args: func(cobraCmd *cobra.Command, args []string) error {
+			if allProjects {
+				return cobra.NoArgs(cobraCmd, args)
+			}
+			return cobra.ExactArgs(1)(cobraCmd, args)
+		},
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
-			projectName := args[0]
 			ctx := context.Background()
 
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var pathErr error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, pathErr = filepath.Abs(configFlag)
-				if pathErr != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", pathErr)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			if allProjects {
+				result, err := orchestrator.PruneAllProjectImages(ctx, reflowBasePath, -1)
+				fmt.Printf("Pruned %d image(s) across %d project(s), reclaiming about %.1fMiB.\n", result.PrunedImages, result.Projects, float64(result.ReclaimedBytes)/(1024*1024))
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
+			projectName := args[0]
 
 			var targetEnvs []string
 			switch strings.ToLower(env) {
@@ -66,7 +82,7 @@ with commits that are no longer active in either 'test' or 'prod' for this proje
 			totalCleanedContainers := 0
 
 			for _, targetEnv := range targetEnvs {
-				cleanedCount, err := orchestrator.CleanupProjectEnv(ctx, reflowBasePath, projectName, targetEnv)
+				cleanedCount, err := orchestrator.CleanupProjectEnv(ctx, reflowBasePath, projectName, targetEnv, time.Duration(stopTimeout)*time.Second)
 				totalCleanedContainers += cleanedCount
 				if err != nil {
 					util.Log.Errorf("Error cleaning project '%s' env '%s': %v", projectName, targetEnv, err)
@@ -78,6 +94,16 @@ with commits that are no longer active in either 'test' or 'prod' for this proje
 				}
 			}
 
+			orphanedConfigs, err := nginx.PruneOrphanedConfigs(ctx, reflowBasePath, projectName, false)
+			if err != nil {
+				util.Log.Errorf("Error pruning orphaned Nginx configs for project '%s': %v", projectName, err)
+				if finalErr == nil {
+					finalErr = fmt.Errorf("error pruning nginx configs: %w", err)
+				} else {
+					finalErr = fmt.Errorf("%w; error pruning nginx configs: %v", finalErr, err)
+				}
+			}
+
 			totalPrunedImages := 0
 			if pruneImages {
 				prunedCount, err := orchestrator.PruneProjectImages(ctx, reflowBasePath, projectName)
@@ -92,14 +118,16 @@ with commits that are no longer active in either 'test' or 'prod' for this proje
 				}
 			}
 
-			util.Log.Infof("Cleanup summary for '%s': Removed %d container(s), Pruned %d image(s).", projectName, totalCleanedContainers, totalPrunedImages)
+			util.Log.Infof("Cleanup summary for '%s': Removed %d container(s), %d orphaned Nginx config(s), Pruned %d image(s).", projectName, totalCleanedContainers, len(orphanedConfigs), totalPrunedImages)
 
 			return finalErr
 		},
 	}
 
 	cleanupCmd.Flags().StringVar(&env, "env", "all", "Specify environment for container cleanup ('test', 'prod', or 'all')")
+	cleanupCmd.Flags().IntVar(&stopTimeout, "stop-timeout", 0, "Seconds to wait after SIGTERM before killing a container (0 uses the project setting)")
 	cleanupCmd.Flags().BoolVar(&pruneImages, "prune-images", false, "Also remove docker images for inactive commits (use with caution)")
+	cleanupCmd.Flags().BoolVar(&allProjects, "all-projects", false, "Remove inactive commits' images of every project instead of cleaning up one project")
 
 	parentCmd.AddCommand(cleanupCmd)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/config"
 	"reflow/internal/util"
 
@@ -29,20 +30,9 @@ func AddConfigCommand(parentCmd *cobra.Command) {
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			projectName := args[0]
 
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var pathErr error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, pathErr = filepath.Abs(configFlag)
-				if pathErr != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", pathErr)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
 
 			projectBasePath := config.GetProjectBasePath(reflowBasePath, projectName)
@@ -76,20 +66,9 @@ in the editor specified by the $EDITOR environment variable (or a common default
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			projectName := args[0]
 
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var pathErr error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, pathErr = filepath.Abs(configFlag)
-				if pathErr != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", pathErr)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
 
 			projectBasePath := config.GetProjectBasePath(reflowBasePath, projectName)
@@ -101,7 +80,7 @@ in the editor specified by the $EDITOR environment variable (or a common default
 				return fmt.Errorf("error checking config file %s: %w", configFilePath, err)
 			}
 
-			err := util.OpenFileInEditor(configFilePath)
+			err = util.OpenFileInEditor(configFilePath)
 			if err != nil {
 				return fmt.Errorf("failed to edit configuration file")
 			}
@@ -2,8 +2,8 @@ package project_ops
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/project"
 
 	"github.com/spf13/cobra"
@@ -19,50 +19,89 @@ func AddCreateCommand(parentCmd *cobra.Command) {
 	var nodeVersion string
 	var testEnvFile string
 	var prodEnvFile string
+	var shallow bool
+	var gitToken string
+	var gitUsername string
+	var localPath string
+	var sshKey string
 
 	var createCmd = &cobra.Command{
-		Use:   "create <project-name> <github-repo-url>",
+		Use:   "create <project-name> [github-repo-url]",
 		Short: "Create and initialize a new project in Reflow",
-		Long: `Clones the specified Git repository and sets up the necessary configuration
-files and directories for a new Reflow project. Uses defaults for port (3000),
+		Long: `Clones the specified Git repository (or copies a local directory given with
+--local-path) and sets up the necessary configuration files and directories for a new Reflow project. Uses defaults for port (3000),
 node version (18-alpine), and env files (.env.development/.env.production) unless overridden by flags.
 
 Example:
   reflow project create my-blog git@github.com:user/my-blog.git
-  reflow project create my-app https://github.com/user/my-app.git --test-domain test.myapp.com --app-port 8080`,
-		Args: cobra.ExactArgs(2),
+  reflow project create my-app https://github.com/user/my-app.git --test-domain test.myapp.com --app-port 8080
+  reflow project create big-repo git@github.com:user/big-repo.git --shallow
+  reflow project create private-app https://github.com/user/private-app.git --git-token -
+  reflow project create ci-app --local-path ./build/checkout
+
+For private HTTPS repositories, --git-token stores an access token for this project
+(0600, outside the repository clone). Pass '-' to enter it at a prompt or pipe it on stdin.
+For SSH repositories that need a key other than the global one, pass it with --ssh-key;
+it is recorded in the project config and used for later fetches too.
+
+Local projects are re-copied from --local-path on every 'reflow deploy' and are
+identified by a hash of their content instead of a commit.`,
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			projectName := args[0]
-			repoURL := args[1]
+			repoURL := ""
+			if len(args) > 1 {
+				repoURL = args[1]
+			}
+			sourceType := config.SourceTypeGit
+			if localPath != "" {
+				if repoURL != "" {
+					return fmt.Errorf("specify either a repository URL or --local-path, not both")
+				}
+				sourceType = config.SourceTypeLocal
+			} else if repoURL == "" {
+				return fmt.Errorf("a repository URL is required unless --local-path is set")
+			}
 
 			// --- Get Base Path ---
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var err error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			if gitToken == "-" {
+				token, err := util.ReadSecretInput("Git access token: ")
 				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
+					return err
 				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, err = filepath.Abs(configFlag)
-				if err != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", err)
+				gitToken = token
+			}
+			if cobraCmd.Flags().Changed("git-token") && gitToken == "" {
+				return fmt.Errorf("--git-token must not be empty")
+			}
+
+			if sshKey != "" {
+				if sshKey, err = filepath.Abs(sshKey); err != nil {
+					return fmt.Errorf("failed to get absolute path for --ssh-key: %w", err)
 				}
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
 			// --- Prepare Args ---
 			createArgs := config.CreateProjectArgs{
 				ProjectName: projectName,
 				RepoURL:     repoURL,
+				SourceType:  sourceType,
+				LocalPath:   localPath,
 				TestDomain:  testDomain,
 				ProdDomain:  prodDomain,
 				AppPort:     appPort,
 				NodeVersion: nodeVersion,
 				TestEnvFile: testEnvFile,
 				ProdEnvFile: prodEnvFile,
+				Shallow:     shallow,
+				GitToken:    gitToken,
+				GitUsername: gitUsername,
+				SSHKeyPath:  sshKey,
 			}
 
 			// --- Call Core Logic ---
@@ -81,6 +120,12 @@ Example:
 	createCmd.Flags().StringVar(&nodeVersion, "node-version", "", "Node.js version for Docker image (default: 18-alpine)")
 	createCmd.Flags().StringVar(&testEnvFile, "test-env-file", "", "Relative path to the test env file (default: .env.development)")
 	createCmd.Flags().StringVar(&prodEnvFile, "prod-env-file", "", "Relative path to the prod env file (default: .env.production)")
+	createCmd.Flags().StringVar(&localPath, "local-path", "", "Deploy from a local directory instead of a Git repository")
+	createCmd.Flags().BoolVar(&shallow, "shallow", false, "Perform a shallow clone (depth 1) to speed up creation of large repositories")
+
+	createCmd.Flags().StringVar(&gitToken, "git-token", "", "Access token for cloning a private repository over HTTPS ('-' to read it from stdin)")
+	createCmd.Flags().StringVar(&gitUsername, "git-username", "", "Username sent with --git-token (default: git)")
+	createCmd.Flags().StringVar(&sshKey, "ssh-key", "", "Private SSH key for this project's repository, instead of the global sshKeyPath")
 
 	parentCmd.AddCommand(createCmd)
 }
@@ -0,0 +1,73 @@
+package project_ops
+
+import (
+	"context"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/git"
+	"reflow/internal/orchestrator"
+	"reflow/internal/project"
+	"reflow/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// AddDuplicateCommand defines the duplicate command and adds it to the parent command.
+func AddDuplicateCommand(parentCmd *cobra.Command) {
+	var branch string
+	var testDomain string
+	var deploy bool
+
+	var duplicateCmd = &cobra.Command{
+		Use:   "duplicate <source-project> <new-name>",
+		Short: "Creates a new project from the config of an existing one",
+		Long: `Creates a new project with the config of an existing project, e.g. to run a feature
+branch next to the main deployment. The repository is cloned afresh (local projects
+are copied again from their source directory), and the source's git credential,
+deploy key and secrets are copied. The new project starts with no deployments.
+
+Domains are not copied: the 'test' environment uses --test-domain, or the default
+domain for the new name, and 'prod' uses the default domain. Images are tagged with
+the new project name, so pruning either project does not affect the other.
+
+With --deploy, --branch (or the repository's default branch) is deployed to the new
+project's 'test' environment right away.
+
+Example:
+  reflow project duplicate my-app my-app-feature-x --branch feature-x --test-domain feat.example.com --deploy`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			sourceName := args[0]
+			newName := args[1]
+			ctx := context.Background()
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			if err := project.DuplicateProject(reflowBasePath, sourceName, newName, testDomain, branch); err != nil {
+				return err
+			}
+			if !deploy {
+				util.Log.Infof("Next step: Deploy the project using 'reflow deploy %s'.", newName)
+				return nil
+			}
+
+			commitIsh := "HEAD"
+			if branch != "" {
+				commitIsh = git.RemoteBranchRevision(branch)
+			}
+			if _, err := orchestrator.DeployTest(ctx, reflowBasePath, newName, commitIsh); err != nil {
+				util.Log.Errorf("Deployment failed: %v", err)
+				return err
+			}
+			return nil
+		},
+	}
+
+	duplicateCmd.Flags().StringVar(&branch, "branch", "", "Branch to deploy; also set as the new project's webhook deploy branch")
+	duplicateCmd.Flags().StringVar(&testDomain, "test-domain", "", "Domain for the new project's 'test' environment (e.g., feat.example.com)")
+	duplicateCmd.Flags().BoolVar(&deploy, "deploy", false, "Deploy --branch to the new project's 'test' environment after creating it")
+
+	parentCmd.AddCommand(duplicateCmd)
+}
@@ -0,0 +1,207 @@
+package project_ops
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/config"
+	"reflow/internal/git"
+	"reflow/internal/orchestrator"
+	"reflow/internal/util"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// AddEnvCommand defines the 'env' parent command and its subcommands.
+func AddEnvCommand(parentCmd *cobra.Command) {
+	var env string
+
+	// --- Parent 'env' Command ---
+	var envCmd = &cobra.Command{
+		Use:   "env",
+		Short: "Manage the env file variables of a project",
+		Long: `Provides subcommands to read and change single variables in the env file configured
+for a project environment (environments.<env>.envFile, in the project's repository
+directory), and to check the environment's required variables.
+
+set and unset rewrite only the lines of the given variables; comments and the order
+of other lines are kept. For values that should not live in the repository, use
+'reflow project secrets' instead.`,
+	}
+	envCmd.PersistentFlags().StringVar(&env, "env", "test", "Specify environment ('test' or 'prod')")
+
+	// --- 'env get' Subcommand ---
+	var getCmd = &cobra.Command{
+		Use:   "get <project-name> KEY",
+		Short: "Print the value of a variable",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName, key := args[0], args[1]
+			_, envFilePath, _, err := resolveEnvFile(cobraCmd, projectName, env)
+			if err != nil {
+				return err
+			}
+
+			value, found, err := config.GetEnvFileVar(envFilePath, key)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("%s is not set in %s", key, envFilePath)
+			}
+			fmt.Println(value)
+			return nil
+		},
+	}
+
+	// --- 'env set' Subcommand ---
+	var setCmd = &cobra.Command{
+		Use:   "set <project-name> KEY=VALUE...",
+		Short: "Add or update variables",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+			reflowBasePath, envFilePath, projCfg, err := resolveEnvFile(cobraCmd, projectName, env)
+			if err != nil {
+				return err
+			}
+
+			if err := config.SetEnvFileVars(envFilePath, args[1:]); err != nil {
+				return err
+			}
+			util.Log.Infof("Set %d variable(s) in %s.", len(args)-1, envFilePath)
+			warnEnvFileNotDeployed(reflowBasePath, projCfg, env)
+			return nil
+		},
+	}
+
+	// --- 'env unset' Subcommand ---
+	var unsetCmd = &cobra.Command{
+		Use:   "unset <project-name> KEY...",
+		Short: "Remove variables",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+			reflowBasePath, envFilePath, projCfg, err := resolveEnvFile(cobraCmd, projectName, env)
+			if err != nil {
+				return err
+			}
+
+			removed, err := config.UnsetEnvFileVars(envFilePath, args[1:])
+			if err != nil {
+				return err
+			}
+			if len(removed) == 0 {
+				util.Log.Warnf("None of the given variables are set in %s.", envFilePath)
+				return nil
+			}
+			util.Log.Infof("Removed %s from %s.", strings.Join(removed, ", "), envFilePath)
+			warnEnvFileNotDeployed(reflowBasePath, projCfg, env)
+			return nil
+		},
+	}
+
+	// --- 'env check' Subcommand ---
+	var checkCmd = &cobra.Command{
+		Use:   "check <project-name>",
+		Short: "Check that the required environment variables are set",
+		Long: `Checks the environment's env file, as currently in the project's repository
+directory, and its secrets against 'environments.<env>.requiredEnv' in the project
+config. Entries are a variable name, which must be set to a non-empty value, or
+KEY=regex, whose value must also match the regular expression as a whole:
+
+  environments:
+    prod:
+      requiredEnv:
+        - DATABASE_URL=postgres://.+
+        - SMTP_PORT=[0-9]+
+        - SESSION_SECRET
+
+With environments.<env>.interpolate set, values in the env file may refer to other
+variables as ${NAME}, resolved from earlier lines of the file, then the environment's
+secrets, then the built-ins PROJECT, ENV, DOMAIN and SLOT (SLOT is empty here, as no
+deployment slot is chosen yet). Write "$$" for a literal '$'. References to undefined
+or later variables are errors.
+
+Deployments and approvals run the same check before building or starting anything.
+Exits with an error if a variable is missing, empty or malformed, so it can be used in CI.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+			if env != "test" && env != "prod" {
+				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			problems, err := orchestrator.CheckRequiredEnv(reflowBasePath, projectName, env)
+			if err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				for _, problem := range problems {
+					fmt.Printf("  - %s\n", problem)
+				}
+				return fmt.Errorf("%d required environment variable(s) for project '%s' (%s) are not set correctly", len(problems), projectName, env)
+			}
+
+			projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+			if err != nil {
+				return err
+			}
+			if len(projCfg.Environments[env].RequiredEnv) == 0 {
+				util.Log.Infof("Project '%s' declares no required variables for '%s' (environments.%s.requiredEnv).", projectName, env, env)
+				return nil
+			}
+			util.Log.Infof("✅ All %d required variable(s) for project '%s' (%s) are set.", len(projCfg.Environments[env].RequiredEnv), projectName, env)
+			return nil
+		},
+	}
+
+	envCmd.AddCommand(getCmd)
+	envCmd.AddCommand(setCmd)
+	envCmd.AddCommand(unsetCmd)
+	envCmd.AddCommand(checkCmd)
+	parentCmd.AddCommand(envCmd)
+}
+
+// resolveEnvFile returns the base path, the path of a project environment's env file and the
+// project config, for the env subcommands.
+func resolveEnvFile(cobraCmd *cobra.Command, projectName, env string) (string, string, *config.ProjectConfig, error) {
+	if env != "test" && env != "prod" {
+		return "", "", nil, fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+	}
+	reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+	if err != nil {
+		return "", "", nil, err
+	}
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to load project '%s': %w", projectName, err)
+	}
+	envFilePath, err := config.ProjectEnvFilePath(reflowBasePath, projCfg, env)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return reflowBasePath, envFilePath, projCfg, nil
+}
+
+// warnEnvFileNotDeployed warns when a change to the env file in the project directory will not
+// reach deployments: local projects are re-copied from their source directory, and env files
+// tracked in git are read as committed.
+func warnEnvFileNotDeployed(reflowBasePath string, projCfg *config.ProjectConfig, env string) {
+	if projCfg.SourceType == config.SourceTypeLocal {
+		util.Log.Warnf("Project '%s' is re-copied from %s on every deploy; make the change there for it to last.", projCfg.ProjectName, projCfg.LocalPath)
+		return
+	}
+	repoPath := filepath.Join(config.GetProjectBasePath(reflowBasePath, projCfg.ProjectName), config.RepoDirName)
+	if _, err := git.ReadFileAtCommit(repoPath, "HEAD", projCfg.Environments[env].EnvFile); err == nil {
+		util.Log.Warnf("%s is tracked in git, and deployments read it as committed; commit the change for it to be deployed.", projCfg.Environments[env].EnvFile)
+		return
+	}
+	util.Log.Info("The change takes effect on the next deployment.")
+}
@@ -0,0 +1,100 @@
+package project_ops
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/app"
+	"reflow/internal/config"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// AddEnvDiffCommand defines the env-diff command and adds it to the parent command.
+func AddEnvDiffCommand(parentCmd *cobra.Command) {
+	var reveal bool
+	var jsonOutput bool
+
+	var envDiffCmd = &cobra.Command{
+		Use:   "env-diff <project-name>",
+		Short: "Show differences between a project's test and prod env files",
+		Long: `Compares the env files configured for the test and prod environments and lists the
+variables only set in one of them and those set in both with different values. Values
+are masked unless --reveal is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			diff, err := app.DiffEnvFiles(reflowBasePath, projectName)
+			if err != nil {
+				return fmt.Errorf("failed to compare env files of project '%s': %w", projectName, err)
+			}
+			if !reveal {
+				for i := range diff.Changed {
+					diff.Changed[i].TestValue = config.MaskSecretValue(diff.Changed[i].TestValue)
+					diff.Changed[i].ProdValue = config.MaskSecretValue(diff.Changed[i].ProdValue)
+				}
+			}
+
+			if jsonOutput {
+				encoded, err := json.MarshalIndent(diff, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode env diff as JSON: %w", err)
+				}
+				fmt.Println(string(encoded))
+				return nil
+			}
+
+			fmt.Printf("Test env file: %s\n", orNone(diff.TestEnvFile))
+			fmt.Printf("Prod env file: %s\n", orNone(diff.ProdEnvFile))
+			if diff.Empty() {
+				fmt.Println("\nNo differences: test and prod define the same variables with the same values.")
+				return nil
+			}
+
+			if len(diff.OnlyInTest) > 0 {
+				fmt.Printf("\nOnly in test (%d):\n", len(diff.OnlyInTest))
+				for _, key := range diff.OnlyInTest {
+					fmt.Printf("  + %s\n", key)
+				}
+			}
+			if len(diff.OnlyInProd) > 0 {
+				fmt.Printf("\nOnly in prod (%d):\n", len(diff.OnlyInProd))
+				for _, key := range diff.OnlyInProd {
+					fmt.Printf("  - %s\n", key)
+				}
+			}
+			if len(diff.Changed) > 0 {
+				fmt.Printf("\nDifferent values (%d):\n", len(diff.Changed))
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+				fmt.Fprintln(w, "  KEY\tTEST\tPROD")
+				fmt.Fprintln(w, "  ---\t----\t----")
+				for _, change := range diff.Changed {
+					fmt.Fprintf(w, "  %s\t%s\t%s\n", change.Key, change.TestValue, change.ProdValue)
+				}
+				return w.Flush()
+			}
+			return nil
+		},
+	}
+
+	envDiffCmd.Flags().BoolVar(&reveal, "reveal", false, "Show differing values in plain text")
+	envDiffCmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the differences as JSON")
+
+	parentCmd.AddCommand(envDiffCmd)
+}
+
+// orNone returns s, or "(none configured)" if it is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "(none configured)"
+	}
+	return s
+}
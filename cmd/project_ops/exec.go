@@ -0,0 +1,93 @@
+package project_ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/app"
+	"reflow/internal/util"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// AddExecCommand defines the exec command and adds it to the parent command.
+func AddExecCommand(parentCmd *cobra.Command) {
+	var env string
+	var interactive bool
+
+	var execCmd = &cobra.Command{
+		Use:   "exec <project-name> [flags] -- <command> [args...]",
+		Short: "Run a command inside the active container of a project environment",
+		Long: `Runs a command inside the container serving the currently active deployment for
+the specified project and environment, like 'docker exec'. Everything after '--'
+is passed to the container untouched. When the slot runs multiple replicas, the
+command runs in the first running replica.
+
+Use --interactive (-i) to attach stdin and allocate a TTY, e.g. for a shell:
+  reflow project exec my-app --env prod -i -- sh`,
+		// Flags are parsed manually so that flags meant for the command after '--' are left alone.
+		DisableFlagParsing: true,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			reflowArgs, command := args, []string(nil)
+			for i, arg := range args {
+				if arg == "--" {
+					reflowArgs, command = args[:i], args[i+1:]
+					break
+				}
+			}
+
+			cobraCmd.InheritedFlags() // Merges --config/--debug from the root into Flags()
+			if err := cobraCmd.Flags().Parse(reflowArgs); err != nil {
+				if errors.Is(err, pflag.ErrHelp) {
+					return cobraCmd.Help()
+				}
+				return err
+			}
+			if help, _ := cobraCmd.Flags().GetBool("help"); help {
+				return cobraCmd.Help()
+			}
+			// The root pre-run saw no parsed flags; honour --debug and --log-format now.
+			debug, _ := cobraCmd.Flags().GetBool("debug")
+			format := util.LogFormat()
+			if cobraCmd.Flags().Changed("log-format") {
+				format, _ = cobraCmd.Flags().GetString("log-format")
+			}
+			if debug || format != util.LogFormat() {
+				util.InitLogger(debug || util.Log.IsLevelEnabled(logrus.DebugLevel), format)
+			}
+
+			positional := cobraCmd.Flags().Args()
+			if len(positional) != 1 {
+				return fmt.Errorf("accepts exactly 1 project name before '--', received %d", len(positional))
+			}
+			if len(command) == 0 {
+				return fmt.Errorf("no command specified; pass it after '--', e.g. 'reflow project exec %s -- ls'", positional[0])
+			}
+			projectName := positional[0]
+
+			if env != "test" && env != "prod" {
+				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+			defer stop()
+
+			return app.ExecInActiveContainer(ctx, reflowBasePath, projectName, env, command, interactive)
+		},
+	}
+
+	execCmd.Flags().StringVar(&env, "env", "test", "Specify environment ('test' or 'prod')")
+	execCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Attach stdin and allocate a TTY")
+
+	parentCmd.AddCommand(execCmd)
+}
@@ -0,0 +1,72 @@
+package project_ops
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// AddGenerateKeyCommand defines the generate-key command and adds it to the parent command.
+func AddGenerateKeyCommand(parentCmd *cobra.Command) {
+	var force bool
+
+	var generateKeyCmd = &cobra.Command{
+		Use:   "generate-key <project-name>",
+		Short: "Generate an SSH deploy key for a project's repository",
+		Long: `Generates an ECDSA key pair for the project, saves the private key to
+reflow/apps/<project-name>/.ssh/id_ecdsa (0600) and records it as the project's sshKeyPath,
+so fetches of this project use it instead of the global SSH key. The public key is printed:
+add it as a read-only deploy key of the repository (on GitHub: Settings > Deploy keys).
+
+Use --force to replace a key generated earlier.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+			if err != nil {
+				return fmt.Errorf("failed to load project '%s': %w", projectName, err)
+			}
+			if projCfg.SourceType == config.SourceTypeLocal {
+				return fmt.Errorf("project '%s' deploys from a local directory and has no repository to fetch", projectName)
+			}
+
+			publicKey, err := config.GenerateDeployKey(reflowBasePath, projectName, force)
+			if err != nil {
+				return err
+			}
+
+			projCfg.SSHKeyPath = filepath.Join(config.SSHDirName, config.DeployKeyFileName)
+			projCfg.SSHKeyPassphrase = ""
+			if err := config.SaveProjectConfig(reflowBasePath, projCfg); err != nil {
+				return err
+			}
+
+			util.Log.Infof("Generated deploy key %s for project '%s'.", config.GetProjectDeployKeyPath(reflowBasePath, projectName), projectName)
+			fmt.Println()
+			fmt.Println("Add this public key as a deploy key of the repository:")
+			fmt.Println()
+			fmt.Println(publicKey)
+			fmt.Println()
+			lowerRepo := strings.ToLower(projCfg.GithubRepo)
+			if strings.HasPrefix(lowerRepo, "https://") || strings.HasPrefix(lowerRepo, "http://") {
+				util.Log.Warnf("The repository URL %s uses HTTPS; SSH keys are only used for SSH URLs such as git@github.com:user/repo.git.", util.RedactURL(projCfg.GithubRepo))
+			}
+			return nil
+		},
+	}
+
+	generateKeyCmd.Flags().BoolVar(&force, "force", false, "Replace the project's existing deploy key")
+
+	parentCmd.AddCommand(generateKeyCmd)
+}
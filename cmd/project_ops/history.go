@@ -0,0 +1,194 @@
+package project_ops
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/config"
+	"reflow/internal/deployment"
+	"reflow/internal/util"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// AddHistoryCommand defines the 'history' command and its subcommands.
+func AddHistoryCommand(parentCmd *cobra.Command) {
+	var keep int
+	var limit, offset int
+	var env, outcome, since, until, output string
+
+	var historyCmd = &cobra.Command{
+		Use:   "history <project-name>",
+		Short: "Show or manage a project's deployment history",
+		Long: `Lists the deployment events recorded in a project's deployments.log, newest first.
+
+--since and --until accept an RFC 3339 timestamp (2024-05-01T15:04:05Z), a date
+(2024-05-01), or a duration counted back from now (90m, 24h, 7d). Use --output json
+for the raw event list.
+
+The 'prune' subcommand trims the log.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+
+			if output != "table" && output != "json" {
+				return fmt.Errorf("invalid value for --output flag: '%s'. Must be 'table' or 'json'", output)
+			}
+			if limit <= 0 {
+				return fmt.Errorf("--limit must be greater than 0")
+			}
+			if offset < 0 {
+				return fmt.Errorf("--offset must not be negative")
+			}
+
+			now := time.Now()
+			filter := deployment.HistoryFilter{Env: env, Outcome: outcome}
+			var err error
+			if since != "" {
+				if filter.Since, err = parseHistoryTime(since, now); err != nil {
+					return fmt.Errorf("invalid value for --since flag: %w", err)
+				}
+			}
+			if until != "" {
+				if filter.Until, err = parseHistoryTime(until, now); err != nil {
+					return fmt.Errorf("invalid value for --until flag: %w", err)
+				}
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+			if _, err := config.LoadProjectConfig(reflowBasePath, projectName); err != nil {
+				return fmt.Errorf("failed to load project '%s': %w", projectName, err)
+			}
+
+			page, err := deployment.ListHistory(reflowBasePath, projectName, strconv.Itoa(limit), strconv.Itoa(offset), "", filter)
+			if err != nil {
+				return fmt.Errorf("failed to read deployment history for '%s': %w", projectName, err)
+			}
+
+			if output == "json" {
+				encoded, err := json.MarshalIndent(page.Events, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode deployment history as JSON: %w", err)
+				}
+				fmt.Println(string(encoded))
+				return nil
+			}
+
+			if len(page.Events) == 0 {
+				util.Log.Infof("No deployment events found for project '%s'.", projectName)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "TIMESTAMP\tTYPE\tENV\tCOMMIT\tSLOT\tOUTCOME\tDURATION\tTRIGGERED BY")
+			fmt.Fprintln(w, "---------\t----\t---\t------\t----\t-------\t--------\t------------")
+			for _, event := range page.Events {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					event.Timestamp.Local().Format("2006-01-02 15:04:05"),
+					orDash(event.EventType),
+					orDash(event.Environment),
+					orDash(shortSHA(event.CommitSHA)),
+					orDash(event.Slot),
+					orDash(event.Outcome),
+					formatEventDuration(event.DurationMs),
+					orDash(event.TriggeredBy),
+				)
+			}
+			if err := w.Flush(); err != nil {
+				util.Log.Errorf("Failed to flush tabwriter: %v", err)
+			}
+
+			if page.Total != nil && offset+len(page.Events) < *page.Total {
+				fmt.Printf("\nShowing %d-%d of %d event(s). Use --offset %d to see more.\n",
+					offset+1, offset+len(page.Events), *page.Total, offset+len(page.Events))
+			}
+			return nil
+		},
+	}
+
+	historyCmd.Flags().IntVar(&limit, "limit", 25, "Maximum number of events to show")
+	historyCmd.Flags().IntVar(&offset, "offset", 0, "Number of matching events to skip")
+	historyCmd.Flags().StringVar(&env, "env", "", "Only show events for this environment ('test' or 'prod')")
+	historyCmd.Flags().StringVar(&outcome, "outcome", "", "Only show events with this outcome (e.g. 'success', 'failure')")
+	historyCmd.Flags().StringVar(&since, "since", "", "Only show events at or after this time")
+	historyCmd.Flags().StringVar(&until, "until", "", "Only show events before this time")
+	historyCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format ('table' or 'json')")
+
+	var pruneCmd = &cobra.Command{
+		Use:   "prune <project-name>",
+		Short: "Remove old deployment events, keeping only the most recent ones",
+		Long: `Rewrites the project's deployments.log so that only the newest --keep events remain.
+Rotated archives (deployments.log.1, .2, ...) are merged in before pruning and removed afterwards.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			removed, err := deployment.PruneHistory(reflowBasePath, projectName, keep)
+			if err != nil {
+				return fmt.Errorf("failed to prune deployment history for '%s': %w", projectName, err)
+			}
+
+			util.Log.Infof("Removed %d old deployment event(s) for project '%s'.", removed, projectName)
+			return nil
+		},
+	}
+
+	pruneCmd.Flags().IntVar(&keep, "keep", 500, "Number of most recent deployment events to keep")
+
+	historyCmd.AddCommand(pruneCmd)
+	parentCmd.AddCommand(historyCmd)
+}
+
+// parseHistoryTime parses a --since/--until value: an RFC 3339 timestamp, a local date, or a
+// duration before now (Go duration syntax, plus 'd' for days).
+func parseHistoryTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, found := strings.CutSuffix(value, "d"); found {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("'%s' is not a timestamp (RFC 3339), date (YYYY-MM-DD) or duration (e.g. 24h, 7d)", value)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func formatEventDuration(durationMs int64) string {
+	if durationMs <= 0 {
+		return "-"
+	}
+	return (time.Duration(durationMs) * time.Millisecond).Round(100 * time.Millisecond).String()
+}
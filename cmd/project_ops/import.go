@@ -0,0 +1,109 @@
+package project_ops
+
+import (
+	"context"
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/config"
+	"reflow/internal/orchestrator"
+	"reflow/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// AddImportCommand defines the import command and adds it to the parent command.
+func AddImportCommand(parentCmd *cobra.Command) {
+	var adoptContainer string
+	var env string
+	var testDomain string
+	var prodDomain string
+	var appPort int
+	var testEnvFile string
+	var prodEnvFile string
+	var shallow bool
+	var gitToken string
+	var gitUsername string
+	var dryRun bool
+
+	var importCmd = &cobra.Command{
+		Use:   "import <project-name> <repo-url>",
+		Short: "Create a project for an app already running in a container, adopting that container",
+		Long: `Brings an app that was deployed by hand with Docker under reflow's management without
+building and redeploying it. The project is created and its repository cloned as with
+'reflow project create', then the running container given with --adopt-container becomes
+the active 'blue' slot of the --env environment (prod by default):
+
+  - its image is tagged <project>:<commit>, using the commit from the container's
+    'reflow.commit' or 'org.opencontainers.image.revision' label, or the cloned HEAD;
+  - since Docker cannot add labels to a container, it is stopped and recreated from its own
+    config with reflow's labels and container name, attached to the reflow network, and
+    health-checked (the original is restarted if the check fails, and removed otherwise);
+  - the project state records the deployment and the environment's Nginx config is written
+    and loaded for its domain.
+
+The app port defaults to the container's only exposed TCP port. Use --dry-run to print the
+planned actions without changing anything.
+
+Example:
+  reflow project import my-app git@github.com:user/my-app.git --adopt-container my-app-old --prod-domain myapp.com`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if env != "test" && env != "prod" {
+				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+			}
+			if adoptContainer == "" {
+				return fmt.Errorf("--adopt-container is required")
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			if gitToken == "-" {
+				token, err := util.ReadSecretInput("Git access token: ")
+				if err != nil {
+					return err
+				}
+				gitToken = token
+			}
+			if cobraCmd.Flags().Changed("git-token") && gitToken == "" {
+				return fmt.Errorf("--git-token must not be empty")
+			}
+
+			importArgs := config.CreateProjectArgs{
+				ProjectName: args[0],
+				RepoURL:     args[1],
+				SourceType:  config.SourceTypeGit,
+				TestDomain:  testDomain,
+				ProdDomain:  prodDomain,
+				AppPort:     appPort,
+				TestEnvFile: testEnvFile,
+				ProdEnvFile: prodEnvFile,
+				Shallow:     shallow,
+				GitToken:    gitToken,
+				GitUsername: gitUsername,
+			}
+
+			ctx := context.Background()
+			if dryRun {
+				ctx = orchestrator.WithDryRun(ctx)
+			}
+			return orchestrator.ImportProject(ctx, reflowBasePath, importArgs, env, adoptContainer)
+		},
+	}
+
+	importCmd.Flags().StringVar(&adoptContainer, "adopt-container", "", "ID or name of the running container to adopt (required)")
+	importCmd.Flags().StringVar(&env, "env", "prod", "Environment the container becomes the active deployment of ('test' or 'prod')")
+	importCmd.Flags().StringVar(&testDomain, "test-domain", "", "Specify custom domain for the 'test' environment (e.g., test.myapp.com)")
+	importCmd.Flags().StringVar(&prodDomain, "prod-domain", "", "Specify custom domain for the 'prod' environment (e.g., myapp.com)")
+	importCmd.Flags().IntVar(&appPort, "app-port", 0, "Port the application listens on (default: the container's only exposed port)")
+	importCmd.Flags().StringVar(&testEnvFile, "test-env-file", "", "Relative path to the test env file (default: .env.development)")
+	importCmd.Flags().StringVar(&prodEnvFile, "prod-env-file", "", "Relative path to the prod env file (default: .env.production)")
+	importCmd.Flags().BoolVar(&shallow, "shallow", false, "Perform a shallow clone (depth 1) to speed up import of large repositories")
+	importCmd.Flags().StringVar(&gitToken, "git-token", "", "Access token for cloning a private repository over HTTPS ('-' to read it from stdin)")
+	importCmd.Flags().StringVar(&gitUsername, "git-username", "", "Username sent with --git-token (default: git)")
+	importCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the planned actions without changing anything")
+
+	parentCmd.AddCommand(importCmd)
+}
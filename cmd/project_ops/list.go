@@ -1,9 +1,10 @@
 package project_ops
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/project"
 	"reflow/internal/util"
 	"text/tabwriter"
@@ -13,29 +14,24 @@ import (
 
 // AddListCommand defines the list command and adds it to the parent command.
 func AddListCommand(parentCmd *cobra.Command) {
+	var noCheck bool
+
 	var listCmd = &cobra.Command{
-		Use:     "list",
-		Short:   "List all configured Reflow projects and their status",
-		Long:    `Scans the Reflow apps directory and displays a summary of each configured project, including its deployment status for test and production environments.`,
+		Use:   "list",
+		Short: "List all configured Reflow projects and their status",
+		Long: `Scans the Reflow apps directory and displays a summary of each configured project,
+including its deployment status and domain for the test and production environments.
+
+The domain of each deployed environment is requested through the Nginx container, as a
+client would, and marked (up) if it answers without a 5xx status or (down) otherwise.
+The requests run concurrently with a short timeout; use --no-check to skip them.`,
 		Aliases: []string{"ls"},
 		Args:    cobra.NoArgs,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var err error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, err = filepath.Abs(configFlag)
-				if err != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", err)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
 			summaries, err := project.ListProjects(reflowBasePath)
 			if err != nil {
@@ -46,13 +42,16 @@ func AddListCommand(parentCmd *cobra.Command) {
 				util.Log.Info("No projects found.")
 				return nil
 			}
+			if !noCheck {
+				project.CheckReachability(context.Background(), reflowBasePath, summaries)
+			}
 
 			util.Log.Info("Configured Projects:")
 			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-			fmt.Fprintln(w, "NAME\tREPOSITORY\tTEST STATUS\tPROD STATUS")
-			fmt.Fprintln(w, "----\t----------\t-----------\t-----------")
+			fmt.Fprintln(w, "NAME\tREPOSITORY\tTEST STATUS\tTEST DOMAIN\tPROD STATUS\tPROD DOMAIN")
+			fmt.Fprintln(w, "----\t----------\t-----------\t-----------\t-----------\t-----------")
 			for _, s := range summaries {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, s.RepoURL, s.TestStatus, s.ProdStatus)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Name, s.RepoURL, s.TestStatus, domainColumn(s.TestDomain, s.TestReachable), s.ProdStatus, domainColumn(s.ProdDomain, s.ProdReachable))
 			}
 			err = w.Flush()
 			if err != nil {
@@ -64,5 +63,22 @@ func AddListCommand(parentCmd *cobra.Command) {
 		},
 	}
 
+	listCmd.Flags().BoolVar(&noCheck, "no-check", false, "Don't request each deployed domain to check it is reachable")
+
 	parentCmd.AddCommand(listCmd)
 }
+
+// domainColumn formats a domain for the list table, marked with the result of its reachability
+// check if it was checked.
+func domainColumn(domain string, reachable *bool) string {
+	switch {
+	case domain == "":
+		return "-"
+	case reachable == nil:
+		return domain
+	case *reachable:
+		return domain + " (up)"
+	default:
+		return domain + " (down)"
+	}
+}
@@ -3,11 +3,10 @@ package project_ops
 import (
 	"context"
 	"fmt"
-	"os"
 	"os/signal"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/app"
-	"reflow/internal/util"
+	"regexp"
 	"syscall"
 
 	"github.com/spf13/cobra"
@@ -18,13 +17,24 @@ func AddLogsCommand(parentCmd *cobra.Command) {
 	var env string
 	var follow bool
 	var tail string
+	var index int
+	var slot string
+	var grepPattern string
+	var contextLines, beforeLines, afterLines int
 
 	var logsCmd = &cobra.Command{
 		Use:   "logs <project-name>",
-		Short: "Show logs for the active container of a project environment",
+		Short: "Show logs for the active container(s) of a project environment",
 		Long: `Workspaces and displays logs from the Docker container associated with the currently
 active deployment for the specified project and environment. Allows following
-logs in real-time and specifying the number of tail lines.`,
+logs in real-time and specifying the number of tail lines. When the slot runs
+multiple replicas, logs from all running replicas are merged unless --index is set.
+
+Use --slot to read the blue or green slot directly instead of the active one, e.g.
+to see why the new containers of a deployment are failing their health check.
+
+Use --grep to show only lines matching a regular expression, and --context (-C),
+--before (-B) or --after (-A) to include surrounding lines, like grep.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			projectName := args[0]
@@ -32,28 +42,42 @@ logs in real-time and specifying the number of tail lines.`,
 			if env != "test" && env != "prod" {
 				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
 			}
+			if slot != "" && slot != "blue" && slot != "green" {
+				return fmt.Errorf("invalid value for --slot flag: '%s'. Must be 'blue' or 'green'", slot)
+			}
+			if index < 0 {
+				return fmt.Errorf("invalid value for --index flag: %d. Must be 1 or greater (or 0 for all replicas)", index)
+			}
 
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var pathErr error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
+			var grep *app.LogGrepOptions
+			if grepPattern != "" {
+				pattern, err := regexp.Compile(grepPattern)
 				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
+					return fmt.Errorf("invalid value for --grep flag: %w", err)
+				}
+				if contextLines < 0 || beforeLines < 0 || afterLines < 0 {
+					return fmt.Errorf("--context, --before and --after must not be negative")
 				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, pathErr = filepath.Abs(configFlag)
-				if pathErr != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", pathErr)
+				grep = &app.LogGrepOptions{Pattern: pattern, Before: contextLines, After: contextLines}
+				if cobraCmd.Flags().Changed("before") {
+					grep.Before = beforeLines
 				}
+				if cobraCmd.Flags().Changed("after") {
+					grep.After = afterLines
+				}
+			} else if cobraCmd.Flags().Changed("context") || cobraCmd.Flags().Changed("before") || cobraCmd.Flags().Changed("after") {
+				return fmt.Errorf("--context, --before and --after require --grep")
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
 			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 			defer stop()
 
-			err := app.StreamAppLogs(ctx, reflowBasePath, projectName, env, follow, tail)
+			err = app.StreamAppLogs(ctx, reflowBasePath, projectName, env, slot, follow, tail, index, grep)
 			if err != nil {
 				return fmt.Errorf("failed to get logs")
 			}
@@ -65,6 +89,13 @@ logs in real-time and specifying the number of tail lines.`,
 	logsCmd.Flags().StringVar(&env, "env", "test", "Specify environment ('test' or 'prod')")
 	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Follow log output")
 	logsCmd.Flags().StringVar(&tail, "tail", "100", "Number of lines to show from the end of the logs")
+	logsCmd.Flags().StringVar(&slot, "slot", "", "Read logs from this slot ('blue' or 'green') instead of the active one")
+	logsCmd.Flags().IntVar(&index, "index", 0, "Replica to show logs for (1-based); 0 merges all running replicas")
+
+	logsCmd.Flags().StringVar(&grepPattern, "grep", "", "Only show lines matching this regular expression")
+	logsCmd.Flags().IntVarP(&contextLines, "context", "C", 0, "Lines of context to show around each --grep match")
+	logsCmd.Flags().IntVarP(&beforeLines, "before", "B", 0, "Lines of context to show before each --grep match (overrides --context)")
+	logsCmd.Flags().IntVarP(&afterLines, "after", "A", 0, "Lines of context to show after each --grep match (overrides --context)")
 
 	parentCmd.AddCommand(logsCmd)
 }
@@ -0,0 +1,64 @@
+package project_ops
+
+import (
+	"context"
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/nginx"
+	"reflow/internal/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+// AddMaintenanceCommand defines the maintenance command and adds it to the parent command.
+func AddMaintenanceCommand(parentCmd *cobra.Command) {
+	var env string
+
+	var maintenanceCmd = &cobra.Command{
+		Use:   "maintenance <project-name> [on|off]",
+		Short: "Serve a maintenance page for a project environment",
+		Long: `Turns maintenance mode on or off for a project environment. While it is on, Nginx
+answers every request for the environment's domain with a 503 and a static
+maintenance page instead of proxying to the app, e.g. to cover a deployment that
+stops the old container before the new one is live.
+
+The page is the file named by 'maintenancePage' in the project config (relative to
+the project directory), or a built-in page. Turning maintenance mode off restores
+the normal site config. Without on|off, shows whether maintenance mode is on.`,
+		Args:      cobra.RangeArgs(1, 2),
+		ValidArgs: []string{"on", "off"},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+
+			if env != "test" && env != "prod" {
+				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				state := "off"
+				if nginx.MaintenanceEnabled(reflowBasePath, projectName, env) {
+					state = "on"
+				}
+				fmt.Printf("Maintenance mode for project '%s' env '%s': %s\n", projectName, env, state)
+				return nil
+			}
+
+			switch args[1] {
+			case "on":
+				return orchestrator.SetMaintenanceMode(context.Background(), reflowBasePath, projectName, env, true)
+			case "off":
+				return orchestrator.SetMaintenanceMode(context.Background(), reflowBasePath, projectName, env, false)
+			default:
+				return fmt.Errorf("invalid argument '%s': must be 'on' or 'off'", args[1])
+			}
+		},
+	}
+
+	maintenanceCmd.Flags().StringVar(&env, "env", "test", "Specify environment ('test' or 'prod')")
+	parentCmd.AddCommand(maintenanceCmd)
+}
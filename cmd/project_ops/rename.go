@@ -0,0 +1,43 @@
+package project_ops
+
+import (
+	"context"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+// AddRenameCommand defines the rename command and adds it to the parent command.
+func AddRenameCommand(parentCmd *cobra.Command) {
+	var renameCmd = &cobra.Command{
+		Use:   "rename <old-name> <new-name>",
+		Short: "Renames an existing project",
+		Long: `Renames a Reflow project. The project directory, config, deploy schedules and Nginx
+configs are moved to the new name.
+
+Container names, labels and image tags embed the project name, so any active
+'test' or 'prod' deployment is redeployed: images are retagged, the old containers
+are removed and new ones are started in the same slot with the same commit.
+Expect a brief interruption while the containers are recreated.
+
+Deploy schedules are only updated in config.yaml. A running 'reflow server' keeps
+triggering them under the old name until it is restarted, sent SIGHUP, or asked to
+reload with POST /api/v1/config/reload.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			oldName := args[0]
+			newName := args[1]
+			ctx := context.Background()
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			return orchestrator.RenameProject(ctx, reflowBasePath, oldName, newName)
+		},
+	}
+
+	parentCmd.AddCommand(renameCmd)
+}
@@ -0,0 +1,55 @@
+package project_ops
+
+import (
+	"context"
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/orchestrator"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// AddScaleCommand defines the scale command and adds it to the parent command.
+func AddScaleCommand(parentCmd *cobra.Command) {
+	var env string
+	var replicas int
+
+	var scaleCmd = &cobra.Command{
+		Use:   "scale <project-name>",
+		Short: "Change the number of containers a project environment runs",
+		Long: `Sets environments.<env>.replicas in the project config and applies it to the
+active deployment without rebuilding. New replicas are started in the active slot
+from the deployed image and health checked before Nginx load balances across them;
+surplus replicas are removed once Nginx no longer routes to them.
+
+If nothing is deployed to the environment yet, only the config is updated and the
+next deployment starts that many containers.`,
+		Example: `  reflow project scale my-app --env prod --replicas 3`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+
+			targetEnv := strings.ToLower(env)
+			if targetEnv != "test" && targetEnv != "prod" {
+				return fmt.Errorf("invalid value for --env flag: %s. Must be 'test' or 'prod'", env)
+			}
+			if replicas < 1 {
+				return fmt.Errorf("invalid value for --replicas flag: %d. Must be at least 1", replicas)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			return orchestrator.ScaleProjectEnv(context.Background(), reflowBasePath, projectName, targetEnv, replicas)
+		},
+	}
+
+	scaleCmd.Flags().StringVar(&env, "env", "prod", "Environment to scale ('test' or 'prod')")
+	scaleCmd.Flags().IntVar(&replicas, "replicas", 0, "Number of containers to run (at least 1)")
+	_ = scaleCmd.MarkFlagRequired("replicas")
+
+	parentCmd.AddCommand(scaleCmd)
+}
@@ -0,0 +1,143 @@
+package project_ops
+
+import (
+	"fmt"
+	"os"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// AddSecretsCommand defines the 'secrets' parent command and its subcommands.
+func AddSecretsCommand(parentCmd *cobra.Command) {
+	var env string
+	var reveal bool
+
+	// --- Parent 'secrets' Command ---
+	var secretsCmd = &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage environment secrets for a project",
+		Long: `Provides subcommands to manage secrets stored by Reflow for a project environment.
+
+Secrets live in reflow/apps/<project-name>/secrets/<env>.env, outside the cloned
+repository, and are merged over the values from the environment's env file
+(secrets win). Changes take effect on the next deployment.`,
+	}
+	secretsCmd.PersistentFlags().StringVar(&env, "env", "test", "Specify environment ('test' or 'prod')")
+
+	// --- 'secrets set' Subcommand ---
+	var setCmd = &cobra.Command{
+		Use:   "set <project-name> KEY=VALUE...",
+		Short: "Add or update secrets",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+			if env != "test" && env != "prod" {
+				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			if _, err := config.LoadProjectConfig(reflowBasePath, projectName); err != nil {
+				return fmt.Errorf("failed to load project '%s': %w", projectName, err)
+			}
+			if err := config.SetSecrets(reflowBasePath, projectName, env, args[1:]); err != nil {
+				return err
+			}
+
+			util.Log.Infof("Set %d secret(s) for project '%s' (%s).", len(args)-1, projectName, env)
+			util.Log.Info("Secrets take effect on the next deployment.")
+			return nil
+		},
+	}
+
+	// --- 'secrets unset' Subcommand ---
+	var unsetCmd = &cobra.Command{
+		Use:   "unset <project-name> KEY...",
+		Short: "Remove secrets",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+			if env != "test" && env != "prod" {
+				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			if _, err := config.LoadProjectConfig(reflowBasePath, projectName); err != nil {
+				return fmt.Errorf("failed to load project '%s': %w", projectName, err)
+			}
+			removed, err := config.UnsetSecrets(reflowBasePath, projectName, env, args[1:])
+			if err != nil {
+				return err
+			}
+			if len(removed) == 0 {
+				util.Log.Warnf("None of the given secrets are set for project '%s' (%s).", projectName, env)
+				return nil
+			}
+
+			util.Log.Infof("Removed %s from project '%s' (%s).", strings.Join(removed, ", "), projectName, env)
+			util.Log.Info("Secrets take effect on the next deployment.")
+			return nil
+		},
+	}
+
+	// --- 'secrets list' Subcommand ---
+	var listCmd = &cobra.Command{
+		Use:     "list <project-name>",
+		Short:   "List secrets (values are masked unless --reveal is set)",
+		Aliases: []string{"ls"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+			if env != "test" && env != "prod" {
+				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			if _, err := config.LoadProjectConfig(reflowBasePath, projectName); err != nil {
+				return fmt.Errorf("failed to load project '%s': %w", projectName, err)
+			}
+			entries, err := config.ReadSecrets(reflowBasePath, projectName, env)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Printf("No secrets set for project '%s' (%s).\n", projectName, env)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "KEY\tVALUE")
+			for _, entry := range entries {
+				key, value, _ := strings.Cut(entry, "=")
+				if !reveal {
+					value = config.MaskSecretValue(value)
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\n", key, value)
+			}
+			return w.Flush()
+		},
+	}
+	listCmd.Flags().BoolVar(&reveal, "reveal", false, "Show secret values in plain text")
+
+	secretsCmd.AddCommand(setCmd)
+	secretsCmd.AddCommand(unsetCmd)
+	secretsCmd.AddCommand(listCmd)
+
+	parentCmd.AddCommand(secretsCmd)
+}
@@ -3,8 +3,7 @@ package project_ops
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/app"
 	"reflow/internal/util"
 	"strings"
@@ -27,22 +26,10 @@ It only starts containers that match the active deployment state.`,
 			projectName := args[0]
 			ctx := context.Background()
 
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var pathErr error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, pathErr = filepath.Abs(configFlag)
-				if pathErr != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", pathErr)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
 			var targetEnvs []string
 			switch strings.ToLower(env) {
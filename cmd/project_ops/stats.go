@@ -0,0 +1,83 @@
+package project_ops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/app"
+	"reflow/internal/util"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const statsPollInterval = 2 * time.Second
+
+// AddStatsCommand defines the stats command and adds it to the parent command.
+func AddStatsCommand(parentCmd *cobra.Command) {
+	var env string
+
+	var statsCmd = &cobra.Command{
+		Use:   "stats <project-name>",
+		Short: "Show live CPU and memory usage for a project environment",
+		Long: `Displays a live table of CPU and memory usage for the container(s) in the currently
+active deployment slot of the specified project environment. The table refreshes
+every 2 seconds until interrupted (Ctrl+C).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+
+			if env != "test" && env != "prod" {
+				return fmt.Errorf("invalid value for --env flag: '%s'. Must be 'test' or 'prod'", env)
+			}
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			ticker := time.NewTicker(statsPollInterval)
+			defer ticker.Stop()
+
+			for {
+				stats, err := app.GetProjectEnvStats(ctx, reflowBasePath, projectName, env)
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return err
+				}
+
+				// Clear the screen and redraw the table in place.
+				fmt.Print("\033[H\033[2J")
+				fmt.Printf("Project: %s   Env: %s   Updated: %s\n\n", projectName, env, time.Now().Format("15:04:05"))
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+				fmt.Fprintln(w, "CONTAINER\tCPU %\tMEM USAGE / LIMIT\tMEM %")
+				fmt.Fprintln(w, "---------\t-----\t-----------------\t-----")
+				for _, s := range stats {
+					fmt.Fprintf(w, "%s\t%.2f%%\t%.1fMiB / %.1fMiB\t%.2f%%\n", s.Name, s.CPUPercent, s.MemoryUsageMB, s.MemoryLimitMB, s.MemoryPercent)
+				}
+				if err := w.Flush(); err != nil {
+					util.Log.Errorf("Failed to flush tabwriter: %v", err)
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	statsCmd.Flags().StringVar(&env, "env", "test", "Specify environment ('test' or 'prod')")
+
+	parentCmd.AddCommand(statsCmd)
+}
@@ -2,43 +2,32 @@ package project_ops
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/internal/project"
-	"reflow/internal/util"
 
 	"github.com/spf13/cobra"
 )
 
 // AddStatusCommand defines the status command and adds it to the parent command.
 func AddStatusCommand(parentCmd *cobra.Command) {
+	var jsonOutput bool
+
 	var statusCmd = &cobra.Command{
 		Use:     "status <project-name>",
 		Short:   "Show detailed status for a specific project",
-		Long:    `Displays detailed information about a specific Reflow project, including configuration paths, deployment state, and the status of associated Docker containers for both test and production environments.`,
+		Long:    `Displays detailed information about a specific Reflow project, including configuration paths, deployment state, and the status of associated Docker containers for both test and production environments. Use --json for machine-readable output.`,
 		Aliases: []string{"info"},
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			projectName := args[0]
 			ctx := context.Background()
 
-			configFlag, _ := cobraCmd.Root().PersistentFlags().GetString("config")
-			var reflowBasePath string
-			var err error
-			if configFlag == "" {
-				cwd, err := os.Getwd()
-				if err != nil {
-					return fmt.Errorf("failed to get current working directory: %w", err)
-				}
-				reflowBasePath = filepath.Join(cwd, "reflow")
-			} else {
-				reflowBasePath, err = filepath.Abs(configFlag)
-				if err != nil {
-					return fmt.Errorf("failed to get absolute path for --config flag: %w", err)
-				}
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
 			}
-			util.Log.Debugf("Using reflow base path: %s", reflowBasePath)
 
 			// --- Get Project Details ---
 			details, err := project.GetProjectDetails(ctx, reflowBasePath, projectName)
@@ -46,6 +35,15 @@ func AddStatusCommand(parentCmd *cobra.Command) {
 				return fmt.Errorf("failed to get status for project '%s': %w", projectName, err)
 			}
 
+			if jsonOutput {
+				encoded, err := json.MarshalIndent(details, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode status as JSON: %w", err)
+				}
+				fmt.Println(string(encoded))
+				return nil
+			}
+
 			// --- Print Details ---
 			fmt.Printf("Project Status: %s\n", details.Name)
 			fmt.Printf("  Repository:   %s\n", details.RepoURL)
@@ -62,6 +60,8 @@ func AddStatusCommand(parentCmd *cobra.Command) {
 		},
 	}
 
+	statusCmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the status as JSON")
+
 	parentCmd.AddCommand(statusCmd)
 }
 
@@ -73,7 +73,15 @@ func printEnvDetails(title string, details project.EnvironmentDetails) {
 	fmt.Printf("  Domain:          %s\n", details.EffectiveDomain)
 	fmt.Printf("  App Port:        %d\n", details.AppPort)
 	fmt.Printf("  Env File Path:   %s\n", details.EnvFilePath)
+	if !details.EnvFileModTime.IsZero() {
+		fmt.Printf("  Env File:        modified %s, hash %s\n", details.EnvFileModTime.Local().Format("2006-01-02 15:04:05"), details.EnvFileHash)
+	}
+	if details.EnvFileChanged {
+		fmt.Println("  Note:            env file changed since last deploy — redeploy to apply.")
+	}
 	fmt.Printf("  Container ID:    %s\n", details.ContainerID)
 	fmt.Printf("  Container Names: %v\n", details.ContainerNames)
 	fmt.Printf("  Container Status:%s\n", details.ContainerStatus)
+	fmt.Printf("  CPU Usage:       %s\n", details.CPUUsage)
+	fmt.Printf("  Memory Usage:    %s\n", details.MemoryUsage)
 }
@@ -0,0 +1,54 @@
+package project_ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/deployment"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// AddTailDeployCommand defines the 'tail-deploy' command and adds it to the parent command.
+func AddTailDeployCommand(parentCmd *cobra.Command) {
+	var tailDeployCmd = &cobra.Command{
+		Use:   "tail-deploy <project-name>",
+		Short: "Follow the progress of a project's current deployment",
+		Long: `Prints the progress messages of the project's running deployment or approval, for
+example one triggered through the API, and keeps following them until it finishes.
+If no deployment is running, the messages of the last one are printed.
+
+Exits with a non-zero status if the deployment failed.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true, // A failed deployment is not a usage error
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			projectName := args[0]
+
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			succeeded, err := deployment.TailProgress(ctx, reflowBasePath, projectName, os.Stdout, nil)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return nil
+				}
+				return fmt.Errorf("failed to follow deployment of project '%s': %w", projectName, err)
+			}
+			if !succeeded {
+				return fmt.Errorf("deployment of project '%s' failed", projectName)
+			}
+			return nil
+		},
+	}
+
+	parentCmd.AddCommand(tailDeployCmd)
+}
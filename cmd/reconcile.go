@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"reflow/cmd/cmdutil"
+	"reflow/internal/orchestrator"
+	"reflow/internal/project"
+	"reflow/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// AddReconcileCommand defines the reconcile command and adds it to the root command.
+func AddReconcileCommand(rootCmd *cobra.Command) {
+	var fix bool
+
+	var reconcileCmd = &cobra.Command{
+		Use:   "reconcile [project-name]",
+		Short: "Compare project state with the containers actually running",
+		Long: `Compares each project's recorded state (state.json) with the Docker containers
+labelled for it, and reports where they disagree:
+
+  - state names a deployment, but another one is serving traffic
+  - a deployment is serving traffic, but state records none
+  - state names a deployment that has no running container
+  - a container belongs to no recorded or serving deployment
+
+The serving deployment is the one whose running, healthy containers the environment's
+Nginx config proxies to. Containers of the previous deployment, kept in the inactive
+slot for rollback, are not reported; 'reflow project cleanup' removes them.
+
+With --fix, state is updated to the serving deployment when exactly one qualifies,
+and orphaned containers are stopped and removed. Without a project name, all
+projects are checked.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			reflowBasePath, err := cmdutil.ResolveBasePath(cobraCmd)
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			var projectNames []string
+			if len(args) == 1 {
+				projectNames = args
+			} else {
+				summaries, err := project.ListProjects(reflowBasePath)
+				if err != nil {
+					return err
+				}
+				for _, summary := range summaries {
+					projectNames = append(projectNames, summary.Name)
+				}
+			}
+
+			problems := 0
+			for _, projectName := range projectNames {
+				discrepancies, err := orchestrator.ReconcileProject(ctx, reflowBasePath, projectName, fix)
+				if err != nil {
+					util.Log.Errorf("Failed to reconcile project '%s': %v", projectName, err)
+					problems++
+					continue
+				}
+				if len(discrepancies) == 0 {
+					fmt.Printf("✅ %s: state matches containers\n", projectName)
+					continue
+				}
+				for _, d := range discrepancies {
+					if d.Fixed {
+						fmt.Printf("🔧 %s/%s: %s (fixed)\n", projectName, d.Environment, d.Message)
+						continue
+					}
+					problems++
+					fmt.Printf("❌ %s/%s: %s\n", projectName, d.Environment, d.Message)
+					if d.Remediation != "" {
+						fmt.Printf("   → %s\n", d.Remediation)
+					}
+				}
+			}
+
+			if problems > 0 {
+				return fmt.Errorf("found %d unresolved discrepancy(ies)", problems)
+			}
+			return nil
+		},
+	}
+
+	reconcileCmd.Flags().BoolVar(&fix, "fix", false, "Update state to the serving deployment and remove orphaned containers")
+	rootCmd.AddCommand(reconcileCmd)
+}
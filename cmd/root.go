@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflow/cmd/cmdutil"
 	"reflow/cmd/deploy"
 	"reflow/internal/update"
 	"sync"
@@ -18,6 +19,7 @@ import (
 
 var (
 	debug              bool
+	logFormat          string
 	cfgFileBase        string
 	updateCheckStarted bool
 	updateCheckMutex   sync.Mutex
@@ -31,27 +33,21 @@ var rootCmd = &cobra.Command{
 It utilizes Docker for containerization and Nginx for reverse proxying,
 implementing a blue-green deployment strategy to minimize downtime.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		configFlag := cmd.Flag("config").Value.String()
-		if configFlag == "" {
-			cwd, err := os.Getwd()
-			if err != nil {
-				return fmt.Errorf("failed to get current working directory: %w", err)
-			}
-			cfgFileBase = filepath.Join(cwd, "reflow")
-		} else {
-			absPath, err := filepath.Abs(configFlag)
-			if err != nil {
-				return fmt.Errorf("failed to get absolute path for --config: %w", err)
-			}
-			cfgFileBase = absPath
+		basePath, err := cmdutil.ResolveBasePath(cmd)
+		if err != nil {
+			return err
 		}
+		cfgFileBase = basePath
 
 		// --- Initialize Logger Early ---
-		util.InitLogger(debug)
+		if logFormat != util.LogFormatText && logFormat != util.LogFormatJSON {
+			return fmt.Errorf("invalid value for --log-format flag: '%s'. Must be '%s' or '%s'", logFormat, util.LogFormatText, util.LogFormatJSON)
+		}
+		util.InitLogger(debug, logFormat)
 		util.Log.Debugf("Debug flag set to: %v", debug)
 		util.Log.Debugf("Using reflow base path: %s", cfgFileBase)
 
-		// --- Check global config ONLY for debug setting override ---
+		// --- Check global config for debug and log format settings ---
 		globalCfg, err := config.LoadGlobalConfig(cfgFileBase)
 		if err != nil {
 			var configFileNotFoundError viper.ConfigFileNotFoundError
@@ -59,10 +55,34 @@ implementing a blue-green deployment strategy to minimize downtime.`,
 				util.Log.Debugf("Global config file not found at %s. Debug setting relies on flag.", filepath.Join(cfgFileBase, config.GlobalConfigFileName))
 			}
 		} else {
+			effectiveFormat := logFormat
+			if !cmd.Flags().Changed("log-format") && globalCfg.LogFormat != "" {
+				if globalCfg.LogFormat == util.LogFormatText || globalCfg.LogFormat == util.LogFormatJSON {
+					effectiveFormat = globalCfg.LogFormat
+				} else {
+					util.Log.Warnf("Ignoring invalid logFormat '%s' in global config; must be '%s' or '%s'.", globalCfg.LogFormat, util.LogFormatText, util.LogFormatJSON)
+				}
+			}
+			levelChanged := false
+			if globalCfg.Logging.Level != "" {
+				if levelErr := util.SetLogLevel(globalCfg.Logging.Level); levelErr != nil {
+					util.Log.Warnf("Ignoring logging.level in global config: %v", levelErr)
+				} else {
+					levelChanged = true
+				}
+			}
+			if globalCfg.Logging.File != "" {
+				if fileErr := util.SetLogFile(logFileSettings(cfgFileBase, globalCfg.Logging)); fileErr != nil {
+					util.Log.Warnf("Could not open log file from global config, logging to stdout only: %v", fileErr)
+				}
+			}
 			if globalCfg.Debug && !debug {
+				util.InitLogger(true, effectiveFormat)
 				util.Log.Debug("Enabling debug mode based on global config file.")
-				util.InitLogger(true)
-			} else if !globalCfg.Debug && debug {
+			} else if effectiveFormat != logFormat || levelChanged {
+				util.InitLogger(debug, effectiveFormat)
+			}
+			if !globalCfg.Debug && debug {
 				util.Log.Debug("Debug mode enabled by flag, overriding config file setting if it was false.")
 			}
 		}
@@ -86,7 +106,7 @@ implementing a blue-green deployment strategy to minimize downtime.`,
 						return
 					}
 
-					cacheDir := filepath.Join(cfgFileBase, ".reflow-state")
+					cacheDir := filepath.Join(cfgFileBase, config.StateDirName)
 					cachePath := filepath.Join(cacheDir, update.CacheFileName)
 
 					util.Log.Debugf("Initiating background update check for repo: %s", repo)
@@ -118,6 +138,24 @@ implementing a blue-green deployment strategy to minimize downtime.`,
 	},
 }
 
+// logFileSettings returns the log file path and rotation limits from the logging config, with relative paths
+// resolved against the base directory and rotation defaults filled in.
+func logFileSettings(basePath string, logging config.LoggingConfig) (string, int, int) {
+	path := logging.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(basePath, path)
+	}
+	maxSizeMB := logging.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = config.DefaultLogFileMaxSizeMB
+	}
+	maxArchives := logging.MaxArchives
+	if maxArchives <= 0 {
+		maxArchives = config.DefaultLogFileMaxArchives
+	}
+	return path, maxSizeMB, maxArchives
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	err := rootCmd.Execute()
@@ -129,6 +167,7 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable verbose debug output")
 	rootCmd.PersistentFlags().StringVarP(&cfgFileBase, "config", "c", "", "Base directory path for reflow configuration (default ./reflow)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", util.LogFormatText, "Log output format ('text' or 'json')")
 
 	deploy.AddDeployCommand(rootCmd)
 	deploy.AddApproveCommand(rootCmd)
@@ -136,6 +175,9 @@ func init() {
 	AddDestroyCommand(rootCmd)
 	AddVersionCommand(rootCmd)
 	AddServerCommand(rootCmd)
+	AddReconcileCommand(rootCmd)
+	AddBackupCommand(rootCmd)
+	AddRestoreCommand(rootCmd)
 }
 
 // GetReflowBasePath allows other commands (like init) to access the calculated base path
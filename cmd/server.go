@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spf13/cobra"
 	"reflow/internal/api"
 	"reflow/internal/util"
@@ -16,17 +19,58 @@ func AddServerCommand(rootCmd *cobra.Command) {
 
 	var host string
 	var port string
+	var detach bool
+	var allowOrigins []string
 
 	startCmd := &cobra.Command{
 		Use:   "start",
 		Short: "Start the internal API server",
 		Long: `Starts the local HTTP server that plugins (like the dashboard) can use
-to interact with Reflow's core functions. Intended for local access only.`,
+to interact with Reflow's core functions. Intended for local access only.
+
+Send the process SIGHUP, or POST to /api/v1/config/reload, to re-read the global
+config, plugin state and deploy schedules without a restart. The --host and --port
+flags and the debug setting only take effect on restart.
+
+Browser-based clients (e.g. a dashboard plugin served from another port) need their
+origin allowed for CORS, with --allow-origin (repeatable) or 'api.allowedOrigins' in
+the global config; '*' allows any origin, without credentials. Origins from the config
+follow reloads.
+
+With --detach the server runs in the background, detached from the terminal, and
+its output is appended to logs/api.log in the Reflow base directory. Use
+'reflow server status' and 'reflow server stop' to manage it.`,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			basePath := GetReflowBasePath()
 			util.Log.Debugf("Using reflow base path for server: %s", basePath)
+			for _, origin := range allowOrigins {
+				if err := api.ValidateOrigin(origin); err != nil {
+					return err
+				}
+			}
+
+			if detach {
+				// Re-run this command without --detach in a background process.
+				serverArgs := []string{"--config", basePath, "server", "start", "--host", host, "--port", port}
+				if debug {
+					serverArgs = append(serverArgs, "--debug")
+				}
+				if cobraCmd.Flags().Changed("log-format") {
+					serverArgs = append(serverArgs, "--log-format", logFormat)
+				}
+				for _, origin := range allowOrigins {
+					serverArgs = append(serverArgs, "--allow-origin", origin)
+				}
+				proc, err := api.StartDetached(basePath, serverArgs)
+				if err != nil {
+					return err
+				}
+				util.Log.Infof("✅ API server started in the background (PID %d) on http://%s", proc.PID, proc.ListenAddr)
+				util.Log.Infof("   Logs: %s", api.GetLogFilePath(basePath))
+				return nil
+			}
 
-			err := api.StartServer(basePath, host, port)
+			err := api.StartServer(basePath, host, port, allowOrigins)
 			if err != nil {
 				return err
 			}
@@ -36,7 +80,58 @@ to interact with Reflow's core functions. Intended for local access only.`,
 
 	startCmd.Flags().StringVar(&host, "host", "localhost", "Host address for the API server to bind to")
 	startCmd.Flags().StringVar(&port, "port", "8585", "Port for the API server to listen on")
+	startCmd.Flags().BoolVar(&detach, "detach", false, "Run the API server in the background, logging to logs/api.log")
+	startCmd.Flags().StringArrayVar(&allowOrigins, "allow-origin", nil, "Origin allowed to call the API from a browser, e.g. http://localhost:3000, or '*' (repeatable)")
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running API server",
+		Long:  `Stops the API server recorded in the pidfile, whether it was started with --detach or in the foreground.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			basePath := GetReflowBasePath()
+			proc, err := api.StopServer(basePath)
+			if err != nil {
+				return err
+			}
+			if proc == nil {
+				util.Log.Info("No API server is running.")
+				return nil
+			}
+			util.Log.Infof("✅ API server (PID %d) stopped.", proc.PID)
+			return nil
+		},
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the API server is running",
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			basePath := GetReflowBasePath()
+			proc, err := api.ReadServerProcess(basePath)
+			if err != nil {
+				return err
+			}
+			if proc == nil {
+				fmt.Println("API server: not running")
+				return nil
+			}
+			if !proc.Running {
+				fmt.Printf("API server: not running (stale pidfile for PID %d; 'reflow server stop' removes it)\n", proc.PID)
+				return nil
+			}
+			fmt.Println("API server: running")
+			fmt.Printf("  PID:        %d\n", proc.PID)
+			fmt.Printf("  Listening:  http://%s\n", proc.ListenAddr)
+			if !proc.StartedAt.IsZero() {
+				fmt.Printf("  Started:    %s\n", proc.StartedAt.Format(time.RFC1123))
+			}
+			fmt.Printf("  Log file:   %s (if started with --detach)\n", api.GetLogFilePath(basePath))
+			return nil
+		},
+	}
 
-	serverCmd.AddCommand(startCmd)
+	serverCmd.AddCommand(startCmd, stopCmd, statusCmd)
 	rootCmd.AddCommand(serverCmd)
 }
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/plugin"
+	"reflow/internal/project"
+	"reflow/internal/util"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/spf13/cobra"
+)
+
+const statusCheckTimeout = 5 * time.Second
+
+var statusAPIURL string
+
+// healthCheck is one row of the 'reflow status' summary.
+type healthCheck struct {
+	Name     string
+	OK       bool
+	Critical bool // A failed critical check makes 'reflow status' exit non-zero
+	Detail   string
+}
+
+// statusCmd represents the top-level status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the overall health of the Reflow environment",
+	Long: `Checks the Docker daemon, the Nginx container and the Reflow network, counts the
+managed containers per project and environment, reports the disk used by project
+images, summarizes projects and plugins, and checks whether the API server responds.
+
+Exits with a non-zero status if the Docker daemon, the Nginx container or the
+network is unhealthy, so it can be used from monitoring scripts.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true, // A failed check is not a usage error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		basePath := GetReflowBasePath()
+		util.Log.Debugf("Checking Reflow status at: %s", basePath)
+		ctx := context.Background()
+
+		checks := []healthCheck{checkDockerDaemon()}
+		if checks[0].OK {
+			checks = append(checks, checkNginxContainer(ctx), checkReflowNetwork(ctx), checkManagedContainers(ctx), checkProjectImages(ctx, basePath))
+		} else {
+			checks = append(checks,
+				healthCheck{Name: "Nginx container", Critical: true, Detail: "skipped, Docker is unavailable"},
+				healthCheck{Name: "Docker network", Critical: true, Detail: "skipped, Docker is unavailable"},
+				healthCheck{Name: "Containers", Detail: "skipped, Docker is unavailable"},
+				healthCheck{Name: "Images", Detail: "skipped, Docker is unavailable"},
+			)
+		}
+		checks = append(checks, checkProjects(basePath), checkPlugins(basePath), checkAPIServer(ctx, statusAPIURL))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		_, _ = fmt.Fprintln(w, "CHECK\tSTATUS\tDETAILS")
+		_, _ = fmt.Fprintln(w, "-----\t------\t-------")
+		failed := 0
+		for _, check := range checks {
+			status := "OK"
+			if !check.OK {
+				status = "WARN"
+				if check.Critical {
+					status = "FAIL"
+					failed++
+				}
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, status, check.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			util.Log.Errorf("Failed to flush tabwriter: %v", err)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d critical check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+func checkDockerDaemon() healthCheck {
+	check := healthCheck{Name: "Docker daemon", Critical: true}
+	cli, err := docker.GetClient()
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.OK = true
+	check.Detail = "reachable (API " + cli.ClientVersion() + ")"
+	return check
+}
+
+func checkNginxContainer(ctx context.Context) healthCheck {
+	check := healthCheck{Name: "Nginx container", Critical: true}
+	ctx, cancel := context.WithTimeout(ctx, statusCheckTimeout)
+	defer cancel()
+
+	inspect, err := docker.InspectContainer(ctx, config.ReflowNginxContainerName)
+	if err != nil {
+		check.Detail = fmt.Sprintf("'%s' not found (run 'reflow init')", config.ReflowNginxContainerName)
+		return check
+	}
+	if inspect.State == nil || !inspect.State.Running {
+		check.Detail = fmt.Sprintf("'%s' is not running", config.ReflowNginxContainerName)
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("'%s' running", config.ReflowNginxContainerName)
+	return check
+}
+
+func checkReflowNetwork(ctx context.Context) healthCheck {
+	check := healthCheck{Name: "Docker network", Critical: true}
+	cli, err := docker.GetClient()
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	ctx, cancel := context.WithTimeout(ctx, statusCheckTimeout)
+	defer cancel()
+
+	if _, err := cli.NetworkInspect(ctx, config.ReflowNetworkName, network.InspectOptions{}); err != nil {
+		check.Detail = fmt.Sprintf("'%s' not found (run 'reflow init')", config.ReflowNetworkName)
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("'%s' exists", config.ReflowNetworkName)
+	return check
+}
+
+// checkManagedContainers counts the Reflow-managed containers, broken down by project and
+// environment (e.g. "my-app/test: 2/2 running").
+func checkManagedContainers(ctx context.Context) healthCheck {
+	check := healthCheck{Name: "Containers"}
+	ctx, cancel := context.WithTimeout(ctx, statusCheckTimeout)
+	defer cancel()
+
+	containers, err := docker.ListManagedContainers(ctx)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	type groupCount struct{ total, running int }
+	groups := make(map[string]*groupCount)
+	running := 0
+	for _, c := range containers {
+		group := c.Labels[docker.LabelProject]
+		if group == "" {
+			group = "other"
+		} else if env := c.Labels[docker.LabelEnvironment]; env != "" {
+			group += "/" + env
+		}
+		if groups[group] == nil {
+			groups[group] = &groupCount{}
+		}
+		groups[group].total++
+		if c.State == "running" {
+			groups[group].running++
+			running++
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %d/%d running", name, groups[name].running, groups[name].total))
+	}
+
+	check.OK = true
+	check.Detail = fmt.Sprintf("%d managed, %d running", len(containers), running)
+	if len(parts) > 0 {
+		check.Detail += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return check
+}
+
+// checkProjectImages reports how many images the projects' builds left behind and their size.
+func checkProjectImages(ctx context.Context, basePath string) healthCheck {
+	check := healthCheck{Name: "Images"}
+	summaries, err := project.ListProjects(basePath)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	projectNames := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		projectNames = append(projectNames, summary.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, statusCheckTimeout)
+	defer cancel()
+	count, size, err := docker.ProjectImageUsage(ctx, projectNames)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("%d project image(s), %.1fMiB", count, float64(size)/(1024*1024))
+	return check
+}
+
+func checkProjects(basePath string) healthCheck {
+	check := healthCheck{Name: "Projects"}
+	summaries, err := project.ListProjects(basePath)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	testDeployed, prodDeployed := 0, 0
+	for _, summary := range summaries {
+		if summary.TestStatus != "Not Deployed" {
+			testDeployed++
+		}
+		if summary.ProdStatus != "Not Deployed" {
+			prodDeployed++
+		}
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("%d total, %d deployed to test, %d deployed to prod", len(summaries), testDeployed, prodDeployed)
+	return check
+}
+
+func checkPlugins(basePath string) healthCheck {
+	check := healthCheck{Name: "Plugins"}
+	plugins, err := plugin.ListInstalledPlugins(basePath)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	enabled := 0
+	names := make([]string, 0, len(plugins))
+	for _, p := range plugins {
+		if p.Enabled {
+			enabled++
+			names = append(names, p.PluginName)
+		} else {
+			names = append(names, p.PluginName+" (disabled)")
+		}
+	}
+	sort.Strings(names)
+	check.OK = true
+	check.Detail = fmt.Sprintf("%d installed, %d enabled", len(plugins), enabled)
+	if len(names) > 0 {
+		check.Detail += ": " + strings.Join(names, ", ")
+	}
+	return check
+}
+
+// checkAPIServer queries the API server's root endpoint. The server is optional, so a failure
+// is only a warning.
+func checkAPIServer(ctx context.Context, apiURL string) healthCheck {
+	check := healthCheck{Name: "API server"}
+	ctx, cancel := context.WithTimeout(ctx, statusCheckTimeout)
+	defer cancel()
+
+	url := strings.TrimSuffix(apiURL, "/") + "/"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		check.Detail = fmt.Sprintf("invalid --api-url: %v", err)
+		return check
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		check.Detail = fmt.Sprintf("not running at %s", apiURL)
+		return check
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		check.Detail = fmt.Sprintf("%s responded with %s", apiURL, resp.Status)
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("responding at %s", apiURL)
+	return check
+}
+
+func init() {
+	statusCmd.Flags().StringVar(&statusAPIURL, "api-url", "http://127.0.0.1:8585", "Address of the Reflow API server to check")
+	rootCmd.AddCommand(statusCmd)
+}
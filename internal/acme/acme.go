@@ -0,0 +1,330 @@
+package acme
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/nginx"
+	"reflow/internal/util"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/providers/http/webroot"
+	"github.com/go-acme/lego/v4/registration"
+)
+
+const (
+	// renewBefore is how long before expiry a certificate is renewed (Let's Encrypt certs last 90 days).
+	renewBefore = 30 * 24 * time.Hour
+	// renewCheckInterval is how often the API server checks certificates for renewal.
+	renewCheckInterval = 12 * time.Hour
+
+	accountKeyFileName  = "account.key"
+	accountFileName     = "account.json"
+	certFileName        = "fullchain.pem"
+	privateKeyFileName  = "privkey.pem"
+	certsLiveDirName    = "live"
+	acmeChallengeSubDir = ".well-known/acme-challenge"
+)
+
+// account implements registration.User for the Reflow-wide ACME account.
+type account struct {
+	Email        string                 `json:"email"`
+	DirectoryURL string                 `json:"directoryUrl"`
+	Registration *registration.Resource `json:"registration"`
+	key          crypto.PrivateKey
+}
+
+func (a *account) GetEmail() string                        { return a.Email }
+func (a *account) GetRegistration() *registration.Resource { return a.Registration }
+func (a *account) GetPrivateKey() crypto.PrivateKey        { return a.key }
+
+// CertDir returns the host directory holding the certificate files for a domain.
+// It is mounted into the Nginx container at config.NginxCertsContainerPath.
+func CertDir(reflowBasePath, domain string) string {
+	return filepath.Join(reflowBasePath, config.NginxDirName, config.NginxCertsDirName, certsLiveDirName, domain)
+}
+
+// HasCertificate reports whether an unexpired certificate for domain is present.
+func HasCertificate(reflowBasePath, domain string) bool {
+	expiry, err := certificateExpiry(reflowBasePath, domain)
+	return err == nil && time.Now().Before(expiry)
+}
+
+// EnsureCertificate makes sure a certificate for domain exists and isn't close to expiry, obtaining
+// one via the HTTP-01 challenge if needed. Challenge files are written to the ACME webroot served by
+// the Nginx container, so the domain's Nginx config must already be live. Returns true if a new
+// certificate was written; the caller is responsible for reloading Nginx.
+func EnsureCertificate(ctx context.Context, reflowBasePath, domain string) (bool, error) {
+	if err := validateDomain(domain); err != nil {
+		return false, err
+	}
+
+	if expiry, err := certificateExpiry(reflowBasePath, domain); err == nil && time.Until(expiry) > renewBefore {
+		util.Log.Debugf("Certificate for %s is valid until %s, no renewal needed.", domain, expiry.Format(time.RFC3339))
+		return false, nil
+	}
+
+	if err := checkNginxMounts(ctx); err != nil {
+		return false, err
+	}
+
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	acct, err := loadOrCreateAccount(reflowBasePath, globalCfg.ACME)
+	if err != nil {
+		return false, err
+	}
+
+	legoCfg := lego.NewConfig(acct)
+	legoCfg.CADirURL = acct.DirectoryURL
+	legoCfg.Certificate.KeyType = certcrypto.EC256
+
+	client, err := lego.NewClient(legoCfg)
+	if err != nil {
+		return false, fmt.Errorf("failed to create ACME client: %w", err)
+	}
+
+	webrootPath := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxAcmeDirName)
+	if err := os.MkdirAll(filepath.Join(webrootPath, acmeChallengeSubDir), 0755); err != nil {
+		return false, fmt.Errorf("failed to create ACME webroot %s: %w", webrootPath, err)
+	}
+	provider, err := webroot.NewHTTPProvider(webrootPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to create ACME HTTP-01 provider: %w", err)
+	}
+	if err := client.Challenge.SetHTTP01Provider(provider); err != nil {
+		return false, fmt.Errorf("failed to configure ACME HTTP-01 provider: %w", err)
+	}
+
+	if acct.Registration == nil {
+		util.Log.Infof("Registering ACME account for %s...", acct.Email)
+		reg, regErr := client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+		if regErr != nil {
+			return false, fmt.Errorf("failed to register ACME account: %w", regErr)
+		}
+		acct.Registration = reg
+		if err := saveAccount(reflowBasePath, acct); err != nil {
+			return false, err
+		}
+	}
+
+	util.Log.Infof("Requesting certificate for %s...", domain)
+	res, err := client.Certificate.Obtain(certificate.ObtainRequest{Domains: []string{domain}, Bundle: true})
+	if err != nil {
+		return false, fmt.Errorf("failed to obtain certificate for %s: %w", domain, err)
+	}
+
+	if err := writeCertificate(reflowBasePath, domain, res); err != nil {
+		return false, err
+	}
+	util.Log.Infof("Certificate for %s saved to %s", domain, CertDir(reflowBasePath, domain))
+	return true, nil
+}
+
+// RenewAll renews existing certificates of autoTLS projects that are close to expiry and reloads
+// Nginx if any changed. Initial certificates are obtained during 'approve'.
+func RenewAll(ctx context.Context, reflowBasePath string) error {
+	appsDir := filepath.Join(reflowBasePath, config.AppsDirName)
+	entries, err := os.ReadDir(appsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read apps directory %s: %w", appsDir, err)
+	}
+
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	var errs []error
+	renewed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		projCfg, loadErr := config.LoadProjectConfig(reflowBasePath, entry.Name())
+		if loadErr != nil || !projCfg.AutoTLS {
+			continue
+		}
+		domain, domainErr := config.GetEffectiveDomain(globalCfg, projCfg, "prod")
+		if domainErr != nil {
+			continue
+		}
+		if _, statErr := os.Stat(filepath.Join(CertDir(reflowBasePath, domain), certFileName)); statErr != nil {
+			continue
+		}
+
+		changed, certErr := EnsureCertificate(ctx, reflowBasePath, domain)
+		if certErr != nil {
+			util.Log.Errorf("Certificate renewal for project '%s' (%s) failed: %v", projCfg.ProjectName, domain, certErr)
+			errs = append(errs, certErr)
+			continue
+		}
+		if changed {
+			renewed++
+		}
+	}
+
+	if renewed > 0 {
+		util.Log.Infof("Renewed %d certificate(s), reloading Nginx...", renewed)
+		if err := nginx.ReloadNginx(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StartRenewer periodically renews certificates in the background until ctx is cancelled.
+func StartRenewer(ctx context.Context, reflowBasePath string) {
+	go func() {
+		ticker := time.NewTicker(renewCheckInterval)
+		defer ticker.Stop()
+		for {
+			if err := RenewAll(ctx, reflowBasePath); err != nil {
+				util.Log.Warnf("Certificate renewal check finished with errors: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// validateDomain rejects names a public CA can't issue for.
+func validateDomain(domain string) error {
+	if domain == "" || domain == "localhost" || strings.HasSuffix(domain, ".localhost") || !strings.Contains(domain, ".") {
+		return fmt.Errorf("autoTLS requires a public domain name, got '%s'", domain)
+	}
+	if net.ParseIP(domain) != nil {
+		return fmt.Errorf("autoTLS requires a domain name, not an IP address ('%s')", domain)
+	}
+	return nil
+}
+
+// checkNginxMounts verifies the Nginx container serves the ACME webroot and can read the certs dir.
+// Containers created before TLS support lack these mounts and must be recreated.
+func checkNginxMounts(ctx context.Context) error {
+	inspect, err := docker.InspectContainer(ctx, config.ReflowNginxContainerName)
+	if err != nil {
+		return fmt.Errorf("failed to inspect nginx container '%s': %w", config.ReflowNginxContainerName, err)
+	}
+	required := map[string]bool{config.NginxAcmeWebrootContainerPath: false, config.NginxCertsContainerPath: false}
+	for _, m := range inspect.Mounts {
+		if _, ok := required[m.Destination]; ok {
+			required[m.Destination] = true
+		}
+	}
+	for path, found := range required {
+		if !found {
+			return fmt.Errorf("nginx container '%s' has no mount at %s; remove it (docker rm -f %s) and run 'reflow init' again to enable autoTLS", config.ReflowNginxContainerName, path, config.ReflowNginxContainerName)
+		}
+	}
+	return nil
+}
+
+// certificateExpiry returns the NotAfter time of the stored certificate for domain.
+func certificateExpiry(reflowBasePath, domain string) (time.Time, error) {
+	certPEM, err := os.ReadFile(filepath.Join(CertDir(reflowBasePath, domain), certFileName))
+	if err != nil {
+		return time.Time{}, err
+	}
+	cert, err := certcrypto.ParsePEMCertificate(certPEM)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse certificate for %s: %w", domain, err)
+	}
+	return cert.NotAfter, nil
+}
+
+// writeCertificate stores the certificate chain and private key in the domain's cert directory.
+func writeCertificate(reflowBasePath, domain string, res *certificate.Resource) error {
+	certDir := CertDir(reflowBasePath, domain)
+	if err := os.MkdirAll(certDir, 0755); err != nil {
+		return fmt.Errorf("failed to create certificate directory %s: %w", certDir, err)
+	}
+	// Write the key first so Nginx never sees a new certificate paired with an old key.
+	if err := os.WriteFile(filepath.Join(certDir, privateKeyFileName), res.PrivateKey, 0600); err != nil {
+		return fmt.Errorf("failed to write private key for %s: %w", domain, err)
+	}
+	if err := os.WriteFile(filepath.Join(certDir, certFileName), res.Certificate, 0644); err != nil {
+		return fmt.Errorf("failed to write certificate for %s: %w", domain, err)
+	}
+	return nil
+}
+
+// loadOrCreateAccount loads the stored ACME account, creating a new key if none exists or if the
+// configured email/directory changed since it was registered.
+func loadOrCreateAccount(reflowBasePath string, acmeCfg config.ACMEConfig) (*account, error) {
+	if acmeCfg.Email == "" {
+		return nil, errors.New("acme.email must be set in the global config to use autoTLS")
+	}
+	directoryURL := acmeCfg.DirectoryURL
+	if directoryURL == "" {
+		directoryURL = lego.LEDirectoryProduction
+	}
+
+	acmeDir := filepath.Join(reflowBasePath, config.AcmeDirName)
+	if err := os.MkdirAll(acmeDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create ACME directory %s: %w", acmeDir, err)
+	}
+
+	keyPath := filepath.Join(acmeDir, accountKeyFileName)
+	var key crypto.PrivateKey
+	if keyPEM, err := os.ReadFile(keyPath); err == nil {
+		if key, err = certcrypto.ParsePEMPrivateKey(keyPEM); err != nil {
+			return nil, fmt.Errorf("failed to parse ACME account key %s: %w", keyPath, err)
+		}
+	} else if os.IsNotExist(err) {
+		if key, err = certcrypto.GeneratePrivateKey(certcrypto.EC256); err != nil {
+			return nil, fmt.Errorf("failed to generate ACME account key: %w", err)
+		}
+		if err := os.WriteFile(keyPath, certcrypto.PEMEncode(key), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write ACME account key %s: %w", keyPath, err)
+		}
+	} else {
+		return nil, fmt.Errorf("failed to read ACME account key %s: %w", keyPath, err)
+	}
+
+	acct := &account{Email: acmeCfg.Email, DirectoryURL: directoryURL, key: key}
+
+	data, err := os.ReadFile(filepath.Join(acmeDir, accountFileName))
+	if err == nil {
+		var stored account
+		if jsonErr := json.Unmarshal(data, &stored); jsonErr == nil && stored.Email == acct.Email && stored.DirectoryURL == acct.DirectoryURL {
+			acct.Registration = stored.Registration
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read ACME account: %w", err)
+	}
+	return acct, nil
+}
+
+// saveAccount persists the account registration next to its key.
+func saveAccount(reflowBasePath string, acct *account) error {
+	data, err := json.MarshalIndent(acct, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ACME account: %w", err)
+	}
+	accountPath := filepath.Join(reflowBasePath, config.AcmeDirName, accountFileName)
+	if err := os.WriteFile(accountPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write ACME account %s: %w", accountPath, err)
+	}
+	return nil
+}
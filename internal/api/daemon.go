@@ -0,0 +1,186 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	detachedStartTimeout = 10 * time.Second
+	processPollInterval  = 200 * time.Millisecond
+)
+
+// ServerProcess describes the API server recorded in the pidfile.
+type ServerProcess struct {
+	PID        int
+	ListenAddr string
+	StartedAt  time.Time
+	Running    bool
+}
+
+// GetPidFilePath returns the path of the API server pidfile.
+func GetPidFilePath(basePath string) string {
+	return filepath.Join(basePath, config.StateDirName, config.APIPidFileName)
+}
+
+// GetLogFilePath returns the path detached API servers write their output to.
+func GetLogFilePath(basePath string) string {
+	return filepath.Join(basePath, config.LogsDirName, config.APILogFileName)
+}
+
+// ReadServerProcess returns the API server recorded in the pidfile, or nil if there is none.
+// Running reports whether that process is still alive; a stale pidfile is left in place.
+func ReadServerProcess(basePath string) (*ServerProcess, error) {
+	pidFile := GetPidFilePath(basePath)
+	content, err := os.ReadFile(pidFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read API server pidfile %s: %w", pidFile, err)
+	}
+
+	// Line 1 is the PID, line 2 the listen address.
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil || pid <= 0 {
+		return nil, fmt.Errorf("invalid API server pidfile %s: %q is not a PID", pidFile, lines[0])
+	}
+	proc := &ServerProcess{PID: pid, Running: processAlive(pid)}
+	if len(lines) > 1 {
+		proc.ListenAddr = strings.TrimSpace(lines[1])
+	}
+	if info, statErr := os.Stat(pidFile); statErr == nil {
+		proc.StartedAt = info.ModTime()
+	}
+	return proc, nil
+}
+
+// claimPidFile records the current process as the API server listening on listenAddr. It
+// fails if the pidfile names another live server, since both would run the same schedules.
+func claimPidFile(basePath, listenAddr string) error {
+	existing, err := ReadServerProcess(basePath)
+	if err != nil {
+		util.Log.Warnf("Ignoring unreadable API server pidfile: %v", err)
+	} else if existing != nil && existing.Running && existing.PID != os.Getpid() {
+		return fmt.Errorf("an API server is already running (PID %d, %s); stop it first with 'reflow server stop'", existing.PID, existing.ListenAddr)
+	}
+
+	pidFile := GetPidFilePath(basePath)
+	if err := os.MkdirAll(filepath.Dir(pidFile), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory for API server pidfile: %w", err)
+	}
+	content := fmt.Sprintf("%d\n%s\n", os.Getpid(), listenAddr)
+	if err := os.WriteFile(pidFile, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write API server pidfile %s: %w", pidFile, err)
+	}
+	return nil
+}
+
+// releasePidFile removes the pidfile if it still names pid. A server removes its own pidfile on
+// shutdown; 'server stop' also cleans up after one that was killed before it could.
+func releasePidFile(basePath string, pid int) {
+	existing, err := ReadServerProcess(basePath)
+	if err != nil || existing == nil || existing.PID != pid {
+		return
+	}
+	if err := os.Remove(GetPidFilePath(basePath)); err != nil && !os.IsNotExist(err) {
+		util.Log.Warnf("Failed to remove API server pidfile: %v", err)
+	}
+}
+
+// StartDetached runs the reflow binary with args (which must start an API server) as a
+// background process in its own session, appending its output to the API log. It returns once
+// the new server has written the pidfile, or with an error if it exits first.
+func StartDetached(basePath string, args []string) (*ServerProcess, error) {
+	if existing, err := ReadServerProcess(basePath); err == nil && existing != nil && existing.Running {
+		return nil, fmt.Errorf("an API server is already running (PID %d, %s)", existing.PID, existing.ListenAddr)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the reflow executable: %w", err)
+	}
+
+	logPath := GetLogFilePath(basePath)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open API server log %s: %w", logPath, err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start API server process: %w", err)
+	}
+	util.Log.Debugf("Started detached API server process %d: %s %s", cmd.Process.Pid, executable, strings.Join(args, " "))
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.After(detachedStartTimeout)
+	for {
+		select {
+		case waitErr := <-exited:
+			if waitErr == nil {
+				waitErr = errors.New("exited with status 0")
+			}
+			return nil, fmt.Errorf("API server exited during startup (%v); see %s", waitErr, logPath)
+		case <-deadline:
+			return nil, fmt.Errorf("API server (PID %d) did not report it was listening within %s; see %s", cmd.Process.Pid, detachedStartTimeout, logPath)
+		case <-time.After(processPollInterval):
+			proc, readErr := ReadServerProcess(basePath)
+			if readErr == nil && proc != nil && proc.PID == cmd.Process.Pid {
+				_ = cmd.Process.Release()
+				return proc, nil
+			}
+		}
+	}
+}
+
+// StopServer asks the API server recorded in the pidfile to shut down and waits for it to exit.
+// A pidfile left behind by a server that is no longer running is removed. Returns nil if no
+// server was recorded.
+func StopServer(basePath string) (*ServerProcess, error) {
+	proc, err := ReadServerProcess(basePath)
+	if err != nil || proc == nil {
+		return nil, err
+	}
+	if !proc.Running {
+		util.Log.Infof("API server PID %d is no longer running; removing stale pidfile.", proc.PID)
+		if err := os.Remove(GetPidFilePath(basePath)); err != nil && !os.IsNotExist(err) {
+			return proc, fmt.Errorf("failed to remove stale API server pidfile: %w", err)
+		}
+		return proc, nil
+	}
+
+	if err := terminateProcess(proc.PID); err != nil {
+		return proc, fmt.Errorf("failed to signal API server (PID %d): %w", proc.PID, err)
+	}
+
+	// The server removes its pidfile as part of a graceful shutdown.
+	deadline := time.Now().Add(shutdownTimeout + 5*time.Second)
+	for time.Now().Before(deadline) {
+		if !processAlive(proc.PID) {
+			proc.Running = false
+			releasePidFile(basePath, proc.PID)
+			return proc, nil
+		}
+		time.Sleep(processPollInterval)
+	}
+	return proc, fmt.Errorf("API server (PID %d) did not exit within %s", proc.PID, shutdownTimeout+5*time.Second)
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package api
+
+import (
+	"errors"
+	"syscall"
+)
+
+// detachedProcAttr starts the server in a new session, so it survives the terminal closing.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func terminateProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
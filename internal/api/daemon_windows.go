@@ -0,0 +1,38 @@
+//go:build windows
+
+package api
+
+import (
+	"os"
+	"syscall"
+)
+
+const processQueryLimitedInformation = 0x1000
+
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{HideWindow: true}
+}
+
+func processAlive(pid int) bool {
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(handle)
+	var exitCode uint32
+	if err := syscall.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return false
+	}
+	const stillActive = 259
+	return exitCode == stillActive
+}
+
+// terminateProcess kills the server outright; Windows has no SIGTERM to trigger a graceful
+// shutdown.
+func terminateProcess(pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
@@ -2,6 +2,9 @@ package api
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,23 +12,54 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
-	"path/filepath"
 	"reflow/internal/app"
+	"reflow/internal/audit"
 	"reflow/internal/config"
 	"reflow/internal/deployment"
 	"reflow/internal/docker"
+	"reflow/internal/git"
 	"reflow/internal/orchestrator"
+	"reflow/internal/plugin"
 	"reflow/internal/project"
+	"reflow/internal/scheduler"
 	"reflow/internal/util"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/gorilla/mux"
 )
 
+// healthzTimeout bounds the Docker ping of the health check, so monitors get an answer even
+// when the daemon hangs.
+const healthzTimeout = 3 * time.Second
+
+// --- Health Handler ---
+
+// handleHealthz reports whether the API server is up and the Docker daemon reachable. It answers
+// 503 when Docker is down, as the server cannot deploy or manage containers without it.
+// GET /healthz
+func handleHealthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthzTimeout)
+		defer cancel()
+
+		if err := docker.Ping(ctx); err != nil {
+			util.Log.Warnf("Health check: Docker daemon unreachable: %v", err)
+			writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "unavailable", "docker": false})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "docker": true})
+	}
+}
+
 // --- Project Handlers ---
 
-// handleListProjects retrieves a list of all projects.
+// handleListProjects retrieves a list of all projects. Deployed domains are checked for
+// reachability unless the 'check' query parameter is "false".
 // GET /api/v1/projects
 func handleListProjects(basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -34,6 +68,9 @@ func handleListProjects(basePath string) http.HandlerFunc {
 			writeError(w, http.StatusInternalServerError, "Failed to list projects", err.Error())
 			return
 		}
+		if r.URL.Query().Get("check") != "false" {
+			project.CheckReachability(r.Context(), basePath, summaries)
+		}
 		writeJSON(w, http.StatusOK, summaries)
 	}
 }
@@ -121,9 +158,12 @@ func handleStopProjectEnv(basePath string) http.HandlerFunc {
 
 // --- Orchestration Handlers ---
 
-// handleDeployProject triggers a deployment to the test environment.
+// handleDeployProject triggers a deployment to the test environment. The deployment is recorded
+// as a job whose steps can be followed with GET /api/v1/jobs/{jobId}. With "async": true the
+// request returns 202 with the job ID right away instead of waiting for the deployment.
 // POST /api/v1/projects/{projectName}/deploy
-// Optional body: {"commit": "commit-hash-or-branch"}
+// Optional body: {"commit": "commit-hash-or-branch", "branch": "feature-x", "noCache": true, "push": true, "async": true}
+// "branch" deploys the head of a remote branch and cannot be combined with "commit".
 func handleDeployProject(basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -134,7 +174,11 @@ func handleDeployProject(basePath string) http.HandlerFunc {
 		}
 
 		var payload struct {
-			Commit string `json:"commit,omitempty"`
+			Commit  string `json:"commit,omitempty"`
+			Branch  string `json:"branch,omitempty"`
+			NoCache bool   `json:"noCache,omitempty"`
+			Push    bool   `json:"push,omitempty"`
+			Async   bool   `json:"async,omitempty"`
 		}
 		// Allow empty body or body with commit
 		if r.Body != nil && r.ContentLength > 0 {
@@ -145,20 +189,83 @@ func handleDeployProject(basePath string) http.HandlerFunc {
 			}
 		}
 		commitIsh := payload.Commit
+		if payload.Branch != "" {
+			if commitIsh != "" {
+				writeError(w, http.StatusBadRequest, "Specify either 'commit' or 'branch', not both")
+				return
+			}
+			commitIsh = git.RemoteBranchRevision(payload.Branch)
+		}
 
 		util.Log.Infof("API Request: Deploy project '%s' (Commit: '%s')", projectName, commitIsh)
-		err := orchestrator.DeployTest(context.Background(), basePath, projectName, commitIsh)
+		deployJob := jobs.start("deploy", projectName, "test")
+		ctx := orchestrator.WithProgressSink(context.Background(), deployJob)
+		if payload.NoCache {
+			ctx = orchestrator.WithNoCache(ctx)
+		}
+		if payload.Push {
+			ctx = orchestrator.WithPush(ctx)
+		}
+		jobID := deployJob.snapshot().ID
+
+		if payload.Async {
+			go func() {
+				commitHash, err := orchestrator.DeployTest(ctx, basePath, projectName, commitIsh)
+				deployJob.finish(commitHash, err)
+			}()
+			writeJSON(w, http.StatusAccepted, map[string]string{
+				"message": fmt.Sprintf("Deployment of project '%s' to test started.", projectName),
+				"jobId":   jobID,
+			})
+			return
+		}
+
+		commitHash, err := orchestrator.DeployTest(ctx, basePath, projectName, commitIsh)
+		deployJob.finish(commitHash, err)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to deploy project %s", projectName), err.Error())
+			writeError(w, deploymentErrorStatus(err), fmt.Sprintf("Failed to deploy project %s", projectName), err.Error())
 			return
 		}
 
-		writeJSON(w, http.StatusOK, map[string]string{"message": fmt.Sprintf("Deployment initiated for project '%s'. Check logs for status.", projectName)})
+		response := map[string]string{
+			"message": fmt.Sprintf("Project '%s' deployed to test.", projectName),
+			"commit":  commitHash,
+			"jobId":   jobID,
+		}
+		if len(commitHash) >= 7 {
+			response["shortCommit"] = commitHash[:7]
+			response["message"] = fmt.Sprintf("Project '%s' deployed to test at commit %s.", projectName, commitHash[:7])
+		}
+		writeJSON(w, http.StatusOK, response)
 	}
 }
 
+// handleGetJob returns a job started through the API, with the steps it has gone through.
+// GET /api/v1/jobs/{jobId}
+func handleGetJob() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobID := mux.Vars(r)["jobId"]
+		j := jobs.get(jobID)
+		if j == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("Job '%s' not found", jobID))
+			return
+		}
+		writeJSON(w, http.StatusOK, j.snapshot())
+	}
+}
+
+// deploymentErrorStatus maps a deployment error to an HTTP status: 409 if another deployment of
+// the project is running, 500 otherwise.
+func deploymentErrorStatus(err error) int {
+	if errors.Is(err, orchestrator.ErrDeploymentInProgress) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
 // handleApproveProject triggers promotion from test to prod.
 // POST /api/v1/projects/{projectName}/approve
+// Optional body: {"commit": "commit-hash-or-branch"}, which must match the commit active in test
 func handleApproveProject(basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -168,10 +275,20 @@ func handleApproveProject(basePath string) http.HandlerFunc {
 			return
 		}
 
-		util.Log.Infof("API Request: Approve project '%s' for production", projectName)
-		err := orchestrator.ApproveProd(context.Background(), basePath, projectName)
+		var payload struct {
+			Commit string `json:"commit,omitempty"`
+		}
+		if r.Body != nil && r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid JSON payload", err.Error())
+				return
+			}
+		}
+
+		util.Log.Infof("API Request: Approve project '%s' for production (Commit: '%s')", projectName, payload.Commit)
+		err := orchestrator.ApproveProd(context.Background(), basePath, projectName, payload.Commit)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to approve project %s for production", projectName), err.Error())
+			writeError(w, deploymentErrorStatus(err), fmt.Sprintf("Failed to approve project %s for production", projectName), err.Error())
 			return
 		}
 
@@ -180,12 +297,13 @@ func handleApproveProject(basePath string) http.HandlerFunc {
 }
 
 // handleGetProjectLogs retrieves logs for a project environment.
-// GET /api/v1/projects/{projectName}/{env}/logs?tail=100
+// GET /api/v1/projects/{projectName}/{env}/logs?tail=100&slot=blue
 func handleGetProjectLogs(basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		projectName := vars["projectName"]
 		env := vars["env"]
+		slot := r.URL.Query().Get("slot")
 		tail := r.URL.Query().Get("tail")
 		if tail == "" {
 			tail = "100"
@@ -199,10 +317,14 @@ func handleGetProjectLogs(basePath string) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, "Invalid environment specified (must be 'test' or 'prod')")
 			return
 		}
+		if slot != "" && slot != "blue" && slot != "green" {
+			writeError(w, http.StatusBadRequest, "Invalid slot specified (must be 'blue' or 'green')")
+			return
+		}
 
-		util.Log.Debugf("API Request: Get logs for project '%s' env '%s' (Tail: %s)", projectName, env, tail)
+		util.Log.Debugf("API Request: Get logs for project '%s' env '%s' (Tail: %s, Slot: %s)", projectName, env, tail, slot)
 
-		logContent, err := app.GetAppLogsAsString(r.Context(), basePath, projectName, env, tail)
+		logContent, err := app.GetAppLogsAsString(r.Context(), basePath, projectName, env, slot, tail)
 		if err != nil {
 			if strings.Contains(err.Error(), "no suitable container found") || strings.Contains(err.Error(), "not found") {
 				writeError(w, http.StatusNotFound, "Logs not available", err.Error())
@@ -218,6 +340,34 @@ func handleGetProjectLogs(basePath string) http.HandlerFunc {
 	}
 }
 
+// handleGetProjectEnvStats retrieves resource usage for the active container(s) of a project environment.
+// GET /api/v1/projects/{projectName}/{env}/stats
+func handleGetProjectEnvStats(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		projectName := vars["projectName"]
+		env := vars["env"]
+
+		if projectName == "" || env == "" {
+			writeError(w, http.StatusBadRequest, "Project name and environment are required")
+			return
+		}
+
+		util.Log.Debugf("API Request: Get stats for project '%s' env '%s'", projectName, env)
+
+		stats, err := app.GetProjectEnvStats(r.Context(), basePath, projectName, env)
+		if err != nil {
+			if strings.Contains(err.Error(), "no active deployment") || strings.Contains(err.Error(), "no running container") || errors.Is(err, os.ErrNotExist) {
+				writeError(w, http.StatusNotFound, "Stats not available", err.Error())
+			} else {
+				writeError(w, http.StatusInternalServerError, "Failed to get stats", err.Error())
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, stats)
+	}
+}
+
 // --- Container Handlers ---
 
 // handleListContainers lists all Reflow-managed containers.
@@ -361,6 +511,30 @@ func handleDeleteContainer() http.HandlerFunc {
 	}
 }
 
+// handleGetContainerStats retrieves a single resource usage snapshot for a container.
+// GET /api/v1/containers/{containerId}/stats
+func handleGetContainerStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		containerID := vars["containerId"]
+		if containerID == "" {
+			writeError(w, http.StatusBadRequest, "Container ID is required")
+			return
+		}
+
+		stats, err := docker.GetContainerStats(r.Context(), containerID)
+		if err != nil {
+			if docker.IsErrNotFound(err) {
+				writeError(w, http.StatusNotFound, "Container not found", err.Error())
+			} else {
+				writeError(w, http.StatusInternalServerError, "Failed to get container stats", err.Error())
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, stats)
+	}
+}
+
 // --- Project Config and Env File Handlers ---
 
 // handleCreateProject creates a new project via the API.
@@ -374,13 +548,20 @@ func handleCreateProject(basePath string) http.HandlerFunc {
 			return
 		}
 
-		if args.ProjectName == "" || args.RepoURL == "" {
-			writeError(w, http.StatusBadRequest, "Missing required fields: projectName and repoUrl")
-			return
+		if args.SourceType == config.SourceTypeLocal {
+			if args.ProjectName == "" || args.LocalPath == "" {
+				writeError(w, http.StatusBadRequest, "Missing required fields: projectName and localPath")
+				return
+			}
+			util.Log.Infof("API Request: Create project '%s' from local directory '%s'", args.ProjectName, args.LocalPath)
+		} else {
+			if args.ProjectName == "" || args.RepoURL == "" {
+				writeError(w, http.StatusBadRequest, "Missing required fields: projectName and repoUrl")
+				return
+			}
+			util.Log.Infof("API Request: Create project '%s' from repo '%s'", args.ProjectName, util.RedactURL(args.RepoURL))
 		}
 
-		util.Log.Infof("API Request: Create project '%s' from repo '%s'", args.ProjectName, args.RepoURL)
-
 		err = project.CreateProject(basePath, args)
 		if err != nil {
 			if strings.Contains(err.Error(), "already exists") {
@@ -396,7 +577,7 @@ func handleCreateProject(basePath string) http.HandlerFunc {
 	}
 }
 
-// handleGetProjectConfig gets the project configuration.
+// handleGetProjectConfig gets the project configuration, with its secrets masked.
 // GET /api/v1/projects/{projectName}/config
 func handleGetProjectConfig(basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -416,11 +597,13 @@ func handleGetProjectConfig(basePath string) http.HandlerFunc {
 			}
 			return
 		}
-		writeJSON(w, http.StatusOK, projCfg)
+		writeJSON(w, http.StatusOK, redactProjectConfig(*projCfg))
 	}
 }
 
-// handleUpdateProjectConfig updates the project configuration.
+// handleUpdateProjectConfig updates the project configuration. The config is validated like on
+// load, e.g. the ExtraLocations of each environment, and rejected with 400 if invalid. Secrets
+// that are omitted or sent masked, as GET returns them, keep their stored value.
 // PUT /api/v1/projects/{projectName}/config
 func handleUpdateProjectConfig(basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -447,44 +630,139 @@ func handleUpdateProjectConfig(basePath string) http.HandlerFunc {
 
 		util.Log.Infof("API Request: Update config for project '%s'", projectName)
 
+		if storedCfg, loadErr := config.LoadProjectConfig(basePath, projectName); loadErr == nil {
+			updatedCfg.WebhookSecret = keepStoredSecret(updatedCfg.WebhookSecret, storedCfg.WebhookSecret, true)
+			updatedCfg.SSHKeyPassphrase = keepStoredSecret(updatedCfg.SSHKeyPassphrase, storedCfg.SSHKeyPassphrase, true)
+		}
+		if err := config.ValidateProjectConfig(basePath, &updatedCfg); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid project config", err.Error())
+			return
+		}
 		err = config.SaveProjectConfig(basePath, &updatedCfg)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "Failed to save project config", err.Error())
 			return
 		}
 
-		writeJSON(w, http.StatusOK, updatedCfg)
+		writeJSON(w, http.StatusOK, redactProjectConfig(updatedCfg))
 	}
 }
 
-// getEnvFilePath helper function to find the env file path
-func getEnvFilePath(basePath, projectName, env string) (string, error) {
-	projCfg, err := config.LoadProjectConfig(basePath, projectName)
-	if err != nil {
-		return "", fmt.Errorf("could not load project config to find env file path: %w", err)
-	}
+// handlePatchProjectConfig merges the provided fields into the existing project configuration.
+// PATCH /api/v1/projects/{projectName}/config
+// Fields omitted from the payload are left untouched; a JSON null removes a field. Secrets sent
+// masked, as GET returns them, keep their stored value.
+func handlePatchProjectConfig(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		projectName := vars["projectName"]
+		if projectName == "" {
+			writeError(w, http.StatusBadRequest, "Project name is required")
+			return
+		}
 
-	envConf, ok := projCfg.Environments[env]
-	if !ok {
-		return "", fmt.Errorf("environment '%s' not defined in project config", env)
+		existingCfg, err := config.LoadProjectConfig(basePath, projectName)
+		if err != nil {
+			if os.IsNotExist(err) || strings.Contains(err.Error(), "config file not found") {
+				writeError(w, http.StatusNotFound, "Project config not found", err.Error())
+			} else {
+				writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+			}
+			return
+		}
+
+		var patch map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON payload", err.Error())
+			return
+		}
+
+		if name, ok := patch["ProjectName"]; ok {
+			if nameStr, isStr := name.(string); !isStr || nameStr != projectName {
+				writeError(w, http.StatusBadRequest, "Project name in payload does not match URL path")
+				return
+			}
+		}
+
+		existingBytes, err := json.Marshal(existingCfg)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to encode existing project config", err.Error())
+			return
+		}
+		var merged map[string]interface{}
+		if err := json.Unmarshal(existingBytes, &merged); err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to decode existing project config", err.Error())
+			return
+		}
+		mergeJSONObjects(merged, patch)
+
+		mergedBytes, err := json.Marshal(merged)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to encode merged project config", err.Error())
+			return
+		}
+		var updatedCfg config.ProjectConfig
+		if err := json.Unmarshal(mergedBytes, &updatedCfg); err != nil {
+			writeError(w, http.StatusBadRequest, "Patched config is invalid", err.Error())
+			return
+		}
+		updatedCfg.ProjectName = projectName
+		updatedCfg.WebhookSecret = keepStoredSecret(updatedCfg.WebhookSecret, existingCfg.WebhookSecret, false)
+		updatedCfg.SSHKeyPassphrase = keepStoredSecret(updatedCfg.SSHKeyPassphrase, existingCfg.SSHKeyPassphrase, false)
+
+		util.Log.Infof("API Request: Patch config for project '%s'", projectName)
+
+		if err := config.ValidateProjectConfig(basePath, &updatedCfg); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid project config", err.Error())
+			return
+		}
+		if err := config.SaveProjectConfig(basePath, &updatedCfg); err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to save project config", err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, redactProjectConfig(updatedCfg))
 	}
-	if envConf.EnvFile == "" {
-		return "", fmt.Errorf("no envFile specified for environment '%s' in project config", env)
+}
+
+// redactProjectConfig returns projCfg with its secrets masked, for API responses.
+func redactProjectConfig(projCfg config.ProjectConfig) config.ProjectConfig {
+	projCfg.WebhookSecret = config.MaskSecretValue(projCfg.WebhookSecret)
+	projCfg.SSHKeyPassphrase = config.MaskSecretValue(projCfg.SSHKeyPassphrase)
+	return projCfg
+}
+
+// keepStoredSecret returns the stored value of a secret when an update sends it masked or, with
+// emptyKeeps, leaves it empty; otherwise it returns the updated value.
+func keepStoredSecret(updated, stored string, emptyKeeps bool) string {
+	if updated == config.MaskedSecretValue || (updated == "" && emptyKeeps) {
+		return stored
 	}
+	return updated
+}
 
-	repoPath := filepath.Join(config.GetProjectBasePath(basePath, projectName), config.RepoDirName)
-	fullPath := filepath.Join(repoPath, envConf.EnvFile)
+// getEnvFilePath helper function to find the env file path. With source "secrets" it returns
+// the Reflow-managed secrets file instead of the env file in the repo.
+func getEnvFilePath(basePath, projectName, env, source string) (string, error) {
+	projCfg, err := config.LoadProjectConfig(basePath, projectName)
+	if err != nil {
+		return "", fmt.Errorf("could not load project config to find env file path: %w", err)
+	}
 
-	cleanPath := filepath.Clean(fullPath)
-	if !strings.HasPrefix(cleanPath, filepath.Clean(repoPath)+string(os.PathSeparator)) && cleanPath != filepath.Clean(repoPath) {
-		return "", fmt.Errorf("invalid env file path '%s' resolves outside repo directory", envConf.EnvFile)
+	switch source {
+	case "", "repo":
+	case "secrets":
+		return config.GetProjectSecretsPath(basePath, projectName, env), nil
+	default:
+		return "", fmt.Errorf("invalid env file path source '%s' (expected repo or secrets)", source)
 	}
 
-	return cleanPath, nil
+	return config.ProjectEnvFilePath(basePath, projCfg, env)
 }
 
-// handleGetEnvFile retrieves the content of a project's environment file.
-// GET /api/v1/projects/{projectName}/{env}/envfile
+// handleGetEnvFile retrieves the content of a project's environment file, or of its secrets file
+// with source=secrets.
+// GET /api/v1/projects/{projectName}/{env}/envfile?source=repo|secrets
 func handleGetEnvFile(basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -495,7 +773,8 @@ func handleGetEnvFile(basePath string) http.HandlerFunc {
 			return
 		}
 
-		envFilePath, err := getEnvFilePath(basePath, projectName, env)
+		source := r.URL.Query().Get("source")
+		envFilePath, err := getEnvFilePath(basePath, projectName, env, source)
 		if err != nil {
 			if strings.Contains(err.Error(), "project config not found") {
 				writeError(w, http.StatusNotFound, "Project not found", err.Error())
@@ -508,10 +787,17 @@ func handleGetEnvFile(basePath string) http.HandlerFunc {
 		}
 
 		util.Log.Debugf("API Request: Get env file content for project '%s', env '%s' from path '%s'", projectName, env, envFilePath)
-		content, err := os.ReadFile(envFilePath)
-		if err != nil {
+		var content []byte
+		if source == "secrets" {
+			content, err = config.LoadEncryptedEnv(basePath, envFilePath)
+		} else {
+			content, err = os.ReadFile(envFilePath)
+		}
+		if err != nil && !(os.IsNotExist(err) && source == "secrets") { // No secrets set yet reads as empty
 			if os.IsNotExist(err) {
 				writeError(w, http.StatusNotFound, "Environment file not found at specified path", envFilePath)
+			} else if errors.Is(err, config.ErrSecretKeyMissing) {
+				writeError(w, http.StatusConflict, "Secrets file is encrypted and no key is available", err.Error())
 			} else {
 				writeError(w, http.StatusInternalServerError, "Failed to read environment file", err.Error())
 			}
@@ -524,8 +810,37 @@ func handleGetEnvFile(basePath string) http.HandlerFunc {
 	}
 }
 
-// handleUpdateEnvFile updates the content of a project's environment file.
-// PUT /api/v1/projects/{projectName}/{env}/envfile
+// handleGetEnvDiff compares a project's test and prod env files.
+// GET /api/v1/projects/{projectName}/env-diff
+func handleGetEnvDiff(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectName := mux.Vars(r)["projectName"]
+		if projectName == "" {
+			writeError(w, http.StatusBadRequest, "Project name is required")
+			return
+		}
+
+		util.Log.Debugf("API Request: Diff env files for project '%s'", projectName)
+		diff, err := app.DiffEnvFiles(basePath, projectName)
+		if err != nil {
+			if strings.Contains(err.Error(), "config file not found") {
+				writeError(w, http.StatusNotFound, "Project not found", err.Error())
+			} else if strings.Contains(err.Error(), "invalid") {
+				writeError(w, http.StatusBadRequest, "Cannot compare env files", err.Error())
+			} else {
+				writeError(w, http.StatusInternalServerError, "Failed to compare env files", err.Error())
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, diff)
+	}
+}
+
+// handleUpdateEnvFile updates the content of a project's environment file, or of its secrets
+// file with source=secrets. The content is validated line by line first and rejected with 422
+// listing the offending lines; with validateOnly=true nothing is written. The response carries
+// the number of variables the content assigns.
+// PUT /api/v1/projects/{projectName}/{env}/envfile?source=repo|secrets&validateOnly=true
 func handleUpdateEnvFile(basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -536,7 +851,8 @@ func handleUpdateEnvFile(basePath string) http.HandlerFunc {
 			return
 		}
 
-		envFilePath, err := getEnvFilePath(basePath, projectName, env)
+		source := r.URL.Query().Get("source")
+		envFilePath, err := getEnvFilePath(basePath, projectName, env, source)
 		if err != nil {
 			if strings.Contains(err.Error(), "project config not found") {
 				writeError(w, http.StatusNotFound, "Project not found", err.Error())
@@ -562,22 +878,167 @@ func handleUpdateEnvFile(basePath string) http.HandlerFunc {
 			}
 		}(r.Body)
 
+		keyCount, problems := util.ValidateEnvContent(bodyBytes)
+		if len(problems) > 0 {
+			util.Log.Warnf("API Error %d: Invalid env file content for project '%s', env '%s': %d invalid line(s)", http.StatusUnprocessableEntity, projectName, env, len(problems))
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":   "Invalid env file content",
+				"details": fmt.Sprintf("%d line(s) are not blank, a '#' comment or KEY=VALUE", len(problems)),
+				"lines":   problems,
+			})
+			return
+		}
+		if r.URL.Query().Get("validateOnly") == "true" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"valid": true, "keyCount": keyCount})
+			return
+		}
+
 		util.Log.Infof("API Request: Update env file content for project '%s', env '%s' at path '%s'", projectName, env, envFilePath)
 
-		err = os.WriteFile(envFilePath, bodyBytes, 0644)
+		if source == "secrets" {
+			err = config.WriteSecretsFile(basePath, projectName, env, bodyBytes)
+		} else {
+			err = config.WriteEnvFile(envFilePath, bodyBytes)
+		}
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "Failed to write environment file", err.Error())
 			return
 		}
 
+		writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Environment file updated.", "keyCount": keyCount})
+	}
+}
+
+// --- Secrets Handlers ---
+
+// secretEntry is a single secret as returned by the secrets API.
+type secretEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// loadSecretsProject checks that the project of a secrets request exists, writing the error
+// response and returning false if it does not.
+func loadSecretsProject(w http.ResponseWriter, basePath, projectName, env string) bool {
+	if projectName == "" || (env != "test" && env != "prod") {
+		writeError(w, http.StatusBadRequest, "Project name and valid environment (test/prod) are required")
+		return false
+	}
+	if _, err := config.LoadProjectConfig(basePath, projectName); err != nil {
+		if os.IsNotExist(err) || strings.Contains(err.Error(), "config file not found") {
+			writeError(w, http.StatusNotFound, "Project config not found", err.Error())
+		} else {
+			writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+		}
+		return false
+	}
+	return true
+}
+
+// handleListSecrets lists the secrets of a project environment. Values are masked unless
+// reveal=true is passed.
+// GET /api/v1/projects/{projectName}/{env}/secrets?reveal=false
+func handleListSecrets(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		projectName := vars["projectName"]
+		env := vars["env"]
+		if !loadSecretsProject(w, basePath, projectName, env) {
+			return
+		}
+		reveal := r.URL.Query().Get("reveal") == "true"
+
+		entries, err := config.ReadSecrets(basePath, projectName, env)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to read secrets", err.Error())
+			return
+		}
+
+		secrets := make([]secretEntry, 0, len(entries))
+		for _, entry := range entries {
+			key, value, _ := strings.Cut(entry, "=")
+			if !reveal {
+				value = config.MaskSecretValue(value)
+			}
+			secrets = append(secrets, secretEntry{Key: key, Value: value})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"secrets": secrets})
+	}
+}
+
+// handleSetSecrets adds or updates secrets of a project environment from a JSON object of
+// KEY: VALUE pairs. Changes apply to the next deployment.
+// PUT /api/v1/projects/{projectName}/{env}/secrets
+func handleSetSecrets(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		projectName := vars["projectName"]
+		env := vars["env"]
+		if !loadSecretsProject(w, basePath, projectName, env) {
+			return
+		}
+
+		var values map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&values); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body: expected a JSON object of secret names to values", err.Error())
+			return
+		}
+		if len(values) == 0 {
+			writeError(w, http.StatusBadRequest, "At least one secret is required")
+			return
+		}
+
+		pairs := make([]string, 0, len(values))
+		for key, value := range values {
+			pairs = append(pairs, key+"="+value)
+		}
+		sort.Strings(pairs)
+
+		util.Log.Infof("API Request: Set %d secret(s) for project '%s', env '%s'", len(pairs), projectName, env)
+		if err := config.SetSecrets(basePath, projectName, env, pairs); err != nil {
+			if strings.HasPrefix(err.Error(), "invalid") {
+				writeError(w, http.StatusBadRequest, "Invalid secret", err.Error())
+			} else {
+				writeError(w, http.StatusInternalServerError, "Failed to save secrets", err.Error())
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleUnsetSecret removes a secret from a project environment.
+// DELETE /api/v1/projects/{projectName}/{env}/secrets/{key}
+func handleUnsetSecret(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		projectName := vars["projectName"]
+		env := vars["env"]
+		key := vars["key"]
+		if !loadSecretsProject(w, basePath, projectName, env) {
+			return
+		}
+
+		util.Log.Infof("API Request: Unset secret '%s' for project '%s', env '%s'", key, projectName, env)
+		removed, err := config.UnsetSecrets(basePath, projectName, env, []string{key})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to save secrets", err.Error())
+			return
+		}
+		if len(removed) == 0 {
+			writeError(w, http.StatusNotFound, "Secret not found", key)
+			return
+		}
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
 // --- Deployment History Handler ---
 
-// handleListDeployments retrieves deployment history for a project.
-// GET /api/v1/projects/{projectName}/deployments?limit=25&offset=0&env=&outcome=
+// handleListDeployments retrieves a page of deployment history for a project. Offset requests
+// include the total number of events matching the filters; pass a page's nextCursor as cursor
+// to fetch the following page without re-reading the whole log.
+// GET /api/v1/projects/{projectName}/deployments?limit=25&offset=0&cursor=&env=&outcome=
 func handleListDeployments(basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -589,22 +1050,394 @@ func handleListDeployments(basePath string) http.HandlerFunc {
 
 		limit := r.URL.Query().Get("limit")
 		offset := r.URL.Query().Get("offset")
+		cursor := r.URL.Query().Get("cursor")
 		envFilter := r.URL.Query().Get("env")
 		outcomeFilter := r.URL.Query().Get("outcome")
 
 		util.Log.Debugf("API Request: Get deployment history for project '%s' (Limit: %s, Offset: %s, Env: %s, Outcome: %s)",
 			projectName, limit, offset, envFilter, outcomeFilter)
 
-		history, err := deployment.ListHistory(basePath, projectName, limit, offset, envFilter, outcomeFilter)
+		page, err := deployment.ListHistory(basePath, projectName, limit, offset, cursor, deployment.HistoryFilter{Env: envFilter, Outcome: outcomeFilter})
+		if errors.Is(err, deployment.ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "Invalid or expired cursor; request the first page without one", err.Error())
+			return
+		}
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "Failed to retrieve deployment history", err.Error())
 			return
 		}
 
-		if history == nil {
-			history = []config.DeploymentEvent{}
+		writeJSON(w, http.StatusOK, page)
+	}
+}
+
+// handleGetDeployProgress streams the progress messages of a project's running deployment as
+// plain text, using chunked transfer encoding, until the deployment finishes. For a finished
+// deployment the messages of the last one are returned at once.
+// GET /api/v1/projects/{projectName}/deploy/progress
+func handleGetDeployProgress(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		projectName := vars["projectName"]
+		if projectName == "" {
+			writeError(w, http.StatusBadRequest, "Project name is required")
+			return
+		}
+
+		util.Log.Debugf("API Request: Stream deployment progress for project '%s'", projectName)
+
+		// Deployments outlast the server's write timeout, so lift it for this response.
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			util.Log.Debugf("Could not clear write deadline for progress stream: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		_, err := deployment.TailProgress(r.Context(), basePath, projectName, w, func() { _ = rc.Flush() })
+		if errors.Is(err, deployment.ErrNoProgress) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No deployment progress found for project '%s'", projectName), err.Error())
+			return
+		}
+		if err != nil && r.Context().Err() == nil {
+			util.Log.Warnf("Deployment progress stream for project '%s' ended: %v", projectName, err)
+		}
+	}
+}
+
+// --- Audit Log Handler ---
+
+// Limits of GET /api/v1/audit.
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 1000
+)
+
+// handleListAuditLog returns the newest API write operations from the audit log, optionally only
+// those at or after 'since' (RFC 3339).
+// GET /api/v1/audit?limit=50&since=2024-01-02T15:04:05Z
+func handleListAuditLog(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultAuditLimit
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			parsed, err := strconv.Atoi(limitStr)
+			if err != nil || parsed < 1 || parsed > maxAuditLimit {
+				writeError(w, http.StatusBadRequest, "Invalid limit", fmt.Sprintf("limit must be a number between 1 and %d", maxAuditLimit))
+				return
+			}
+			limit = parsed
+		}
+		var since time.Time
+		if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+			parsed, err := time.Parse(time.RFC3339, sinceStr)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid since", "since must be an RFC 3339 timestamp, e.g. 2024-01-02T15:04:05Z")
+				return
+			}
+			since = parsed
+		}
+
+		entries, err := audit.ReadEntries(basePath, limit, since)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to read audit log", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"entries": entries})
+	}
+}
+
+// --- Webhook Handlers ---
+
+// githubPushPayload holds the subset of a GitHub push event payload used by the webhook handler.
+type githubPushPayload struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+	HeadCommit *struct {
+		ID string `json:"id"`
+	} `json:"head_commit"`
+}
+
+// verifyGithubSignature checks the X-Hub-Signature-256 header against the HMAC-SHA256 of the body.
+func verifyGithubSignature(secret string, body []byte, signatureHeader string) bool {
+	if secret == "" || !strings.HasPrefix(signatureHeader, "sha256=") {
+		return false
+	}
+	receivedSig, err := hex.DecodeString(strings.TrimPrefix(signatureHeader, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(receivedSig, mac.Sum(nil))
+}
+
+// maxWebhookBodyBytes bounds the payload a webhook request may send, matching GitHub's own cap,
+// since the endpoint is reachable without API authentication.
+const maxWebhookBodyBytes = 25 << 20
+
+// handleProjectWebhook triggers a test deployment from a GitHub push event.
+// POST /api/v1/projects/{projectName}/webhook
+func handleProjectWebhook(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		projectName := vars["projectName"]
+		if projectName == "" {
+			writeError(w, http.StatusBadRequest, "Project name is required")
+			return
+		}
+
+		projCfg, err := config.LoadProjectConfig(basePath, projectName)
+		if err != nil {
+			if strings.Contains(err.Error(), "config file not found") {
+				writeError(w, http.StatusNotFound, "Project not found", err.Error())
+			} else {
+				writeError(w, http.StatusInternalServerError, "Failed to load project config", err.Error())
+			}
+			return
+		}
+
+		bodyBytes, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeError(w, http.StatusRequestEntityTooLarge, "Request body too large", fmt.Sprintf("Webhook payloads are limited to %d bytes.", maxWebhookBodyBytes))
+				return
+			}
+			writeError(w, http.StatusBadRequest, "Failed to read request body", err.Error())
+			return
+		}
+
+		if projCfg.WebhookSecret == "" {
+			writeError(w, http.StatusUnauthorized, "Webhook not configured", fmt.Sprintf("Project '%s' has no webhookSecret set.", projectName))
+			return
+		}
+		if !verifyGithubSignature(projCfg.WebhookSecret, bodyBytes, r.Header.Get("X-Hub-Signature-256")) {
+			writeError(w, http.StatusUnauthorized, "Invalid webhook signature")
+			return
+		}
+
+		eventType := r.Header.Get("X-GitHub-Event")
+		if eventType == "ping" {
+			writeJSON(w, http.StatusOK, map[string]string{"message": "pong"})
+			return
+		}
+		if eventType != "" && eventType != "push" {
+			writeJSON(w, http.StatusOK, map[string]string{"message": fmt.Sprintf("Ignoring '%s' event.", eventType)})
+			return
+		}
+
+		var payload githubPushPayload
+		if err := json.Unmarshal(bodyBytes, &payload); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON payload", err.Error())
+			return
+		}
+
+		branch := strings.TrimPrefix(payload.Ref, "refs/heads/")
+		if projCfg.DeployBranch == "" || branch != projCfg.DeployBranch {
+			util.Log.Debugf("Webhook for project '%s': push to '%s' does not match deployBranch '%s', ignoring.", projectName, branch, projCfg.DeployBranch)
+			writeJSON(w, http.StatusOK, map[string]string{"message": fmt.Sprintf("Push to '%s' ignored (deployBranch is '%s').", branch, projCfg.DeployBranch)})
+			return
+		}
+		if payload.Deleted {
+			writeJSON(w, http.StatusOK, map[string]string{"message": fmt.Sprintf("Branch '%s' deleted, nothing to deploy.", branch)})
+			return
+		}
+
+		commitIsh := payload.After
+		if payload.HeadCommit != nil && payload.HeadCommit.ID != "" {
+			commitIsh = payload.HeadCommit.ID
+		}
+		if commitIsh == "" {
+			writeError(w, http.StatusBadRequest, "Push payload does not contain a head commit")
+			return
+		}
+
+		util.Log.Infof("Webhook: Push to '%s' for project '%s', deploying commit %s to test", branch, projectName, commitIsh)
+		go func() {
+			if _, deployErr := orchestrator.DeployTest(context.Background(), basePath, projectName, commitIsh); deployErr != nil {
+				util.Log.Errorf("Webhook-triggered deployment for project '%s' failed: %v", projectName, deployErr)
+			}
+		}()
+
+		writeJSON(w, http.StatusAccepted, map[string]string{"message": fmt.Sprintf("Deployment of commit '%s' to test triggered for project '%s'.", commitIsh, projectName)})
+	}
+}
+
+// --- Config Handlers ---
+
+// reloadConfiguration re-reads the global config, the plugin state and the deploy schedules from
+// disk. The API server's listen address and port, and the debug setting, still need a restart.
+func reloadConfiguration(basePath string, sched *scheduler.Scheduler) error {
+	if _, err := config.ReloadGlobalConfig(basePath); err != nil {
+		return err
+	}
+	if _, err := config.ReloadGlobalPluginState(basePath); err != nil {
+		return err
+	}
+	if err := sched.Reload(); err != nil {
+		return fmt.Errorf("failed to reload deploy schedules: %w", err)
+	}
+	return nil
+}
+
+// handleReloadConfig re-reads the global config, plugin state and schedules without a restart.
+// POST /api/v1/config/reload
+func handleReloadConfig(basePath string, sched *scheduler.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		util.Log.Info("API Request: Reload configuration")
+		if err := reloadConfiguration(basePath, sched); err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to reload configuration", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"message":   "Configuration reloaded. Changes to the API server's host, port or debug setting require a restart.",
+			"schedules": len(sched.Entries()),
+		})
+	}
+}
+
+// --- Plugin Handlers ---
+
+// handleGetPluginStatus retrieves an installed plugin's state, including its container's live
+// Docker state, uptime and resource usage, Nginx config file, access URL and config values
+// (sensitive ones masked).
+// GET /api/v1/plugins/{pluginName}/status
+func handleGetPluginStatus(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pluginName := mux.Vars(r)["pluginName"]
+		if pluginName == "" {
+			writeError(w, http.StatusBadRequest, "Plugin name is required")
+			return
+		}
+
+		status, err := plugin.GetPluginStatus(r.Context(), basePath, pluginName)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				writeError(w, http.StatusNotFound, "Plugin not found", fmt.Sprintf("Plugin '%s' is not installed.", pluginName))
+			} else {
+				writeError(w, http.StatusInternalServerError, "Failed to get plugin status", err.Error())
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+// handleGetPluginLogs returns the logs of a container plugin's container as plain text. With
+// follow=true the response is streamed, using chunked transfer encoding, until the client
+// disconnects.
+// GET /api/v1/plugins/{pluginName}/logs?tail=100&follow=true
+func handleGetPluginLogs(basePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pluginName := mux.Vars(r)["pluginName"]
+		if pluginName == "" {
+			writeError(w, http.StatusBadRequest, "Plugin name is required")
+			return
+		}
+		tail := r.URL.Query().Get("tail")
+		if tail == "" {
+			tail = "100"
+		}
+		follow := r.URL.Query().Get("follow") == "true"
+
+		util.Log.Debugf("API Request: Get logs for plugin '%s' (Tail: %s, Follow: %v)", pluginName, tail, follow)
+
+		logReader, err := plugin.GetPluginLogs(r.Context(), basePath, pluginName, follow, tail)
+		if err != nil {
+			switch {
+			case strings.Contains(err.Error(), "has no container"), strings.Contains(err.Error(), "is disabled"):
+				writeError(w, http.StatusConflict, "Logs not available", err.Error())
+			case strings.Contains(err.Error(), "not found"):
+				writeError(w, http.StatusNotFound, "Logs not available", err.Error())
+			default:
+				writeError(w, http.StatusInternalServerError, "Failed to get plugin logs", err.Error())
+			}
+			return
+		}
+		defer logReader.Close()
+
+		rc := http.NewResponseController(w)
+		if follow {
+			// A followed log outlasts the server's write timeout, so lift it for this response.
+			if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+				util.Log.Debugf("Could not clear write deadline for plugin log stream: %v", err)
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+
+		out := flushWriter{w: w, flush: func() { _ = rc.Flush() }}
+		if _, err := stdcopy.StdCopy(out, out, logReader); err != nil && !errors.Is(err, io.EOF) && r.Context().Err() == nil {
+			util.Log.Warnf("Log stream for plugin '%s' ended: %v", pluginName, err)
+		}
+	}
+}
+
+// flushWriter flushes the response after every write, so streamed output reaches the client as
+// it is produced.
+type flushWriter struct {
+	w     io.Writer
+	flush func()
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.flush()
+	return n, err
+}
+
+// --- Schedule Handlers ---
+
+func handleListSchedules(sched *scheduler.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, sched.Entries())
+	}
+}
+
+// scheduleConfigMutex serializes loading, changing and saving the deploy schedules in the global
+// config, so concurrent requests cannot drop each other's schedules.
+var scheduleConfigMutex sync.Mutex
+
+func handleCreateSchedule(basePath string, sched *scheduler.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var entry config.ScheduleEntry
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON payload", err.Error())
+			return
+		}
+
+		util.Log.Infof("API Request: Create %s schedule '%s' for project '%s'", entry.Env, entry.CronExpr, entry.ProjectName)
+
+		if err := scheduler.ValidateEntry(basePath, entry); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid schedule", err.Error())
+			return
+		}
+
+		scheduleConfigMutex.Lock()
+		defer scheduleConfigMutex.Unlock()
+
+		globalCfg, err := config.LoadGlobalConfig(basePath)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to load global config", err.Error())
+			return
+		}
+		// The loaded config is a shallow copy of the cached one, so append to a fresh slice
+		// rather than into the cached config's backing array.
+		schedules := make([]config.ScheduleEntry, 0, len(globalCfg.DeploySchedules)+1)
+		schedules = append(schedules, globalCfg.DeploySchedules...)
+		globalCfg.DeploySchedules = append(schedules, entry)
+		if err := config.SaveGlobalConfig(basePath, globalCfg); err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to save schedule", err.Error())
+			return
+		}
+
+		if err := sched.Add(entry); err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to register schedule", err.Error())
+			return
 		}
 
-		writeJSON(w, http.StatusOK, history)
+		writeJSON(w, http.StatusCreated, entry)
 	}
 }
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflow/internal/config"
+	"reflow/internal/scheduler"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	testWebhookSecret = "webhook-secret"
+	testPassphrase    = "key-passphrase"
+)
+
+// newConfigTestBase creates a base directory with a project "app" whose config has secrets.
+func newConfigTestBase(t *testing.T) string {
+	t.Helper()
+	base := t.TempDir()
+	projCfg := &config.ProjectConfig{
+		ProjectName:      "app",
+		GithubRepo:       "https://example.com/app.git",
+		SourceType:       config.SourceTypeGit,
+		Replicas:         1,
+		WebhookSecret:    testWebhookSecret,
+		SSHKeyPassphrase: testPassphrase,
+	}
+	if err := config.SaveProjectConfig(base, projCfg); err != nil {
+		t.Fatal(err)
+	}
+	return base
+}
+
+// serveConfigRequest runs handler for a request to the config of project "app".
+func serveConfigRequest(t *testing.T, handler http.HandlerFunc, method string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *bytes.Reader
+	if body == nil {
+		reader = bytes.NewReader(nil)
+	} else {
+		content, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(content)
+	}
+	req := mux.SetURLVars(httptest.NewRequest(method, "/api/v1/projects/app/config", reader), map[string]string{"projectName": "app"})
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+// assertSecretsMasked fails if the response body contains a stored secret.
+func assertSecretsMasked(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if body := rec.Body.String(); strings.Contains(body, testWebhookSecret) || strings.Contains(body, testPassphrase) {
+		t.Errorf("response exposes a secret: %s", body)
+	}
+	var projCfg config.ProjectConfig
+	if err := json.Unmarshal(rec.Body.Bytes(), &projCfg); err != nil {
+		t.Fatal(err)
+	}
+	if projCfg.WebhookSecret != config.MaskedSecretValue || projCfg.SSHKeyPassphrase != config.MaskedSecretValue {
+		t.Errorf("secrets in response = %q, %q; want them masked", projCfg.WebhookSecret, projCfg.SSHKeyPassphrase)
+	}
+}
+
+// assertStoredSecrets fails unless the saved config of project "app" has the given secrets.
+func assertStoredSecrets(t *testing.T, base, webhookSecret, passphrase string) {
+	t.Helper()
+	projCfg, err := config.LoadProjectConfig(base, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projCfg.WebhookSecret != webhookSecret || projCfg.SSHKeyPassphrase != passphrase {
+		t.Errorf("stored secrets = %q, %q; want %q, %q", projCfg.WebhookSecret, projCfg.SSHKeyPassphrase, webhookSecret, passphrase)
+	}
+}
+
+func TestProjectConfigSecretsAreMasked(t *testing.T) {
+	base := newConfigTestBase(t)
+
+	rec := serveConfigRequest(t, handleGetProjectConfig(base), http.MethodGet, nil)
+	assertSecretsMasked(t, rec)
+
+	// A config read from GET and sent back unchanged keeps the stored secrets.
+	var fetched config.ProjectConfig
+	if err := json.Unmarshal(rec.Body.Bytes(), &fetched); err != nil {
+		t.Fatal(err)
+	}
+	fetched.Replicas = 2
+	assertSecretsMasked(t, serveConfigRequest(t, handleUpdateProjectConfig(base), http.MethodPut, fetched))
+	assertStoredSecrets(t, base, testWebhookSecret, testPassphrase)
+
+	// Omitted from PUT, they are kept as well.
+	fetched.WebhookSecret, fetched.SSHKeyPassphrase = "", ""
+	assertSecretsMasked(t, serveConfigRequest(t, handleUpdateProjectConfig(base), http.MethodPut, fetched))
+	assertStoredSecrets(t, base, testWebhookSecret, testPassphrase)
+
+	patch := map[string]any{"WebhookSecret": config.MaskedSecretValue, "Replicas": 3}
+	assertSecretsMasked(t, serveConfigRequest(t, handlePatchProjectConfig(base), http.MethodPatch, patch))
+	assertStoredSecrets(t, base, testWebhookSecret, testPassphrase)
+
+	patch = map[string]any{"WebhookSecret": "rotated"}
+	assertSecretsMasked(t, serveConfigRequest(t, handlePatchProjectConfig(base), http.MethodPatch, patch))
+	assertStoredSecrets(t, base, "rotated", testPassphrase)
+}
+
+func TestProjectWebhookBodyLimit(t *testing.T) {
+	base := newConfigTestBase(t)
+	body := bytes.NewReader(make([]byte, maxWebhookBodyBytes+1))
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/v1/projects/app/webhook", body), map[string]string{"projectName": "app"})
+	rec := httptest.NewRecorder()
+	handleProjectWebhook(base)(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestCreateSchedulesConcurrently(t *testing.T) {
+	base := newConfigTestBase(t)
+	if err := config.SaveGlobalConfig(base, &config.GlobalConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	handler := handleCreateSchedule(base, scheduler.New(base))
+
+	const count = 10
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(minute int) {
+			defer wg.Done()
+			entry := config.ScheduleEntry{ProjectName: "app", Env: "test", CronExpr: fmt.Sprintf("%d 3 * * *", minute)}
+			content, _ := json.Marshal(entry)
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedules", bytes.NewReader(content)))
+			if rec.Code != http.StatusCreated {
+				t.Errorf("create schedule: status %d: %s", rec.Code, rec.Body.String())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	globalCfg, err := config.ReloadGlobalConfig(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(globalCfg.DeploySchedules) != count {
+		t.Errorf("saved %d schedules, want %d", len(globalCfg.DeploySchedules), count)
+	}
+}
@@ -30,3 +30,21 @@ func writeError(w http.ResponseWriter, status int, message string, details ...st
 	util.Log.Warnf("API Error %d: %s %v", status, message, details)
 	writeJSON(w, status, errorResponse)
 }
+
+// mergeJSONObjects applies patch onto target following JSON merge patch semantics:
+// nested objects are merged recursively, null values delete keys, anything else replaces.
+func mergeJSONObjects(target, patch map[string]interface{}) {
+	for key, patchVal := range patch {
+		if patchVal == nil {
+			delete(target, key)
+			continue
+		}
+		patchObj, patchIsObj := patchVal.(map[string]interface{})
+		targetObj, targetIsObj := target[key].(map[string]interface{})
+		if patchIsObj && targetIsObj {
+			mergeJSONObjects(targetObj, patchObj)
+			continue
+		}
+		target[key] = patchVal
+	}
+}
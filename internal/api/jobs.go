@@ -0,0 +1,134 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"reflow/internal/deployment"
+	"reflow/internal/util"
+	"sync"
+	"time"
+)
+
+// maxJobs is how many jobs are kept in memory; the oldest finished ones are dropped first.
+const maxJobs = 100
+
+// States of a job.
+const (
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+)
+
+// jobRecord is the state of a job as returned by GET /api/v1/jobs/{jobId}.
+type jobRecord struct {
+	ID          string            `json:"id"`
+	Type        string            `json:"type"`
+	Project     string            `json:"project"`
+	Environment string            `json:"environment"`
+	State       string            `json:"state"`
+	Commit      string            `json:"commit,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	StartedAt   time.Time         `json:"startedAt"`
+	FinishedAt  *time.Time        `json:"finishedAt,omitempty"`
+	Steps       []deployment.Step `json:"steps"`
+}
+
+// job is a deployment started through the API. It is the deployment's ProgressSink, so its
+// steps are recorded as they are reported.
+type job struct {
+	mu     sync.Mutex
+	record jobRecord
+}
+
+// ReportStep implements deployment.ProgressSink.
+func (j *job) ReportStep(index int, step deployment.Step) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for len(j.record.Steps) <= index {
+		j.record.Steps = append(j.record.Steps, deployment.Step{})
+	}
+	j.record.Steps[index] = step
+}
+
+// finish records the outcome of the job.
+func (j *job) finish(commit string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.record.FinishedAt = &now
+	j.record.Commit = commit
+	j.record.State = jobSucceeded
+	if err != nil {
+		j.record.State = jobFailed
+		j.record.Error = util.Redact(err.Error())
+	}
+}
+
+// snapshot returns a copy of the job's current state.
+func (j *job) snapshot() jobRecord {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	record := j.record
+	record.Steps = append([]deployment.Step{}, j.record.Steps...)
+	return record
+}
+
+// jobStore keeps the jobs started by the API server in memory. They are lost on restart.
+type jobStore struct {
+	mu    sync.Mutex
+	jobs  map[string]*job
+	order []string // IDs, oldest first
+}
+
+// jobs holds the jobs of this API server.
+var jobs = &jobStore{jobs: make(map[string]*job)}
+
+// start records a new running job and returns it.
+func (s *jobStore) start(jobType, projectName, env string) *job {
+	j := &job{record: jobRecord{
+		ID:          newJobID(),
+		Type:        jobType,
+		Project:     projectName,
+		Environment: env,
+		State:       jobRunning,
+		StartedAt:   time.Now(),
+		Steps:       []deployment.Step{},
+	}}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[j.record.ID] = j
+	s.order = append(s.order, j.record.ID)
+	s.prune()
+	return j
+}
+
+// get returns the job with the given ID, or nil.
+func (s *jobStore) get(id string) *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[id]
+}
+
+// prune drops the oldest finished jobs while there are more than maxJobs. Running jobs are
+// always kept.
+func (s *jobStore) prune() {
+	for i := 0; len(s.order) > maxJobs && i < len(s.order); {
+		id := s.order[i]
+		if s.jobs[id].snapshot().State == jobRunning {
+			i++
+			continue
+		}
+		delete(s.jobs, id)
+		s.order = append(s.order[:i], s.order[i+1:]...)
+	}
+}
+
+// newJobID returns a random job ID.
+func newJobID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(id)
+}
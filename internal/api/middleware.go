@@ -1,9 +1,17 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"reflow/internal/audit"
 	"reflow/internal/util"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
 )
 
 type loggingResponseWriter struct {
@@ -31,6 +39,11 @@ func (lrw *loggingResponseWriter) Write(b []byte) (int, error) {
 	return lrw.ResponseWriter.Write(b)
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController, e.g. for flushing streams.
+func (lrw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
+
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -39,38 +52,143 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(lrw, r)
 
 		duration := time.Since(start)
-		util.Log.Infof("API Request: %s %s | Status: %d | Duration: %v | From: %s",
-			r.Method,
-			r.RequestURI,
-			lrw.statusCode,
-			duration,
-			r.RemoteAddr,
-		)
+		util.Log.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"path":        r.RequestURI,
+			"status":      lrw.statusCode,
+			"duration_ms": float64(duration.Microseconds()) / 1000,
+			"remote":      r.RemoteAddr,
+		}).Info("API request")
 	})
 }
 
-func corsMiddleware(next http.Handler, allowedOrigin string) http.Handler {
+// auditedMethods are the methods of API write operations, which auditMiddleware records.
+var auditedMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// auditResourceVars are the route variables naming what a request acts on, in the order they
+// are joined into the audit log's resource, e.g. "my-app/prod".
+var auditResourceVars = []string{"projectName", "env", "key", "containerId", "pluginName"}
+
+// routeVarPattern matches a route variable with a pattern, e.g. {env:(?:test|prod)}.
+var routeVarPattern = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+// auditMiddleware records the API's write operations in the audit log. The matched route is the
+// action, and responses with a status of 400 or above are recorded as errors.
+func auditMiddleware(basePath string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !auditedMethods[r.Method] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			lrw := newLoggingResponseWriter(w)
+			next.ServeHTTP(lrw, r)
+
+			action := r.Method + " " + r.URL.Path
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					action = r.Method + " " + routeVarPattern.ReplaceAllString(strings.TrimPrefix(template, "/api/v1"), "{$1}")
+				}
+			}
+			var resource []string
+			vars := mux.Vars(r)
+			for _, name := range auditResourceVars {
+				if value := vars[name]; value != "" {
+					resource = append(resource, value)
+				}
+			}
+			outcome := audit.OutcomeSuccess
+			if lrw.statusCode >= http.StatusBadRequest {
+				outcome = audit.OutcomeError
+			}
+			audit.LogAPIAction(basePath, r, action, strings.Join(resource, "/"), outcome)
+		})
+	}
+}
+
+// corsAllowAnyOrigin in the allowed origins lets every origin call the API, without credentials.
+const corsAllowAnyOrigin = "*"
+
+// corsMiddleware answers CORS preflight requests for every route and adds CORS headers to
+// responses for allowed origins. allowedOrigins is called per request, so origins from the
+// global config follow config reloads. An allowed origin is echoed back; with "*" allowed, any
+// origin is accepted but credentials are not. Requests from other origins get no CORS headers,
+// and their preflights and credentialed requests (cookies or an Authorization header) are
+// rejected with 403.
+func corsMiddleware(next http.Handler, allowedOrigins func() []string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if allowedOrigin == "" {
-			origin := r.Header.Get("Origin")
-			if origin != "" {
-				util.Log.Debugf("CORS: Blocked request from disallowed origin: %s", origin)
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		allowed, listed := matchOrigin(origin, allowedOrigins())
+		if !allowed {
+			if preflight || r.Header.Get("Cookie") != "" || r.Header.Get("Authorization") != "" {
+				util.Log.Debugf("CORS: Rejected %s %s from disallowed origin: %s", r.Method, r.URL.Path, origin)
+				writeError(w, http.StatusForbidden, "Origin not allowed", origin)
+				return
 			}
+			util.Log.Debugf("CORS: No CORS headers for disallowed origin: %s", origin)
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		w.Header().Set("Vary", "Origin")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		if listed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", corsAllowAnyOrigin)
+		}
 
-		if r.Method == http.MethodOptions {
-			util.Log.Debugf("Handled OPTIONS preflight request for origin %s, path: %s", allowedOrigin, r.URL.Path)
-			w.WriteHeader(http.StatusOK)
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			util.Log.Debugf("Handled OPTIONS preflight request for origin %s, path: %s", origin, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// matchOrigin reports whether origin is allowed, and whether it is listed explicitly rather
+// than only allowed by "*". Origins compare case-insensitively, ignoring a trailing slash.
+func matchOrigin(origin string, allowedOrigins []string) (allowed, listed bool) {
+	origin = strings.TrimSuffix(origin, "/")
+	for _, candidate := range allowedOrigins {
+		if candidate == corsAllowAnyOrigin {
+			allowed = true
+			continue
+		}
+		if strings.EqualFold(strings.TrimSuffix(candidate, "/"), origin) {
+			return true, true
+		}
+	}
+	return allowed, false
+}
+
+// ValidateOrigin checks an allowed origin: "*", or a scheme and host with an optional port,
+// e.g. http://localhost:3000.
+func ValidateOrigin(origin string) error {
+	if origin == corsAllowAnyOrigin {
+		return nil
+	}
+	u, err := url.Parse(strings.TrimSuffix(origin, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+		return fmt.Errorf("invalid allowed origin '%s': expected '*' or scheme://host[:port], e.g. http://localhost:3000", origin)
+	}
+	return nil
+}
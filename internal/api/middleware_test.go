@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCorsMiddleware(t *testing.T) {
+	tests := []struct {
+		name            string
+		allowedOrigins  []string
+		method          string
+		headers         map[string]string
+		wantStatus      int
+		wantAllowOrigin string
+		wantCredentials bool
+		wantNextCalled  bool
+	}{
+		{
+			name:           "no origin",
+			allowedOrigins: []string{"https://app.example.com"},
+			method:         http.MethodGet,
+			wantStatus:     http.StatusOK,
+			wantNextCalled: true,
+		},
+		{
+			name:            "allowed origin",
+			allowedOrigins:  []string{"https://app.example.com"},
+			method:          http.MethodGet,
+			headers:         map[string]string{"Origin": "https://APP.example.com"},
+			wantStatus:      http.StatusOK,
+			wantAllowOrigin: "https://APP.example.com",
+			wantCredentials: true,
+			wantNextCalled:  true,
+		},
+		{
+			name:            "allowed origin preflight",
+			allowedOrigins:  []string{"https://app.example.com/"},
+			method:          http.MethodOptions,
+			headers:         map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "PUT"},
+			wantStatus:      http.StatusNoContent,
+			wantAllowOrigin: "https://app.example.com",
+			wantCredentials: true,
+		},
+		{
+			name:           "disallowed origin",
+			allowedOrigins: []string{"https://app.example.com"},
+			method:         http.MethodGet,
+			headers:        map[string]string{"Origin": "https://evil.example.com"},
+			wantStatus:     http.StatusOK,
+			wantNextCalled: true,
+		},
+		{
+			name:           "disallowed origin preflight",
+			allowedOrigins: []string{"https://app.example.com"},
+			method:         http.MethodOptions,
+			headers:        map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": "DELETE"},
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "disallowed origin with cookie",
+			allowedOrigins: []string{"https://app.example.com"},
+			method:         http.MethodPost,
+			headers:        map[string]string{"Origin": "https://evil.example.com", "Cookie": "session=1"},
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "disallowed origin with authorization",
+			allowedOrigins: nil,
+			method:         http.MethodGet,
+			headers:        map[string]string{"Origin": "https://evil.example.com", "Authorization": "Bearer token"},
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:            "wildcard",
+			allowedOrigins:  []string{"*"},
+			method:          http.MethodGet,
+			headers:         map[string]string{"Origin": "https://any.example.com", "Cookie": "session=1"},
+			wantStatus:      http.StatusOK,
+			wantAllowOrigin: "*",
+			wantNextCalled:  true,
+		},
+		{
+			name:            "wildcard with listed origin",
+			allowedOrigins:  []string{"*", "https://app.example.com"},
+			method:          http.MethodGet,
+			headers:         map[string]string{"Origin": "https://app.example.com"},
+			wantStatus:      http.StatusOK,
+			wantAllowOrigin: "https://app.example.com",
+			wantCredentials: true,
+			wantNextCalled:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextCalled := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := corsMiddleware(next, func() []string { return tt.allowedOrigins })
+
+			req := httptest.NewRequest(tt.method, "/api/v1/projects", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if nextCalled != tt.wantNextCalled {
+				t.Errorf("next handler called = %v, want %v", nextCalled, tt.wantNextCalled)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantAllowOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials set = %v, want %v", got, tt.wantCredentials)
+			}
+		})
+	}
+}
+
+func TestMatchOrigin(t *testing.T) {
+	tests := []struct {
+		origin         string
+		allowedOrigins []string
+		wantAllowed    bool
+		wantListed     bool
+	}{
+		{"https://app.example.com", []string{"https://app.example.com"}, true, true},
+		{"https://app.example.com/", []string{"HTTPS://APP.EXAMPLE.COM"}, true, true},
+		{"https://app.example.com:8443", []string{"https://app.example.com"}, false, false},
+		{"http://app.example.com", []string{"https://app.example.com"}, false, false},
+		{"https://other.example.com", []string{"*"}, true, false},
+		{"https://app.example.com", []string{"*", "https://app.example.com"}, true, true},
+		{"https://app.example.com", nil, false, false},
+	}
+	for _, tt := range tests {
+		allowed, listed := matchOrigin(tt.origin, tt.allowedOrigins)
+		if allowed != tt.wantAllowed || listed != tt.wantListed {
+			t.Errorf("matchOrigin(%q, %q) = %v, %v; want %v, %v", tt.origin, tt.allowedOrigins, allowed, listed, tt.wantAllowed, tt.wantListed)
+		}
+	}
+}
@@ -2,13 +2,19 @@ package api
 
 import (
 	"net/http"
+	"reflow/internal/scheduler"
 
 	"github.com/gorilla/mux"
 )
 
 // RegisterRoutes sets up the API endpoints and handlers.
-func RegisterRoutes(router *mux.Router, basePath string) {
+func RegisterRoutes(router *mux.Router, basePath string, sched *scheduler.Scheduler) {
+	// --- Health Route ---
+	// Outside /api/v1, so monitors can rely on it across API versions.
+	router.HandleFunc("/healthz", handleHealthz()).Methods(http.MethodGet)
+
 	apiV1 := router.PathPrefix("/api/v1").Subrouter()
+	apiV1.Use(auditMiddleware(basePath))
 
 	// --- Project Routes ---
 	apiV1.HandleFunc("/projects", handleListProjects(basePath)).Methods(http.MethodGet)
@@ -16,11 +22,17 @@ func RegisterRoutes(router *mux.Router, basePath string) {
 	apiV1.HandleFunc("/projects/{projectName}/status", handleGetProjectStatus(basePath)).Methods(http.MethodGet)
 	apiV1.HandleFunc("/projects/{projectName}/config", handleGetProjectConfig(basePath)).Methods(http.MethodGet)
 	apiV1.HandleFunc("/projects/{projectName}/config", handleUpdateProjectConfig(basePath)).Methods(http.MethodPut)
+	apiV1.HandleFunc("/projects/{projectName}/config", handlePatchProjectConfig(basePath)).Methods(http.MethodPatch)
 	apiV1.HandleFunc("/projects/{projectName}/{env:(?:test|prod)}/start", handleStartProjectEnv(basePath)).Methods(http.MethodPost)
 	apiV1.HandleFunc("/projects/{projectName}/{env:(?:test|prod)}/stop", handleStopProjectEnv(basePath)).Methods(http.MethodPost)
 	apiV1.HandleFunc("/projects/{projectName}/{env:(?:test|prod)}/logs", handleGetProjectLogs(basePath)).Methods(http.MethodGet)
+	apiV1.HandleFunc("/projects/{projectName}/{env:(?:test|prod)}/stats", handleGetProjectEnvStats(basePath)).Methods(http.MethodGet)
 	apiV1.HandleFunc("/projects/{projectName}/{env:(?:test|prod)}/envfile", handleGetEnvFile(basePath)).Methods(http.MethodGet)
 	apiV1.HandleFunc("/projects/{projectName}/{env:(?:test|prod)}/envfile", handleUpdateEnvFile(basePath)).Methods(http.MethodPut)
+	apiV1.HandleFunc("/projects/{projectName}/{env:(?:test|prod)}/secrets", handleListSecrets(basePath)).Methods(http.MethodGet)
+	apiV1.HandleFunc("/projects/{projectName}/{env:(?:test|prod)}/secrets", handleSetSecrets(basePath)).Methods(http.MethodPut)
+	apiV1.HandleFunc("/projects/{projectName}/{env:(?:test|prod)}/secrets/{key}", handleUnsetSecret(basePath)).Methods(http.MethodDelete)
+	apiV1.HandleFunc("/projects/{projectName}/env-diff", handleGetEnvDiff(basePath)).Methods(http.MethodGet)
 
 	// --- Deployment History Route ---
 	apiV1.HandleFunc("/projects/{projectName}/deployments", handleListDeployments(basePath)).Methods(http.MethodGet)
@@ -28,15 +40,35 @@ func RegisterRoutes(router *mux.Router, basePath string) {
 	// --- Orchestration Routes ---
 	apiV1.HandleFunc("/projects/{projectName}/deploy", handleDeployProject(basePath)).Methods(http.MethodPost)
 	apiV1.HandleFunc("/projects/{projectName}/approve", handleApproveProject(basePath)).Methods(http.MethodPost)
+	apiV1.HandleFunc("/projects/{projectName}/deploy/progress", handleGetDeployProgress(basePath)).Methods(http.MethodGet)
+	apiV1.HandleFunc("/jobs/{jobId}", handleGetJob()).Methods(http.MethodGet)
+
+	// --- Webhook Routes ---
+	apiV1.HandleFunc("/projects/{projectName}/webhook", handleProjectWebhook(basePath)).Methods(http.MethodPost)
+
+	// --- Config Routes ---
+	apiV1.HandleFunc("/config/reload", handleReloadConfig(basePath, sched)).Methods(http.MethodPost)
+
+	// --- Audit Route ---
+	apiV1.HandleFunc("/audit", handleListAuditLog(basePath)).Methods(http.MethodGet)
+
+	// --- Schedule Routes ---
+	apiV1.HandleFunc("/schedules", handleListSchedules(sched)).Methods(http.MethodGet)
+	apiV1.HandleFunc("/schedules", handleCreateSchedule(basePath, sched)).Methods(http.MethodPost)
 
 	// --- Container Routes ---
 	apiV1.HandleFunc("/containers", handleListContainers()).Methods(http.MethodGet)
 	apiV1.HandleFunc("/containers/{containerId}", handleGetContainer()).Methods(http.MethodGet)
+	apiV1.HandleFunc("/containers/{containerId}/stats", handleGetContainerStats()).Methods(http.MethodGet)
 	apiV1.HandleFunc("/containers/{containerId}/start", handleStartContainer()).Methods(http.MethodPost)
 	apiV1.HandleFunc("/containers/{containerId}/stop", handleStopContainer()).Methods(http.MethodPost)
 	apiV1.HandleFunc("/containers/{containerId}/restart", handleRestartContainer()).Methods(http.MethodPost)
 	apiV1.HandleFunc("/containers/{containerId}", handleDeleteContainer()).Methods(http.MethodDelete)
 
-	// TODO: Add routes for plugin management?
-	// e.g., GET /api/v1/plugins, GET /api/v1/plugins/{pluginName}/status, POST /api/v1/plugins/{pluginName}/enable etc.
+	// --- Plugin Routes ---
+	apiV1.HandleFunc("/plugins/{pluginName}/status", handleGetPluginStatus(basePath)).Methods(http.MethodGet)
+	apiV1.HandleFunc("/plugins/{pluginName}/logs", handleGetPluginLogs(basePath)).Methods(http.MethodGet)
+
+	// TODO: Add routes for the rest of plugin management?
+	// e.g., GET /api/v1/plugins, POST /api/v1/plugins/{pluginName}/enable etc.
 }
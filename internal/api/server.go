@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"reflow/internal/acme"
+	"reflow/internal/config"
+	"reflow/internal/scheduler"
 	"reflow/internal/util"
 	"syscall"
 	"time"
@@ -22,7 +25,7 @@ const (
 )
 
 // StartServer initializes and runs the Reflow internal API server.
-func StartServer(basePath string, hostFlag string, portFlag string) error {
+func StartServer(basePath string, hostFlag string, portFlag string, allowOrigins []string) error {
 	bindAddr := defaultBindAddr
 	if hostFlag != "" {
 		if hostFlag == "localhost" {
@@ -40,13 +43,55 @@ func StartServer(basePath string, hostFlag string, portFlag string) error {
 	}
 	listenAddr := net.JoinHostPort(bindAddr, port)
 
+	for _, origin := range allowOrigins {
+		if err := ValidateOrigin(origin); err != nil {
+			return err
+		}
+	}
+	if globalCfg, err := config.LoadGlobalConfig(basePath); err == nil {
+		for _, origin := range globalCfg.API.AllowedOrigins {
+			if err := ValidateOrigin(origin); err != nil {
+				util.Log.Warnf("Ignoring api.allowedOrigins entry from the global config: %v", err)
+			}
+		}
+	}
+
+	// Bind before recording the pidfile, so a recorded server is one that is listening, and
+	// before starting the scheduler, so a refused second server never runs any schedules.
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to start API server: %w", err)
+	}
+	if err := claimPidFile(basePath, listenAddr); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	defer releasePidFile(basePath, os.Getpid())
+
+	sched := scheduler.New(basePath)
+	if err := sched.LoadFromConfig(); err != nil {
+		util.Log.Warnf("Failed to load deploy schedules: %v", err)
+	}
+	schedCtx, cancelSched := context.WithCancel(context.Background())
+	defer cancelSched()
+	sched.Start(schedCtx)
+	acme.StartRenewer(schedCtx, basePath)
+
 	router := mux.NewRouter()
-	RegisterRoutes(router, basePath)
+	RegisterRoutes(router, basePath, sched)
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusOK, map[string]string{"status": "Reflow API Server running"})
 	}).Methods(http.MethodGet)
 
-	loggingHandler := loggingMiddleware(router)
+	// Config origins are re-read per request, so they follow config reloads.
+	allowedOrigins := func() []string {
+		origins := allowOrigins
+		if globalCfg, err := config.LoadGlobalConfig(basePath); err == nil {
+			origins = append(append([]string(nil), allowOrigins...), globalCfg.API.AllowedOrigins...)
+		}
+		return origins
+	}
+	loggingHandler := loggingMiddleware(corsMiddleware(router, allowedOrigins))
 
 	srv := &http.Server{
 		Addr:         listenAddr,
@@ -61,8 +106,8 @@ func StartServer(basePath string, hostFlag string, portFlag string) error {
 	go func() {
 		util.Log.Infof("Starting Reflow API server on http://%s", listenAddr)
 		util.Log.Warn("API server is intended for local access by plugins only.")
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			util.Log.Errorf("API server ListenAndServe error: %v", err)
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			util.Log.Errorf("API server Serve error: %v", err)
 			serverErrChan <- fmt.Errorf("failed to start API server: %w", err)
 		}
 		close(serverErrChan)
@@ -70,14 +115,28 @@ func StartServer(basePath string, hostFlag string, portFlag string) error {
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
 
-	select {
-	case err := <-serverErrChan:
-		return err
-	case sig := <-quit:
-		util.Log.Infof("Received signal %v. Shutting down API server...", sig)
+waitLoop:
+	for {
+		select {
+		case err := <-serverErrChan:
+			return err
+		case <-reload:
+			util.Log.Info("Received SIGHUP. Reloading configuration...")
+			if err := reloadConfiguration(basePath, sched); err != nil {
+				util.Log.Errorf("Configuration reload failed: %v", err)
+			}
+		case sig := <-quit:
+			util.Log.Infof("Received signal %v. Shutting down API server...", sig)
+			break waitLoop
+		}
 	}
 
+	cancelSched()
+
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"sort"
+	"strings"
+)
+
+// EnvDiff lists the differences between a project's test and prod env files. Keys are sorted.
+type EnvDiff struct {
+	TestEnvFile string           `json:"testEnvFile"` // Resolved path, empty if test has no envFile
+	ProdEnvFile string           `json:"prodEnvFile"` // Resolved path, empty if prod has no envFile
+	OnlyInTest  []string         `json:"onlyInTest"`
+	OnlyInProd  []string         `json:"onlyInProd"`
+	Changed     []EnvValueChange `json:"changed"`
+}
+
+// EnvValueChange is a key set in both env files with different values.
+type EnvValueChange struct {
+	Key       string `json:"key"`
+	TestValue string `json:"testValue"`
+	ProdValue string `json:"prodValue"`
+}
+
+// Empty reports whether both env files define the same variables with the same values.
+func (d EnvDiff) Empty() bool {
+	return len(d.OnlyInTest) == 0 && len(d.OnlyInProd) == 0 && len(d.Changed) == 0
+}
+
+// DiffEnvFiles compares the env files of a project's test and prod environments, as currently
+// checked out in its repository. A missing or unconfigured env file counts as empty.
+func DiffEnvFiles(reflowBasePath, projectName string) (EnvDiff, error) {
+	diff := EnvDiff{OnlyInTest: []string{}, OnlyInProd: []string{}, Changed: []EnvValueChange{}}
+
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		return diff, err
+	}
+
+	var testVars, prodVars map[string]string
+	if diff.TestEnvFile, testVars, err = loadEnvFileVars(reflowBasePath, projCfg, "test"); err != nil {
+		return diff, err
+	}
+	if diff.ProdEnvFile, prodVars, err = loadEnvFileVars(reflowBasePath, projCfg, "prod"); err != nil {
+		return diff, err
+	}
+
+	for key, testValue := range testVars {
+		prodValue, inProd := prodVars[key]
+		switch {
+		case !inProd:
+			diff.OnlyInTest = append(diff.OnlyInTest, key)
+		case prodValue != testValue:
+			diff.Changed = append(diff.Changed, EnvValueChange{Key: key, TestValue: testValue, ProdValue: prodValue})
+		}
+	}
+	for key := range prodVars {
+		if _, inTest := testVars[key]; !inTest {
+			diff.OnlyInProd = append(diff.OnlyInProd, key)
+		}
+	}
+
+	sort.Strings(diff.OnlyInTest)
+	sort.Strings(diff.OnlyInProd)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Key < diff.Changed[j].Key })
+	return diff, nil
+}
+
+// loadEnvFileVars reads an environment's env file into a map. If a key is set more than once,
+// the last value wins, as it does for the container.
+func loadEnvFileVars(reflowBasePath string, projCfg *config.ProjectConfig, env string) (string, map[string]string, error) {
+	vars := make(map[string]string)
+	envFile := projCfg.Environments[env].EnvFile
+	if envFile == "" {
+		return "", vars, nil
+	}
+
+	repoPath := filepath.Join(config.GetProjectBasePath(reflowBasePath, projCfg.ProjectName), config.RepoDirName)
+	envFilePath := filepath.Clean(filepath.Join(repoPath, envFile))
+	if !strings.HasPrefix(envFilePath, filepath.Clean(repoPath)+string(os.PathSeparator)) {
+		return "", nil, fmt.Errorf("invalid %s env file path '%s' resolves outside repo directory", env, envFile)
+	}
+
+	lines, err := util.LoadEnvFile(envFilePath)
+	if err != nil {
+		return envFilePath, nil, fmt.Errorf("failed to load %s env file: %w", env, err)
+	}
+	for _, line := range lines {
+		key, value, _ := strings.Cut(line, "=")
+		vars[strings.TrimSpace(key)] = value
+	}
+	return envFilePath, vars, nil
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/util"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"golang.org/x/term"
+)
+
+// ExecInActiveContainer runs a command inside the active container of a project environment,
+// wiring it to the current terminal. With interactive set, stdin is attached and a TTY is
+// allocated, putting the local terminal in raw mode for the duration of the command.
+// When the slot runs multiple replicas, the command runs in the first running replica.
+func ExecInActiveContainer(ctx context.Context, reflowBasePath, projectName, env string, cmd []string, interactive bool) error {
+	if len(cmd) == 0 {
+		return fmt.Errorf("no command specified")
+	}
+
+	// --- 1. Find Active Container ---
+	projState, err := config.LoadProjectState(reflowBasePath, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to load project state for '%s': %w", projectName, err)
+	}
+
+	var envState config.EnvironmentState
+	switch env {
+	case "test":
+		envState = projState.Test
+	case "prod":
+		envState = projState.Prod
+	default:
+		return fmt.Errorf("invalid environment specified: %s", env)
+	}
+	if envState.ActiveCommit == "" || envState.ActiveSlot == "" {
+		return fmt.Errorf("no active deployment found for project '%s', environment '%s'", projectName, env)
+	}
+
+	labels := map[string]string{
+		docker.LabelProject:     projectName,
+		docker.LabelEnvironment: env,
+		docker.LabelSlot:        envState.ActiveSlot,
+	}
+	containers, err := docker.FindContainersByLabels(ctx, labels)
+	if err != nil {
+		return fmt.Errorf("failed to find containers for project '%s' env '%s' slot '%s': %w", projectName, env, envState.ActiveSlot, err)
+	}
+	sort.Slice(containers, func(i, j int) bool { return replicaIndex(containers[i]) < replicaIndex(containers[j]) })
+
+	var target *container.Summary
+	for i := range containers {
+		if containers[i].State == "running" {
+			target = &containers[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("no running container found for project '%s' env '%s' slot '%s'", projectName, env, envState.ActiveSlot)
+	}
+	containerName := strings.TrimPrefix(target.Names[0], "/")
+	util.Log.Debugf("Executing %q in container %s (%s)", cmd, containerName, target.ID[:12])
+
+	// --- 2. Create & Attach Exec ---
+	cli, err := docker.GetClient()
+	if err != nil {
+		return err
+	}
+
+	stdinFd := int(os.Stdin.Fd())
+	if interactive && !term.IsTerminal(stdinFd) {
+		return fmt.Errorf("interactive mode requires a terminal on stdin")
+	}
+
+	execResp, err := cli.ContainerExecCreate(ctx, target.ID, container.ExecOptions{
+		Cmd:          cmd,
+		AttachStdin:  interactive,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          interactive,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create exec in container %s: %w", containerName, err)
+	}
+
+	attachResp, err := cli.ContainerExecAttach(ctx, execResp.ID, container.ExecAttachOptions{Tty: interactive})
+	if err != nil {
+		return fmt.Errorf("failed to attach to exec in container %s: %w", containerName, err)
+	}
+	defer attachResp.Close()
+
+	// --- 3. Stream I/O ---
+	if interactive {
+		oldState, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return fmt.Errorf("failed to put terminal in raw mode: %w", err)
+		}
+		defer func() {
+			if restoreErr := term.Restore(stdinFd, oldState); restoreErr != nil {
+				util.Log.Warnf("Failed to restore terminal state: %v", restoreErr)
+			}
+		}()
+
+		if width, height, sizeErr := term.GetSize(int(os.Stdout.Fd())); sizeErr == nil {
+			resizeOpts := container.ResizeOptions{Height: uint(height), Width: uint(width)}
+			if resizeErr := cli.ContainerExecResize(ctx, execResp.ID, resizeOpts); resizeErr != nil {
+				util.Log.Debugf("Failed to resize exec TTY: %v", resizeErr)
+			}
+		}
+
+		go func() {
+			_, _ = io.Copy(attachResp.Conn, os.Stdin)
+			_ = attachResp.CloseWrite()
+		}()
+	}
+
+	outputDone := make(chan error, 1)
+	go func() {
+		var copyErr error
+		if interactive {
+			// With a TTY, stdout and stderr arrive as a single raw stream.
+			_, copyErr = io.Copy(os.Stdout, attachResp.Reader)
+		} else {
+			_, copyErr = stdcopy.StdCopy(os.Stdout, os.Stderr, attachResp.Reader)
+		}
+		outputDone <- copyErr
+	}()
+
+	select {
+	case copyErr := <-outputDone:
+		if copyErr != nil && copyErr != io.EOF {
+			return fmt.Errorf("error streaming exec output: %w", copyErr)
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// --- 4. Report Exit Code ---
+	inspect, err := cli.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect exec in container %s: %w", containerName, err)
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("command exited with code %d", inspect.ExitCode)
+	}
+	return nil
+}
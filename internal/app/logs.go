@@ -6,37 +6,36 @@ import (
 	"errors"
 	"fmt"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
 	"io"
 	"os"
 	"reflow/internal/config"
 	"reflow/internal/docker"
 	"reflow/internal/util"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 )
 
-// StreamAppLogs fetches and streams logs for the active container of a specific project environment.
-func StreamAppLogs(ctx context.Context, reflowBasePath, projectName, env string, follow bool, tail string) error {
+// LogGrepOptions filters streamed logs down to lines matching Pattern, like grep -B/-A.
+type LogGrepOptions struct {
+	Pattern *regexp.Regexp
+	Before  int // Lines of context to show before each match
+	After   int // Lines of context to show after each match
+}
+
+// StreamAppLogs fetches and streams logs for the active container(s) of a specific project environment.
+// A non-empty slot ("blue" or "green") reads that slot's containers instead of the active one, e.g. the
+// new containers of a deployment that is still running its health checks.
+// When the slot runs multiple replicas, index selects a single replica (1-based); 0 merges all running replicas.
+// A non-nil grep filters each container's log lines.
+func StreamAppLogs(ctx context.Context, reflowBasePath, projectName, env, slot string, follow bool, tail string, index int, grep *LogGrepOptions) error {
 	util.Log.Debugf("Attempting to get logs for project '%s', environment '%s'...", projectName, env)
 
-	projState, err := config.LoadProjectState(reflowBasePath, projectName)
+	activeSlot, _, err := resolveLogSlot(reflowBasePath, projectName, env, slot)
 	if err != nil {
-		return fmt.Errorf("failed to load project state for '%s': %w", projectName, err)
-	}
-
-	var activeSlot string
-	var activeCommit string
-	if env == "test" {
-		activeSlot = projState.Test.ActiveSlot
-		activeCommit = projState.Test.ActiveCommit
-	} else if env == "prod" {
-		activeSlot = projState.Prod.ActiveSlot
-		activeCommit = projState.Prod.ActiveCommit
-	} else {
-		return fmt.Errorf("invalid environment specified: %s", env)
-	}
-
-	if activeCommit == "" || activeSlot == "" {
-		return fmt.Errorf("no active deployment found in state for project '%s', environment '%s'. Cannot get logs", projectName, env)
+		return err
 	}
 
 	util.Log.Debugf("Looking for active container: project=%s, env=%s, slot=%s", projectName, env, activeSlot)
@@ -52,16 +51,34 @@ func StreamAppLogs(ctx context.Context, reflowBasePath, projectName, env string,
 		return fmt.Errorf("failed to find containers for project '%s' env '%s' slot '%s': %w", projectName, env, activeSlot, err)
 	}
 
-	var targetContainer *container.Summary = nil
-	for i := range containers {
-		c := containers[i]
-		if c.State == "running" {
-			if targetContainer != nil {
-				util.Log.Errorf("Found multiple RUNNING containers for project '%s' env '%s' slot '%s'!", projectName, env, activeSlot)
-				return fmt.Errorf("ambiguity: found multiple running containers for active slot")
+	if index > 0 {
+		var replicaContainers []container.Summary
+		for _, c := range containers {
+			if replicaIndex(c) == index {
+				replicaContainers = append(replicaContainers, c)
 			}
-			targetContainer = &c
 		}
+		if len(replicaContainers) == 0 {
+			return fmt.Errorf("no container found for replica %d of project '%s' env '%s' slot '%s'", index, projectName, env, activeSlot)
+		}
+		containers = replicaContainers
+	}
+
+	var runningContainers []container.Summary
+	for _, c := range containers {
+		if c.State == "running" {
+			runningContainers = append(runningContainers, c)
+		}
+	}
+
+	if len(runningContainers) > 1 {
+		util.Log.Infof("Merging logs from %d running replicas (use --index to select one)...", len(runningContainers))
+		return streamMergedLogs(ctx, runningContainers, follow, tail, grep)
+	}
+
+	var targetContainer *container.Summary = nil
+	if len(runningContainers) == 1 {
+		targetContainer = &runningContainers[0]
 	}
 
 	if targetContainer == nil {
@@ -101,7 +118,13 @@ func StreamAppLogs(ctx context.Context, reflowBasePath, projectName, env string,
 		}
 	}(logReader)
 
-	_, err = io.Copy(os.Stdout, logReader)
+	var out io.Writer = os.Stdout
+	if grep != nil {
+		gw := newGrepWriter(grep, os.Stdout)
+		defer gw.Flush()
+		out = gw
+	}
+	_, err = stdcopy.StdCopy(out, out, logReader)
 	if err != nil && err != io.EOF {
 		if errors.Is(ctx.Err(), context.Canceled) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			util.Log.Debug("Log streaming context cancelled or deadline exceeded.")
@@ -114,30 +137,199 @@ func StreamAppLogs(ctx context.Context, reflowBasePath, projectName, env string,
 	return nil
 }
 
-// GetAppLogsAsString fetches logs for the active container and returns as a string.
-func GetAppLogsAsString(ctx context.Context, reflowBasePath, projectName, env string, tail string) (string, error) {
-	util.Log.Debugf("Attempting to get logs as string for project '%s', environment '%s'...", projectName, env)
+// resolveLogSlot returns the slot to read logs from and the commit expected to run there. An
+// explicit slot is used as given with no expected commit; otherwise the environment's active
+// slot and commit are read from the project state.
+func resolveLogSlot(reflowBasePath, projectName, env, slot string) (string, string, error) {
+	if env != "test" && env != "prod" {
+		return "", "", fmt.Errorf("invalid environment specified: %s", env)
+	}
+	if slot != "" {
+		if slot != "blue" && slot != "green" {
+			return "", "", fmt.Errorf("invalid slot specified: %s (must be 'blue' or 'green')", slot)
+		}
+		util.Log.Debugf("Using explicitly requested slot '%s' for project '%s' env '%s'", slot, projectName, env)
+		return slot, "", nil
+	}
 
 	projState, err := config.LoadProjectState(reflowBasePath, projectName)
 	if err != nil {
-		return "", fmt.Errorf("failed to load project state for '%s': %w", projectName, err)
+		return "", "", fmt.Errorf("failed to load project state for '%s': %w", projectName, err)
+	}
+	envState := projState.Test
+	if env == "prod" {
+		envState = projState.Prod
 	}
+	if envState.ActiveCommit == "" || envState.ActiveSlot == "" {
+		return "", "", fmt.Errorf("no active deployment found in state for project '%s', environment '%s'. Cannot get logs without an explicit slot", projectName, env)
+	}
+	return envState.ActiveSlot, envState.ActiveCommit, nil
+}
 
-	// --- Logic to find the targetContainer (similar to StreamAppLogs) ---
-	var activeSlot string
-	var activeCommit string
-	if env == "test" {
-		activeSlot = projState.Test.ActiveSlot
-		activeCommit = projState.Test.ActiveCommit
-	} else if env == "prod" {
-		activeSlot = projState.Prod.ActiveSlot
-		activeCommit = projState.Prod.ActiveCommit
-	} else {
-		return "", fmt.Errorf("invalid environment specified: %s", env)
+// replicaIndex returns the replica number of a container, treating containers without the label as replica 1.
+func replicaIndex(c container.Summary) int {
+	index, err := strconv.Atoi(c.Labels[docker.LabelReplica])
+	if err != nil {
+		return 1
 	}
+	return index
+}
 
-	if activeCommit == "" || activeSlot == "" {
-		return "", fmt.Errorf("no active deployment found in state for project '%s', environment '%s'", projectName, env)
+// prefixWriter writes complete lines to out, prefixing each with the source container name.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.mu.Lock()
+		_, err := fmt.Fprintf(w.out, "%s%s", w.prefix, w.buf[:i+1])
+		w.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// grepWriter writes only the lines matching a pattern, plus the requested context lines, to out.
+// Non-adjacent groups of output are separated by "--" when context is requested, as with grep.
+type grepWriter struct {
+	opts        *LogGrepOptions
+	out         io.Writer
+	buf         []byte
+	before      [][]byte // Sliding window of the most recent non-printed lines
+	afterLeft   int      // Context lines still to print after the last match
+	printedOnce bool
+	skipped     bool // Lines were dropped since the last printed line
+}
+
+func newGrepWriter(opts *LogGrepOptions, out io.Writer) *grepWriter {
+	return &grepWriter{opts: opts, out: out}
+}
+
+func (w *grepWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := append([]byte(nil), w.buf[:i+1]...)
+		w.buf = w.buf[i+1:]
+		if err := w.processLine(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush processes a trailing line that has no newline yet.
+func (w *grepWriter) Flush() {
+	if len(w.buf) > 0 {
+		_ = w.processLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *grepWriter) processLine(line []byte) error {
+	switch {
+	case w.opts.Pattern.Match(line):
+		if w.printedOnce && w.skipped && (w.opts.Before > 0 || w.opts.After > 0) {
+			if _, err := w.out.Write([]byte("--\n")); err != nil {
+				return err
+			}
+		}
+		for _, ctxLine := range w.before {
+			if _, err := w.out.Write(ctxLine); err != nil {
+				return err
+			}
+		}
+		w.before = w.before[:0]
+		w.skipped = false
+		w.printedOnce = true
+		w.afterLeft = w.opts.After
+		_, err := w.out.Write(line)
+		return err
+	case w.afterLeft > 0:
+		w.afterLeft--
+		_, err := w.out.Write(line)
+		return err
+	default:
+		if w.opts.Before == 0 {
+			w.skipped = true
+			return nil
+		}
+		if len(w.before) == w.opts.Before {
+			w.before = w.before[1:]
+			w.skipped = true
+		}
+		w.before = append(w.before, line)
+		return nil
+	}
+}
+
+// streamMergedLogs streams logs from several containers concurrently, prefixing each line with its container name.
+func streamMergedLogs(ctx context.Context, containers []container.Summary, follow bool, tail string, grep *LogGrepOptions) error {
+	var wg sync.WaitGroup
+	var outMu sync.Mutex
+	errChan := make(chan error, len(containers))
+
+	for _, c := range containers {
+		containerID := c.ID
+		containerName := strings.TrimPrefix(strings.Join(c.Names, ","), "/")
+
+		logReader, err := docker.GetContainerLogs(ctx, containerID, follow, tail)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve logs for container %s: %w", containerName, err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer logReader.Close()
+
+			var w io.Writer = &prefixWriter{prefix: fmt.Sprintf("[%s] ", containerName), out: os.Stdout, mu: &outMu}
+			if grep != nil {
+				gw := newGrepWriter(grep, w)
+				defer gw.Flush()
+				w = gw
+			}
+			if _, err := stdcopy.StdCopy(w, w, logReader); err != nil && !errors.Is(err, io.EOF) {
+				if ctx.Err() == nil {
+					errChan <- fmt.Errorf("error streaming logs for %s: %w", containerName, err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errChan)
+	for err := range errChan {
+		util.Log.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// GetAppLogsAsString fetches logs for the active container and returns as a string. A non-empty
+// slot reads that slot's newest container instead, regardless of the commit it runs.
+func GetAppLogsAsString(ctx context.Context, reflowBasePath, projectName, env, slot string, tail string) (string, error) {
+	util.Log.Debugf("Attempting to get logs as string for project '%s', environment '%s'...", projectName, env)
+
+	// --- Logic to find the targetContainer (similar to StreamAppLogs) ---
+	activeSlot, activeCommit, err := resolveLogSlot(reflowBasePath, projectName, env, slot)
+	if err != nil {
+		return "", err
 	}
 
 	labels := map[string]string{
@@ -154,7 +346,7 @@ func GetAppLogsAsString(ctx context.Context, reflowBasePath, projectName, env st
 	var targetContainer *container.Summary = nil
 	for i := range containers {
 		c := containers[i]
-		commitMatches := c.Labels[docker.LabelCommit] == activeCommit
+		commitMatches := activeCommit == "" || c.Labels[docker.LabelCommit] == activeCommit
 		if c.Labels[docker.LabelCommit] == "" {
 			commitMatches = true
 		}
@@ -169,7 +361,7 @@ func GetAppLogsAsString(ctx context.Context, reflowBasePath, projectName, env st
 		var latestExitedContainer *container.Summary = nil
 		for i := range containers {
 			c := containers[i]
-			commitMatches := c.Labels[docker.LabelCommit] == activeCommit
+			commitMatches := activeCommit == "" || c.Labels[docker.LabelCommit] == activeCommit
 			if c.Labels[docker.LabelCommit] == "" {
 				commitMatches = true
 			}
@@ -180,6 +372,9 @@ func GetAppLogsAsString(ctx context.Context, reflowBasePath, projectName, env st
 			}
 		}
 		if latestExitedContainer == nil {
+			if activeCommit == "" {
+				return "", fmt.Errorf("no running or recently stopped container found for project '%s' env '%s' slot '%s'", projectName, env, activeSlot)
+			}
 			return "", fmt.Errorf("no running or recently stopped container found for project '%s' env '%s' slot '%s' commit '%s'", projectName, env, activeSlot, activeCommit[:7])
 		}
 		targetContainer = latestExitedContainer
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"sort"
+)
+
+// GetProjectEnvStats returns a resource usage snapshot for each running container in the active slot
+// of a project environment, ordered by replica index.
+func GetProjectEnvStats(ctx context.Context, reflowBasePath, projectName, env string) ([]docker.ContainerStats, error) {
+	projState, err := config.LoadProjectState(reflowBasePath, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project state for '%s': %w", projectName, err)
+	}
+
+	var envState config.EnvironmentState
+	switch env {
+	case "test":
+		envState = projState.Test
+	case "prod":
+		envState = projState.Prod
+	default:
+		return nil, fmt.Errorf("invalid environment specified: %s", env)
+	}
+	if envState.ActiveCommit == "" || envState.ActiveSlot == "" {
+		return nil, fmt.Errorf("no active deployment found for project '%s', environment '%s'", projectName, env)
+	}
+
+	labels := map[string]string{
+		docker.LabelProject:     projectName,
+		docker.LabelEnvironment: env,
+		docker.LabelSlot:        envState.ActiveSlot,
+	}
+	containers, err := docker.FindContainersByLabels(ctx, labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find containers for project '%s' env '%s' slot '%s': %w", projectName, env, envState.ActiveSlot, err)
+	}
+	sort.Slice(containers, func(i, j int) bool { return replicaIndex(containers[i]) < replicaIndex(containers[j]) })
+
+	var results []docker.ContainerStats
+	for _, c := range containers {
+		if c.State != "running" {
+			continue
+		}
+		stats, err := docker.GetContainerStats(ctx, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *stats)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no running container found for project '%s' env '%s' slot '%s'", projectName, env, envState.ActiveSlot)
+	}
+	return results, nil
+}
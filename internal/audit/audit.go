@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"sync"
+	"time"
+)
+
+// UserHeader is the request header naming the user on whose behalf a client calls the API.
+// It is taken as given; the API has no authentication yet.
+const UserHeader = "X-Reflow-User"
+
+// Outcomes of an audited action.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Entry is a single API write operation in the audit log.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Action    string    `json:"action"`             // Route of the operation, e.g. "POST /projects/{projectName}/deploy"
+	Resource  string    `json:"resource,omitempty"` // What it acted on, e.g. "my-app/prod"
+	Outcome   string    `json:"outcome"`            // OutcomeSuccess or OutcomeError
+	RemoteIP  string    `json:"remoteIp"`
+	User      string    `json:"user,omitempty"` // From UserHeader, if the client sent it
+}
+
+var logMutex sync.Mutex
+
+// getLogFilePath returns the path of the audit log in the reflow base directory.
+func getLogFilePath(reflowBasePath string) string {
+	return filepath.Join(reflowBasePath, config.AuditLogFileName)
+}
+
+// LogAPIAction appends an entry for an API request to the audit log. Failures are only logged,
+// so auditing never fails the request itself.
+func LogAPIAction(reflowBasePath string, r *http.Request, action, resource, outcome string) {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+	entry := Entry{
+		Timestamp: time.Now().UTC(),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Action:    action,
+		Resource:  resource,
+		Outcome:   outcome,
+		RemoteIP:  remoteIP,
+		User:      r.Header.Get(UserHeader),
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		util.Log.Errorf("Failed to marshal audit log entry: %v", err)
+		return
+	}
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	logFilePath := getLogFilePath(reflowBasePath)
+	file, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		util.Log.Errorf("Failed to open audit log '%s': %v", logFilePath, err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		util.Log.Errorf("Failed to write audit log entry to '%s': %v", logFilePath, err)
+	}
+}
+
+// ReadEntries returns up to limit audit log entries recorded at or after since (ignored if
+// zero), newest first. A missing audit log has no entries.
+func ReadEntries(reflowBasePath string, limit int, since time.Time) ([]Entry, error) {
+	logFilePath := getLogFilePath(reflowBasePath)
+
+	logMutex.Lock()
+	file, err := os.Open(logFilePath)
+	if err != nil {
+		logMutex.Unlock()
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log '%s': %w", logFilePath, err)
+	}
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			util.Log.Warnf("Failed to parse audit log line %d in '%s': %v. Skipping line.", lineNumber, logFilePath, err)
+			continue
+		}
+		if !since.IsZero() && entry.Timestamp.Before(since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	scanErr := scanner.Err()
+	file.Close()
+	logMutex.Unlock()
+	if scanErr != nil {
+		return nil, fmt.Errorf("error reading audit log '%s': %w", logFilePath, scanErr)
+	}
+
+	// Entries are appended in order, so the newest are at the end.
+	result := make([]Entry, 0, min(limit, len(entries)))
+	for i := len(entries) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, entries[i])
+	}
+	return result, nil
+}
@@ -46,6 +46,10 @@ func LoadGlobalConfig(basePath string) (*GlobalConfig, error) {
 
 	v.SetDefault("defaultDomain", "localhost")
 	v.SetDefault("debug", false)
+	v.SetDefault("deploymentLog.maxSizeMB", DefaultDeploymentLogMaxSizeMB)
+	v.SetDefault("deploymentLog.maxEvents", 0)
+	v.SetDefault("deploymentLog.maxArchives", DefaultDeploymentLogMaxArchives)
+	v.SetDefault("keepImages", DefaultKeepImages)
 
 	if err := v.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
@@ -55,10 +59,17 @@ func LoadGlobalConfig(basePath string) (*GlobalConfig, error) {
 		util.Log.Warnf("Global config file not found at %s, using defaults.", configFilePath)
 	}
 
+	if err := checkNotificationsList(v); err != nil {
+		return nil, fmt.Errorf("invalid global config: %w", err)
+	}
 	var config GlobalConfig
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal global config: %w", err)
 	}
+	if config.KeepImages < 0 {
+		util.Log.Warnf("Invalid keepImages value %d, using %d.", config.KeepImages, DefaultKeepImages)
+		config.KeepImages = DefaultKeepImages
+	}
 
 	loadedGlobalConfig = &config
 	util.Log.Debugf("Loaded global config from %s", configFilePath)
@@ -66,11 +77,77 @@ func LoadGlobalConfig(basePath string) (*GlobalConfig, error) {
 	return &cfgCopy, nil
 }
 
+// checkNotificationsList rejects a notifications setting that is a single mapping rather than a
+// list, which weakly typed decoding would otherwise read as a one-entry list.
+func checkNotificationsList(v *viper.Viper) error {
+	if _, isMap := v.Get("notifications").(map[string]interface{}); isMap {
+		return fmt.Errorf("notifications must be a list of webhooks, e.g. '- type: slack'")
+	}
+	return nil
+}
+
+// ReloadGlobalConfig drops the cached global config and reads it again from disk, so a running
+// server picks up edits to config.yaml. If the file cannot be read, the previous config stays in
+// effect and the error is returned.
+func ReloadGlobalConfig(basePath string) (*GlobalConfig, error) {
+	globalConfigMutex.Lock()
+	previous := loadedGlobalConfig
+	loadedGlobalConfig = nil
+	globalConfigMutex.Unlock()
+
+	cfg, err := LoadGlobalConfig(basePath)
+	if err != nil {
+		globalConfigMutex.Lock()
+		if loadedGlobalConfig == nil {
+			loadedGlobalConfig = previous
+		}
+		globalConfigMutex.Unlock()
+		return nil, fmt.Errorf("failed to reload global config, keeping the previous one: %w", err)
+	}
+	util.Log.Info("Reloaded global config from disk.")
+	return cfg, nil
+}
+
+// SaveGlobalConfig writes the global configuration to disk and refreshes the cached copy.
+func SaveGlobalConfig(basePath string, cfg *GlobalConfig) error {
+	globalConfigMutex.Lock()
+	defer globalConfigMutex.Unlock()
+
+	configFilePath := filepath.Join(basePath, GlobalConfigFileName)
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal global config: %w", err)
+	}
+
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", basePath, err)
+	}
+
+	if err := writeFileAtomic(configFilePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write global config file %s: %w", configFilePath, err)
+	}
+
+	cfgCopy := *cfg
+	loadedGlobalConfig = &cfgCopy
+	util.Log.Debugf("Saved global config to %s", configFilePath)
+	return nil
+}
+
 // GetProjectBasePath returns the path to a specific project's directory.
 func GetProjectBasePath(reflowBasePath, projectName string) string {
 	return filepath.Join(reflowBasePath, AppsDirName, projectName)
 }
 
+// GetProjectDataPath returns the directory holding host-path volumes for a project.
+func GetProjectDataPath(reflowBasePath, projectName string) string {
+	return filepath.Join(GetProjectBasePath(reflowBasePath, projectName), DataDirName)
+}
+
+// GetProjectSecretsPath returns the path to a project environment's secrets file.
+func GetProjectSecretsPath(reflowBasePath, projectName, env string) string {
+	return filepath.Join(GetProjectBasePath(reflowBasePath, projectName), SecretsDirName, env+".env")
+}
+
 // LoadProjectConfig loads a specific project's configuration.
 func LoadProjectConfig(reflowBasePath, projectName string) (*ProjectConfig, error) {
 	projectBasePath := GetProjectBasePath(reflowBasePath, projectName)
@@ -85,6 +162,8 @@ func LoadProjectConfig(reflowBasePath, projectName string) (*ProjectConfig, erro
 	// v.SetDefault("appPort", 3000)
 	// v.SetDefault("nodeVersion", "18-alpine")
 	// ... etc ...
+	v.SetDefault("replicas", DefaultReplicas)
+	v.SetDefault("sourceType", SourceTypeGit)
 
 	if err := v.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
@@ -94,16 +173,74 @@ func LoadProjectConfig(reflowBasePath, projectName string) (*ProjectConfig, erro
 		return nil, fmt.Errorf("failed to read project config file %s: %w", configFilePath, err)
 	}
 
+	if err := checkNotificationsList(v); err != nil {
+		return nil, fmt.Errorf("invalid project '%s' config: %w", projectName, err)
+	}
 	var config ProjectConfig
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal project '%s' config: %w", projectName, err)
 	}
 	config.ProjectName = projectName
+	if config.SourceType != SourceTypeGit && config.SourceType != SourceTypeLocal {
+		return nil, fmt.Errorf("invalid project '%s' config: sourceType must be '%s' or '%s', got '%s'", projectName, SourceTypeGit, SourceTypeLocal, config.SourceType)
+	}
+	if config.Clone.Depth < 0 {
+		util.Log.Warnf("Invalid clone.depth value %d for project '%s', fetching full history.", config.Clone.Depth, projectName)
+		config.Clone.Depth = 0
+	}
+	if config.Replicas < 1 {
+		util.Log.Warnf("Invalid replicas value %d for project '%s', using %d.", config.Replicas, projectName, DefaultReplicas)
+		config.Replicas = DefaultReplicas
+	}
+	if config.KeepImages < 0 {
+		util.Log.Warnf("Invalid keepImages value %d for project '%s', using the global setting.", config.KeepImages, projectName)
+		config.KeepImages = 0
+	}
+	for envName, envCfg := range config.Environments {
+		if envCfg.Replicas < 0 {
+			util.Log.Warnf("Invalid replicas value %d for project '%s' environment '%s', using the project setting.", envCfg.Replicas, projectName, envName)
+			envCfg.Replicas = 0
+			config.Environments[envName] = envCfg
+		}
+	}
+	if err := ValidateProjectConfig(reflowBasePath, &config); err != nil {
+		return nil, fmt.Errorf("invalid project '%s' config: %w", projectName, err)
+	}
 
 	util.Log.Debugf("Loaded project config for '%s' from %s", projectName, configFilePath)
 	return &config, nil
 }
 
+// ValidateProjectConfig checks the settings of a project config that cannot be corrected with a
+// warning: resources, restart policies, volumes, rate limits, extra Nginx locations and switch
+// hook paths.
+func ValidateProjectConfig(reflowBasePath string, projCfg *ProjectConfig) error {
+	if _, err := ParseResources("resources", projCfg.Resources); err != nil {
+		return err
+	}
+	if _, err := ParseRestartPolicy("restartPolicy", projCfg.RestartPolicy); err != nil {
+		return err
+	}
+	for envName, envCfg := range projCfg.Environments {
+		if _, err := ParseResources(fmt.Sprintf("environments.%s.resources", envName), EffectiveResources(projCfg, envName)); err != nil {
+			return err
+		}
+		if _, err := ParseRestartPolicy(fmt.Sprintf("environments.%s.restartPolicy", envName), envCfg.RestartPolicy); err != nil {
+			return err
+		}
+	}
+	if _, err := ResolveVolumes("volumes", GetProjectDataPath(reflowBasePath, projCfg.ProjectName), ProjectVolumePrefix(projCfg.ProjectName), projCfg.Volumes); err != nil {
+		return err
+	}
+	if err := ValidateRateLimits(projCfg); err != nil {
+		return err
+	}
+	if err := ValidateExtraLocations(projCfg); err != nil {
+		return err
+	}
+	return ValidateHooks(reflowBasePath, projCfg)
+}
+
 // SaveProjectConfig saves the project configuration file.
 func SaveProjectConfig(reflowBasePath string, projConfig *ProjectConfig) error {
 	projectBasePath := GetProjectBasePath(reflowBasePath, projConfig.ProjectName)
@@ -162,7 +299,7 @@ func SaveProjectState(reflowBasePath, projectName string, state *ProjectState) e
 		return fmt.Errorf("failed to create directory %s: %w", projectBasePath, err)
 	}
 
-	if err := os.WriteFile(stateFilePath, data, 0644); err != nil {
+	if err := writeFileAtomic(stateFilePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write project state file %s: %w", stateFilePath, err)
 	}
 	util.Log.Debugf("Saved project state for '%s' to %s", projectName, stateFilePath)
@@ -231,6 +368,33 @@ func GetPluginConfigPath(reflowBasePath, pluginName string) string {
 	return filepath.Join(GetPluginInstallPath(reflowBasePath, pluginName), PluginConfigDirName, PluginDefaultConfigName)
 }
 
+// GetPluginDataPath returns the directory holding host-path volumes for a plugin.
+// It lives outside the install path so plugin data can survive an uninstall.
+func GetPluginDataPath(reflowBasePath, pluginName string) string {
+	return filepath.Join(reflowBasePath, PluginDataDirName, pluginName)
+}
+
+// ReloadGlobalPluginState drops the cached plugin state and reads it again from disk. If the
+// file cannot be read, the previous state stays in effect and the error is returned.
+func ReloadGlobalPluginState(reflowBasePath string) (*GlobalPluginState, error) {
+	pluginStateMutex.Lock()
+	previous := loadedPluginState
+	loadedPluginState = nil
+	pluginStateMutex.Unlock()
+
+	state, err := LoadGlobalPluginState(reflowBasePath)
+	if err != nil {
+		pluginStateMutex.Lock()
+		if loadedPluginState == nil {
+			loadedPluginState = previous
+		}
+		pluginStateMutex.Unlock()
+		return nil, fmt.Errorf("failed to reload plugin state, keeping the previous one: %w", err)
+	}
+	util.Log.Info("Reloaded plugin state from disk.")
+	return state, nil
+}
+
 // LoadGlobalPluginState loads the global state of all installed plugins.
 func LoadGlobalPluginState(reflowBasePath string) (*GlobalPluginState, error) {
 	pluginStateMutex.RLock()
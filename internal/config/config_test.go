@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlobalConfigNotificationsList(t *testing.T) {
+	base := t.TempDir()
+	configPath := filepath.Join(base, GlobalConfigFileName)
+
+	list := "notifications:\n  - type: slack\n    webhookUrl: https://hooks.example.com/a\n"
+	if err := os.WriteFile(configPath, []byte(list), 0644); err != nil {
+		t.Fatal(err)
+	}
+	globalCfg, err := ReloadGlobalConfig(base)
+	if err != nil {
+		t.Fatalf("ReloadGlobalConfig with a notifications list: %v", err)
+	}
+	if len(globalCfg.Notifications) != 1 || globalCfg.Notifications[0].Type != "slack" {
+		t.Errorf("notifications = %+v, want one slack entry", globalCfg.Notifications)
+	}
+
+	mapping := "notifications:\n  type: slack\n  webhookUrl: https://hooks.example.com/a\n"
+	if err := os.WriteFile(configPath, []byte(mapping), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReloadGlobalConfig(base); err == nil {
+		t.Error("ReloadGlobalConfig accepted notifications as a single mapping")
+	}
+}
+
+func TestSaveGlobalConfig(t *testing.T) {
+	base := t.TempDir()
+	cfg := &GlobalConfig{DefaultDomain: "example.com"}
+	if err := SaveGlobalConfig(base, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filepath.Join(base, GlobalConfigFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("config.yaml has mode %v, want 0644", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("base dir holds %d entries after saving, want only config.yaml", len(entries))
+	}
+
+	loaded, err := ReloadGlobalConfig(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.DefaultDomain != "example.com" {
+		t.Errorf("defaultDomain = %q, want %q", loaded.DefaultDomain, "example.com")
+	}
+}
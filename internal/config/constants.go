@@ -5,19 +5,67 @@ const (
 	ReflowNginxContainerName = "reflow-nginx"
 	NginxImage               = "nginx:stable-alpine"
 
-	GlobalConfigFileName   = "config.yaml"
-	ProjectConfigFileName  = "config.yaml"
-	ProjectStateFileName   = "state.json"
-	DeploymentsLogFileName = "deployments.log"
-	AppsDirName            = "apps"
-	NginxDirName           = "nginx"
-	NginxConfDirName       = "conf.d"
-	NginxLogDirName        = "logs"
-	RepoDirName            = "repo"
+	GlobalConfigFileName    = "config.yaml"
+	GitCredentialsFileName  = "git-credentials.yaml" // In the base dir (per host) or a project dir (per project), 0600
+	ProjectConfigFileName   = "config.yaml"
+	ProjectStateFileName    = "state.json"
+	DeploymentsLogFileName  = "deployments.log"
+	AuditLogFileName        = "audit.log"        // In the base dir; API write operations, one JSON object per line
+	DeployProgressFileName  = ".deploy-progress" // Messages of the current or last deployment, for 'project tail-deploy'
+	AppsDirName             = "apps"
+	NginxDirName            = "nginx"
+	NginxConfDirName        = "conf.d"
+	NginxLimitsDirName      = "limits"      // Inside conf.d; holds http-level limit_req_zone definitions
+	NginxMaintenanceDirName = "maintenance" // Inside conf.d; holds the pages served in maintenance mode
+	NginxLogDirName         = "logs"
+	NginxCertsDirName       = "certs"
+	NginxAcmeDirName        = "acme-webroot"
+	AcmeDirName             = "acme"
+	RepoDirName             = "repo"
+	DataDirName             = "data"
+	SecretsDirName          = "secrets"       // Per-environment <env>.env files, kept outside the repo clone
+	SSHDirName              = ".ssh"          // In a project dir; holds the deploy key from 'project generate-key'
+	DeployKeyFileName       = "id_ecdsa"      // In SSHDirName; the public key is next to it with a .pub suffix
+	StateDirName            = ".reflow-state" // Runtime files: update check cache, API server pidfile
+	LogsDirName             = "logs"
+	APIPidFileName          = "api.pid" // In StateDirName; PID and listen address of the running API server
+	APILogFileName          = "api.log" // In LogsDirName; output of a detached API server
 
 	PluginsDirName          = "plugins"
 	PluginMetadataFileName  = "reflow-plugin.yaml"
 	PluginConfigDirName     = "config"
 	PluginStateFileName     = "plugins.json"
 	PluginDefaultConfigName = "config.json"
+	PluginDataDirName       = "plugin-data"
+
+	// Paths inside the Nginx container where certificates and ACME challenge files are mounted.
+	NginxCertsContainerPath       = "/etc/nginx/ssl"
+	NginxAcmeWebrootContainerPath = "/var/www/acme"
+	NginxLimitsContainerPath      = "/etc/nginx/conf.d/limits"
+	NginxMaintenanceContainerPath = "/etc/nginx/conf.d/maintenance"
+
+	DefaultDeploymentLogMaxSizeMB   = 5
+	DefaultDeploymentLogMaxArchives = 5
+	DefaultLogFileMaxSizeMB         = 10
+	DefaultLogFileMaxArchives       = 3
+	DefaultReplicas                 = 1
+	DefaultRestartPolicy            = "unless-stopped" // Docker restart policy for project and plugin containers
+	DefaultKeepImages               = 3
+	DefaultStopTimeoutSeconds       = 10 // Matches Docker's default stop grace period
+	DefaultStartTimeoutSeconds      = 30 // How long a new container may take to report running
+	PostStartHookTimeoutSeconds     = 300
+	SwitchHookTimeoutSeconds        = 300 // Limit for each hooks.preSwitch/postSwitch run
+	DefaultBuildRetryBackoffSeconds = 5
+
+	// SwitchHookContainerPrefix marks a switch hook that runs inside the new container instead
+	// of on the host, e.g. "container:/app/scripts/smoke.sh".
+	SwitchHookContainerPrefix = "container:"
+
+	// Project source types. Git projects are cloned and fetched; local projects are copied from a
+	// directory and identified by a hash of its contents instead of a commit.
+	SourceTypeGit   = "git"
+	SourceTypeLocal = "local"
+
+	// MaxContainerNameLength is the DNS label limit; Nginx resolves upstreams by container name.
+	MaxContainerNameLength = 63
 )
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// GetProjectDeployKeyPath returns the path of the deploy key 'project generate-key' creates.
+func GetProjectDeployKeyPath(reflowBasePath, projectName string) string {
+	return filepath.Join(GetProjectBasePath(reflowBasePath, projectName), SSHDirName, DeployKeyFileName)
+}
+
+// ProjectSSHKeyPath returns the absolute path of a project's own SSH key, or "" if it uses the
+// global one. Relative paths are resolved against the project directory, so they survive a rename.
+func ProjectSSHKeyPath(reflowBasePath string, projCfg *ProjectConfig) string {
+	if projCfg.SSHKeyPath == "" || filepath.IsAbs(projCfg.SSHKeyPath) {
+		return projCfg.SSHKeyPath
+	}
+	return filepath.Join(GetProjectBasePath(reflowBasePath, projCfg.ProjectName), projCfg.SSHKeyPath)
+}
+
+// GenerateDeployKey creates an unencrypted ECDSA P-256 key pair for a project in its .ssh
+// directory and returns the public key in authorized_keys format, for adding as a deploy key.
+// An existing key is only replaced if overwrite is set.
+func GenerateDeployKey(reflowBasePath, projectName string, overwrite bool) (string, error) {
+	keyPath := GetProjectDeployKeyPath(reflowBasePath, projectName)
+	if _, err := os.Stat(keyPath); err == nil && !overwrite {
+		return "", fmt.Errorf("deploy key %s already exists (use --force to replace it)", keyPath)
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ECDSA key: %w", err)
+	}
+	der, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode private key: %w", err)
+	}
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))) + " reflow-" + projectName
+
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", filepath.Dir(keyPath), err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return "", fmt.Errorf("failed to write private key %s: %w", keyPath, err)
+	}
+	if err := os.WriteFile(keyPath+".pub", []byte(authorizedKey+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write public key %s.pub: %w", keyPath, err)
+	}
+	return authorizedKey, nil
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	SecretKeyFileName = ".secret.key"
+	SecretKeyEnvVar   = "REFLOW_SECRET_KEY"
+
+	secretKeySize = 32 // AES-256
+)
+
+// encryptedEnvHeader marks an env file stored as ciphertext; the rest of the file is the
+// base64-encoded AES-GCM nonce followed by the sealed content.
+var encryptedEnvHeader = []byte("REFLOW-ENCRYPTED-ENV v1\n")
+
+// ErrSecretKeyMissing is returned when an encrypted env file is read but no key is available.
+var ErrSecretKeyMissing = errors.New("file is encrypted but no secret key is available: export " + SecretKeyEnvVar + " with the key from reflow/" + SecretKeyFileName)
+
+// GetSecretKeyPath returns the path of the generated secrets encryption key.
+func GetSecretKeyPath(reflowBasePath string) string {
+	return filepath.Join(reflowBasePath, SecretKeyFileName)
+}
+
+// GenerateSecretKey creates a random encryption key at reflow/.secret.key (0600). An existing key
+// is kept, since replacing it would make files encrypted with it unreadable. Returns true if a
+// new key was written.
+func GenerateSecretKey(reflowBasePath string) (bool, error) {
+	keyPath := GetSecretKeyPath(reflowBasePath)
+	if _, err := os.Stat(keyPath); err == nil {
+		return false, nil
+	} else if !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to check for secret key %s: %w", keyPath, err)
+	}
+
+	key := make([]byte, secretKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return false, fmt.Errorf("failed to generate secret key: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(key) + "\n"
+	if err := os.WriteFile(keyPath, []byte(encoded), 0600); err != nil {
+		return false, fmt.Errorf("failed to write secret key %s: %w", keyPath, err)
+	}
+	return true, nil
+}
+
+// LoadSecretKey returns the secrets encryption key from REFLOW_SECRET_KEY, or else from
+// reflow/.secret.key. Returns nil without an error when encryption is not set up.
+func LoadSecretKey(reflowBasePath string) ([]byte, error) {
+	source := SecretKeyEnvVar
+	encoded := os.Getenv(SecretKeyEnvVar)
+	if encoded == "" {
+		keyPath := GetSecretKeyPath(reflowBasePath)
+		content, err := os.ReadFile(keyPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to read secret key %s: %w", keyPath, err)
+		}
+		source, encoded = keyPath, string(content)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != secretKeySize {
+		return nil, fmt.Errorf("invalid secret key in %s: expected %d base64-encoded bytes", source, secretKeySize)
+	}
+	return key, nil
+}
+
+// IsEncryptedEnv reports whether env file content was written by SaveEncryptedEnv.
+func IsEncryptedEnv(content []byte) bool {
+	return bytes.HasPrefix(content, encryptedEnvHeader)
+}
+
+// SaveEncryptedEnv encrypts content with AES-GCM using the configured secret key and writes it
+// to filePath (0600) under the encrypted env header.
+func SaveEncryptedEnv(reflowBasePath, filePath string, content []byte) error {
+	key, err := LoadSecretKey(reflowBasePath)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return fmt.Errorf("cannot encrypt %s: no secret key configured (run 'reflow init --encrypt-secrets' or export %s)", filePath, SecretKeyEnvVar)
+	}
+
+	gcm, err := newEnvCipher(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, content, nil)
+
+	var out bytes.Buffer
+	out.Write(encryptedEnvHeader)
+	out.WriteString(base64.StdEncoding.EncodeToString(sealed))
+	out.WriteString("\n")
+	return writePrivateFile(filePath, out.Bytes())
+}
+
+// LoadEncryptedEnv reads an env file, decrypting it in memory if it was written by
+// SaveEncryptedEnv. Plaintext files are returned as they are. Returns ErrSecretKeyMissing for
+// an encrypted file when no key is available.
+func LoadEncryptedEnv(reflowBasePath, filePath string) ([]byte, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !IsEncryptedEnv(content) {
+		return content, nil
+	}
+
+	key, err := LoadSecretKey(reflowBasePath)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrSecretKeyMissing
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content[len(encryptedEnvHeader):])))
+	if err != nil {
+		return nil, fmt.Errorf("encrypted file %s is corrupt: %w", filePath, err)
+	}
+	gcm, err := newEnvCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted file %s is corrupt: too short", filePath)
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s (wrong %s?): %w", filePath, SecretKeyEnvVar, err)
+	}
+	return plaintext, nil
+}
+
+func newEnvCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return gcm, nil
+}
+
+// writePrivateFile writes content to path atomically and leaves it readable only by its owner.
+func writePrivateFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(path), err)
+	}
+	return writeFileAtomic(path, content, 0600)
+}
+
+// writeFileAtomic writes content to path through a temporary file in the same directory, so the
+// file is either fully replaced or unchanged, even if the process dies mid-write.
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	tempPath := tempFile.Name()
+	if _, err := tempFile.Write(content); err != nil {
+		_ = tempFile.Close()
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to write %s: %w", tempPath, err)
+	}
+	if err := tempFile.Close(); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to write %s: %w", tempPath, err)
+	}
+	// CreateTemp always uses 0600, so apply the requested mode explicitly.
+	if err := os.Chmod(tempPath, perm); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to set permissions on %s: %w", tempPath, err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to save %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ProjectEnvFilePath returns the path of an environment's env file (environments.<env>.envFile)
+// in the project's repository directory. Paths that resolve outside the repository are rejected.
+func ProjectEnvFilePath(reflowBasePath string, projCfg *ProjectConfig, env string) (string, error) {
+	envConf, ok := projCfg.Environments[env]
+	if !ok {
+		return "", fmt.Errorf("environment '%s' not defined in project config", env)
+	}
+	if envConf.EnvFile == "" {
+		return "", fmt.Errorf("no envFile specified for environment '%s' in project config", env)
+	}
+
+	repoPath := filepath.Join(GetProjectBasePath(reflowBasePath, projCfg.ProjectName), RepoDirName)
+	cleanPath := filepath.Clean(filepath.Join(repoPath, envConf.EnvFile))
+	if !strings.HasPrefix(cleanPath, filepath.Clean(repoPath)+string(os.PathSeparator)) && cleanPath != filepath.Clean(repoPath) {
+		return "", fmt.Errorf("invalid env file path '%s' resolves outside repo directory", envConf.EnvFile)
+	}
+	return cleanPath, nil
+}
+
+// GetEnvFileVar returns the value of key in the env file at path. As for the container, the
+// last line setting key wins. A missing file sets nothing.
+func GetEnvFileVar(path, key string) (string, bool, error) {
+	lines, err := readEnvFileLines(path)
+	if err != nil {
+		return "", false, err
+	}
+	var value string
+	found := false
+	for _, line := range lines {
+		if lineKey, lineValue, ok := envFileAssignment(line); ok && lineKey == key {
+			value, found = lineValue, true
+		}
+	}
+	return value, found, nil
+}
+
+// SetEnvFileVars adds or updates KEY=VALUE pairs in the env file at path, creating it if needed.
+// Every line setting a given key is updated in place and new keys are appended, so comments and
+// the order of other lines are kept.
+func SetEnvFileVars(path string, pairs []string) error {
+	updates, order, err := parseAssignments("variable", pairs)
+	if err != nil {
+		return err
+	}
+	lines, err := readEnvFileLines(path)
+	if err != nil {
+		return err
+	}
+
+	set := make(map[string]bool, len(updates))
+	for i, line := range lines {
+		if key, _, ok := envFileAssignment(line); ok {
+			if value, update := updates[key]; update {
+				lines[i] = key + "=" + value
+				set[key] = true
+			}
+		}
+	}
+	for _, key := range order {
+		if !set[key] {
+			lines = append(lines, key+"="+updates[key])
+		}
+	}
+	return writeEnvFileLines(path, lines)
+}
+
+// UnsetEnvFileVars removes every line setting one of keys from the env file at path and returns
+// the keys that were set. Other lines, including comments, are kept as they are.
+func UnsetEnvFileVars(path string, keys []string) ([]string, error) {
+	remove := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		remove[key] = true
+	}
+	lines, err := readEnvFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept, removed []string
+	for _, line := range lines {
+		if key, _, ok := envFileAssignment(line); ok && remove[key] {
+			if !slices.Contains(removed, key) {
+				removed = append(removed, key)
+			}
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	return removed, writeEnvFileLines(path, kept)
+}
+
+// envFileAssignment parses a KEY=VALUE line of an env file the way util.ParseEnvVars reads it.
+// Blank lines, comments and lines without '=' are not assignments.
+func envFileAssignment(line string) (key, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	key, value, ok = strings.Cut(line, "=")
+	return key, value, ok
+}
+
+// readEnvFileLines returns the lines of the env file at path; a missing file has none.
+func readEnvFileLines(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read env file %s: %w", path, err)
+	}
+	text := strings.TrimSuffix(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// writeEnvFileLines replaces the env file at path with lines.
+func writeEnvFileLines(path string, lines []string) error {
+	content := ""
+	if len(lines) > 0 {
+		content = strings.Join(lines, "\n") + "\n"
+	}
+	return WriteEnvFile(path, []byte(content))
+}
+
+// WriteEnvFile replaces the env file at path with content, creating it if needed. The file is
+// written through a temporary file and renamed into place, so a failed write never leaves a
+// truncated env file for the next deployment; an existing file keeps its mode.
+func WriteEnvFile(path string, content []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(path), err)
+	}
+	return writeFileAtomic(path, content, perm)
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflow/internal/util"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GitCredential is a username/token pair used for cloning and fetching over HTTPS.
+type GitCredential struct {
+	Host     string `yaml:"host,omitempty"`     // e.g. github.com; unset for a project's own credential
+	Username string `yaml:"username,omitempty"` // Most hosts accept any non-empty name with a token
+	Token    string `yaml:"token"`
+}
+
+// gitCredentialsFile is the on-disk format of the global git credentials file.
+type gitCredentialsFile struct {
+	Credentials []GitCredential `yaml:"credentials"`
+}
+
+// GetGitCredentialsPath returns the path of the global git credentials file.
+func GetGitCredentialsPath(reflowBasePath string) string {
+	return filepath.Join(reflowBasePath, GitCredentialsFileName)
+}
+
+// GetProjectGitCredentialPath returns the path of a project's own git credential, next to
+// (not inside) its repository clone.
+func GetProjectGitCredentialPath(reflowBasePath, projectName string) string {
+	return filepath.Join(GetProjectBasePath(reflowBasePath, projectName), GitCredentialsFileName)
+}
+
+// LoadGitCredentials returns the stored per-host git credentials, sorted by host.
+func LoadGitCredentials(reflowBasePath string) ([]GitCredential, error) {
+	var file gitCredentialsFile
+	if err := readCredentialsYAML(GetGitCredentialsPath(reflowBasePath), &file); err != nil {
+		return nil, err
+	}
+	return file.Credentials, nil
+}
+
+// SetGitCredential adds or replaces the stored credential for cred.Host.
+func SetGitCredential(reflowBasePath string, cred GitCredential) error {
+	cred.Host = normalizeGitHost(cred.Host)
+	if cred.Host == "" {
+		return fmt.Errorf("git credential host must not be empty")
+	}
+	if cred.Token == "" {
+		return fmt.Errorf("git credential token must not be empty")
+	}
+
+	creds, err := LoadGitCredentials(reflowBasePath)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range creds {
+		if creds[i].Host == cred.Host {
+			creds[i] = cred
+			replaced = true
+		}
+	}
+	if !replaced {
+		creds = append(creds, cred)
+	}
+	sort.Slice(creds, func(i, j int) bool { return creds[i].Host < creds[j].Host })
+	return writeCredentialsYAML(GetGitCredentialsPath(reflowBasePath), gitCredentialsFile{Credentials: creds})
+}
+
+// UnsetGitCredential removes the stored credential for host. Returns false if there was none.
+func UnsetGitCredential(reflowBasePath, host string) (bool, error) {
+	host = normalizeGitHost(host)
+	creds, err := LoadGitCredentials(reflowBasePath)
+	if err != nil {
+		return false, err
+	}
+	kept := creds[:0]
+	for _, cred := range creds {
+		if cred.Host != host {
+			kept = append(kept, cred)
+		}
+	}
+	if len(kept) == len(creds) {
+		return false, nil
+	}
+	return true, writeCredentialsYAML(GetGitCredentialsPath(reflowBasePath), gitCredentialsFile{Credentials: kept})
+}
+
+// LoadProjectGitCredential returns a project's own git credential, or nil if it has none.
+func LoadProjectGitCredential(reflowBasePath, projectName string) (*GitCredential, error) {
+	var cred GitCredential
+	path := GetProjectGitCredentialPath(reflowBasePath, projectName)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err := readCredentialsYAML(path, &cred); err != nil {
+		return nil, err
+	}
+	if cred.Token == "" {
+		return nil, nil
+	}
+	return &cred, nil
+}
+
+// SaveProjectGitCredential stores a project's own git credential, which takes precedence over
+// the per-host credentials.
+func SaveProjectGitCredential(reflowBasePath, projectName string, cred GitCredential) error {
+	if cred.Token == "" {
+		return fmt.Errorf("git credential token must not be empty")
+	}
+	return writeCredentialsYAML(GetProjectGitCredentialPath(reflowBasePath, projectName), cred)
+}
+
+// ResolveGitCredential returns the credential to use for an HTTPS repository URL: the project's
+// own credential if set (projectName may be empty), otherwise the one stored for the URL's host.
+// Returns nil for non-HTTP URLs or when nothing matches.
+func ResolveGitCredential(reflowBasePath, projectName, repoURL string) (*GitCredential, error) {
+	parsed, err := url.Parse(repoURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return nil, nil
+	}
+
+	if projectName != "" {
+		cred, err := LoadProjectGitCredential(reflowBasePath, projectName)
+		if err != nil || cred != nil {
+			return cred, err
+		}
+	}
+
+	creds, err := LoadGitCredentials(reflowBasePath)
+	if err != nil {
+		return nil, err
+	}
+	host := normalizeGitHost(parsed.Host)
+	for _, cred := range creds {
+		if cred.Host == host {
+			found := cred
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// normalizeGitHost lowercases a host and strips any scheme or path, so "https://GitHub.com/"
+// and "github.com" match.
+func normalizeGitHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	return host
+}
+
+func readCredentialsYAML(path string, out interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read git credentials %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse git credentials %s: %w", path, err)
+	}
+	registerCredentialSecrets(out)
+	return nil
+}
+
+func writeCredentialsYAML(path string, in interface{}) error {
+	data, err := yaml.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode git credentials: %w", err)
+	}
+	if err := writePrivateFile(path, data); err != nil {
+		return err
+	}
+	registerCredentialSecrets(in)
+	util.Log.Debugf("Saved git credentials to %s", path)
+	return nil
+}
+
+// registerCredentialSecrets keeps loaded tokens out of log output.
+func registerCredentialSecrets(v interface{}) {
+	switch c := v.(type) {
+	case *GitCredential:
+		util.RegisterSecret(c.Token)
+	case GitCredential:
+		util.RegisterSecret(c.Token)
+	case *gitCredentialsFile:
+		for _, cred := range c.Credentials {
+			util.RegisterSecret(cred.Token)
+		}
+	case gitCredentialsFile:
+		for _, cred := range c.Credentials {
+			util.RegisterSecret(cred.Token)
+		}
+	}
+}
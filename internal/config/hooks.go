@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SwitchHookScriptPath resolves a host switch hook script against the project's repository. The
+// script must be a relative path inside the repository: hooks run on the host as the reflow
+// user and can be set through the config API, so they may not name arbitrary executables.
+func SwitchHookScriptPath(repoPath, script string) (string, error) {
+	if script == "" || filepath.IsAbs(script) {
+		return "", fmt.Errorf("'%s' must be a path relative to the repository", script)
+	}
+	repoPath = filepath.Clean(repoPath)
+	scriptPath := filepath.Join(repoPath, script)
+	if !strings.HasPrefix(scriptPath, repoPath+string(filepath.Separator)) {
+		return "", fmt.Errorf("'%s' resolves outside the repository", script)
+	}
+	return scriptPath, nil
+}
+
+// ValidateHooks checks that the host switch hooks of a project config are scripts inside its
+// repository. Hooks prefixed with SwitchHookContainerPrefix run in the container and are not
+// checked.
+func ValidateHooks(reflowBasePath string, projCfg *ProjectConfig) error {
+	repoPath := filepath.Join(GetProjectBasePath(reflowBasePath, projCfg.ProjectName), RepoDirName)
+	for _, hook := range []struct{ name, script string }{
+		{"hooks.preSwitch", projCfg.Hooks.PreSwitch},
+		{"hooks.postSwitch", projCfg.Hooks.PostSwitch},
+	} {
+		if hook.script == "" || strings.HasPrefix(hook.script, SwitchHookContainerPrefix) {
+			continue
+		}
+		if _, err := SwitchHookScriptPath(repoPath, hook.script); err != nil {
+			return fmt.Errorf("invalid %s: %w", hook.name, err)
+		}
+	}
+	return nil
+}
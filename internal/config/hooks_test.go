@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestValidateHooks(t *testing.T) {
+	tests := []struct {
+		preSwitch string
+		wantErr   bool
+	}{
+		{"", false},
+		{"scripts/migrate.sh", false},
+		{"./scripts/../migrate.sh", false},
+		{"container:/app/scripts/smoke.sh", false},
+		{"/usr/bin/id", true},
+		{"../../../../bin/sh", true},
+		{"scripts/../../config.yaml", true},
+		{".", true},
+	}
+	for _, tt := range tests {
+		projCfg := &ProjectConfig{ProjectName: "app", Hooks: HooksConfig{PreSwitch: tt.preSwitch}}
+		if err := ValidateHooks(t.TempDir(), projCfg); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateHooks(preSwitch %q) error = %v, wantErr %v", tt.preSwitch, err, tt.wantErr)
+		}
+	}
+}
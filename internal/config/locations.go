@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	locationPathPattern  = regexp.MustCompile(`^/[^\s{};]*$`)
+	upstreamNamePattern  = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	reservedLocationPath = map[string]bool{"/": true, "/.well-known/acme-challenge/": true}
+)
+
+// ContainerServer returns the "container:port" the location proxies to, or false if ProxyPass
+// is a URL or the name of an upstream.
+func (l NginxLocation) ContainerServer() (string, bool) {
+	if strings.Contains(l.ProxyPass, "://") {
+		return "", false
+	}
+	if _, _, found := strings.Cut(l.ProxyPass, ":"); !found {
+		return "", false
+	}
+	return l.ProxyPass, true
+}
+
+// ValidateExtraLocations checks the extra Nginx locations of every environment in a project config.
+func ValidateExtraLocations(projCfg *ProjectConfig) error {
+	for _, env := range []string{"test", "prod"} {
+		paths := make(map[string]bool)
+		for i, loc := range projCfg.Environments[env].ExtraLocations {
+			field := fmt.Sprintf("environments.%s.extraLocations[%d]", env, i)
+			if !locationPathPattern.MatchString(loc.Path) {
+				return fmt.Errorf("invalid %s.path '%s': must start with '/' and contain no whitespace, braces or ';'", field, loc.Path)
+			}
+			if reservedLocationPath[loc.Path] {
+				return fmt.Errorf("invalid %s.path '%s': reflow already routes this path", field, loc.Path)
+			}
+			if paths[loc.Path] {
+				return fmt.Errorf("invalid %s.path '%s': listed more than once", field, loc.Path)
+			}
+			paths[loc.Path] = true
+
+			if err := validateProxyPass(loc.ProxyPass); err != nil {
+				return fmt.Errorf("invalid %s.proxyPass '%s': %w", field, loc.ProxyPass, err)
+			}
+			for _, directive := range loc.ExtraDirectives {
+				if strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(directive), ";")) == "" || strings.ContainsAny(directive, "{}\n\r") {
+					return fmt.Errorf("invalid %s.extraDirectives entry '%s': must be a single non-empty directive without braces", field, directive)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateProxyPass checks that target is an http(s) URL, a container:port or an upstream name.
+func validateProxyPass(target string) error {
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return fmt.Errorf("not a valid URL: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("must be an http:// or https:// URL with a host")
+		}
+		if strings.ContainsAny(target, " \t;{}") {
+			return fmt.Errorf("must not contain whitespace, braces or ';'")
+		}
+		return nil
+	}
+
+	name, port, hasPort := strings.Cut(target, ":")
+	if !upstreamNamePattern.MatchString(name) {
+		return fmt.Errorf("must be an http(s) URL, a container:port or an upstream name")
+	}
+	if hasPort {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("port '%s' must be a number between 1 and 65535", port)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProjectNetworkName returns the name of the Docker network a project with a network policy
+// gets in addition to the main reflow network.
+func ProjectNetworkName(projectName string) string {
+	return fmt.Sprintf("reflow-project-%s", strings.ToLower(projectName))
+}
+
+// PluginNetworkName returns the name of the Docker network a container plugin with a network
+// policy runs on instead of the main reflow network.
+func PluginNetworkName(pluginName string) string {
+	return fmt.Sprintf("reflow-plugin-%s-net", pluginName)
+}
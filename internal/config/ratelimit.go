@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var rateLimitZonePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// EffectiveRateLimit returns the rate limit for a project environment with its zone name
+// filled in, or nil if the environment has none configured.
+func EffectiveRateLimit(projCfg *ProjectConfig, env string) *NginxRateLimit {
+	envCfg, ok := projCfg.Environments[env]
+	if !ok || envCfg.RateLimit == nil {
+		return nil
+	}
+	rl := *envCfg.RateLimit
+	if rl.ZoneName == "" {
+		rl.ZoneName = fmt.Sprintf("reflow_%s_%s", projCfg.ProjectName, env)
+	}
+	return &rl
+}
+
+// ValidateRateLimits checks the rate limit settings of every environment in a project config.
+func ValidateRateLimits(projCfg *ProjectConfig) error {
+	zones := make(map[string]string)
+	for _, env := range []string{"test", "prod"} {
+		rl := EffectiveRateLimit(projCfg, env)
+		if rl == nil {
+			continue
+		}
+		field := fmt.Sprintf("environments.%s.rateLimit", env)
+		if rl.RequestsPerSecond < 1 {
+			return fmt.Errorf("invalid %s.requestsPerSecond %d: must be at least 1", field, rl.RequestsPerSecond)
+		}
+		if rl.BurstSize < 0 {
+			return fmt.Errorf("invalid %s.burstSize %d: must not be negative", field, rl.BurstSize)
+		}
+		if !rateLimitZonePattern.MatchString(rl.ZoneName) {
+			return fmt.Errorf("invalid %s.zoneName '%s': only letters, digits, '_' and '-' are allowed", field, rl.ZoneName)
+		}
+		if other, dup := zones[rl.ZoneName]; dup {
+			return fmt.Errorf("invalid %s.zoneName '%s': already used by the '%s' environment", field, rl.ZoneName, other)
+		}
+		zones[rl.ZoneName] = env
+	}
+	return nil
+}
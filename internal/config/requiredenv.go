@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RequiredEnvVar is an entry of environments.<env>.requiredEnv: a variable that must be set to a
+// non-empty value, which must match Pattern if that is set.
+type RequiredEnvVar struct {
+	Key     string
+	Pattern *regexp.Regexp // Matched against the whole value; nil accepts any non-empty value
+}
+
+// ParseRequiredEnv parses requiredEnv entries of the form "KEY" or "KEY=regex". fieldName is
+// used to name the offending field in errors (e.g., "environments.prod.requiredEnv").
+func ParseRequiredEnv(fieldName string, entries []string) ([]RequiredEnvVar, error) {
+	required := make([]RequiredEnvVar, 0, len(entries))
+	for _, entry := range entries {
+		key, pattern, hasPattern := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("invalid %s entry '%s': missing variable name", fieldName, entry)
+		}
+		requiredVar := RequiredEnvVar{Key: key}
+		if hasPattern && pattern != "" {
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s entry '%s': %w", fieldName, entry, err)
+			}
+			requiredVar.Pattern = re
+		}
+		required = append(required, requiredVar)
+	}
+	return required, nil
+}
+
+// CheckRequiredEnv checks envVars (KEY=value entries, later entries win) against the required
+// variables and describes each one that is missing, empty or has a value not matching its
+// pattern, in the order they were declared. Values are never included.
+func CheckRequiredEnv(required []RequiredEnvVar, envVars []string) []string {
+	values := make(map[string]string, len(envVars))
+	for _, envVar := range envVars {
+		key, value, _ := strings.Cut(envVar, "=")
+		values[key] = value
+	}
+
+	var problems []string
+	for _, requiredVar := range required {
+		value, ok := values[requiredVar.Key]
+		switch {
+		case !ok:
+			problems = append(problems, requiredVar.Key+" (missing)")
+		case value == "":
+			problems = append(problems, requiredVar.Key+" (empty)")
+		case requiredVar.Pattern != nil && !requiredVar.Pattern.MatchString(value):
+			problems = append(problems, fmt.Sprintf("%s (does not match %s)", requiredVar.Key, strings.TrimSuffix(strings.TrimPrefix(requiredVar.Pattern.String(), "^(?:"), ")$")))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ResourceLimits holds parsed container resource limits. Zero values mean unlimited.
+type ResourceLimits struct {
+	MemoryBytes            int64
+	MemoryReservationBytes int64
+	NanoCPUs               int64
+}
+
+// EffectiveReplicas returns the replica count for a project environment, falling back to the
+// project-level setting when the environment doesn't override it.
+func EffectiveReplicas(projCfg *ProjectConfig, env string) int {
+	if replicas := projCfg.Environments[env].Replicas; replicas > 0 {
+		return replicas
+	}
+	return projCfg.Replicas
+}
+
+// EffectiveKeepImages returns how many recent images of a project pruning keeps besides those
+// of its active commits: the project's keepImages if set, otherwise the global one.
+func EffectiveKeepImages(globalCfg *GlobalConfig, projCfg *ProjectConfig) int {
+	if projCfg != nil && projCfg.KeepImages > 0 {
+		return projCfg.KeepImages
+	}
+	if globalCfg != nil {
+		return globalCfg.KeepImages
+	}
+	return DefaultKeepImages
+}
+
+// EffectiveResources returns the resource limits for a project environment. Each field set in
+// the environment's resources overrides the project-level one.
+func EffectiveResources(projCfg *ProjectConfig, env string) ResourcesConfig {
+	res := projCfg.Resources
+	envRes := projCfg.Environments[env].Resources
+	if envRes.Memory != "" {
+		res.Memory = envRes.Memory
+	}
+	if envRes.MemoryReservation != "" {
+		res.MemoryReservation = envRes.MemoryReservation
+	}
+	if envRes.CPUs != "" {
+		res.CPUs = envRes.CPUs
+	}
+	return res
+}
+
+// ParseResources validates a ResourcesConfig and converts it to Docker-ready limits.
+// fieldPrefix is used to name the offending field in errors (e.g., "resources").
+func ParseResources(fieldPrefix string, res ResourcesConfig) (ResourceLimits, error) {
+	var limits ResourceLimits
+	var err error
+
+	if limits.MemoryBytes, err = ParseMemorySize(res.Memory); err != nil {
+		return limits, fmt.Errorf("invalid %s.memory '%s': %w", fieldPrefix, res.Memory, err)
+	}
+	if limits.MemoryReservationBytes, err = ParseMemorySize(res.MemoryReservation); err != nil {
+		return limits, fmt.Errorf("invalid %s.memoryReservation '%s': %w", fieldPrefix, res.MemoryReservation, err)
+	}
+	if limits.NanoCPUs, err = ParseCPUs(res.CPUs); err != nil {
+		return limits, fmt.Errorf("invalid %s.cpus '%s': %w", fieldPrefix, res.CPUs, err)
+	}
+
+	if limits.MemoryBytes > 0 && limits.MemoryReservationBytes > limits.MemoryBytes {
+		return limits, fmt.Errorf("invalid %s.memoryReservation '%s': must not exceed %s.memory '%s'", fieldPrefix, res.MemoryReservation, fieldPrefix, res.Memory)
+	}
+	return limits, nil
+}
+
+// ParseMemorySize parses a size like "512m", "1g" or "1048576" into bytes. Empty means no limit.
+func ParseMemorySize(value string) (int64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	numberPart := strings.TrimSuffix(value, "b")
+	switch {
+	case strings.HasSuffix(numberPart, "k"):
+		multiplier = 1024
+	case strings.HasSuffix(numberPart, "m"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(numberPart, "g"):
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		numberPart = numberPart[:len(numberPart)-1]
+	}
+
+	number, err := strconv.ParseFloat(numberPart, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number with optional b, k, m or g suffix")
+	}
+	if number <= 0 {
+		return 0, fmt.Errorf("must be greater than zero")
+	}
+
+	bytes := int64(number * float64(multiplier))
+	// Docker rejects memory limits below 6MB.
+	if bytes < 6*1024*1024 {
+		return 0, fmt.Errorf("must be at least 6m")
+	}
+	return bytes, nil
+}
+
+// ParseCPUs parses a fractional CPU count like "0.5" into Docker NanoCPUs. Empty means no limit.
+func ParseCPUs(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	cpus, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number of CPUs (e.g., 0.5)")
+	}
+	if cpus <= 0 {
+		return 0, fmt.Errorf("must be greater than zero")
+	}
+	return int64(cpus * 1e9), nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RestartPolicy is a parsed Docker container restart policy.
+type RestartPolicy struct {
+	Mode       string // "no", "always", "unless-stopped" or "on-failure"
+	MaxRetries int    // Only set for "on-failure"; zero means unlimited
+}
+
+// EffectiveRestartPolicy returns the restart policy for a project environment, falling back to
+// the project-level setting when the environment doesn't override it. Empty means the default.
+func EffectiveRestartPolicy(projCfg *ProjectConfig, env string) string {
+	if policy := projCfg.Environments[env].RestartPolicy; policy != "" {
+		return policy
+	}
+	return projCfg.RestartPolicy
+}
+
+// ParseRestartPolicy validates a restart policy like "unless-stopped" or "on-failure:5", using
+// the same syntax as 'docker run --restart'. Empty means DefaultRestartPolicy.
+// fieldName is used to name the offending field in errors (e.g., "restartPolicy").
+func ParseRestartPolicy(fieldName, value string) (RestartPolicy, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return RestartPolicy{Mode: DefaultRestartPolicy}, nil
+	}
+
+	mode, retries, hasRetries := strings.Cut(value, ":")
+	switch mode {
+	case "no", "always", "unless-stopped":
+		if hasRetries {
+			return RestartPolicy{}, fmt.Errorf("invalid %s '%s': a maximum retry count is only allowed with 'on-failure'", fieldName, value)
+		}
+		return RestartPolicy{Mode: mode}, nil
+	case "on-failure":
+		policy := RestartPolicy{Mode: mode}
+		if hasRetries {
+			maxRetries, err := strconv.Atoi(retries)
+			if err != nil || maxRetries < 0 {
+				return RestartPolicy{}, fmt.Errorf("invalid %s '%s': maximum retry count must be a non-negative integer", fieldName, value)
+			}
+			policy.MaxRetries = maxRetries
+		}
+		return policy, nil
+	default:
+		return RestartPolicy{}, fmt.Errorf("invalid %s '%s': must be 'no', 'always', 'unless-stopped' or 'on-failure[:max-retries]'", fieldName, value)
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/util"
+	"regexp"
+	"strings"
+)
+
+// secretKeyPattern matches the variable names accepted by 'project secrets set' and 'project env set'.
+var secretKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ReadSecrets returns the KEY=VALUE entries of a project environment's secrets file, in file
+// order, decrypting the file in memory if needed. A missing file yields no entries.
+func ReadSecrets(reflowBasePath, projectName, env string) ([]string, error) {
+	secretsPath := GetProjectSecretsPath(reflowBasePath, projectName, env)
+	content, err := LoadEncryptedEnv(reflowBasePath, secretsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read secrets file %s: %w", secretsPath, err)
+	}
+
+	var entries []string
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.Contains(line, "=") {
+			util.Log.Warnf("Skipping invalid line %d in secrets file %s: Missing '='", i+1, secretsPath)
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, nil
+}
+
+// WriteSecretsFile replaces a project environment's secrets file with content. The file is
+// only readable by its owner, and is encrypted when a secret key is configured.
+func WriteSecretsFile(reflowBasePath, projectName, env string, content []byte) error {
+	secretsPath := GetProjectSecretsPath(reflowBasePath, projectName, env)
+	key, err := LoadSecretKey(reflowBasePath)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		return SaveEncryptedEnv(reflowBasePath, secretsPath, content)
+	}
+	return writePrivateFile(secretsPath, content)
+}
+
+// EncryptSecretsFiles encrypts every plaintext project secrets file with the configured key and
+// returns the paths it encrypted.
+func EncryptSecretsFiles(reflowBasePath string) ([]string, error) {
+	pattern := filepath.Join(reflowBasePath, AppsDirName, "*", SecretsDirName, "*.env")
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets files: %w", err)
+	}
+
+	var encrypted []string
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return encrypted, fmt.Errorf("failed to read secrets file %s: %w", path, err)
+		}
+		if IsEncryptedEnv(content) {
+			continue
+		}
+		if err := SaveEncryptedEnv(reflowBasePath, path, content); err != nil {
+			return encrypted, err
+		}
+		encrypted = append(encrypted, path)
+	}
+	return encrypted, nil
+}
+
+// SetSecrets adds or updates KEY=VALUE pairs in a project environment's secrets file.
+func SetSecrets(reflowBasePath, projectName, env string, pairs []string) error {
+	updates, order, err := parseAssignments("secret", pairs)
+	if err != nil {
+		return err
+	}
+
+	entries, err := ReadSecrets(reflowBasePath, projectName, env)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		key, _, _ := strings.Cut(entry, "=")
+		if value, ok := updates[key]; ok {
+			entries[i] = key + "=" + value
+			delete(updates, key)
+		}
+	}
+	for _, key := range order {
+		if value, ok := updates[key]; ok {
+			entries = append(entries, key+"="+value)
+		}
+	}
+	return WriteSecretsFile(reflowBasePath, projectName, env, formatSecrets(entries))
+}
+
+// UnsetSecrets removes keys from a project environment's secrets file and returns the keys
+// that were present.
+func UnsetSecrets(reflowBasePath, projectName, env string, keys []string) ([]string, error) {
+	remove := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		remove[key] = true
+	}
+
+	entries, err := ReadSecrets(reflowBasePath, projectName, env)
+	if err != nil {
+		return nil, err
+	}
+	var kept, removed []string
+	for _, entry := range entries {
+		key, _, _ := strings.Cut(entry, "=")
+		if remove[key] {
+			removed = append(removed, key)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	return removed, WriteSecretsFile(reflowBasePath, projectName, env, formatSecrets(kept))
+}
+
+// parseAssignments validates KEY=VALUE pairs given on the command line and returns their values
+// by key, with the keys in the order first given. kind names a pair in errors, e.g. "secret".
+func parseAssignments(kind string, pairs []string) (map[string]string, []string, error) {
+	updates := make(map[string]string, len(pairs))
+	var order []string
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid %s '%s': expected KEY=VALUE", kind, pair)
+		}
+		if !secretKeyPattern.MatchString(key) {
+			return nil, nil, fmt.Errorf("invalid %s name '%s': use letters, digits and underscores, not starting with a digit", kind, key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, nil, fmt.Errorf("invalid value for %s '%s': values must be a single line", kind, key)
+		}
+		if _, seen := updates[key]; !seen {
+			order = append(order, key)
+		}
+		updates[key] = value
+	}
+	return updates, order, nil
+}
+
+// MaskedSecretValue is what MaskSecretValue shows instead of a non-empty secret.
+const MaskedSecretValue = "********"
+
+// MaskSecretValue hides a secret value for display; empty values stay visibly empty.
+func MaskSecretValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return MaskedSecretValue
+}
+
+func formatSecrets(entries []string) []byte {
+	if len(entries) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(entries, "\n") + "\n")
+}
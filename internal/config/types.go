@@ -4,23 +4,238 @@ import "time"
 
 // GlobalConfig represents the structure of the global reflow/config.yaml
 type GlobalConfig struct {
-	DefaultDomain string `mapstructure:"defaultDomain" yaml:"defaultDomain"`
-	Debug         bool   `mapstructure:"debug"         yaml:"debug"`
+	DefaultDomain string              `mapstructure:"defaultDomain" yaml:"defaultDomain"`
+	Debug         bool                `mapstructure:"debug"         yaml:"debug"`
+	LogFormat     string              `mapstructure:"logFormat"     yaml:"logFormat,omitempty"` // "text" (default) or "json"; the --log-format flag takes precedence
+	Logging       LoggingConfig       `mapstructure:"logging"       yaml:"logging,omitempty"`
+	DeploymentLog DeploymentLogConfig `mapstructure:"deploymentLog" yaml:"deploymentLog,omitempty"`
+
+	// Optional: private key used for git over SSH. Falls back to the SSH agent when empty.
+	// The passphrase can also be supplied via the REFLOW_SSH_PASSPHRASE environment variable.
+	SSHKeyPath       string `mapstructure:"sshKeyPath"       yaml:"sshKeyPath,omitempty"`
+	SSHKeyPassphrase string `mapstructure:"sshKeyPassphrase" yaml:"sshKeyPassphrase,omitempty"`
+
+	DeploySchedules []ScheduleEntry `mapstructure:"deploySchedules" yaml:"deploySchedules,omitempty"`
+
+	Notifications []NotificationConfig `mapstructure:"notifications" yaml:"notifications,omitempty"`
+
+	ACME ACMEConfig `mapstructure:"acme" yaml:"acme,omitempty"`
+
+	// Optional: remove old project images after each successful deploy/approve, keeping the
+	// KeepImages newest ones (default 3; projects can override it). Images used by a container
+	// or of an active commit are never removed.
+	AutoPruneImages bool `mapstructure:"autoPruneImages" yaml:"autoPruneImages,omitempty"`
+	KeepImages      int  `mapstructure:"keepImages"      yaml:"keepImages,omitempty"`
+
+	// Optional: credentials used when pulling images (e.g. for container plugins) from private registries.
+	Registries []RegistryCredential `mapstructure:"registries" yaml:"registries,omitempty"`
+
+	// Optional: registry that 'deploy --push' (or every deploy, with push set) pushes project
+	// images to, and that 'approve' pulls an image from when it is not present locally, e.g. on
+	// another host.
+	Registry ImageRegistryConfig `mapstructure:"registry" yaml:"registry,omitempty"`
+
+	// Optional (Linux only): run CLI plugin executables in a user and mount namespace where the
+	// filesystem is read-only apart from a per-run temp dir.
+	PluginSandbox bool `mapstructure:"pluginSandbox" yaml:"pluginSandbox,omitempty"`
+
+	// Optional: build images with BuildKit (via the docker CLI), reusing the previous image of the
+	// environment as cache. Defaults to true when the Docker Engine is version 23 or newer.
+	UseBuildKit *bool `mapstructure:"useBuildKit" yaml:"useBuildKit,omitempty"`
+
+	API APIConfig `mapstructure:"api" yaml:"api,omitempty"`
+}
+
+// APIConfig holds settings for the API server started with 'reflow server start'.
+type APIConfig struct {
+	// Origins allowed to call the API from a browser, e.g. "http://localhost:3000", or "*" for
+	// any origin (without credentials). Added to those given with --allow-origin.
+	AllowedOrigins []string `mapstructure:"allowedOrigins" yaml:"allowedOrigins,omitempty"`
+}
+
+// RegistryCredential holds the credentials for one container registry. Set Username and
+// Password (many registries accept an access token as the password), or Token for a bearer token.
+type RegistryCredential struct {
+	Registry string `mapstructure:"registry" yaml:"registry"` // Registry host, e.g. ghcr.io; "docker.io" for Docker Hub
+	Username string `mapstructure:"username" yaml:"username,omitempty"`
+	Password string `mapstructure:"password" yaml:"password,omitempty"`
+	Token    string `mapstructure:"token"    yaml:"token,omitempty"` // Registry bearer token, used instead of username/password
+}
+
+// ImageRegistryConfig is where project images are pushed as <url>/<project>:<commit>. The token
+// is sent as the password with Username, or as a bearer token without one.
+type ImageRegistryConfig struct {
+	URL            string `mapstructure:"url"            yaml:"url,omitempty"` // Registry host and optional namespace, e.g. ghcr.io/my-org
+	Username       string `mapstructure:"username"       yaml:"username,omitempty"`
+	Token          string `mapstructure:"token"          yaml:"token,omitempty"`
+	PasswordEnvVar string `mapstructure:"passwordEnvVar" yaml:"passwordEnvVar,omitempty"` // Environment variable holding the token, to keep it out of the config file
+	Push           bool   `mapstructure:"push"           yaml:"push,omitempty"`           // Push every image deploy builds, as if --push were given
+}
+
+// ACMEConfig holds the account settings used to obtain certificates for projects with autoTLS.
+type ACMEConfig struct {
+	Email        string `mapstructure:"email"        yaml:"email,omitempty"`        // Contact address registered with the CA
+	DirectoryURL string `mapstructure:"directoryUrl" yaml:"directoryUrl,omitempty"` // Defaults to Let's Encrypt production
+}
+
+// NotificationConfig sends deployment notifications to one webhook.
+type NotificationConfig struct {
+	Type          string   `mapstructure:"type"          yaml:"type,omitempty"`          // "slack", "discord" or "generic" (raw event JSON)
+	WebhookURL    string   `mapstructure:"webhookUrl"    yaml:"webhookUrl,omitempty"`    // The entry is skipped when empty
+	Events        []string `mapstructure:"events"        yaml:"events,omitempty"`        // e.g. "deploy.success", "approve.failure"; empty = all
+	ProjectFilter []string `mapstructure:"projectFilter" yaml:"projectFilter,omitempty"` // Project names to notify for; empty = all
+}
+
+// ScheduleEntry defines a cron-triggered deployment ('test') or promotion ('prod') for a project.
+type ScheduleEntry struct {
+	ProjectName string `mapstructure:"projectName" yaml:"projectName"         json:"projectName"`
+	Env         string `mapstructure:"env"         yaml:"env"                 json:"env"`                 // "test" (deploy) or "prod" (approve)
+	CronExpr    string `mapstructure:"cronExpr"    yaml:"cronExpr"            json:"cronExpr"`            // Standard 5-field cron expression
+	CommitIsh   string `mapstructure:"commitIsh"   yaml:"commitIsh,omitempty" json:"commitIsh,omitempty"` // Only used for 'test' deploys
+}
+
+// LoggingConfig controls the level and file of reflow's own log output; its format is set with
+// logFormat. The --debug flag takes precedence over Level.
+type LoggingConfig struct {
+	Level       string `mapstructure:"level"       yaml:"level,omitempty"`       // "debug", "info" (default), "warn" or "error"
+	File        string `mapstructure:"file"        yaml:"file,omitempty"`        // Also log to this file; relative paths are inside the base directory
+	MaxSizeMB   int    `mapstructure:"maxSizeMB"   yaml:"maxSizeMB,omitempty"`   // Rotate the file once it exceeds this size (default 10)
+	MaxArchives int    `mapstructure:"maxArchives" yaml:"maxArchives,omitempty"` // Rotated files to keep (default 3)
+}
+
+// DeploymentLogConfig controls rotation of the per-project deployments.log file.
+type DeploymentLogConfig struct {
+	MaxSizeMB   int `mapstructure:"maxSizeMB"   yaml:"maxSizeMB,omitempty"`   // Rotate once the log exceeds this size (default 5)
+	MaxEvents   int `mapstructure:"maxEvents"   yaml:"maxEvents,omitempty"`   // Rotate once the log holds this many events (0 = no limit)
+	MaxArchives int `mapstructure:"maxArchives" yaml:"maxArchives,omitempty"` // Number of rotated files to keep (default 5)
 }
 
 // ProjectEnvConfig represents environment-specific settings within a project
 type ProjectEnvConfig struct {
-	Domain  string `mapstructure:"domain"  yaml:"domain,omitempty"`
-	EnvFile string `mapstructure:"envFile" yaml:"envFile,omitempty"`
+	Domain         string          `mapstructure:"domain"         yaml:"domain,omitempty"`
+	EnvFile        string          `mapstructure:"envFile"        yaml:"envFile,omitempty"`        // Relative to the repo
+	Interpolate    bool            `mapstructure:"interpolate"    yaml:"interpolate,omitempty"`    // Expand ${NAME} in env file values from earlier lines, then secrets, then PROJECT/ENV/DOMAIN/SLOT; "$$" is a literal '$'
+	RequireEnvFile bool            `mapstructure:"requireEnvFile" yaml:"requireEnvFile,omitempty"` // Fail deployments when the env file is missing instead of warning
+	RateLimit      *NginxRateLimit `mapstructure:"rateLimit"      yaml:"rateLimit,omitempty"`      // Per-client-IP request limit enforced by Nginx
+	Replicas       int             `mapstructure:"replicas"       yaml:"replicas,omitempty"`       // Overrides the project's replicas when set
+	Resources      ResourcesConfig `mapstructure:"resources"      yaml:"resources,omitempty"`      // Fields set here override the project's resources
+	RestartPolicy  string          `mapstructure:"restartPolicy"  yaml:"restartPolicy,omitempty"`  // Overrides the project's restartPolicy when set
+	ExtraLocations []NginxLocation `mapstructure:"extraLocations" yaml:"extraLocations,omitempty"` // Additional paths Nginx proxies to other upstreams
+	RequiredEnv    []string        `mapstructure:"requiredEnv"    yaml:"requiredEnv,omitempty"`    // Variables that must be set and non-empty: "KEY", or "KEY=regex" to also check the value
+}
+
+// NetworkPolicyConfig limits which containers share a Docker network with a project or plugin.
+// AllowedContainers are container names, e.g. "reflow-plugin-vault" or a database container
+// started outside reflow; they are connected to the network when it is set up.
+type NetworkPolicyConfig struct {
+	AllowedContainers []string `mapstructure:"allowedContainers" yaml:"allowedContainers,omitempty"`
+}
+
+// NginxLocation proxies an additional path of an environment's domain to another upstream,
+// e.g. /api/ to a backend running in its own container. ProxyPass is either an http(s) URL, a
+// "container:port" on the reflow network, which gets its own upstream block, or the name of an
+// upstream defined elsewhere in the Nginx config.
+type NginxLocation struct {
+	Path            string   `mapstructure:"path"            yaml:"path"`
+	ProxyPass       string   `mapstructure:"proxyPass"       yaml:"proxyPass"`
+	ExtraDirectives []string `mapstructure:"extraDirectives" yaml:"extraDirectives,omitempty"` // Added to the location block, e.g. "client_max_body_size 50m"
+}
+
+// NginxRateLimit limits requests per client IP using Nginx's limit_req. Requests beyond the
+// rate and burst are rejected with 429.
+type NginxRateLimit struct {
+	RequestsPerSecond int    `mapstructure:"requestsPerSecond" yaml:"requestsPerSecond"`
+	BurstSize         int    `mapstructure:"burstSize"         yaml:"burstSize,omitempty"` // Extra requests served immediately above the rate
+	ZoneName          string `mapstructure:"zoneName"          yaml:"zoneName,omitempty"`  // Defaults to reflow_<project>_<env>; must be unique across projects
+}
+
+// ResourcesConfig defines optional container resource limits, e.g. memory: 512m, cpus: 0.5.
+type ResourcesConfig struct {
+	Memory            string `mapstructure:"memory"            yaml:"memory,omitempty"`            // Hard memory limit (b, k, m, g suffixes)
+	MemoryReservation string `mapstructure:"memoryReservation" yaml:"memoryReservation,omitempty"` // Soft memory limit
+	CPUs              string `mapstructure:"cpus"              yaml:"cpus,omitempty"`              // Fractional number of CPUs
+}
+
+// VolumeConfig defines persistent storage mounted into a container. Source is either a named
+// volume (e.g. "pgdata") or a host path relative to the owner's data directory (e.g. "./uploads").
+type VolumeConfig struct {
+	Source   string `mapstructure:"source"   yaml:"source"`
+	Target   string `mapstructure:"target"   yaml:"target"`
+	ReadOnly bool   `mapstructure:"readOnly" yaml:"readOnly,omitempty"` // Mount read-only in the container
+}
+
+// CloneConfig controls how much history is fetched for a git project.
+type CloneConfig struct {
+	Depth int `mapstructure:"depth" yaml:"depth,omitempty"` // Commits of history to fetch; 0 means full history
+}
+
+// HooksConfig defines scripts run around the Nginx switch of a deployment. A path is relative
+// to the project's repository, must stay inside it and runs on the host; prefix it with
+// "container:" to run a path inside each new container instead.
+type HooksConfig struct {
+	PreSwitch  string `mapstructure:"preSwitch"  yaml:"preSwitch,omitempty"`  // Runs after the health check; a non-zero exit aborts the deployment
+	PostSwitch string `mapstructure:"postSwitch" yaml:"postSwitch,omitempty"` // Runs after the Nginx reload, e.g. cache warmups; failures only warn
 }
 
 // ProjectConfig represents the structure of reflow/apps/<project>/config.yaml
 type ProjectConfig struct {
 	ProjectName  string                      `mapstructure:"projectName" yaml:"projectName"`
 	GithubRepo   string                      `mapstructure:"githubRepo"  yaml:"githubRepo"`
+	SourceType   string                      `mapstructure:"sourceType"  yaml:"sourceType,omitempty"` // "git" (default) or "local"
+	LocalPath    string                      `mapstructure:"localPath"   yaml:"localPath,omitempty"`  // Source directory of a "local" project, re-copied on each deploy
+	Clone        CloneConfig                 `mapstructure:"clone"       yaml:"clone,omitempty"`
 	AppPort      int                         `mapstructure:"appPort"     yaml:"appPort"`
 	NodeVersion  string                      `mapstructure:"nodeVersion" yaml:"nodeVersion"`
 	Environments map[string]ProjectEnvConfig `mapstructure:"environments" yaml:"environments"`
+	Replicas     int                         `mapstructure:"replicas"    yaml:"replicas,omitempty"` // Containers per deployment slot, load balanced by Nginx
+	Resources    ResourcesConfig             `mapstructure:"resources"   yaml:"resources,omitempty"`
+	Volumes      []VolumeConfig              `mapstructure:"volumes"     yaml:"volumes,omitempty"`       // Persistent storage shared by all slots and replicas
+	AutoTLS      bool                        `mapstructure:"autoTLS"     yaml:"autoTLS,omitempty"`       // Obtain a Let's Encrypt certificate for the prod domain on approve
+	StopTimeout  int                         `mapstructure:"stopTimeout" yaml:"stopTimeout,omitempty"`   // Seconds to wait after SIGTERM before SIGKILL when stopping old containers
+	StartTimeout int                         `mapstructure:"startTimeout" yaml:"startTimeout,omitempty"` // Seconds to wait for a new container to report running (default 30)
+
+	// Optional: private SSH key used to clone and fetch this project's repository instead of the
+	// global sshKeyPath, e.g. a deploy key from 'reflow project generate-key'. Relative paths are
+	// inside the project directory. The passphrase can also be set in REFLOW_SSH_PASSPHRASE_<PROJECT>.
+	SSHKeyPath       string `mapstructure:"sshKeyPath"       yaml:"sshKeyPath,omitempty"`
+	SSHKeyPassphrase string `mapstructure:"sshKeyPassphrase" yaml:"sshKeyPassphrase,omitempty"`
+
+	// Optional: Docker restart policy for the project's containers: "no", "always",
+	// "unless-stopped" (default) or "on-failure", with an optional max retry count
+	// ("on-failure:5").
+	RestartPolicy string `mapstructure:"restartPolicy" yaml:"restartPolicy,omitempty"`
+
+	// Optional: how many of the project's most recently built images pruning keeps for
+	// rollbacks, besides those of the active commits. Defaults to the global keepImages (3).
+	KeepImages int `mapstructure:"keepImages" yaml:"keepImages,omitempty"`
+
+	// Optional: retry a failed image build, e.g. after a flaky 'npm ci' network error. Each retry
+	// waits twice as long as the previous one, starting at BuildRetryBackoff seconds (default 5).
+	BuildRetries      int `mapstructure:"buildRetries"      yaml:"buildRetries,omitempty"`
+	BuildRetryBackoff int `mapstructure:"buildRetryBackoff" yaml:"buildRetryBackoff,omitempty"`
+
+	// Optional: shell commands run inside each new container, in order, once it is running and
+	// before its health check, e.g. database migrations. A failing hook fails the deployment.
+	PostStartHooks []string `mapstructure:"postStartHooks" yaml:"postStartHooks,omitempty"`
+
+	// Optional: smoke test and warmup scripts run before and after traffic is switched.
+	Hooks HooksConfig `mapstructure:"hooks" yaml:"hooks,omitempty"`
+
+	// Optional: HTML page served with a 503 while an environment is in maintenance mode, relative
+	// to the project directory. Defaults to a built-in page.
+	MaintenancePage string `mapstructure:"maintenancePage" yaml:"maintenancePage,omitempty"`
+
+	// Optional: deployment notifications for this project. When set, they replace the global
+	// notifications list for the project's events.
+	Notifications []NotificationConfig `mapstructure:"notifications" yaml:"notifications,omitempty"`
+
+	// Optional: also attach the project's containers to a network of their own, which the
+	// containers in networkPolicy.allowedContainers join too.
+	NetworkPolicy *NetworkPolicyConfig `mapstructure:"networkPolicy" yaml:"networkPolicy,omitempty"`
+
+	// Optional: GitHub push webhook settings. Pushes to DeployBranch trigger a 'test' deployment.
+	WebhookSecret string `mapstructure:"webhookSecret" yaml:"webhookSecret,omitempty"`
+	DeployBranch  string `mapstructure:"deployBranch"  yaml:"deployBranch,omitempty"`
 
 	// These are populated from flags if provided during 'create', not saved by default
 	// but used for domain calculation if Environments.Test/Prod.Domain are empty.
@@ -32,20 +247,29 @@ type ProjectConfig struct {
 type CreateProjectArgs struct {
 	ProjectName string `json:"projectName" yaml:"projectName"`
 	RepoURL     string `json:"repoUrl" yaml:"repoUrl"`
+	SourceType  string `json:"sourceType,omitempty" yaml:"sourceType,omitempty"` // "git" (default) or "local"
+	LocalPath   string `json:"localPath,omitempty" yaml:"localPath,omitempty"`   // Directory to copy when SourceType is "local"
 	AppPort     int    `json:"appPort,omitempty" yaml:"appPort,omitempty"`
 	NodeVersion string `json:"nodeVersion,omitempty" yaml:"nodeVersion,omitempty"`
 	TestDomain  string `json:"testDomain,omitempty" yaml:"testDomain,omitempty"`
 	ProdDomain  string `json:"prodDomain,omitempty" yaml:"prodDomain,omitempty"`
 	TestEnvFile string `json:"testEnvFile,omitempty" yaml:"testEnvFile,omitempty"`
 	ProdEnvFile string `json:"prodEnvFile,omitempty" yaml:"prodEnvFile,omitempty"`
+	Shallow     bool   `json:"shallow,omitempty" yaml:"shallow,omitempty"`
+	GitToken    string `json:"gitToken,omitempty" yaml:"-"` // Stored in the project's git credentials file, never in config.yaml
+	GitUsername string `json:"gitUsername,omitempty" yaml:"-"`
+	SSHKeyPath  string `json:"sshKeyPath,omitempty" yaml:"sshKeyPath,omitempty"` // Project-specific SSH key for cloning, instead of the global one
 }
 
 // EnvironmentState State tracks the deployment status per environment for a project
 type EnvironmentState struct {
-	ActiveSlot    string `json:"activeSlot"`    // "blue" or "green"
-	ActiveCommit  string `json:"activeCommit"`  // Git commit hash currently active
-	InactiveSlot  string `json:"inactiveSlot"`  // The other slot
-	PendingCommit string `json:"pendingCommit"` // Commit deployed but not yet made active (used during deployment)
+	ActiveSlot    string `json:"activeSlot"`             // "blue" or "green"
+	ActiveCommit  string `json:"activeCommit"`           // Git commit hash currently active
+	InactiveSlot  string `json:"inactiveSlot"`           // The other slot
+	PendingCommit string `json:"pendingCommit"`          // Commit deployed but not yet made active (used during deployment)
+	LastImageTag  string `json:"lastImageTag,omitempty"` // Image of the active deployment, used as build cache for the next one
+	EnvFileHash   string `json:"envFileHash,omitempty"`  // Hash of the env file content the active deployment loaded
+	Replicas      int    `json:"replicas,omitempty"`     // Containers the active deployment runs
 }
 
 // ProjectState represents the structure of reflow/apps/<project>/state.json
@@ -64,14 +288,15 @@ type MergedProjectConfig struct {
 // DeploymentEvent represents a logged deployment or approval action.
 type DeploymentEvent struct {
 	Timestamp    time.Time `json:"timestamp"` // Time the event was logged (usually end of action)
-	EventType    string    `json:"eventType"` // "deploy" or "approve"
+	EventType    string    `json:"eventType"` // "deploy", "approve", "rename", "import", "scale" or "stop-timeout"
 	ProjectName  string    `json:"projectName"`
 	Environment  string    `json:"environment"`            // "test" or "prod"
 	CommitSHA    string    `json:"commitSHA"`              // Full commit hash involved
+	Slot         string    `json:"slot,omitempty"`         // Slot deployed to ("blue" or "green"), for deploy and approve events
 	Outcome      string    `json:"outcome"`                // "started", "success", "failure"
 	ErrorMessage string    `json:"errorMessage,omitempty"` // Details on failure
 	DurationMs   int64     `json:"durationMs,omitempty"`   // How long the action took (for success/failure events)
-	TriggeredBy  string    `json:"triggeredBy,omitempty"`  // How it was triggered (e.g., "cli", "api", "user:xyz" - future enhancement)
+	TriggeredBy  string    `json:"triggeredBy,omitempty"`  // How it was triggered: "cli/api", "scheduler", "cli" (import) or "cleanup"
 }
 
 // PluginType defines the kind of plugin.
@@ -104,6 +329,13 @@ type PluginNginxConfig struct {
 	ContainerPort int `yaml:"containerPort,omitempty"`
 }
 
+// PluginDependency names another plugin that must be installed for a plugin to work.
+type PluginDependency struct {
+	Name       string `yaml:"name"`                 // Installed plugin name, or a glob pattern such as "metrics-*"
+	MinVersion string `yaml:"minVersion,omitempty"` // Lowest acceptable version, e.g. "1.2.0"
+	Repo       string `yaml:"repo,omitempty"`       // Git repository to install it from with 'plugin install --with-deps'
+}
+
 // PluginMetadata defines the structure of a plugin's metadata file (e.g., reflow-plugin.yaml).
 type PluginMetadata struct {
 	Name        string              `yaml:"name"`                  // User-friendly name of the plugin
@@ -111,6 +343,8 @@ type PluginMetadata struct {
 	Description string              `yaml:"description,omitempty"` // Short description
 	Type        PluginType          `yaml:"type"`                  // "cli" or "container"
 	Setup       []PluginSetupPrompt `yaml:"setup,omitempty"`       // List of prompts for initial configuration
+	// Optional: Other plugins that must be installed before this one.
+	Dependencies []PluginDependency `yaml:"dependencies,omitempty"`
 	// Optional: Defines Docker build/run settings for container plugins.
 	Container *struct {
 		// Optional: Path relative to plugin repo root to a Dockerfile. If omitted, assumes pre-built image.
@@ -121,6 +355,17 @@ type PluginMetadata struct {
 		BuildArgs map[string]string `yaml:"buildArgs,omitempty"`
 		// Optional: Environment variables to set in the container. Values can reference plugin config keys.
 		Env map[string]string `yaml:"env,omitempty"`
+		// Optional: Resource limits for the plugin container (e.g., memory: 256m, cpus: 0.25).
+		Resources ResourcesConfig `yaml:"resources,omitempty"`
+		// Optional: Persistent volumes (named volumes or paths under the plugin's data directory).
+		Volumes []VolumeConfig `yaml:"volumes,omitempty"`
+		// Optional: Seconds to wait for the container to report running (default 30).
+		StartTimeout int `yaml:"startTimeout,omitempty"`
+		// Optional: Shell commands run inside the container, in order, once it is running.
+		PostStartHooks []string `yaml:"postStartHooks,omitempty"`
+		// Optional: Run on a network of the plugin's own instead of the main reflow network, so
+		// only Nginx and the containers in allowedContainers can reach it.
+		NetworkPolicy *NetworkPolicyConfig `yaml:"networkPolicy,omitempty"`
 	} `yaml:"container,omitempty"`
 	// Optional: Nginx configuration for container plugins.
 	Nginx *PluginNginxConfig `yaml:"nginx,omitempty"`
@@ -149,6 +394,7 @@ type PluginInstanceConfig struct {
 	ContainerID   string            `json:"containerId,omitempty"`   // Docker container ID if applicable
 	NginxConfigOk bool              `json:"nginxConfigOk,omitempty"` // Status of Nginx config generation/reload
 	InstallTime   time.Time         `json:"installTime"`             // Timestamp of installation
+	RequiredBy    []string          `json:"requiredBy,omitempty"`    // Installed plugins that declare this one as a dependency
 	Metadata      *PluginMetadata   `json:"-"`                       // Loaded metadata (transient, not saved in state)
 }
 
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ProjectVolumePrefix returns the Docker volume name prefix for a project's named volumes.
+func ProjectVolumePrefix(projectName string) string {
+	return fmt.Sprintf("reflow-app-%s-", strings.ToLower(projectName))
+}
+
+// PluginVolumePrefix returns the Docker volume name prefix for a plugin's named volumes.
+func PluginVolumePrefix(pluginName string) string {
+	return fmt.Sprintf("reflow-plugin-%s-", pluginName)
+}
+
+// IsHostPathVolume reports whether a volume source refers to a host path rather than a named volume.
+func IsHostPathVolume(source string) bool {
+	return strings.Contains(source, "/") || source == "." || source == ".."
+}
+
+// ResolveVolumes validates volume entries and resolves their sources. Relative host paths are
+// resolved under dataDir and may not escape it; absolute host paths are kept as-is (RunContainer
+// checks them against the Reflow base path); named volumes get volumePrefix prepended.
+// fieldPrefix is used to name the offending field in errors (e.g., "volumes").
+func ResolveVolumes(fieldPrefix, dataDir, volumePrefix string, vols []VolumeConfig) ([]VolumeConfig, error) {
+	resolved := make([]VolumeConfig, 0, len(vols))
+	targets := make(map[string]bool, len(vols))
+
+	for i, v := range vols {
+		field := fmt.Sprintf("%s[%d]", fieldPrefix, i)
+		source := strings.TrimSpace(v.Source)
+		target := filepath.Clean(strings.TrimSpace(v.Target))
+
+		if source == "" {
+			return nil, fmt.Errorf("invalid %s: source is required", field)
+		}
+		if v.Target == "" || !filepath.IsAbs(target) {
+			return nil, fmt.Errorf("invalid %s.target '%s': must be an absolute path in the container", field, v.Target)
+		}
+		if targets[target] {
+			return nil, fmt.Errorf("invalid %s.target '%s': duplicate mount target", field, v.Target)
+		}
+		targets[target] = true
+
+		switch {
+		case filepath.IsAbs(source):
+			source = filepath.Clean(source)
+		case IsHostPathVolume(source):
+			hostPath := filepath.Join(dataDir, source)
+			rel, err := filepath.Rel(dataDir, hostPath)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return nil, fmt.Errorf("invalid %s.source '%s': relative host paths must stay inside %s", field, v.Source, dataDir)
+			}
+			source = hostPath
+		default:
+			if !volumeNamePattern.MatchString(source) {
+				return nil, fmt.Errorf("invalid %s.source '%s': not a valid volume name", field, v.Source)
+			}
+			source = volumePrefix + source
+		}
+
+		resolved = append(resolved, VolumeConfig{Source: source, Target: target, ReadOnly: v.ReadOnly})
+	}
+	return resolved, nil
+}
@@ -0,0 +1,180 @@
+package deployment
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"time"
+)
+
+// ErrInvalidCursor is returned by ListHistory when a cursor is malformed or no longer points at
+// an event, e.g. after the history was pruned.
+var ErrInvalidCursor = errors.New("invalid or expired history cursor")
+
+const reverseReadChunkSize = 64 * 1024
+
+// historyCursor locates the last event of a page. The log format is unchanged JSON lines, so the
+// event's timestamp is stored too: it identifies the event again after rotation has shifted files.
+type historyCursor struct {
+	File      int       `json:"f"`
+	Offset    int64     `json:"o"`
+	Timestamp time.Time `json:"t"`
+}
+
+// encodeCursor returns the opaque cursor for continuing after the given event.
+func encodeCursor(stored storedEvent) string {
+	data, _ := json.Marshal(historyCursor{File: stored.File, Offset: stored.Offset, Timestamp: stored.Event.Timestamp})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses an opaque cursor string.
+func decodeCursor(cursor string) (*historyCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cur historyCursor
+	if err := json.Unmarshal(data, &cur); err != nil || cur.File < 0 || cur.Offset < 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cur, nil
+}
+
+// resolveCursor finds the file currently holding the cursor's event. Each rotation moves the
+// active log to archive 1 and shifts the others up, so the event can only have moved to a
+// higher index; it is recognised by its timestamp at the same byte offset.
+func resolveCursor(files []string, cur *historyCursor) (int, error) {
+	for i := cur.File; i < len(files); i++ {
+		file, err := os.Open(files[i])
+		if err != nil {
+			continue
+		}
+		matched := false
+		if _, err := file.Seek(cur.Offset, io.SeekStart); err == nil {
+			line, readErr := bufio.NewReader(file).ReadBytes('\n')
+			if readErr == nil || readErr == io.EOF {
+				var event config.DeploymentEvent
+				matched = json.Unmarshal(bytes.TrimSpace(line), &event) == nil && event.Timestamp.Equal(cur.Timestamp)
+			}
+		}
+		_ = file.Close()
+		if matched {
+			return i, nil
+		}
+	}
+	return 0, ErrInvalidCursor
+}
+
+// listHistoryFromCursor returns up to limit matching events older than the cursor, reading the
+// log files backwards so only the part of the history the page needs is read.
+func listHistoryFromCursor(logFilePath, cursor string, limit int, matches func(config.DeploymentEvent) bool) (*HistoryPage, error) {
+	cur, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	files := listLogFiles(logFilePath)
+	startFile, err := resolveCursor(files, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &HistoryPage{Limit: limit, Events: []config.DeploymentEvent{}}
+	var last storedEvent
+	hasMore := false
+
+	for i := startFile; i < len(files) && !hasMore; i++ {
+		file, err := os.Open(files[i])
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to open deployment log file '%s': %w", files[i], err)
+		}
+		end := int64(-1)
+		if i == startFile {
+			end = cur.Offset
+		}
+
+		readErr := readLinesBackward(file, end, func(offset int64, line []byte) bool {
+			var event config.DeploymentEvent
+			if err := json.Unmarshal(line, &event); err != nil {
+				util.Log.Warnf("Failed to parse deployment event at byte %d in '%s': %v. Skipping line.", offset, files[i], err)
+				return true
+			}
+			if !matches(event) {
+				return true
+			}
+			if len(page.Events) == limit {
+				// One more match exists, so the page gets a cursor.
+				hasMore = true
+				return false
+			}
+			page.Events = append(page.Events, event)
+			last = storedEvent{Event: event, File: i, Offset: offset}
+			return true
+		})
+		_ = file.Close()
+		if readErr != nil {
+			return nil, fmt.Errorf("error reading deployment log file '%s': %w", files[i], readErr)
+		}
+	}
+
+	if hasMore {
+		page.NextCursor = encodeCursor(last)
+	}
+	return page, nil
+}
+
+// readLinesBackward calls fn for each non-empty line that starts before end (-1 for the end of
+// the file), last line first, with the line's byte offset. It stops when fn returns false.
+func readLinesBackward(file *os.File, end int64, fn func(offset int64, line []byte) bool) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if end < 0 || end > info.Size() {
+		end = info.Size()
+	}
+
+	var carry []byte // Start of the line that continues into the previously read chunk
+	pos := end
+	for pos > 0 {
+		readSize := int64(reverseReadChunkSize)
+		if pos < readSize {
+			readSize = pos
+		}
+		pos -= readSize
+		chunk := make([]byte, readSize, readSize+int64(len(carry)))
+		if _, err := file.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return err
+		}
+		data := append(chunk, carry...)
+
+		for {
+			trimmed := bytes.TrimSuffix(data, []byte("\n"))
+			i := bytes.LastIndexByte(trimmed, '\n')
+			if i < 0 {
+				break
+			}
+			line := trimmed[i+1:]
+			if len(bytes.TrimSpace(line)) > 0 && !fn(pos+int64(i+1), line) {
+				return nil
+			}
+			data = data[:i+1]
+		}
+		carry = data
+	}
+
+	line := bytes.TrimSuffix(carry, []byte("\n"))
+	if len(bytes.TrimSpace(line)) > 0 {
+		fn(0, line)
+	}
+	return nil
+}
@@ -1,7 +1,10 @@
 package deployment
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflow/internal/config"
@@ -17,6 +20,11 @@ func getLogFilePath(basePath, projectName string) string {
 	return filepath.Join(projectBasePath, config.DeploymentsLogFileName)
 }
 
+// getArchiveFilePath constructs the path to the Nth rotated deployment log file (e.g., deployments.log.1).
+func getArchiveFilePath(logFilePath string, index int) string {
+	return fmt.Sprintf("%s.%d", logFilePath, index)
+}
+
 // safeShortSha returns a shortened SHA string or "N/A" if the SHA is empty.
 func safeShortSha(sha string) string {
 	if len(sha) >= 7 {
@@ -26,6 +34,100 @@ func safeShortSha(sha string) string {
 	}
 }
 
+// loadRotationSettings returns the effective rotation limits from the global config.
+func loadRotationSettings(basePath string) config.DeploymentLogConfig {
+	settings := config.DeploymentLogConfig{
+		MaxSizeMB:   config.DefaultDeploymentLogMaxSizeMB,
+		MaxArchives: config.DefaultDeploymentLogMaxArchives,
+	}
+	globalCfg, err := config.LoadGlobalConfig(basePath)
+	if err != nil {
+		util.Log.Debugf("Could not load global config for deployment log rotation, using defaults: %v", err)
+		return settings
+	}
+	if globalCfg.DeploymentLog.MaxSizeMB > 0 {
+		settings.MaxSizeMB = globalCfg.DeploymentLog.MaxSizeMB
+	}
+	if globalCfg.DeploymentLog.MaxArchives > 0 {
+		settings.MaxArchives = globalCfg.DeploymentLog.MaxArchives
+	}
+	settings.MaxEvents = globalCfg.DeploymentLog.MaxEvents
+	return settings
+}
+
+// countLines returns the number of non-empty lines in a file.
+func countLines(filePath string) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+// rotateIfNeeded rolls the deployment log over to deployments.log.1 when it exceeds the configured limits.
+// Must be called with logMutex held.
+func rotateIfNeeded(basePath, logFilePath string) {
+	info, err := os.Stat(logFilePath)
+	if err != nil {
+		return
+	}
+
+	settings := loadRotationSettings(basePath)
+	needsRotation := info.Size() >= int64(settings.MaxSizeMB)*1024*1024
+	if !needsRotation && settings.MaxEvents > 0 {
+		lines, countErr := countLines(logFilePath)
+		if countErr != nil {
+			util.Log.Warnf("Failed to count events in deployment log '%s': %v", logFilePath, countErr)
+		} else if lines >= settings.MaxEvents {
+			needsRotation = true
+		}
+	}
+	if !needsRotation {
+		return
+	}
+
+	util.Log.Debugf("Rotating deployment log '%s' (size: %d bytes)", logFilePath, info.Size())
+
+	oldest := getArchiveFilePath(logFilePath, settings.MaxArchives)
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		util.Log.Warnf("Failed to remove oldest deployment log archive '%s': %v", oldest, err)
+	}
+	for i := settings.MaxArchives - 1; i >= 1; i-- {
+		src := getArchiveFilePath(logFilePath, i)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := os.Rename(src, getArchiveFilePath(logFilePath, i+1)); err != nil {
+			util.Log.Warnf("Failed to shift deployment log archive '%s': %v", src, err)
+		}
+	}
+	if err := os.Rename(logFilePath, getArchiveFilePath(logFilePath, 1)); err != nil {
+		util.Log.Errorf("Failed to rotate deployment log '%s': %v", logFilePath, err)
+	}
+}
+
+// listLogFiles returns the active log file followed by any existing archives, newest first.
+func listLogFiles(logFilePath string) []string {
+	files := []string{logFilePath}
+	for i := 1; ; i++ {
+		archive := getArchiveFilePath(logFilePath, i)
+		if _, err := os.Stat(archive); err != nil {
+			break
+		}
+		files = append(files, archive)
+	}
+	return files
+}
+
 // LogEvent logs a deployment event to the project's deployment log file.
 func LogEvent(basePath, projectName string, event *config.DeploymentEvent) {
 	logMutex.Lock()
@@ -46,6 +148,8 @@ func LogEvent(basePath, projectName string, event *config.DeploymentEvent) {
 	}
 	logEntry := string(logEntryBytes) + "\n"
 
+	rotateIfNeeded(basePath, logFilePath)
+
 	file, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		util.Log.Errorf("Failed to open deployment log file '%s' for appending: %v", logFilePath, err)
@@ -63,6 +167,59 @@ func LogEvent(basePath, projectName string, event *config.DeploymentEvent) {
 	if _, err := file.WriteString(logEntry); err != nil {
 		util.Log.Errorf("Failed to write deployment event to log file '%s': %v", logFilePath, err)
 	} else {
-		util.Log.Debugf("Logged deployment event to %s: Type=%s Env=%s Commit=%s Outcome=%s", logFilePath, event.EventType, event.Environment, safeShortSha(event.CommitSHA[:7]), event.Outcome)
+		util.Log.Debugf("Logged deployment event to %s: Type=%s Env=%s Commit=%s Outcome=%s", logFilePath, event.EventType, event.Environment, safeShortSha(event.CommitSHA), event.Outcome)
+	}
+}
+
+// PruneHistory keeps only the newest 'keep' events for a project, rewriting the log and removing archives.
+// Returns the number of events removed.
+func PruneHistory(basePath, projectName string, keep int) (int, error) {
+	if keep < 0 {
+		return 0, fmt.Errorf("keep must be zero or greater, got %d", keep)
 	}
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	logFilePath := getLogFilePath(basePath, projectName)
+	allEvents, err := readAllEvents(logFilePath)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(allEvents) <= keep {
+		util.Log.Debugf("Deployment history for '%s' has %d event(s), nothing to prune (keep %d).", projectName, len(allEvents), keep)
+		return 0, nil
+	}
+
+	kept := allEvents[:keep]
+	removed := len(allEvents) - keep
+
+	var buf bytes.Buffer
+	for i := len(kept) - 1; i >= 0; i-- {
+		line, err := json.Marshal(kept[i].Event)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal deployment event while pruning: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmpPath := logFilePath + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write pruned deployment log '%s': %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, logFilePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to replace deployment log '%s': %w", logFilePath, err)
+	}
+
+	for _, archive := range listLogFiles(logFilePath)[1:] {
+		if err := os.Remove(archive); err != nil {
+			util.Log.Warnf("Failed to remove deployment log archive '%s': %v", archive, err)
+		}
+	}
+
+	util.Log.Infof("Pruned %d deployment event(s) for project '%s', kept %d.", removed, projectName, len(kept))
+	return removed, nil
 }
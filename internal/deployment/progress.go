@@ -0,0 +1,284 @@
+package deployment
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Sentinels written as the last line of a progress file once the deployment has finished.
+	progressSucceededMarker = "==> REFLOW DEPLOYMENT SUCCEEDED"
+	progressFailedMarker    = "==> REFLOW DEPLOYMENT FAILED"
+
+	progressPollInterval = 500 * time.Millisecond
+)
+
+// ErrNoProgress is returned by TailProgress when the project has no recorded deployment.
+var ErrNoProgress = errors.New("no deployment progress recorded for this project yet")
+
+// GetProgressFilePath returns the path of a project's deployment progress file.
+func GetProgressFilePath(basePath, projectName string) string {
+	return filepath.Join(config.GetProjectBasePath(basePath, projectName), config.DeployProgressFileName)
+}
+
+// Progress records the messages of a running deployment to the project's progress file, so it
+// can be followed from another process with TailProgress, and reports its steps to the attached
+// sinks. A nil *Progress only logs.
+type Progress struct {
+	mu     sync.Mutex
+	file   *os.File
+	fields logrus.Fields
+	sinks  []ProgressSink
+	steps  []Step
+}
+
+// StartProgress truncates the project's progress file and writes a header for a new deployment.
+// Steps are reported to a LogSink and to any sinks given. If the file cannot be created the
+// deployment goes on without it.
+func StartProgress(basePath, projectName, description string, sinks ...ProgressSink) *Progress {
+	p := &Progress{sinks: append([]ProgressSink{LogSink{}}, sinks...)}
+	file, err := os.OpenFile(GetProgressFilePath(basePath, projectName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		util.Log.Warnf("Could not create deployment progress file, progress will not be trackable: %v", err)
+		return p
+	}
+	p.file = file
+	p.write(fmt.Sprintf("Started %s", description))
+	return p
+}
+
+// Reporting reports whether a sink other than the log is attached, i.e. whether someone follows
+// the steps closely enough to be sent progress within a step, such as build percentages.
+func (p *Progress) Reporting() bool {
+	return p != nil && len(p.sinks) > 1
+}
+
+// StartStep finishes the current step, if any, and starts a new one.
+func (p *Progress) StartStep(name string) {
+	if p == nil {
+		return
+	}
+	p.write("==> " + name)
+	p.mu.Lock()
+	now := time.Now()
+	var changed []int
+	if n := len(p.steps); n > 0 && p.steps[n-1].State == StepRunning {
+		p.steps[n-1].State, p.steps[n-1].FinishedAt = StepSucceeded, &now
+		changed = append(changed, n-1)
+	}
+	p.steps = append(p.steps, Step{Name: name, State: StepRunning, StartedAt: now})
+	changed = append(changed, len(p.steps)-1)
+	p.mu.Unlock()
+	p.report(changed...)
+}
+
+// StepDetail reports progress within the current step, e.g. "3/12" for a health check.
+func (p *Progress) StepDetail(format string, args ...interface{}) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	n := len(p.steps)
+	if n == 0 || p.steps[n-1].State != StepRunning {
+		p.mu.Unlock()
+		return
+	}
+	p.steps[n-1].Detail = fmt.Sprintf(format, args...)
+	p.mu.Unlock()
+	p.report(n - 1)
+}
+
+// report sends the current state of the steps at indexes to the sinks.
+func (p *Progress) report(indexes ...int) {
+	p.mu.Lock()
+	steps := make([]Step, len(indexes))
+	for i, index := range indexes {
+		steps[i] = p.steps[index]
+	}
+	sinks := p.sinks
+	p.mu.Unlock()
+	for i, index := range indexes {
+		for _, sink := range sinks {
+			sink.ReportStep(index, steps[i])
+		}
+	}
+}
+
+// AddFields attaches fields (e.g. project, env, commit) to the log entries of all later
+// messages, so a single deployment can be filtered out of structured logs.
+func (p *Progress) AddFields(fields logrus.Fields) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fields == nil {
+		p.fields = logrus.Fields{}
+	}
+	for k, v := range fields {
+		p.fields[k] = v
+	}
+}
+
+// logger returns the logger for the next message, carrying the fields added so far.
+func (p *Progress) logger() logrus.FieldLogger {
+	if p == nil {
+		return util.Log
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.fields) == 0 {
+		return util.Log
+	}
+	return util.Log.WithFields(p.fields)
+}
+
+// Info logs a message and records it in the progress file.
+func (p *Progress) Info(args ...interface{}) {
+	p.logger().Info(args...)
+	p.write(fmt.Sprint(args...))
+}
+
+// Infof logs a formatted message and records it in the progress file.
+func (p *Progress) Infof(format string, args ...interface{}) {
+	p.logger().Infof(format, args...)
+	p.write(fmt.Sprintf(format, args...))
+}
+
+// Warnf logs a formatted warning and records it in the progress file.
+func (p *Progress) Warnf(format string, args ...interface{}) {
+	p.logger().Warnf(format, args...)
+	p.write("WARNING: " + fmt.Sprintf(format, args...))
+}
+
+// Finish marks the current step as succeeded or failed, writes the success or failure sentinel
+// and closes the progress file.
+func (p *Progress) Finish(err error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	n := len(p.steps)
+	if n > 0 && p.steps[n-1].State == StepRunning {
+		now := time.Now()
+		p.steps[n-1].State, p.steps[n-1].FinishedAt = StepSucceeded, &now
+		if err != nil {
+			p.steps[n-1].State = StepFailed
+		}
+	}
+	p.mu.Unlock()
+	if n > 0 {
+		p.report(n - 1)
+	}
+
+	if err != nil {
+		p.write(fmt.Sprintf("%s: %s", progressFailedMarker, util.Redact(err.Error())))
+	} else {
+		p.write(progressSucceededMarker)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.file == nil {
+		return
+	}
+	if closeErr := p.file.Close(); closeErr != nil {
+		util.Log.Debugf("Failed to close deployment progress file: %v", closeErr)
+	}
+	p.file = nil
+}
+
+func (p *Progress) write(message string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.file == nil {
+		return
+	}
+	line := fmt.Sprintf("%s %s\n", time.Now().UTC().Format(time.RFC3339), util.Redact(message))
+	if _, err := p.file.WriteString(line); err != nil {
+		util.Log.Debugf("Failed to write deployment progress: %v", err)
+	}
+}
+
+// TailProgress copies the project's progress file to w, following it as it grows, until the
+// deployment's success or failure sentinel is read or ctx is cancelled. flush, if non-nil, is
+// called after each batch of lines. For a deployment that already finished, the whole file is
+// copied at once. Returns whether the deployment succeeded.
+func TailProgress(ctx context.Context, basePath, projectName string, w io.Writer, flush func()) (bool, error) {
+	file, err := os.Open(GetProgressFilePath(basePath, projectName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, ErrNoProgress
+		}
+		return false, fmt.Errorf("failed to open deployment progress: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var partial string
+	var offset int64
+	for {
+		chunk, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return false, fmt.Errorf("failed to read deployment progress: %w", readErr)
+		}
+		offset += int64(len(chunk))
+		partial += chunk
+		if readErr == nil { // A complete line
+			line := partial
+			partial = ""
+			if _, err := io.WriteString(w, line); err != nil {
+				return false, err
+			}
+			message := strings.TrimSpace(line)
+			if strings.Contains(message, progressSucceededMarker) {
+				flushProgress(flush)
+				return true, nil
+			}
+			if strings.Contains(message, progressFailedMarker) {
+				flushProgress(flush)
+				return false, nil
+			}
+			continue
+		}
+
+		// At the end of the file for now. A file shorter than what was read means a new
+		// deployment truncated it, so start over from its beginning.
+		if info, statErr := file.Stat(); statErr == nil && info.Size() < offset {
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return false, fmt.Errorf("failed to rewind deployment progress: %w", err)
+			}
+			reader.Reset(file)
+			partial, offset = "", 0
+			continue
+		}
+
+		// Wait for the deployment to write more.
+		flushProgress(flush)
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(progressPollInterval):
+		}
+	}
+}
+
+func flushProgress(flush func()) {
+	if flush != nil {
+		flush()
+	}
+}
@@ -11,72 +11,126 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// ListHistory reads deployment events from the log file.
-func ListHistory(basePath, projectName, limitStr, offsetStr, envFilter, outcomeFilter string) ([]config.DeploymentEvent, error) {
-	logFilePath := getLogFilePath(basePath, projectName)
-	util.Log.Debugf("Reading deployment history from: %s", logFilePath)
+// storedEvent is a deployment event along with where it is stored, so a cursor can point at it.
+type storedEvent struct {
+	Event  config.DeploymentEvent
+	File   int   // Index into listLogFiles: 0 is the active log, N is archive N
+	Offset int64 // Byte offset of the event's line within the file
+}
 
-	file, err := os.Open(logFilePath)
+// readEventsFromFile parses all deployment events from a single log file.
+func readEventsFromFile(filePath string, fileIndex int) ([]storedEvent, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			util.Log.Debugf("Deployment log file '%s' not found, returning empty history.", logFilePath)
-			return []config.DeploymentEvent{}, nil
+			util.Log.Debugf("Deployment log file '%s' not found, skipping.", filePath)
+			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to open deployment log file '%s': %w", logFilePath, err)
+		return nil, fmt.Errorf("failed to open deployment log file '%s': %w", filePath, err)
 	}
 	defer func(file *os.File) {
 		err := file.Close()
 		if err != nil {
-			util.Log.Errorf("Failed to close file '%s': %v", logFilePath, err)
+			util.Log.Errorf("Failed to close file '%s': %v", filePath, err)
 		} else {
-			util.Log.Debugf("Closed deployment log file '%s'", logFilePath)
+			util.Log.Debugf("Closed deployment log file '%s'", filePath)
 		}
 	}(file)
 
-	var allEvents []config.DeploymentEvent
+	var events []storedEvent
 	scanner := bufio.NewScanner(file)
 	lineNumber := 0
+	var offset int64
 	for scanner.Scan() {
 		lineNumber++
 		line := scanner.Bytes()
+		lineOffset := offset
+		offset += int64(len(line)) + 1
 		if len(bytes.TrimSpace(line)) == 0 {
 			continue
 		}
 		var event config.DeploymentEvent
 		if err := json.Unmarshal(line, &event); err != nil {
-			util.Log.Warnf("Failed to parse deployment event log line %d in '%s': %v. Skipping line.", lineNumber, logFilePath, err)
+			util.Log.Warnf("Failed to parse deployment event log line %d in '%s': %v. Skipping line.", lineNumber, filePath, err)
 			continue
 		}
-		allEvents = append(allEvents, event)
+		events = append(events, storedEvent{Event: event, File: fileIndex, Offset: lineOffset})
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading deployment log file '%s': %w", logFilePath, err)
+		return nil, fmt.Errorf("error reading deployment log file '%s': %w", filePath, err)
+	}
+	return events, nil
+}
+
+// readAllEvents reads events from the active log and all rotated archives, newest first.
+func readAllEvents(logFilePath string) ([]storedEvent, error) {
+	var allEvents []storedEvent
+	for i, filePath := range listLogFiles(logFilePath) {
+		events, err := readEventsFromFile(filePath, i)
+		if err != nil {
+			return nil, err
+		}
+		allEvents = append(allEvents, events...)
 	}
 
 	sort.SliceStable(allEvents, func(i, j int) bool {
-		return allEvents[i].Timestamp.After(allEvents[j].Timestamp)
+		return allEvents[i].Event.Timestamp.After(allEvents[j].Event.Timestamp)
 	})
+	return allEvents, nil
+}
 
-	var filteredEvents []config.DeploymentEvent
-	for _, event := range allEvents {
-		envMatch := true
-		if envFilter != "" && !strings.EqualFold(event.Environment, envFilter) {
-			envMatch = false
-		}
-		outcomeMatch := true
-		if outcomeFilter != "" && !strings.EqualFold(event.Outcome, outcomeFilter) {
-			outcomeMatch = false
-		}
+// HistoryPage is one page of filtered deployment events. Total counts all events matching the
+// filters before limit and offset are applied; it is only reported for offset-based requests.
+// NextCursor is empty once there are no older matching events.
+type HistoryPage struct {
+	Total      *int                     `json:"total,omitempty"`
+	Limit      int                      `json:"limit"`
+	Offset     int                      `json:"offset"`
+	Events     []config.DeploymentEvent `json:"events"`
+	NextCursor string                   `json:"nextCursor,omitempty"`
+}
 
-		if envMatch && outcomeMatch {
-			filteredEvents = append(filteredEvents, event)
-		}
+// HistoryFilter selects the deployment events ListHistory returns. Zero fields match everything.
+type HistoryFilter struct {
+	Env     string    // Case-insensitive
+	Outcome string    // Case-insensitive
+	Since   time.Time // Only events at or after this time
+	Until   time.Time // Only events before this time
+}
+
+func (f HistoryFilter) matches(event config.DeploymentEvent) bool {
+	if f.Env != "" && !strings.EqualFold(event.Environment, f.Env) {
+		return false
+	}
+	if f.Outcome != "" && !strings.EqualFold(event.Outcome, f.Outcome) {
+		return false
 	}
+	if !f.Since.IsZero() && event.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !event.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// ListHistory reads deployment events, newest first, from the log file and its rotated archives.
+//
+// Without a cursor, every file is read and the page starts at offset, as before. With a cursor
+// (the NextCursor of a previous page), offset is ignored and reading starts at the position the
+// cursor points to, touching only as much of the log as the page needs; Total is not reported.
+// Cursors survive log rotation but not 'history prune' or archives aging out, in which case
+// ErrInvalidCursor is returned and the client should start over without one.
+func ListHistory(basePath, projectName, limitStr, offsetStr, cursor string, filter HistoryFilter) (*HistoryPage, error) {
+	logFilePath := getLogFilePath(basePath, projectName)
+	util.Log.Debugf("Reading deployment history from: %s", logFilePath)
+
+	matches := filter.matches
 
-	totalFiltered := len(filteredEvents)
 	offset := 0
 	limit := 25
 
@@ -97,9 +151,27 @@ func ListHistory(basePath, projectName, limitStr, offsetStr, envFilter, outcomeF
 		}
 	}
 
+	if cursor != "" {
+		return listHistoryFromCursor(logFilePath, cursor, limit, matches)
+	}
+
+	allEvents, err := readAllEvents(logFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredEvents []storedEvent
+	for _, stored := range allEvents {
+		if matches(stored.Event) {
+			filteredEvents = append(filteredEvents, stored)
+		}
+	}
+
+	totalFiltered := len(filteredEvents)
+	page := &HistoryPage{Total: &totalFiltered, Limit: limit, Offset: offset, Events: []config.DeploymentEvent{}}
 	start := offset
 	if start >= totalFiltered {
-		return []config.DeploymentEvent{}, nil
+		return page, nil
 	}
 
 	end := start + limit
@@ -107,5 +179,28 @@ func ListHistory(basePath, projectName, limitStr, offsetStr, envFilter, outcomeF
 		end = totalFiltered
 	}
 
-	return filteredEvents[start:end], nil
+	for _, stored := range filteredEvents[start:end] {
+		page.Events = append(page.Events, stored.Event)
+	}
+	if end < totalFiltered {
+		page.NextCursor = encodeCursor(filteredEvents[end-1])
+	}
+	return page, nil
+}
+
+// HasSuccessfulDeployment reports whether the history (including rotated archives) records a
+// successful deploy or approval of commitSHA to env.
+func HasSuccessfulDeployment(basePath, projectName, env, commitSHA string) (bool, error) {
+	events, err := readAllEvents(getLogFilePath(basePath, projectName))
+	if err != nil {
+		return false, err
+	}
+	for _, stored := range events {
+		event := stored.Event
+		if (event.EventType == "deploy" || event.EventType == "approve") &&
+			event.Environment == env && event.Outcome == "success" && event.CommitSHA == commitSHA {
+			return true, nil
+		}
+	}
+	return false, nil
 }
@@ -0,0 +1,44 @@
+package deployment
+
+import (
+	"reflow/internal/util"
+	"time"
+)
+
+// States of a deployment step.
+const (
+	StepRunning   = "running"
+	StepSucceeded = "succeeded"
+	StepFailed    = "failed"
+)
+
+// Step is one stage of a deployment, e.g. "building image" or "health check".
+type Step struct {
+	Name       string     `json:"name"`
+	State      string     `json:"state"`
+	Detail     string     `json:"detail,omitempty"` // Latest progress within the step, e.g. "3/12"
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// ProgressSink receives the steps of a running deployment. ReportStep is called with the
+// current state of the step at index each time it starts, reports progress or finishes.
+type ProgressSink interface {
+	ReportStep(index int, step Step)
+}
+
+// LogSink is the sink of the CLI: it logs each step as it starts. Progress within a step is
+// only logged at debug level, since the deployment logs its own messages along the way.
+type LogSink struct{}
+
+// ReportStep implements ProgressSink.
+func (LogSink) ReportStep(index int, step Step) {
+	if step.State != StepRunning {
+		return
+	}
+	if step.Detail == "" {
+		util.Log.Infof("==> [%d] %s", index+1, step.Name)
+	} else {
+		util.Log.Debugf("    %s: %s", step.Name, step.Detail)
+	}
+}
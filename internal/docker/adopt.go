@@ -0,0 +1,126 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/util"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+)
+
+// RecreateWithLabels replaces a container with a copy named newName that carries extra labels and
+// is also attached to networkName. Docker cannot change the labels of an existing container, so
+// the copy is created from the original's config: same image, command, env, port bindings,
+// restart policy and networks, and the same volumes, including anonymous ones.
+//
+// The original is stopped first, since it may hold host ports the copy needs, and is left
+// stopped but not removed: the caller removes it once the copy is known to work, or restarts it
+// to roll back. If the copy can't be created or started, the original is restarted.
+func RecreateWithLabels(ctx context.Context, containerID, newName string, labels map[string]string, networkName string) (string, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return "", err
+	}
+
+	// --- 1. Copy the Original's Config ---
+	original, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+	if original.Config == nil || original.HostConfig == nil {
+		return "", fmt.Errorf("container %s returned an incomplete inspect response", containerID)
+	}
+	networkMode := original.HostConfig.NetworkMode
+	if networkMode.IsHost() || networkMode.IsContainer() || networkMode.IsNone() {
+		return "", fmt.Errorf("container %s uses network mode '%s', which cannot be attached to network '%s'", containerID, networkMode, networkName)
+	}
+
+	containerConfig := *original.Config
+	containerConfig.Labels = make(map[string]string, len(original.Config.Labels)+len(labels))
+	for k, v := range original.Config.Labels {
+		containerConfig.Labels[k] = v
+	}
+	for k, v := range labels {
+		containerConfig.Labels[k] = v
+	}
+	// Docker defaults the hostname to the short container ID; let the copy get its own.
+	if containerConfig.Hostname == shortContainerID(original.ID) {
+		containerConfig.Hostname = ""
+	}
+
+	hostConfig := *original.HostConfig
+	hostConfig.Mounts = append([]mount.Mount(nil), original.HostConfig.Mounts...)
+	for _, mp := range original.Mounts {
+		if mp.Type != mount.TypeVolume || mountTargetCovered(&hostConfig, mp.Destination) {
+			continue
+		}
+		// An anonymous volume (e.g. from the image's VOLUME) would otherwise be replaced by an empty one.
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{Type: mount.TypeVolume, Source: mp.Name, Target: mp.Destination, ReadOnly: !mp.RW})
+	}
+
+	endpoints := map[string]*network.EndpointSettings{networkName: {}}
+	if original.NetworkSettings != nil {
+		for name, endpoint := range original.NetworkSettings.Networks {
+			if name == networkName || endpoint == nil {
+				continue
+			}
+			endpoints[name] = &network.EndpointSettings{Aliases: endpoint.Aliases, Links: endpoint.Links}
+		}
+	}
+
+	// --- 2. Stop the Original ---
+	util.Log.Infof("Stopping container %s to replace it with '%s'...", shortContainerID(original.ID), newName)
+	if err := StopContainer(ctx, original.ID, nil); err != nil {
+		return "", err
+	}
+	restoreOriginal := func() {
+		util.Log.Warnf("Restarting original container %s...", shortContainerID(original.ID))
+		if err := StartContainer(context.Background(), original.ID); err != nil {
+			util.Log.Errorf("Failed to restart original container %s: %v", shortContainerID(original.ID), err)
+		}
+	}
+
+	// --- 3. Create and Start the Copy ---
+	resp, err := cli.ContainerCreate(ctx, &containerConfig, &hostConfig, &network.NetworkingConfig{EndpointsConfig: endpoints}, nil, newName)
+	if err != nil {
+		restoreOriginal()
+		return "", fmt.Errorf("failed to create container '%s': %w", newName, err)
+	}
+	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		if rmErr := RemoveContainer(context.Background(), resp.ID); rmErr != nil {
+			util.Log.Warnf("Failed to clean up container %s after start failure: %v", shortContainerID(resp.ID), rmErr)
+		}
+		restoreOriginal()
+		return "", fmt.Errorf("failed to start container '%s': %w", newName, err)
+	}
+
+	util.Log.Infof("Container '%s' (ID: %s) replaces %s.", newName, shortContainerID(resp.ID), shortContainerID(original.ID))
+	return resp.ID, nil
+}
+
+// mountTargetCovered reports whether a bind or mount in hostConfig already targets dest.
+func mountTargetCovered(hostConfig *container.HostConfig, dest string) bool {
+	for _, m := range hostConfig.Mounts {
+		if m.Target == dest {
+			return true
+		}
+	}
+	for _, bind := range hostConfig.Binds {
+		// Format: source:target[:options]
+		parts := strings.Split(bind, ":")
+		if len(parts) >= 2 && parts[1] == dest {
+			return true
+		}
+	}
+	return false
+}
+
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -6,11 +6,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"reflow/internal/util"
+	"regexp"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/docker/docker/api/types"
@@ -78,28 +82,55 @@ func GenerateDockerfileContent(data DockerfileData) (string, error) {
 	return buf.String(), nil
 }
 
-// BuildImage builds a Docker image from a given context directory and Dockerfile path.
-func BuildImage(ctx context.Context, dockerfilePath, contextPath, imageName string, buildArgs map[string]*string) error {
+// BuildImage builds a Docker image from a given context directory and Dockerfile path, with
+// BuildKit or the legacy builder depending on opts.BuildKit.
+func BuildImage(ctx context.Context, dockerfilePath, contextPath, imageName string, buildArgs map[string]*string, opts BuildOptions) error {
 	cli, err := GetClient()
 	if err != nil {
 		return err
 	}
 
 	util.Log.Infof("Building Docker image '%s'...", imageName)
+	if opts.NoCache {
+		util.Log.Info("Build cache disabled; every step is built from scratch.")
+	}
 	util.Log.Debugf(" Build Context: %s", contextPath)
 	util.Log.Debugf(" Dockerfile: %s", dockerfilePath)
 
-	buildContextReader, err := createTarStream(contextPath)
+	ignorePatterns, err := LoadIgnorePatterns(contextPath)
+	if err != nil {
+		return fmt.Errorf("failed to load build context ignore patterns: %w", err)
+	}
+	if len(ignorePatterns) > 0 {
+		// Like 'docker build', send the Dockerfile even if a pattern excludes it.
+		ignorePatterns = append(ignorePatterns, "!"+filepath.Base(dockerfilePath))
+	}
+
+	buildContextReader, err := createTarStream(contextPath, ignorePatterns)
 	if err != nil {
 		return fmt.Errorf("failed to create build context tar stream: %w", err)
 	}
 
+	if opts.BuildKit {
+		util.Log.Info("Starting image build with BuildKit (this may take a while)...")
+		err := buildImageBuildKit(ctx, buildContextReader, filepath.Base(dockerfilePath), imageName, buildArgs, opts)
+		if err == nil {
+			util.Log.Infof("Successfully built image '%s'", imageName)
+			return nil
+		}
+		if !errors.Is(err, errDockerCLIMissing) {
+			return err
+		}
+		util.Log.Warnf("Cannot build with BuildKit (%v); falling back to the legacy builder.", err)
+	}
+
 	options := types.ImageBuildOptions{
 		Dockerfile:  filepath.Base(dockerfilePath),
 		Tags:        []string{imageName},
 		Remove:      true,
 		ForceRemove: true,
 		BuildArgs:   buildArgs,
+		NoCache:     opts.NoCache,
 	}
 
 	util.Log.Info("Starting image build (this may take a while)...")
@@ -123,7 +154,12 @@ func BuildImage(ctx context.Context, dockerfilePath, contextPath, imageName stri
 		var msg map[string]interface{}
 		if err := json.Unmarshal(line, &msg); err == nil {
 			if stream, ok := msg["stream"].(string); ok {
-				fmt.Print(stream)
+				if opts.Progress == nil {
+					fmt.Print(stream)
+				} else {
+					util.Log.Debug(strings.TrimRight(stream, "\n"))
+					reportBuildStep(opts.Progress, legacyStepPattern, stream)
+				}
 			} else if errorDetail, ok := msg["errorDetail"].(map[string]interface{}); ok {
 				errorMsg := "unknown build error"
 				if code, ok := errorDetail["code"].(float64); ok {
@@ -137,9 +173,13 @@ func BuildImage(ctx context.Context, dockerfilePath, contextPath, imageName stri
 				if id, ok := aux["ID"].(string); ok {
 					util.Log.Debugf("Built image layer/result ID: %s", id)
 				}
+			} else if opts.Progress != nil {
+				reportLayerProgress(opts.Progress, msg)
 			}
-		} else {
+		} else if opts.Progress == nil {
 			fmt.Println(string(line))
+		} else {
+			util.Log.Debug(string(line))
 		}
 	}
 
@@ -152,10 +192,48 @@ func BuildImage(ctx context.Context, dockerfilePath, contextPath, imageName stri
 	return nil
 }
 
-// createTarStream creates a tar stream from the specified directory.
-func createTarStream(dir string) (io.Reader, error) {
+// Lines that start a build step: "Step 3/12 : RUN npm ci" in the legacy builder's stream and
+// "#7 [builder 3/6] RUN npm ci" in BuildKit's plain progress output.
+var (
+	legacyStepPattern   = regexp.MustCompile(`^Step (\d+)/(\d+) :`)
+	buildKitStepPattern = regexp.MustCompile(`^#\d+ \[(?:[^\]]*\s)?(\d+)/(\d+)\]`)
+)
+
+// reportBuildStep reports the build step line starts, if any, with the share of steps done.
+func reportBuildStep(report func(detail string), pattern *regexp.Regexp, line string) {
+	match := pattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return
+	}
+	step, _ := strconv.Atoi(match[1])
+	total, _ := strconv.Atoi(match[2])
+	if total <= 0 || step <= 0 {
+		return
+	}
+	report(fmt.Sprintf("step %d/%d (%d%%)", step, total, (step-1)*100/total))
+}
+
+// reportLayerProgress reports the download progress of a base image layer from a status message
+// of the legacy builder's stream, e.g. {"status":"Downloading","progressDetail":{"current":1,"total":4},"id":"a1b2"}.
+func reportLayerProgress(report func(detail string), msg map[string]interface{}) {
+	status, _ := msg["status"].(string)
+	id, _ := msg["id"].(string)
+	progressDetail, _ := msg["progressDetail"].(map[string]interface{})
+	current, _ := progressDetail["current"].(float64)
+	total, _ := progressDetail["total"].(float64)
+	if status == "" || id == "" || total <= 0 {
+		return
+	}
+	report(fmt.Sprintf("%s layer %s: %d%%", strings.ToLower(status), id, int(current*100/total)))
+}
+
+// createTarStream creates a tar stream from the specified directory, leaving out paths excluded
+// by ignorePatterns (see LoadIgnorePatterns).
+func createTarStream(dir string, ignorePatterns []string) (io.Reader, error) {
 	var buf bytes.Buffer
 	tw := tar.NewWriter(&buf)
+	matcher := newIgnoreMatcher(ignorePatterns)
+	totalFiles, includedFiles := 0, 0
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -166,16 +244,34 @@ func createTarStream(dir string) (io.Reader, error) {
 			return nil
 		}
 
-		header, err := tar.FileInfoHeader(info, info.Name())
+		relPath, err := filepath.Rel(dir, path)
 		if err != nil {
 			return err
 		}
+		relPath = filepath.ToSlash(relPath)
 
-		relPath, err := filepath.Rel(dir, path)
+		if len(ignorePatterns) > 0 && matcher.excluded(relPath) {
+			if !info.IsDir() {
+				totalFiles++
+				return nil
+			}
+			if !matcher.mayReinclude(relPath) {
+				totalFiles += countFiles(path)
+				return filepath.SkipDir
+			}
+			// Walk into it for re-included paths, but leave the directory entry itself out.
+			return nil
+		}
+		if !info.IsDir() {
+			totalFiles++
+			includedFiles++
+		}
+
+		header, err := tar.FileInfoHeader(info, info.Name())
 		if err != nil {
 			return err
 		}
-		header.Name = filepath.ToSlash(relPath)
+		header.Name = relPath
 
 		if err := tw.WriteHeader(header); err != nil {
 			return err
@@ -209,5 +305,22 @@ func createTarStream(dir string) (io.Reader, error) {
 		return nil, fmt.Errorf("failed to close tar writer: %w", err)
 	}
 
+	if len(ignorePatterns) > 0 {
+		util.Log.Infof("Build context: %d of %d file(s) included, %d excluded by ignore patterns (%.1f MiB)", includedFiles, totalFiles, totalFiles-includedFiles, float64(buf.Len())/(1024*1024))
+	} else {
+		util.Log.Debugf("Build context: %d file(s) (%d bytes)", includedFiles, buf.Len())
+	}
 	return &buf, nil
 }
+
+// countFiles returns the number of non-directory entries below dir, ignoring unreadable parts.
+func countFiles(dir string) int {
+	count := 0
+	_ = filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
@@ -0,0 +1,154 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflow/internal/util"
+	"sort"
+	"strings"
+
+	hversion "github.com/hashicorp/go-version"
+)
+
+// minBuildKitDefaultVersion is the first Docker Engine release that uses BuildKit by default.
+const minBuildKitDefaultVersion = "23.0.0"
+
+// BuildOptions tunes how BuildImage builds an image.
+type BuildOptions struct {
+	// Build with BuildKit by running 'docker build' with DOCKER_BUILDKIT=1, instead of the
+	// legacy builder API. Falls back to the legacy builder if the docker CLI is not installed.
+	BuildKit bool
+	// Images whose layers may be reused as build cache, e.g. the previous build of the project.
+	// Only used with BuildKit; images that don't exist locally are skipped.
+	CacheFrom []string
+	// Build every step from scratch instead of reusing cached layers.
+	NoCache bool
+	// If set, receives the build's progress, e.g. "step 3/12 (16%)", and the raw build output
+	// is only logged at debug level instead of being printed.
+	Progress func(detail string)
+}
+
+// errDockerCLIMissing is returned by buildImageBuildKit if there is no docker CLI to run.
+var errDockerCLIMissing = errors.New("docker CLI not found in PATH")
+
+// UseBuildKit resolves the useBuildKit setting: an explicit value wins, otherwise BuildKit is
+// used if the Docker Engine is version 23 or newer, where it is the default builder.
+func UseBuildKit(ctx context.Context, setting *bool) bool {
+	if setting != nil {
+		return *setting
+	}
+	cli, err := GetClient()
+	if err != nil {
+		return false
+	}
+	serverVersion, err := cli.ServerVersion(ctx)
+	if err != nil {
+		util.Log.Debugf("Could not determine Docker Engine version, using the legacy builder: %v", err)
+		return false
+	}
+	current, err := hversion.NewVersion(serverVersion.Version)
+	if err != nil {
+		util.Log.Debugf("Could not parse Docker Engine version '%s', using the legacy builder: %v", serverVersion.Version, err)
+		return false
+	}
+	return current.GreaterThanOrEqual(hversion.Must(hversion.NewVersion(minBuildKitDefaultVersion)))
+}
+
+// buildImageBuildKit builds an image with BuildKit by piping the build context to 'docker build -'.
+// Build arg values are handed over in the environment rather than on the command line, so they
+// don't show up in the process list. The image carries inline cache metadata so that later
+// builds can use it with --cache-from.
+func buildImageBuildKit(ctx context.Context, buildContext io.Reader, dockerfileName, imageName string, buildArgs map[string]*string, opts BuildOptions) error {
+	dockerPath, err := exec.LookPath("docker")
+	if err != nil {
+		return errDockerCLIMissing
+	}
+
+	args := []string{"build", "--progress=plain", "-f", dockerfileName, "-t", imageName, "--build-arg", "BUILDKIT_INLINE_CACHE=1"}
+	env := append(os.Environ(), "DOCKER_BUILDKIT=1")
+
+	keys := make([]string, 0, len(buildArgs))
+	for key := range buildArgs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--build-arg", key)
+		if value := buildArgs[key]; value != nil {
+			env = append(env, key+"="+*value)
+		}
+	}
+
+	if opts.NoCache {
+		args = append(args, "--no-cache")
+	}
+	for _, cacheImage := range opts.CacheFrom {
+		if cacheImage == "" || cacheImage == imageName {
+			continue
+		}
+		if img, findErr := FindImage(ctx, cacheImage); findErr != nil || img == nil {
+			util.Log.Debugf("Cache image '%s' not available locally, skipping it.", cacheImage)
+			continue
+		}
+		util.Log.Infof("Using '%s' as build cache.", cacheImage)
+		args = append(args, "--cache-from", cacheImage)
+	}
+	args = append(args, "-")
+
+	cmd := exec.CommandContext(ctx, dockerPath, args...)
+	cmd.Env = env
+	cmd.Stdin = buildContext
+	var output *buildOutput
+	if opts.Progress != nil {
+		output = &buildOutput{report: opts.Progress}
+		cmd.Stdout, cmd.Stderr = output, output
+	} else {
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stdout
+	}
+	util.Log.Debugf("Running: %s %v", dockerPath, args)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("docker build cancelled: %w", ctx.Err())
+		}
+		if output != nil && len(output.tail) > 0 {
+			return fmt.Errorf("docker build failed: %w; last output:\n%s", err, strings.Join(output.tail, "\n"))
+		}
+		return fmt.Errorf("docker build failed: %w", err)
+	}
+	return nil
+}
+
+// buildOutputTailLines is how many lines of captured BuildKit output a failed build reports.
+const buildOutputTailLines = 10
+
+// buildOutput takes BuildKit's plain progress output in place of stdout: it reports the step
+// each line starts, logs the lines at debug level and keeps the last few for error messages.
+type buildOutput struct {
+	report  func(detail string)
+	partial []byte
+	tail    []string
+}
+
+func (o *buildOutput) Write(p []byte) (int, error) {
+	o.partial = append(o.partial, p...)
+	for {
+		i := bytes.IndexByte(o.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := strings.TrimRight(string(o.partial[:i]), "\r")
+		o.partial = o.partial[i+1:]
+
+		util.Log.Debug(line)
+		reportBuildStep(o.report, buildKitStepPattern, line)
+		o.tail = append(o.tail, line)
+		if len(o.tail) > buildOutputTailLines {
+			o.tail = o.tail[1:]
+		}
+	}
+}
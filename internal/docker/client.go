@@ -6,6 +6,7 @@ import (
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
 	dockerAPIClient "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"io"
 	"io/ioutil"
 	"reflow/internal/util"
@@ -37,15 +38,29 @@ func GetClient() (*client.Client, error) {
 	return dockerClient, nil
 }
 
-// PullImage pulls a Docker image from a registry.
-func PullImage(ctx context.Context, imageName string) error {
+// Ping checks that the Docker daemon is reachable. Unlike GetClient, which only pings when it
+// first connects, it asks the daemon every time.
+func Ping(ctx context.Context) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+	if _, err := cli.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping Docker daemon: %w", err)
+	}
+	return nil
+}
+
+// PullImage pulls a Docker image from a registry. registryAuth is the encoded credential
+// (see RegistryAuthFromGlobal); leave it empty for an anonymous pull.
+func PullImage(ctx context.Context, imageName, registryAuth string) error {
 	cli, err := GetClient()
 	if err != nil {
 		return err
 	}
 
 	util.Log.Infof("Pulling image '%s'...", imageName)
-	pullOptions := image.PullOptions{}
+	pullOptions := image.PullOptions{RegistryAuth: registryAuth}
 	reader, err := cli.ImagePull(ctx, imageName, pullOptions)
 	if err != nil {
 		util.Log.Errorf("Failed to start image pull for '%s': %v", imageName, err)
@@ -71,6 +86,29 @@ func PullImage(ctx context.Context, imageName string) error {
 	return nil
 }
 
+// PushImage pushes a local image to its registry. registryAuth is the encoded credential (see
+// ImageRegistryAuth), sent in the X-Registry-Auth header; leave it empty for an anonymous push.
+func PushImage(ctx context.Context, imageName, registryAuth string) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	util.Log.Infof("Pushing image '%s'...", imageName)
+	reader, err := cli.ImagePush(ctx, imageName, image.PushOptions{RegistryAuth: registryAuth})
+	if err != nil {
+		return fmt.Errorf("failed to push image '%s': %w", imageName, err)
+	}
+	defer reader.Close()
+
+	// Errors such as a denied push are reported in the stream, not as the response status.
+	if err := jsonmessage.DisplayJSONMessagesStream(reader, io.Discard, 0, false, nil); err != nil {
+		return fmt.Errorf("failed to push image '%s': %w", imageName, err)
+	}
+	util.Log.Infof("Successfully pushed image '%s'.", imageName)
+	return nil
+}
+
 // IsErrNotFound checks if a Docker error is a "not found" error.
 func IsErrNotFound(err error) bool {
 	return dockerAPIClient.IsErrNotFound(err)
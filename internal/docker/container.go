@@ -1,9 +1,12 @@
 package docker
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"reflow/internal/config"
 	"reflow/internal/util"
 	"strings"
 	"time"
@@ -11,9 +14,9 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
-	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	dockerAPIClient "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 )
 
@@ -23,6 +26,8 @@ const (
 	LabelSlot        = "reflow.slot"
 	LabelCommit      = "reflow.commit"
 	LabelManaged     = "reflow.managed"
+	LabelReplica     = "reflow.replica"
+	LabelPluginName  = "reflow.plugin.name"
 )
 
 // FindContainersByLabels finds containers matching a given set of labels.
@@ -138,6 +143,59 @@ func StopContainer(ctx context.Context, containerID string, timeout *time.Durati
 	return nil
 }
 
+// StopContainerGracefully sends SIGTERM to a running container and waits up to gracePeriod for it
+// to exit before sending SIGKILL. Returns forced=true if SIGKILL was required.
+func StopContainerGracefully(ctx context.Context, containerID string, gracePeriod time.Duration) (forced bool, err error) {
+	cli, err := GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	inspect, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect container %s: %w", containerID[:12], err)
+	}
+	if inspect.State == nil || !inspect.State.Running {
+		util.Log.Debugf("Container %s is not running, nothing to stop.", containerID[:12])
+		return false, nil
+	}
+
+	util.Log.Infof("Stopping container %s (SIGTERM, grace period %v)...", containerID[:12], gracePeriod)
+	// Register the wait before signalling so a fast exit isn't missed.
+	waitCtx, cancel := context.WithTimeout(ctx, gracePeriod)
+	defer cancel()
+	waitCh, waitErrCh := cli.ContainerWait(waitCtx, containerID, container.WaitConditionNotRunning)
+
+	if err := cli.ContainerKill(ctx, containerID, "SIGTERM"); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "is not running") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to send SIGTERM to container %s: %w", containerID[:12], err)
+	}
+
+	select {
+	case <-waitCh:
+		util.Log.Infof("Container %s stopped gracefully.", containerID[:12])
+		return false, nil
+	case waitErr := <-waitErrCh:
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		if !errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+			return false, fmt.Errorf("failed waiting for container %s to stop: %w", containerID[:12], waitErr)
+		}
+	}
+
+	util.Log.Warnf("Container %s did not stop within %v after SIGTERM, sending SIGKILL.", containerID[:12], gracePeriod)
+	if err := cli.ContainerKill(ctx, containerID, "SIGKILL"); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "is not running") {
+			return false, nil
+		}
+		return true, fmt.Errorf("failed to send SIGKILL to container %s: %w", containerID[:12], err)
+	}
+	return true, nil
+}
+
 // StartContainer starts a container by its ID.
 func StartContainer(ctx context.Context, containerID string) error {
 	// Get client explicitly
@@ -161,6 +219,114 @@ func StartContainer(ctx context.Context, containerID string) error {
 	return nil
 }
 
+// containerPollInterval is how often WaitForContainerRunning and ExecInContainer re-check state.
+const containerPollInterval = 250 * time.Millisecond
+
+// WaitForContainerRunning polls a container until Docker reports it running. It fails as soon as
+// the container exits or is found dead, or once timeout has passed.
+func WaitForContainerRunning(ctx context.Context, containerID string, timeout time.Duration) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+	shortID := containerID[:min(12, len(containerID))]
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		inspect, err := cli.ContainerInspect(waitCtx, containerID)
+		if err != nil {
+			if waitCtx.Err() != nil {
+				return fmt.Errorf("timed out after %s waiting for container %s to start", timeout, shortID)
+			}
+			return fmt.Errorf("failed to inspect container %s: %w", shortID, err)
+		}
+		if inspect.State != nil {
+			switch {
+			case inspect.State.Running && !inspect.State.Restarting:
+				util.Log.Debugf("Container %s is running.", shortID)
+				return nil
+			case inspect.State.Status == "exited" || inspect.State.Dead:
+				return fmt.Errorf("container %s exited during startup (exit code %d): %s", shortID, inspect.State.ExitCode, inspect.State.Error)
+			}
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return fmt.Errorf("timed out after %s waiting for container %s to start", timeout, shortID)
+		case <-time.After(containerPollInterval):
+		}
+	}
+}
+
+// ExecInContainer runs a command inside a running container and waits for it to finish. env
+// holds extra KEY=value variables for the command and may be nil. It returns the combined
+// stdout/stderr output and the command's exit code.
+func ExecInContainer(ctx context.Context, containerID string, cmd []string, env []string) (string, int, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return "", 0, err
+	}
+	shortID := containerID[:min(12, len(containerID))]
+
+	execResp, err := cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Cmd:          cmd,
+		Env:          env,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create exec in container %s: %w", shortID, err)
+	}
+	attachResp, err := cli.ContainerExecAttach(ctx, execResp.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to attach to exec in container %s: %w", shortID, err)
+	}
+	defer attachResp.Close()
+
+	var output bytes.Buffer
+	if _, err := stdcopy.StdCopy(&output, &output, attachResp.Reader); err != nil {
+		return output.String(), 0, fmt.Errorf("failed to read exec output from container %s: %w", shortID, err)
+	}
+
+	// The exec can still be marked running for a moment after its output closes.
+	inspect, err := cli.ContainerExecInspect(ctx, execResp.ID)
+	for err == nil && inspect.Running {
+		select {
+		case <-ctx.Done():
+			return output.String(), 0, fmt.Errorf("timed out waiting for exec in container %s: %w", shortID, ctx.Err())
+		case <-time.After(containerPollInterval):
+		}
+		inspect, err = cli.ContainerExecInspect(ctx, execResp.ID)
+	}
+	if err != nil {
+		return output.String(), 0, fmt.Errorf("failed to inspect exec in container %s: %w", shortID, err)
+	}
+	return output.String(), inspect.ExitCode, nil
+}
+
+// RunPostStartHooks runs each hook as a shell command ('sh -c') inside a container, in order.
+// It stops at the first hook that fails or exits non-zero.
+func RunPostStartHooks(ctx context.Context, containerID string, hooks []string, timeout time.Duration) error {
+	shortID := containerID[:min(12, len(containerID))]
+	for i, hook := range hooks {
+		util.Log.Infof("Running post-start hook %d/%d in container %s: %s", i+1, len(hooks), shortID, hook)
+		hookCtx, cancel := context.WithTimeout(ctx, timeout)
+		output, exitCode, err := ExecInContainer(hookCtx, containerID, []string{"sh", "-c", hook}, nil)
+		cancel()
+		if output = strings.TrimSpace(output); output != "" {
+			util.Log.Debugf("Post-start hook output:\n%s", output)
+		}
+		if err != nil {
+			return fmt.Errorf("post-start hook '%s' failed: %w", hook, err)
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("post-start hook '%s' exited with code %d: %s", hook, exitCode, output)
+		}
+	}
+	return nil
+}
+
 // RestartContainer restarts a container by ID.
 func RestartContainer(ctx context.Context, containerID string, timeout *time.Duration) error {
 	cli, err := GetClient()
@@ -220,10 +386,22 @@ type ContainerRunOptions struct {
 	ImageName     string
 	ContainerName string
 	NetworkName   string
+	ExtraNetworks []string // Further networks the container is connected to before it starts
 	Labels        map[string]string
 	EnvVars       []string
 	AppPort       int
-	RestartPolicy string
+	RestartPolicy string // Docker restart policy mode; defaults to config.DefaultRestartPolicy
+	MaxRetries    int    // Restart attempts for the "on-failure" policy; zero means unlimited
+
+	// Optional resource limits; zero means unlimited.
+	MemoryLimit       int64
+	MemoryReservation int64
+	NanoCPUs          int64
+
+	// Optional persistent storage. Bind-mounted host paths must be inside HostPathRoot.
+	Volumes      []VolumeMount
+	VolumeLabels map[string]string
+	HostPathRoot string
 }
 
 // RunContainer creates and starts a container based on provided options.
@@ -244,10 +422,26 @@ func RunContainer(ctx context.Context, options ContainerRunOptions) (string, err
 		},
 	}
 
+	mounts, err := buildMounts(ctx, options.Volumes, options.HostPathRoot, options.VolumeLabels)
+	if err != nil {
+		return "", fmt.Errorf("invalid volumes for container '%s': %w", options.ContainerName, err)
+	}
+
+	restartPolicy := options.RestartPolicy
+	if restartPolicy == "" {
+		restartPolicy = config.DefaultRestartPolicy
+	}
+
 	hostConfig := &container.HostConfig{
-		Mounts: []mount.Mount{},
+		Mounts: mounts,
 		RestartPolicy: container.RestartPolicy{
-			Name: container.RestartPolicyMode(options.RestartPolicy),
+			Name:              container.RestartPolicyMode(restartPolicy),
+			MaximumRetryCount: options.MaxRetries,
+		},
+		Resources: container.Resources{
+			Memory:            options.MemoryLimit,
+			MemoryReservation: options.MemoryReservation,
+			NanoCPUs:          options.NanoCPUs,
 		},
 	}
 
@@ -274,6 +468,15 @@ func RunContainer(ctx context.Context, options ContainerRunOptions) (string, err
 	containerID := resp.ID
 	util.Log.Debugf("Container '%s' created with ID: %s", options.ContainerName, containerID)
 
+	for _, networkName := range options.ExtraNetworks {
+		if err := ConnectContainerToNetwork(ctx, networkName, containerID); err != nil {
+			if rmErr := RemoveContainer(context.Background(), containerID); rmErr != nil {
+				util.Log.Warnf("Failed to clean up container %s after network failure: %v", containerID[:12], rmErr)
+			}
+			return "", err
+		}
+	}
+
 	util.Log.Infof("Starting container '%s'...", options.ContainerName)
 	startOptions := container.StartOptions{}
 	if err := cli.ContainerStart(ctx, containerID, startOptions); err != nil {
@@ -0,0 +1,139 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Ignore files read from the root of a build context, in order of preference.
+var ignoreFileNames = []string{".reflowignore", ".dockerignore"}
+
+// LoadIgnorePatterns reads the build context's .reflowignore, or its .dockerignore if there is
+// none. Patterns use .dockerignore syntax: one per line, relative to the context root, '#'
+// comments, '*' and '?' wildcards within a path segment, '**' for any number of segments, and
+// '!' to re-include paths an earlier pattern excluded. Returns nil if neither file exists.
+func LoadIgnorePatterns(contextPath string) ([]string, error) {
+	for _, name := range ignoreFileNames {
+		ignorePath := filepath.Join(contextPath, name)
+		content, err := os.ReadFile(ignorePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", ignorePath, err)
+		}
+
+		var patterns []string
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			pattern, negated := strings.CutPrefix(line, "!")
+			pattern = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(strings.TrimSpace(pattern))), "/")
+			if pattern == "" {
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s' in %s: %w", line, ignorePath, err)
+			}
+			if negated {
+				pattern = "!" + pattern
+			}
+			patterns = append(patterns, pattern)
+		}
+		return patterns, nil
+	}
+	return nil, nil
+}
+
+// ignoreMatcher decides which paths of a build context are excluded by ignore patterns.
+type ignoreMatcher struct {
+	patterns []string
+	negated  []bool
+}
+
+func newIgnoreMatcher(patterns []string) *ignoreMatcher {
+	m := &ignoreMatcher{}
+	for _, p := range patterns {
+		pattern, negated := strings.CutPrefix(p, "!")
+		m.patterns = append(m.patterns, pattern)
+		m.negated = append(m.negated, negated)
+	}
+	return m
+}
+
+// excluded reports whether relPath (slash-separated, relative to the context root) is excluded.
+// As in .dockerignore, a pattern matching a directory also matches everything below it, and the
+// last matching pattern wins.
+func (m *ignoreMatcher) excluded(relPath string) bool {
+	segments := strings.Split(relPath, "/")
+	excluded := false
+	for i, pattern := range m.patterns {
+		patternSegments := strings.Split(pattern, "/")
+		for n := 1; n <= len(segments); n++ {
+			if matchSegments(patternSegments, segments[:n]) {
+				excluded = !m.negated[i]
+				break
+			}
+		}
+	}
+	return excluded
+}
+
+// mayReinclude reports whether a '!' pattern could match a path below the excluded directory
+// dirPath, in which case the directory has to be walked rather than skipped.
+func (m *ignoreMatcher) mayReinclude(dirPath string) bool {
+	dirSegments := strings.Split(dirPath, "/")
+	for i, pattern := range m.patterns {
+		if m.negated[i] && couldMatchBelow(strings.Split(pattern, "/"), dirSegments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where a "**" segment matches
+// any number of path segments, including none.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(segments); skip++ {
+			if matchSegments(pattern[1:], segments[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// couldMatchBelow reports whether pattern could match some path inside the directory dirSegments.
+func couldMatchBelow(pattern, dirSegments []string) bool {
+	for i, dirSegment := range dirSegments {
+		if i >= len(pattern) {
+			return false
+		}
+		if pattern[i] == "**" {
+			return true
+		}
+		if matched, _ := path.Match(pattern[i], dirSegment); !matched {
+			return false
+		}
+	}
+	return len(pattern) > len(dirSegments)
+}
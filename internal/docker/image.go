@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflow/internal/util"
+	"strings"
 
 	"github.com/docker/docker/api/types/image"
 	dockerAPIClient "github.com/docker/docker/client"
@@ -56,3 +57,96 @@ func RemoveImage(ctx context.Context, imageID string) error {
 	util.Log.Infof("Successfully removed image %s", imageID)
 	return nil
 }
+
+// TagImage adds a new tag (target) to an existing local image (source).
+func TagImage(ctx context.Context, source, target string) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	util.Log.Debugf("Tagging image %s as %s", source, target)
+	if err := cli.ImageTag(ctx, source, target); err != nil {
+		return fmt.Errorf("failed to tag image %s as %s: %w", source, target, err)
+	}
+	return nil
+}
+
+// UntagImage removes a tag from a local image. The image itself is only deleted if that was its
+// last tag.
+func UntagImage(ctx context.Context, tag string) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+	if _, err := cli.ImageRemove(ctx, tag, image.RemoveOptions{}); err != nil && !dockerAPIClient.IsErrNotFound(err) {
+		return fmt.Errorf("failed to remove tag %s: %w", tag, err)
+	}
+	return nil
+}
+
+// PruneOldProjectImages removes a project's images (tagged <project>:<commit>) beyond the
+// keepCount newest, skipping any image a container still uses or that is tagged with one of
+// activeCommits. Removal failures are logged and skipped. Returns the number of images removed.
+func PruneOldProjectImages(ctx context.Context, projectName string, keepCount int, activeCommits ...string) (int, error) {
+	images, err := ListProjectImages(ctx, projectName)
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	for _, img := range PrunableProjectImages(images, keepCount, activeCommits...) {
+		// Remove by tag rather than ID, so other tags on the same image survive; Docker deletes
+		// the image once its last tag is gone.
+		if err := UntagImage(ctx, img.Tag); err != nil {
+			util.Log.Warnf("Failed to prune image %s (%s): %v", shortImageID(img.ID), img.Tag, err)
+			continue
+		}
+		util.Log.Infof("Pruned old image %s (%s)", shortImageID(img.ID), img.Tag)
+		pruned++
+	}
+	return pruned, nil
+}
+
+// ProjectImageUsage returns the number and total size in bytes of the local images tagged
+// <project>:<tag> for any of the given projects. Layers shared between images are counted for
+// each image, so the size is an upper bound of the disk actually used.
+func ProjectImageUsage(ctx context.Context, projectNames []string) (int, int64, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	images, err := cli.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list images: %w", err)
+	}
+	count, size := 0, int64(0)
+	for _, img := range images {
+		if imageBelongsToProjects(img, projectNames) {
+			count++
+			size += img.Size
+		}
+	}
+	return count, size, nil
+}
+
+func imageBelongsToProjects(img image.Summary, projectNames []string) bool {
+	for _, tag := range img.RepoTags {
+		for _, name := range projectNames {
+			if strings.HasPrefix(tag, strings.ToLower(name)+":") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// shortImageID returns the 12-character form of an image ID, without its "sha256:" prefix.
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,114 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/util"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+)
+
+// ProjectImage is a local image tagged <project>:<commit>. An image carrying several such tags
+// is listed once per tag.
+type ProjectImage struct {
+	Project   string    `json:"project"`
+	Commit    string    `json:"commit"`
+	Tag       string    `json:"tag"`
+	ID        string    `json:"id"`
+	Size      int64     `json:"size"`
+	Created   time.Time `json:"created"`
+	OtherTags int       `json:"otherTags"`         // Further tags on the same image, of any repository
+	InUse     bool      `json:"inUse"`             // Whether a container, running or stopped, uses the image
+	InUseBy   []string  `json:"inUseBy,omitempty"` // Environments of the project's containers using the image
+}
+
+// ListProjectImages returns the local images of a project, most recently built first.
+func ListProjectImages(ctx context.Context, projectName string) ([]ProjectImage, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := cli.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	// Stopped containers block removal too, so they count as using their image.
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	return projectImages(images, containers, projectName), nil
+}
+
+// projectImages picks a project's images out of images, newest first, and records which
+// containers use them.
+func projectImages(images []image.Summary, containers []container.Summary, projectName string) []ProjectImage {
+	imagePrefix := strings.ToLower(projectName) + ":"
+
+	inUse := make(map[string]bool, len(containers))
+	envsByImage := make(map[string][]string)
+	for _, c := range containers {
+		inUse[c.ImageID] = true
+		env := c.Labels[LabelEnvironment]
+		if c.Labels[LabelProject] == projectName && env != "" && !slices.Contains(envsByImage[c.ImageID], env) {
+			envsByImage[c.ImageID] = append(envsByImage[c.ImageID], env)
+		}
+	}
+
+	var result []ProjectImage
+	for _, img := range images {
+		for _, tag := range img.RepoTags {
+			if !strings.HasPrefix(tag, imagePrefix) {
+				continue
+			}
+			envs := envsByImage[img.ID]
+			sort.Strings(envs)
+			result = append(result, ProjectImage{
+				Project:   projectName,
+				Commit:    strings.TrimPrefix(tag, imagePrefix),
+				Tag:       tag,
+				ID:        img.ID,
+				Size:      img.Size,
+				Created:   time.Unix(img.Created, 0),
+				OtherTags: len(img.RepoTags) - 1,
+				InUse:     inUse[img.ID],
+				InUseBy:   envs,
+			})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Created.After(result[j].Created) })
+	return result
+}
+
+// PrunableProjectImages returns the images pruning may remove from a project's images (as
+// returned by ListProjectImages): all but the keepCount most recently built, except those tagged
+// with one of activeCommits and those a container still uses.
+func PrunableProjectImages(images []ProjectImage, keepCount int, activeCommits ...string) []ProjectImage {
+	active := make(map[string]bool, len(activeCommits))
+	for _, commit := range activeCommits {
+		if commit != "" {
+			active[commit] = true
+		}
+	}
+
+	var prunable []ProjectImage
+	for i, img := range images {
+		switch {
+		case i < keepCount:
+			util.Log.Debugf("Keeping image %s (%s): one of the %d most recent.", shortImageID(img.ID), img.Tag, keepCount)
+		case active[img.Commit]:
+			util.Log.Debugf("Keeping image %s (%s): its commit is active.", shortImageID(img.ID), img.Tag)
+		case img.InUse:
+			util.Log.Debugf("Keeping image %s (%s): in use by a container.", shortImageID(img.ID), img.Tag)
+		default:
+			prunable = append(prunable, img)
+		}
+	}
+	return prunable
+}
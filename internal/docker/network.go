@@ -0,0 +1,116 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/util"
+	"strings"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+)
+
+// CreateNetworkIfNotExists creates an attachable bridge network with the given labels, unless a
+// network of that name exists already.
+func CreateNetworkIfNotExists(ctx context.Context, name string, labels map[string]string) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	if _, err := cli.NetworkInspect(ctx, name, network.InspectOptions{}); err == nil {
+		util.Log.Debugf("Docker network '%s' already exists.", name)
+		return nil
+	} else if !IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect network '%s': %w", name, err)
+	}
+
+	util.Log.Infof("Creating Docker network '%s'...", name)
+	enableIPv6 := false
+	createOptions := network.CreateOptions{
+		Driver:     "bridge",
+		EnableIPv6: &enableIPv6,
+		Attachable: true,
+		Labels:     labels,
+	}
+	if _, err := cli.NetworkCreate(ctx, name, createOptions); err != nil {
+		return fmt.Errorf("failed to create network '%s': %w", name, err)
+	}
+	return nil
+}
+
+// ConnectContainerToNetwork connects a container, by name or ID, to a network. A container that
+// is already connected is left as it is.
+func ConnectContainerToNetwork(ctx context.Context, networkName, containerNameOrID string) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	if err := cli.NetworkConnect(ctx, networkName, containerNameOrID, nil); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			util.Log.Debugf("Container '%s' is already connected to network '%s'.", containerNameOrID, networkName)
+			return nil
+		}
+		return fmt.Errorf("failed to connect container '%s' to network '%s': %w", containerNameOrID, networkName, err)
+	}
+	util.Log.Debugf("Connected container '%s' to network '%s'.", containerNameOrID, networkName)
+	return nil
+}
+
+// RemoveNetwork disconnects all containers from a network and removes it. A missing network is
+// not an error.
+func RemoveNetwork(ctx context.Context, name string) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	inspect, err := cli.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err != nil {
+		if IsErrNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to inspect network '%s': %w", name, err)
+	}
+	for containerID := range inspect.Containers {
+		if err := cli.NetworkDisconnect(ctx, name, containerID, true); err != nil && !IsErrNotFound(err) {
+			return fmt.Errorf("failed to disconnect container %s from network '%s': %w", containerID[:12], name, err)
+		}
+	}
+
+	util.Log.Infof("Removing Docker network '%s'...", name)
+	if err := cli.NetworkRemove(ctx, name); err != nil && !IsErrNotFound(err) {
+		return fmt.Errorf("failed to remove network '%s': %w", name, err)
+	}
+	return nil
+}
+
+// RemoveNetworksByLabels removes all networks matching the given labels (see RemoveNetwork).
+func RemoveNetworksByLabels(ctx context.Context, labels map[string]string) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	filterArgs := filters.NewArgs()
+	for key, value := range labels {
+		filterArgs.Add("label", fmt.Sprintf("%s=%s", key, value))
+	}
+	networks, err := cli.NetworkList(ctx, network.ListOptions{Filters: filterArgs})
+	if err != nil {
+		return fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	var failed []string
+	for _, n := range networks {
+		if rmErr := RemoveNetwork(ctx, n.Name); rmErr != nil {
+			util.Log.Errorf("%v", rmErr)
+			failed = append(failed, n.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to remove network(s): %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
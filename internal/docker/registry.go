@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// dockerHubAliases are the host names Docker Hub is known by; all of them match a "docker.io" credential.
+var dockerHubAliases = map[string]bool{
+	"docker.io":            true,
+	"index.docker.io":      true,
+	"registry-1.docker.io": true,
+}
+
+// ImageRegistry returns the registry host an image reference is pulled from, e.g. "ghcr.io".
+// Images without an explicit host resolve to "docker.io".
+func ImageRegistry(imageName string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference '%s': %w", imageName, err)
+	}
+	return reference.Domain(named), nil
+}
+
+// RegistryAuthFromGlobal returns the encoded RegistryAuth for pulling imageName, using the
+// credential in the global config whose registry matches the image's. Returns an empty string
+// (anonymous pull) when no credential matches.
+func RegistryAuthFromGlobal(globalCfg *config.GlobalConfig, imageName string) (string, error) {
+	if globalCfg == nil || len(globalCfg.Registries) == 0 {
+		return "", nil
+	}
+	imageRegistry, err := ImageRegistry(imageName)
+	if err != nil {
+		return "", err
+	}
+
+	for _, cred := range globalCfg.Registries {
+		if normalizeRegistryHost(cred.Registry) != normalizeRegistryHost(imageRegistry) {
+			continue
+		}
+		if cred.Token == "" && cred.Username == "" {
+			return "", fmt.Errorf("registry credential for '%s' needs a username/password or a token", cred.Registry)
+		}
+		authConfig := registry.AuthConfig{
+			Username:      cred.Username,
+			Password:      cred.Password,
+			RegistryToken: cred.Token,
+			ServerAddress: imageRegistry,
+		}
+		encoded, err := registry.EncodeAuthConfig(authConfig)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode registry credentials for '%s': %w", cred.Registry, err)
+		}
+		return encoded, nil
+	}
+	return "", nil
+}
+
+// RegistryImageName returns the name a project image is pushed to the registry as:
+// <registry url>/<project>:<commit>.
+func RegistryImageName(registryURL, projectName, commitHash string) string {
+	base := strings.ToLower(strings.TrimSpace(registryURL))
+	base = strings.TrimPrefix(base, "https://")
+	base = strings.TrimPrefix(base, "http://")
+	base = strings.TrimSuffix(base, "/")
+	return fmt.Sprintf("%s/%s:%s", base, strings.ToLower(projectName), commitHash)
+}
+
+// ImageRegistryAuth returns the encoded RegistryAuth for the registry section of the global
+// config, or an empty string if it has no credentials. Without a token in the config, it is
+// read from the environment variable named by PasswordEnvVar. The token is registered as a
+// secret so it is redacted from logs.
+func ImageRegistryAuth(registryCfg config.ImageRegistryConfig) (string, error) {
+	if registryCfg.Token == "" && registryCfg.PasswordEnvVar != "" {
+		registryCfg.Token = os.Getenv(registryCfg.PasswordEnvVar)
+		if registryCfg.Token == "" {
+			return "", fmt.Errorf("registry.passwordEnvVar is set, but environment variable %s is empty", registryCfg.PasswordEnvVar)
+		}
+	}
+	if registryCfg.Username == "" && registryCfg.Token == "" {
+		return "", nil
+	}
+	util.RegisterSecret(registryCfg.Token)
+	authConfig := registry.AuthConfig{ServerAddress: normalizeRegistryHost(registryCfg.URL)}
+	if registryCfg.Username != "" {
+		authConfig.Username = registryCfg.Username
+		authConfig.Password = registryCfg.Token
+	} else {
+		authConfig.RegistryToken = registryCfg.Token
+	}
+	encoded, err := registry.EncodeAuthConfig(authConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode registry credentials for '%s': %w", registryCfg.URL, err)
+	}
+	return encoded, nil
+}
+
+// normalizeRegistryHost strips any scheme and trailing path from a registry address and maps
+// Docker Hub aliases to "docker.io".
+func normalizeRegistryHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	if dockerHubAliases[host] {
+		return "docker.io"
+	}
+	return host
+}
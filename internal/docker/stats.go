@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflow/internal/util"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+const bytesPerMB = 1024 * 1024
+
+// ContainerStats is a single resource usage snapshot for a container.
+type ContainerStats struct {
+	ContainerID   string    `json:"containerId"`
+	Name          string    `json:"name"`
+	CPUPercent    float64   `json:"cpuPercent"`
+	MemoryUsageMB float64   `json:"memoryUsageMB"`
+	MemoryLimitMB float64   `json:"memoryLimitMB"`
+	MemoryPercent float64   `json:"memoryPercent"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// GetContainerStats takes a single (non-streaming) resource usage snapshot for a container.
+func GetContainerStats(ctx context.Context, containerID string) (*ContainerStats, error) {
+	cli, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	util.Log.Debugf("Getting stats for container %s", containerID)
+	statsReader, err := cli.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats for container %s: %w", containerID, err)
+	}
+	defer statsReader.Body.Close()
+
+	var raw container.StatsResponse
+	if err := json.NewDecoder(statsReader.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode stats for container %s: %w", containerID, err)
+	}
+
+	memUsage := calculateMemoryUsage(raw.MemoryStats)
+	stats := &ContainerStats{
+		ContainerID:   raw.ID,
+		Name:          strings.TrimPrefix(raw.Name, "/"),
+		CPUPercent:    calculateCPUPercent(raw),
+		MemoryUsageMB: float64(memUsage) / bytesPerMB,
+		MemoryLimitMB: float64(raw.MemoryStats.Limit) / bytesPerMB,
+		Timestamp:     raw.Read,
+	}
+	if raw.MemoryStats.Limit > 0 {
+		stats.MemoryPercent = float64(memUsage) / float64(raw.MemoryStats.Limit) * 100.0
+	}
+	if stats.ContainerID == "" {
+		stats.ContainerID = containerID
+	}
+	return stats, nil
+}
+
+// calculateCPUPercent computes CPU usage from the delta between the current and previous samples,
+// scaled by the number of online CPUs, the same way 'docker stats' does.
+func calculateCPUPercent(stats container.StatsResponse) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+
+	numCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if numCPUs == 0 {
+		numCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+
+	if cpuDelta <= 0 || systemDelta <= 0 || numCPUs == 0 {
+		return 0.0
+	}
+	return cpuDelta / systemDelta * numCPUs * 100.0
+}
+
+// calculateMemoryUsage returns memory usage excluding page cache, matching 'docker stats'.
+func calculateMemoryUsage(mem container.MemoryStats) uint64 {
+	// cgroup v2 reports 'inactive_file', cgroup v1 reports 'total_inactive_file'.
+	if v, ok := mem.Stats["inactive_file"]; ok && v < mem.Usage {
+		return mem.Usage - v
+	}
+	if v, ok := mem.Stats["total_inactive_file"]; ok && v < mem.Usage {
+		return mem.Usage - v
+	}
+	return mem.Usage
+}
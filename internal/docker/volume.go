@@ -0,0 +1,130 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/util"
+	"strings"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// VolumeMount describes persistent storage for a container. An absolute Source is bind-mounted
+// from the host; anything else is treated as a named Docker volume.
+type VolumeMount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// buildMounts converts volume mounts into Docker mounts. Host paths must be inside hostPathRoot and
+// are created if missing; named volumes are created with the given labels if they don't exist yet.
+func buildMounts(ctx context.Context, volumes []VolumeMount, hostPathRoot string, volumeLabels map[string]string) ([]mount.Mount, error) {
+	mounts := []mount.Mount{}
+	for _, v := range volumes {
+		if filepath.IsAbs(v.Source) {
+			if err := checkHostPathWithin(v.Source, hostPathRoot); err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(v.Source, 0755); err != nil {
+				return nil, fmt.Errorf("failed to create host path %s for volume: %w", v.Source, err)
+			}
+			mounts = append(mounts, mount.Mount{Type: mount.TypeBind, Source: v.Source, Target: v.Target, ReadOnly: v.ReadOnly})
+			continue
+		}
+
+		if err := EnsureVolume(ctx, v.Source, volumeLabels); err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, mount.Mount{Type: mount.TypeVolume, Source: v.Source, Target: v.Target, ReadOnly: v.ReadOnly})
+	}
+	return mounts, nil
+}
+
+// checkHostPathWithin rejects host paths (after resolving symlinks where possible) that escape root.
+func checkHostPathWithin(hostPath, root string) error {
+	if root == "" {
+		return fmt.Errorf("host path volume %s is not allowed for this container", hostPath)
+	}
+	resolvedRoot := resolveExistingPrefix(filepath.Clean(root))
+	resolvedPath := resolveExistingPrefix(filepath.Clean(hostPath))
+
+	rel, err := filepath.Rel(resolvedRoot, resolvedPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("host path volume %s is outside the Reflow base path %s", hostPath, root)
+	}
+	return nil
+}
+
+// resolveExistingPrefix evaluates symlinks for the longest existing prefix of a path,
+// so paths that don't exist yet are still checked against where they will actually land.
+func resolveExistingPrefix(path string) string {
+	var missing []string
+	current := path
+	for {
+		if resolved, err := filepath.EvalSymlinks(current); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path
+		}
+		missing = append([]string{filepath.Base(current)}, missing...)
+		current = parent
+	}
+}
+
+// EnsureVolume creates a named volume with the given labels if it doesn't already exist.
+func EnsureVolume(ctx context.Context, name string, labels map[string]string) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	if _, err := cli.VolumeInspect(ctx, name); err == nil {
+		return nil
+	} else if !IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect volume %s: %w", name, err)
+	}
+
+	util.Log.Infof("Creating volume '%s'...", name)
+	if _, err := cli.VolumeCreate(ctx, volume.CreateOptions{Name: name, Labels: labels}); err != nil {
+		return fmt.Errorf("failed to create volume %s: %w", name, err)
+	}
+	return nil
+}
+
+// RemoveVolumesByLabels removes all named volumes matching the given labels.
+func RemoveVolumesByLabels(ctx context.Context, labels map[string]string) error {
+	cli, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	filterArgs := filters.NewArgs()
+	for key, value := range labels {
+		filterArgs.Add("label", fmt.Sprintf("%s=%s", key, value))
+	}
+
+	resp, err := cli.VolumeList(ctx, volume.ListOptions{Filters: filterArgs})
+	if err != nil {
+		return fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	var failed []string
+	for _, v := range resp.Volumes {
+		util.Log.Infof("Removing volume '%s'...", v.Name)
+		if rmErr := cli.VolumeRemove(ctx, v.Name, false); rmErr != nil && !IsErrNotFound(rmErr) {
+			util.Log.Errorf("Failed to remove volume %s: %v", v.Name, rmErr)
+			failed = append(failed, v.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to remove volume(s): %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
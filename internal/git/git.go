@@ -4,18 +4,164 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"reflow/internal/config"
 	"reflow/internal/util"
 )
 
+// SSHPassphraseEnvVar names the environment variable that overrides the configured SSH key passphrase.
+const SSHPassphraseEnvVar = "REFLOW_SSH_PASSPHRASE"
+
+// AuthConfig holds the credentials used for repository access.
+// If SSHKeyPath is empty, the SSH agent is used when available. HTTPToken is only used for
+// http(s) repository URLs.
+type AuthConfig struct {
+	SSHKeyPath    string
+	SSHPassphrase string
+	HTTPUsername  string
+	HTTPToken     string
+}
+
+// defaultHTTPUsername is sent with a token when no username is stored; GitHub and GitLab
+// accept any non-empty name for personal access tokens.
+const defaultHTTPUsername = "git"
+
+// AuthConfigFromGlobal builds an AuthConfig from the global config, preferring the passphrase from REFLOW_SSH_PASSPHRASE.
+func AuthConfigFromGlobal(globalCfg *config.GlobalConfig) AuthConfig {
+	authCfg := AuthConfig{}
+	if globalCfg != nil {
+		authCfg.SSHKeyPath = globalCfg.SSHKeyPath
+		authCfg.SSHPassphrase = globalCfg.SSHKeyPassphrase
+	}
+	if envPassphrase := os.Getenv(SSHPassphraseEnvVar); envPassphrase != "" {
+		authCfg.SSHPassphrase = envPassphrase
+	}
+	return authCfg
+}
+
+// ProjectSSHPassphraseEnvVar returns the environment variable that holds the passphrase of a
+// project's own SSH key, e.g. REFLOW_SSH_PASSPHRASE_MY_APP for project 'my-app'.
+func ProjectSSHPassphraseEnvVar(projectName string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, projectName)
+	return SSHPassphraseEnvVar + "_" + strings.ToUpper(name)
+}
+
+// WithProjectSSHKey returns a copy of authCfg that uses the project's own SSH key, if it has one,
+// instead of the global key. Its passphrase comes from ProjectSSHPassphraseEnvVar if set.
+func (authCfg AuthConfig) WithProjectSSHKey(projectName, keyPath, passphrase string) AuthConfig {
+	if keyPath == "" {
+		return authCfg
+	}
+	authCfg.SSHKeyPath = keyPath
+	authCfg.SSHPassphrase = passphrase
+	if envPassphrase := os.Getenv(ProjectSSHPassphraseEnvVar(projectName)); envPassphrase != "" {
+		authCfg.SSHPassphrase = envPassphrase
+	}
+	return authCfg
+}
+
+// WithHTTPCredentials returns a copy of authCfg with the stored HTTPS credential for repoURL:
+// the project's own token if set (projectName may be empty), otherwise the one for its host.
+func (authCfg AuthConfig) WithHTTPCredentials(reflowBasePath, projectName, repoURL string) (AuthConfig, error) {
+	cred, err := config.ResolveGitCredential(reflowBasePath, projectName, repoURL)
+	if err != nil {
+		return authCfg, err
+	}
+	if cred != nil {
+		authCfg.HTTPUsername = cred.Username
+		authCfg.HTTPToken = cred.Token
+	}
+	return authCfg, nil
+}
+
+// NewSSHAuthFromKey creates an SSH auth method from a private key file.
+func NewSSHAuthFromKey(keyPath, passphrase string) (transport.AuthMethod, error) {
+	if _, err := os.Stat(keyPath); err != nil {
+		return nil, fmt.Errorf("ssh key file '%s' is not accessible: %w", keyPath, err)
+	}
+	auth, err := ssh.NewPublicKeysFromFile("git", keyPath, passphrase)
+	if err != nil {
+		if passphrase == "" && strings.Contains(err.Error(), "passphrase") {
+			return nil, fmt.Errorf("ssh key '%s' is encrypted; set 'sshKeyPassphrase' in config or %s: %w", keyPath, SSHPassphraseEnvVar, err)
+		}
+		return nil, fmt.Errorf("failed to load ssh key '%s': %w", keyPath, err)
+	}
+	return auth, nil
+}
+
+// resolveAuth returns the auth method for a git operation on repoURL. HTTP(S) URLs use the stored
+// token, if any. For SSH, a configured key file takes precedence; otherwise the SSH agent is used
+// if available, or nil to rely on system credentials.
+func resolveAuth(authCfg AuthConfig, repoURL, operation string) (transport.AuthMethod, error) {
+	if isHTTPURL(repoURL) {
+		if authCfg.HTTPToken == "" {
+			util.Log.Debugf("No stored token for %s over HTTPS, proceeding without authentication.", operation)
+			return nil, nil
+		}
+		util.RegisterSecret(authCfg.HTTPToken)
+		username := authCfg.HTTPUsername
+		if username == "" {
+			username = defaultHTTPUsername
+		}
+		util.Log.Debugf("Using stored HTTPS token for %s.", operation)
+		return &http.BasicAuth{Username: username, Password: authCfg.HTTPToken}, nil
+	}
+
+	if authCfg.SSHKeyPath != "" {
+		util.Log.Debugf("Using SSH key '%s' for %s.", authCfg.SSHKeyPath, operation)
+		return NewSSHAuthFromKey(authCfg.SSHKeyPath, authCfg.SSHPassphrase)
+	}
+
+	publicKeysCallback, err := ssh.NewSSHAgentAuth("git")
+	if err != nil {
+		util.Log.Debugf("SSH Agent not found for %s, proceeding without explicit SSH auth: %v", operation, err)
+		return nil, nil
+	}
+	util.Log.Debugf("SSH Agent detected, attempting SSH authentication for %s.", operation)
+	return publicKeysCallback, nil
+}
+
+// isHTTPURL reports whether a repository URL uses HTTP(S) rather than SSH.
+func isHTTPURL(repoURL string) bool {
+	lower := strings.ToLower(repoURL)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")
+}
+
+// originURL returns the first URL of a repository's 'origin' remote.
+func originURL(repo *git.Repository) string {
+	remote, err := repo.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
+
+// unshallowDepth mirrors the depth 'git fetch --unshallow' requests from the server.
+const unshallowDepth = 2147483647
+
 // CloneRepo clones a Git repository to the specified destination path.
-// It currently relies on system-configured credentials (SSH agent, credential helpers).
-func CloneRepo(repoURL, destPath string) error {
-	util.Log.Infof("Cloning repository '%s' into '%s'...", repoURL, destPath)
+// If depth is greater than 0, only that many commits of history are fetched (a shallow clone).
+// It uses the configured SSH key if set, otherwise the SSH agent or system credential helpers.
+func CloneRepo(repoURL, destPath string, depth int, authCfg AuthConfig) error {
+	if depth > 0 {
+		util.Log.Infof("Shallow cloning repository '%s' (depth %d) into '%s'...", util.RedactURL(repoURL), depth, destPath)
+	} else {
+		util.Log.Infof("Cloning repository '%s' into '%s'...", util.RedactURL(repoURL), destPath)
+	}
 
 	if _, err := os.Stat(destPath); err == nil {
 		return fmt.Errorf("destination path '%s' already exists", destPath)
@@ -26,35 +172,38 @@ func CloneRepo(repoURL, destPath string) error {
 	cloneOptions := &git.CloneOptions{
 		URL:      repoURL,
 		Progress: os.Stdout,
-		// Depth: 1, // Todo: Decide whether to perform a shallow clone initially. Might be faster.
 		// RecurseSubmodules: git.DefaultSubmoduleRecursionDepth, // Handle submodules if needed
 	}
 
-	// Attempt to detect SSH key auth automatically using agent or known_hosts
-	// This relies on the user having SSH keys configured correctly.
-	publicKeysCallback, err := ssh.NewSSHAgentAuth("git")
-	if err == nil {
-		util.Log.Debug("SSH Agent detected, attempting SSH authentication.")
-		cloneOptions.Auth = publicKeysCallback
-	} else {
-		util.Log.Debugf("SSH Agent not found or failed to initialize, proceeding without explicit SSH auth: %v", err)
+	if depth > 0 {
+		cloneOptions.Depth = depth
+	}
+
+	auth, err := resolveAuth(authCfg, repoURL, "clone")
+	if err != nil {
+		return err
 	}
+	cloneOptions.Auth = auth
 
 	_, err = git.PlainClone(destPath, false, cloneOptions)
 	if err != nil {
-		util.Log.Errorf("Failed to clone repository '%s': %v", repoURL, err)
+		util.Log.Errorf("Failed to clone repository '%s': %v", util.RedactURL(repoURL), err)
 		if strings.Contains(err.Error(), "authentication required") {
-			util.Log.Error("Authentication failed. Ensure your SSH keys are set up correctly for private repositories or use HTTPS URL with credentials if supported.")
+			if isHTTPURL(repoURL) {
+				util.Log.Error("Authentication failed. For private repositories over HTTPS, store a token with 'reflow config git-credentials set <host>' or 'reflow project create --git-token'.")
+			} else {
+				util.Log.Error("Authentication failed. Ensure your SSH keys are set up correctly for private repositories.")
+			}
 		}
-		return fmt.Errorf("failed to clone repository '%s': %w", repoURL, err)
+		return fmt.Errorf("failed to clone repository '%s': %w", util.RedactURL(repoURL), err)
 	}
 
-	util.Log.Infof("Successfully cloned repository '%s' to '%s'", repoURL, destPath)
+	util.Log.Infof("Successfully cloned repository '%s' to '%s'", util.RedactURL(repoURL), destPath)
 	return nil
 }
 
 // FetchUpdates fetches the latest changes from the 'origin' remote for a given repo path.
-func FetchUpdates(repoPath string) error {
+func FetchUpdates(repoPath string, authCfg AuthConfig) error {
 	util.Log.Debugf("Opening repository at %s", repoPath)
 	repo, err := git.PlainOpen(repoPath)
 	if err != nil {
@@ -64,16 +213,18 @@ func FetchUpdates(repoPath string) error {
 	util.Log.Infof("Fetching updates for repository at %s...", repoPath)
 	fetchOptions := &git.FetchOptions{
 		RemoteName: "origin",
-		Progress:   os.Stdout,
+		// Every branch, whatever the remote's configured refspec, so "origin/<branch>" resolves.
+		RefSpecs: []gitconfig.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
+		// Drop remote-tracking branches deleted upstream, so they no longer resolve.
+		Prune:    true,
+		Progress: os.Stdout,
 	}
 
-	publicKeysCallback, authErr := ssh.NewSSHAgentAuth("git")
-	if authErr == nil {
-		util.Log.Debug("SSH Agent detected, attempting SSH authentication for fetch.")
-		fetchOptions.Auth = publicKeysCallback
-	} else {
-		util.Log.Debugf("SSH Agent not found for fetch, proceeding without explicit SSH auth: %v", authErr)
+	auth, err := resolveAuth(authCfg, originURL(repo), "fetch")
+	if err != nil {
+		return err
 	}
+	fetchOptions.Auth = auth
 
 	err = repo.Fetch(fetchOptions)
 	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
@@ -92,6 +243,134 @@ func FetchUpdates(repoPath string) error {
 	return nil
 }
 
+// RemoteBranchRevision returns the revision of the remote-tracking branch for branch, which
+// ResolveRevision resolves to the branch even if a tag has the same name.
+func RemoteBranchRevision(branch string) string {
+	return "origin/" + strings.TrimPrefix(branch, "origin/")
+}
+
+// ResolveRevision resolves a commit hash, tag, branch or other revision to a commit in the
+// repository at repoPath. A plain branch name resolves to the remote-tracking branch
+// origin/<name>, which fetches keep current, rather than to a local branch of the same name that
+// was left at whatever the clone checked out. A tag of the same name takes precedence, as in git.
+func ResolveRevision(repoPath, revision string) (plumbing.Hash, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to open repository at %s: %w", repoPath, err)
+	}
+
+	if revision != "HEAD" && !plumbing.IsHash(revision) && !strings.ContainsAny(revision, "~^@:") {
+		if _, tagErr := repo.Reference(plumbing.NewTagReferenceName(revision), true); errors.Is(tagErr, plumbing.ErrReferenceNotFound) {
+			if ref, refErr := repo.Reference(plumbing.NewRemoteReferenceName("origin", revision), true); refErr == nil {
+				util.Log.Debugf("Resolved branch '%s' via origin/%s", revision, revision)
+				return ref.Hash(), nil
+			}
+		}
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return *hash, nil
+}
+
+// FetchRevision fetches a single branch, tag or full commit hash from 'origin' with the given
+// depth, so a shallow clone can resolve a revision beyond its current history without fetching
+// everything. Revisions the remote does not advertise (e.g. short hashes) are not an error; the
+// caller finds out when resolving them.
+func FetchRevision(repoPath, revision string, depth int, authCfg AuthConfig) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository at %s: %w", repoPath, err)
+	}
+
+	var refSpecs []gitconfig.RefSpec
+	if plumbing.IsHash(revision) {
+		refSpecs = []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("%s:refs/reflow/fetched/%s", revision, revision))}
+	} else {
+		// Branches land in refs/remotes/origin like a normal fetch, so "origin/<branch>" resolves.
+		name := strings.TrimPrefix(revision, "origin/")
+		refSpecs = []gitconfig.RefSpec{
+			gitconfig.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", name, name)),
+			gitconfig.RefSpec(fmt.Sprintf("+refs/tags/%s:refs/tags/%s", name, name)),
+		}
+	}
+
+	auth, err := resolveAuth(authCfg, originURL(repo), "fetch")
+	if err != nil {
+		return err
+	}
+
+	util.Log.Infof("Fetching '%s' (depth %d) for repository at %s...", revision, depth, repoPath)
+	fetched := false
+	for _, refSpec := range refSpecs {
+		err = repo.Fetch(&git.FetchOptions{
+			RemoteName: "origin",
+			RefSpecs:   []gitconfig.RefSpec{refSpec},
+			Depth:      depth,
+			Auth:       auth,
+			Progress:   os.Stdout,
+		})
+		switch {
+		case err == nil, errors.Is(err, git.NoErrAlreadyUpToDate):
+			fetched = true
+		case errors.Is(err, git.NoMatchingRefSpecError{}):
+			util.Log.Debugf("Remote has no ref matching %s", refSpec)
+		default:
+			return fmt.Errorf("failed to fetch '%s' for repository '%s': %w", revision, repoPath, err)
+		}
+	}
+	if !fetched {
+		util.Log.Debugf("Remote does not advertise '%s'; it may be a short hash or an unreachable commit", revision)
+	}
+	return nil
+}
+
+// IsShallow reports whether the repository at repoPath is a shallow clone.
+func IsShallow(repoPath string) (bool, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open repository at %s: %w", repoPath, err)
+	}
+	shallowCommits, err := repo.Storer.Shallow()
+	if err != nil {
+		return false, fmt.Errorf("failed to read shallow state for repository at %s: %w", repoPath, err)
+	}
+	return len(shallowCommits) > 0, nil
+}
+
+// Unshallow fetches the full history of a shallow clone so older revisions can be resolved.
+func Unshallow(repoPath string, authCfg AuthConfig) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository at %s: %w", repoPath, err)
+	}
+
+	util.Log.Infof("Fetching full history for shallow repository at %s...", repoPath)
+	fetchOptions := &git.FetchOptions{
+		RemoteName: "origin",
+		Progress:   os.Stdout,
+		Depth:      unshallowDepth,
+		Tags:       git.AllTags,
+	}
+
+	auth, err := resolveAuth(authCfg, originURL(repo), "unshallow fetch")
+	if err != nil {
+		return err
+	}
+	fetchOptions.Auth = auth
+
+	err = repo.Fetch(fetchOptions)
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		util.Log.Errorf("Failed to fetch full history for repository '%s': %v", repoPath, err)
+		return fmt.Errorf("failed to unshallow repository '%s': %w", repoPath, err)
+	}
+
+	util.Log.Infof("Successfully fetched full history for repository '%s'", repoPath)
+	return nil
+}
+
 // CheckoutCommit checks out a specific commit hash or branch in the repository.
 func CheckoutCommit(repoPath, commitHashOrBranch string) error {
 	util.Log.Debugf("Opening repository at %s", repoPath)
@@ -130,3 +409,38 @@ func CheckoutCommit(repoPath, commitHashOrBranch string) error {
 	util.Log.Infof("Successfully checked out '%s' (commit: %s)", commitHashOrBranch, hash.String()[:7])
 	return nil
 }
+
+// ErrFileNotInCommit is returned by ReadFileAtCommit when the file is not tracked at that commit.
+var ErrFileNotInCommit = errors.New("file not found in commit")
+
+// ReadFileAtCommit returns the contents of filePath (relative to the repository root) as stored
+// at revision, the equivalent of 'git show <revision>:<path>'. Unlike reading the working tree,
+// the result does not depend on which commit happens to be checked out.
+func ReadFileAtCommit(repoPath, revision, filePath string) ([]byte, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository at %s: %w", repoPath, err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision '%s': %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit %s: %w", hash.String(), err)
+	}
+
+	file, err := commit.File(filepath.ToSlash(filepath.Clean(filePath)))
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, fmt.Errorf("'%s' at commit %s: %w", filePath, hash.String()[:7], ErrFileNotInCommit)
+		}
+		return nil, fmt.Errorf("failed to look up '%s' at commit %s: %w", filePath, hash.String()[:7], err)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s' at commit %s: %w", filePath, hash.String()[:7], err)
+	}
+	return []byte(contents), nil
+}
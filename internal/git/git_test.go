@@ -0,0 +1,154 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitAndPush commits a file on a new branch of the working repository, started from the
+// current commit (or as the first commit of an empty repository), and pushes it to origin.
+func commitAndPush(t *testing.T, repo *git.Repository, branch, fileName string) plumbing.Hash {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	if head, err := repo.Head(); err == nil {
+		if err := worktree.Checkout(&git.CheckoutOptions{Branch: branchRef, Hash: head.Hash(), Create: true}); err != nil {
+			t.Fatal(err)
+		}
+	} else if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef)); err != nil {
+		t.Fatal(err)
+	}
+	return commitOnBranch(t, repo, branch, fileName)
+}
+
+// commitOnBranch adds a commit on the checked-out branch of the working repository and pushes it
+// to origin.
+func commitOnBranch(t *testing.T, repo *git.Repository, branch, fileName string) plumbing.Hash {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree.Filesystem.Root(), fileName), []byte(fileName+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add(fileName); err != nil {
+		t.Fatal(err)
+	}
+	signature := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	hash, err := worktree.Commit("Add "+fileName, &git.CommitOptions{Author: signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	refSpec := gitconfig.RefSpec(branchRef + ":" + branchRef)
+	if err := repo.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []gitconfig.RefSpec{refSpec}}); err != nil {
+		t.Fatalf("failed to push %s: %v", branch, err)
+	}
+	return hash
+}
+
+// TestFetchUpdatesResolvesNewBranch checks that a branch created on the remote after cloning is
+// fetched, and resolves both by its bare name and as origin/<name>.
+func TestFetchUpdatesResolvesNewBranch(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	dir := t.TempDir()
+	remotePath := filepath.Join(dir, "remote.git")
+	if _, err := git.PlainInit(remotePath, true); err != nil {
+		t.Fatal(err)
+	}
+	work, err := git.PlainInit(filepath.Join(dir, "work"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := work.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{remotePath}}); err != nil {
+		t.Fatal(err)
+	}
+	commitAndPush(t, work, "master", "README.md")
+
+	clonePath := filepath.Join(dir, "clone")
+	if err := CloneRepo(remotePath, clonePath, 0, AuthConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	featureHash := commitAndPush(t, work, "feature", "feature.txt")
+
+	if _, err := ResolveRevision(clonePath, "feature"); err == nil {
+		t.Fatal("branch created after cloning resolved before fetching")
+	}
+	if err := FetchUpdates(clonePath, AuthConfig{}); err != nil {
+		t.Fatalf("FetchUpdates: %v", err)
+	}
+
+	for _, revision := range []string{"feature", RemoteBranchRevision("feature"), "origin/feature"} {
+		hash, err := ResolveRevision(clonePath, revision)
+		if err != nil {
+			t.Errorf("ResolveRevision(%q): %v", revision, err)
+			continue
+		}
+		if hash != featureHash {
+			t.Errorf("ResolveRevision(%q) = %s, want %s", revision, hash, featureHash)
+		}
+	}
+}
+
+// TestResolveRevisionDeploysFetchedBranch checks that deploying by branch name picks up the
+// branch as last fetched from origin, not the stale local branch left by the clone, and that a
+// tag of the same name still wins.
+func TestResolveRevisionDeploysFetchedBranch(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	dir := t.TempDir()
+	remotePath := filepath.Join(dir, "remote.git")
+	if _, err := git.PlainInit(remotePath, true); err != nil {
+		t.Fatal(err)
+	}
+	work, err := git.PlainInit(filepath.Join(dir, "work"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := work.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{remotePath}}); err != nil {
+		t.Fatal(err)
+	}
+	clonedHash := commitAndPush(t, work, "master", "README.md")
+
+	clonePath := filepath.Join(dir, "clone")
+	if err := CloneRepo(remotePath, clonePath, 0, AuthConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	pushedHash := commitOnBranch(t, work, "master", "CHANGELOG.md")
+	if err := FetchUpdates(clonePath, AuthConfig{}); err != nil {
+		t.Fatalf("FetchUpdates: %v", err)
+	}
+
+	hash, err := ResolveRevision(clonePath, "master")
+	if err != nil {
+		t.Fatalf("ResolveRevision(master): %v", err)
+	}
+	if hash != pushedHash {
+		t.Errorf("ResolveRevision(master) = %s, want the fetched %s, not the cloned %s", hash, pushedHash, clonedHash)
+	}
+
+	clone, err := git.PlainOpen(clonePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clone.CreateTag("master", clonedHash, nil); err != nil {
+		t.Fatal(err)
+	}
+	hash, err = ResolveRevision(clonePath, "master")
+	if err != nil {
+		t.Fatalf("ResolveRevision(master) with a tag: %v", err)
+	}
+	if hash != clonedHash {
+		t.Errorf("ResolveRevision(master) with a tag = %s, want the tagged %s", hash, clonedHash)
+	}
+}
@@ -10,6 +10,10 @@ import (
 	"reflow/internal/config"
 	"reflow/internal/docker"
 	"reflow/internal/util"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"text/template"
 	"time"
 
@@ -18,45 +22,110 @@ import (
 
 const nginxReloadSignal = "HUP"
 
+var (
+	rateLimitZonePattern = regexp.MustCompile(`(?m)^limit_req_zone \S+ zone=([^:\s]+):\S+ rate=(\d+)r/s;`)
+	rateLimitRefPattern  = regexp.MustCompile(`limit_req zone=([^\s;]+)`)
+)
+
 const nginxSiteTemplateContent = `
+{{- define "rateLimit"}}
+{{- with .RateLimit}}
+        limit_req zone={{.ZoneName}}{{if .BurstSize}} burst={{.BurstSize}} nodelay{{end}};
+        limit_req_status 429;
+{{- end}}
+{{- end}}
+{{- define "proxyHeaders"}}
+        proxy_http_version 1.1;
+        proxy_set_header Upgrade $http_upgrade;
+        proxy_set_header Connection 'upgrade';
+        proxy_set_header Host $host;
+        proxy_set_header X-Real-IP $remote_addr;
+        proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+        proxy_set_header X-Forwarded-Proto $scheme;
+        proxy_cache_bypass $http_upgrade;
+{{- end}}
+{{- define "proxy"}}
+    # Proxy requests to the upstream Node.js application
+    location / {
+{{- template "rateLimit" .}}
+        proxy_pass http://reflow_{{.ProjectName}}_{{.Env}}_{{.Slot}}_upstream;
+{{- template "proxyHeaders"}}
+    }
+{{- range .Locations}}
+
+    # Additional location: {{.Path}} -> {{.Target}}
+    location {{.Path}} {
+{{- template "rateLimit" $}}
+        proxy_pass {{.ProxyPass}};
+{{- template "proxyHeaders"}}
+{{- range .Directives}}
+        {{.}}
+{{- end}}
+    }
+{{- end}}
+{{- end}}
 # Upstream server for {{.ProjectName}} - {{.Env}} - {{.Slot}}
-# Points to the specific container for this deployment slot
+# Points to the replica container(s) for this deployment slot (round-robin)
 upstream reflow_{{.ProjectName}}_{{.Env}}_{{.Slot}}_upstream {
-    server {{.ContainerName}}:{{.AppPort}};
-    # hmm.. maybe add more servers for load balancing if deploying multiple instances per slot
+{{- range .ContainerNames}}
+    server {{.}}:{{$.AppPort}};
+{{- end}}
+}
+{{- range .Locations}}{{if .Upstream}}
+
+# Upstream server for the {{.Path}} location of {{$.ProjectName}} - {{$.Env}}
+upstream {{.Upstream}} {
+    server {{.Target}};
 }
+{{- end}}{{end}}
 
 server {
     listen 80;
     listen [::]:80;
-    # Listen on 443 for SSL later? We would need to impl cert management integration.
-    # listen 443 ssl http2;
-    # listen [::]:443 ssl http2;
 
     server_name {{.Domain}}; # Domain for this specific environment
 
-    # SSL configuration (requires certs mounted into nginx container)
-    # ssl_certificate /etc/nginx/ssl/live/{{.Domain}}/fullchain.pem;
-    # ssl_certificate_key /etc/nginx/ssl/live/{{.Domain}}/privkey.pem;
-    # include /etc/nginx/ssl/options-ssl-nginx.conf; # Common SSL settings
-    # ssl_dhparam /etc/nginx/ssl/ssl-dhparams.pem;
-
-    # Proxy requests to the upstream Node.js application
+    # ACME HTTP-01 challenges for automatic certificates
+    location /.well-known/acme-challenge/ {
+        root ` + config.NginxAcmeWebrootContainerPath + `;
+    }
+{{if .TLS}}
     location / {
-        proxy_pass http://reflow_{{.ProjectName}}_{{.Env}}_{{.Slot}}_upstream;
-        proxy_http_version 1.1;
-        proxy_set_header Upgrade $http_upgrade;
-        proxy_set_header Connection 'upgrade';
-        proxy_set_header Host $host;
-        proxy_set_header X-Real-IP $remote_addr;
-        proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
-        proxy_set_header X-Forwarded-Proto $scheme;
-        proxy_cache_bypass $http_upgrade;
+        return 301 https://$host$request_uri;
     }
+{{- else}}{{template "proxy" .}}
+{{- end}}
 
     access_log /var/log/nginx/{{.ProjectName}}.{{.Env}}.access.log;
     error_log /var/log/nginx/{{.ProjectName}}.{{.Env}}.error.log;
 }
+{{- if .TLS}}
+
+server {
+    listen 443 ssl;
+    listen [::]:443 ssl;
+    http2 on;
+
+    server_name {{.Domain}};
+
+    ssl_certificate ` + config.NginxCertsContainerPath + `/live/{{.Domain}}/fullchain.pem;
+    ssl_certificate_key ` + config.NginxCertsContainerPath + `/live/{{.Domain}}/privkey.pem;
+    ssl_protocols TLSv1.2 TLSv1.3;
+    ssl_session_cache shared:SSL:10m;
+{{template "proxy" .}}
+
+    access_log /var/log/nginx/{{.ProjectName}}.{{.Env}}.access.log;
+    error_log /var/log/nginx/{{.ProjectName}}.{{.Env}}.error.log;
+}
+{{- end}}
+`
+
+// Template for a project's rate limit zones. limit_req_zone is only valid at the http level,
+// so these are written to the limits dir, which 00-default.conf includes.
+const nginxRateLimitTemplateContent = `# Rate limit zones for {{.ProjectName}}
+{{- range .Zones}}
+limit_req_zone $binary_remote_addr zone={{.ZoneName}}:10m rate={{.RequestsPerSecond}}r/s;
+{{- end}}
 `
 
 // Template for Plugin Sites (similar but simpler upstream)
@@ -97,12 +166,54 @@ server {
 
 // TemplateData holds the data for rendering the Nginx configuration template.
 type TemplateData struct {
-	ProjectName   string
-	Env           string
-	Slot          string
-	ContainerName string
-	Domain        string
-	AppPort       int
+	ProjectName    string
+	Env            string
+	Slot           string
+	ContainerNames []string // One upstream server per replica
+	Domain         string
+	AppPort        int
+	TLS            bool                   // Serve HTTPS using the certificate under the certs dir; HTTP redirects to it
+	RateLimit      *config.NginxRateLimit // Optional; the zone must be defined via WriteRateLimitConfig
+	ExtraLocations []config.NginxLocation // Additional paths proxied to other upstreams
+}
+
+// siteLocation is an extra location as rendered in the site template.
+type siteLocation struct {
+	Path       string
+	Target     string // ProxyPass as configured
+	ProxyPass  string // What the proxy_pass directive points to
+	Upstream   string // Name of the location's own upstream block, if it proxies to a container
+	Directives []string
+}
+
+// siteLocations prepares the extra locations of data for the site template. A location that
+// proxies to a container:port gets an upstream block named after the project, env and index.
+func siteLocations(data TemplateData) []siteLocation {
+	locations := make([]siteLocation, 0, len(data.ExtraLocations))
+	for i, loc := range data.ExtraLocations {
+		site := siteLocation{Path: loc.Path, Target: loc.ProxyPass, ProxyPass: loc.ProxyPass}
+		if _, isContainer := loc.ContainerServer(); isContainer {
+			site.Upstream = fmt.Sprintf("reflow_%s_%s_loc%d_upstream", data.ProjectName, data.Env, i+1)
+			site.ProxyPass = "http://" + site.Upstream
+		} else if !strings.Contains(loc.ProxyPass, "://") {
+			site.ProxyPass = "http://" + loc.ProxyPass
+		}
+		for _, directive := range loc.ExtraDirectives {
+			directive = strings.TrimSpace(directive)
+			if !strings.HasSuffix(directive, ";") {
+				directive += ";"
+			}
+			site.Directives = append(site.Directives, directive)
+		}
+		locations = append(locations, site)
+	}
+	return locations
+}
+
+// RateLimitTemplateData holds the data for rendering a project's rate limit zones.
+type RateLimitTemplateData struct {
+	ProjectName string
+	Zones       []config.NginxRateLimit
 }
 
 // PluginTemplateData holds the data for rendering the Nginx configuration template for plugins.
@@ -120,13 +231,30 @@ func GenerateNginxConfig(data TemplateData) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to parse nginx site template: %w", err)
 	}
+	siteData := struct {
+		TemplateData
+		Locations []siteLocation
+	}{data, siteLocations(data)}
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := tmpl.Execute(&buf, siteData); err != nil {
 		return "", fmt.Errorf("failed to execute nginx site template: %w", err)
 	}
 	return buf.String(), nil
 }
 
+// GenerateRateLimitConfig generates the http-level rate limit zone definitions for a project.
+func GenerateRateLimitConfig(data RateLimitTemplateData) (string, error) {
+	tmpl, err := template.New("nginx-ratelimit").Parse(nginxRateLimitTemplateContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse nginx rate limit template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute nginx rate limit template: %w", err)
+	}
+	return buf.String(), nil
+}
+
 // GenerateNginxPluginConfig generates the Nginx configuration for a plugin using default template.
 func GenerateNginxPluginConfig(data PluginTemplateData) (string, error) {
 	tmpl, err := template.New("nginx-plugin").Parse(nginxPluginTemplateContent)
@@ -158,6 +286,140 @@ func WriteNginxConfig(reflowBasePath, projectName, env, content string) error {
 	return nil
 }
 
+// ConfigSnapshot holds the contents Nginx config files had before an update, so the update can be
+// undone when Nginx rejects it.
+type ConfigSnapshot struct {
+	files map[string][]byte // nil content means the file did not exist
+}
+
+// SnapshotProjectConfig records the current site config of a project environment and the
+// project's rate limit config.
+func SnapshotProjectConfig(reflowBasePath, projectName, env string) (*ConfigSnapshot, error) {
+	confDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName)
+	return snapshotFiles([]string{
+		filepath.Join(confDir, fmt.Sprintf("%s.%s.conf", projectName, env)),
+		filepath.Join(confDir, config.NginxLimitsDirName, rateLimitFileName(projectName)),
+	})
+}
+
+func snapshotFiles(paths []string) (*ConfigSnapshot, error) {
+	snapshot := &ConfigSnapshot{files: make(map[string][]byte, len(paths))}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read nginx config %s: %w", path, err)
+		}
+		snapshot.files[path] = content
+	}
+	return snapshot, nil
+}
+
+// Restore puts the snapshotted files back, deleting those that did not exist. It does not
+// reload Nginx.
+func (s *ConfigSnapshot) Restore() error {
+	var errs []error
+	for path, content := range s.files {
+		if content == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to remove nginx config %s: %w", path, err))
+			}
+			continue
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore nginx config %s: %w", path, err))
+			continue
+		}
+		util.Log.Debugf("Restored Nginx config file: %s", path)
+	}
+	return errors.Join(errs...)
+}
+
+// WriteRateLimitConfig writes the rate limit zones for a project to the limits dir, or removes the
+// file if there are none. Zones come from the project config, plus any zone still referenced by
+// an environment's current site config, since an environment only picks up config changes on its
+// next deploy. Call it after writing the site config and before reloading Nginx.
+func WriteRateLimitConfig(reflowBasePath string, projCfg *config.ProjectConfig) error {
+	confDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName)
+	limitsDir := filepath.Join(confDir, config.NginxLimitsDirName)
+	limitsFilePath := filepath.Join(limitsDir, rateLimitFileName(projCfg.ProjectName))
+
+	zones := make(map[string]config.NginxRateLimit)
+	if existing, err := os.ReadFile(limitsFilePath); err == nil {
+		declared := make(map[string]config.NginxRateLimit)
+		for _, m := range rateLimitZonePattern.FindAllStringSubmatch(string(existing), -1) {
+			rps, _ := strconv.Atoi(m[2])
+			declared[m[1]] = config.NginxRateLimit{ZoneName: m[1], RequestsPerSecond: rps}
+		}
+		for _, env := range []string{"test", "prod"} {
+			siteConf, readErr := os.ReadFile(filepath.Join(confDir, fmt.Sprintf("%s.%s.conf", projCfg.ProjectName, env)))
+			if readErr != nil {
+				continue
+			}
+			for _, m := range rateLimitRefPattern.FindAllStringSubmatch(string(siteConf), -1) {
+				if zone, ok := declared[m[1]]; ok {
+					zones[m[1]] = zone
+				}
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read nginx rate limit config %s: %w", limitsFilePath, err)
+	}
+	for _, env := range []string{"test", "prod"} {
+		if rl := config.EffectiveRateLimit(projCfg, env); rl != nil {
+			zones[rl.ZoneName] = *rl
+		}
+	}
+
+	if len(zones) == 0 {
+		return RemoveRateLimitConfig(reflowBasePath, projCfg.ProjectName)
+	}
+
+	data := RateLimitTemplateData{ProjectName: projCfg.ProjectName}
+	for _, zone := range zones {
+		data.Zones = append(data.Zones, zone)
+	}
+	sort.Slice(data.Zones, func(i, j int) bool { return data.Zones[i].ZoneName < data.Zones[j].ZoneName })
+	content, err := GenerateRateLimitConfig(data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(limitsDir, 0755); err != nil {
+		return fmt.Errorf("failed to ensure nginx limits dir %s exists: %w", limitsDir, err)
+	}
+	util.Log.Debugf("Writing Nginx rate limit config to: %s", limitsFilePath)
+	if err := os.WriteFile(limitsFilePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write nginx rate limit config %s: %w", limitsFilePath, err)
+	}
+	util.Log.Infof("Updated Nginx rate limit config file: %s", limitsFilePath)
+	return nil
+}
+
+// RemoveRateLimitConfig deletes a project's rate limit zones file, if present.
+func RemoveRateLimitConfig(reflowBasePath, projectName string) error {
+	limitsFilePath := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName, config.NginxLimitsDirName, rateLimitFileName(projectName))
+	if err := os.Remove(limitsFilePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove nginx rate limit config %s: %w", limitsFilePath, err)
+	}
+	return nil
+}
+
+// ProjectConfigFiles returns the paths of every Nginx file a project may have: the site config,
+// maintenance config and maintenance page of each environment, and its rate limit config.
+func ProjectConfigFiles(reflowBasePath, projectName string) []string {
+	confDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName)
+	var paths []string
+	for _, env := range []string{"test", "prod"} {
+		confPath, pagePath := maintenancePaths(reflowBasePath, projectName, env)
+		paths = append(paths, filepath.Join(confDir, fmt.Sprintf("%s.%s.conf", projectName, env)), confPath, pagePath)
+	}
+	return append(paths, filepath.Join(confDir, config.NginxLimitsDirName, rateLimitFileName(projectName)))
+}
+
+func rateLimitFileName(projectName string) string {
+	return fmt.Sprintf("ratelimit-%s.conf", projectName)
+}
+
 // WriteNginxPluginConfig writes the Nginx configuration to a file for a plugin.
 func WriteNginxPluginConfig(reflowBasePath, confFileName, content string) error {
 	confDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName)
@@ -203,6 +465,12 @@ func ReloadNginx(ctx context.Context) error {
 		return fmt.Errorf("nginx container '%s' is not running", containerName)
 	}
 
+	// SIGHUP with an invalid config is silently ignored by Nginx, so validate it first.
+	if err := testNginxConfig(ctx, containerName); err != nil {
+		util.Log.Errorf("Nginx rejected the new configuration: %v", err)
+		return err
+	}
+
 	// Short kill timeout
 	killCtx, killCancel := context.WithTimeout(ctx, 5*time.Second)
 	defer killCancel()
@@ -223,3 +491,19 @@ func ReloadNginx(ctx context.Context) error {
 	util.Log.Info("Nginx configuration reloaded successfully.")
 	return nil
 }
+
+// testNginxConfig runs 'nginx -t' inside the Nginx container and returns its output as an error
+// if the configuration is invalid.
+func testNginxConfig(ctx context.Context, containerName string) error {
+	testCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	output, exitCode, err := docker.ExecInContainer(testCtx, containerName, []string{"nginx", "-t"}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to run nginx config test: %w", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("nginx config test failed: %s", strings.TrimSpace(output))
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package nginx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"strings"
+	"time"
+)
+
+// Log types accepted by LogFilePaths.
+const (
+	LogTypeAccess = "access"
+	LogTypeError  = "error"
+	LogTypeAll    = "all"
+)
+
+const (
+	logPollInterval  = 500 * time.Millisecond
+	logTailBlockSize = 64 << 10
+)
+
+// LogFilePaths returns the paths of the Nginx log files of a project environment, of a plugin if
+// pluginName is set, or of the default server if neither is. The files are named as the
+// generated configs name them, in the mounted log directory: <project>.<env>.<type>.log,
+// plugin.<name>.<type>.log and default.<type>.log. logType is LogTypeAccess, LogTypeError or
+// LogTypeAll for both.
+func LogFilePaths(reflowBasePath, projectName, env, pluginName, logType string) ([]string, error) {
+	var prefix string
+	switch {
+	case projectName != "" && pluginName != "":
+		return nil, fmt.Errorf("a project and a plugin cannot be given together")
+	case projectName != "":
+		if env != "test" && env != "prod" {
+			return nil, fmt.Errorf("invalid environment '%s': must be 'test' or 'prod'", env)
+		}
+		if strings.ContainsAny(projectName, `/\`) {
+			return nil, fmt.Errorf("invalid project name '%s'", projectName)
+		}
+		prefix = fmt.Sprintf("%s.%s", projectName, env)
+	case pluginName != "":
+		if strings.ContainsAny(pluginName, `/\`) {
+			return nil, fmt.Errorf("invalid plugin name '%s'", pluginName)
+		}
+		prefix = "plugin." + pluginName
+	default:
+		prefix = "default"
+	}
+
+	var types []string
+	switch logType {
+	case LogTypeAccess, LogTypeError:
+		types = []string{logType}
+	case LogTypeAll, "":
+		types = []string{LogTypeAccess, LogTypeError}
+	default:
+		return nil, fmt.Errorf("invalid log type '%s': must be '%s', '%s' or '%s'", logType, LogTypeAccess, LogTypeError, LogTypeAll)
+	}
+
+	logDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxLogDirName)
+	paths := make([]string, 0, len(types))
+	for _, t := range types {
+		paths = append(paths, filepath.Join(logDir, fmt.Sprintf("%s.%s.log", prefix, t)))
+	}
+	return paths, nil
+}
+
+// logTail follows one log file.
+type logTail struct {
+	path    string
+	prefix  string // Prepended to each line when several files are shown
+	file    *os.File
+	reader  *bufio.Reader
+	offset  int64
+	partial string
+}
+
+// TailLogFiles writes the last lines of each log file to w, then, with follow, keeps writing lines
+// as they are appended until ctx is done. Files that are rotated or truncated are reopened from
+// their beginning, and files that don't exist yet are picked up once Nginx creates them. With
+// several files, each line is prefixed with the name of its file.
+func TailLogFiles(ctx context.Context, paths []string, lines int, follow bool, w io.Writer) error {
+	tails := make([]*logTail, 0, len(paths))
+	defer func() {
+		for _, t := range tails {
+			t.close()
+		}
+	}()
+
+	found := 0
+	for _, path := range paths {
+		t := &logTail{path: path}
+		if len(paths) > 1 {
+			t.prefix = "[" + filepath.Base(path) + "] "
+		}
+		tails = append(tails, t)
+
+		file, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				util.Log.Warnf("Nginx log file %s does not exist (yet).", path)
+				continue
+			}
+			return fmt.Errorf("failed to open nginx log %s: %w", path, err)
+		}
+		found++
+		lastLines, offset, err := readLastLines(file, lines)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to read nginx log %s: %w", path, err)
+		}
+		for _, line := range lastLines {
+			if _, err := fmt.Fprintf(w, "%s%s\n", t.prefix, line); err != nil {
+				file.Close()
+				return err
+			}
+		}
+		if !follow {
+			file.Close()
+			continue
+		}
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to read nginx log %s: %w", path, err)
+		}
+		t.file, t.reader, t.offset = file, bufio.NewReader(file), offset
+	}
+
+	if !follow {
+		if found == 0 {
+			return fmt.Errorf("no nginx log files found")
+		}
+		return nil
+	}
+
+	for {
+		for _, t := range tails {
+			if err := t.poll(w); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(logPollInterval):
+		}
+	}
+}
+
+// poll writes the complete lines appended to the file since the last poll, and reopens the file
+// if it was rotated or truncated, or opens it if it appeared.
+func (t *logTail) poll(w io.Writer) error {
+	if t.file == nil {
+		file, err := os.Open(t.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to open nginx log %s: %w", t.path, err)
+		}
+		t.file, t.reader, t.offset, t.partial = file, bufio.NewReader(file), 0, ""
+	}
+
+	for {
+		chunk, err := t.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read nginx log %s: %w", t.path, err)
+		}
+		t.offset += int64(len(chunk))
+		t.partial += chunk
+		if err == io.EOF {
+			break
+		}
+		if _, err := fmt.Fprintf(w, "%s%s", t.prefix, t.partial); err != nil {
+			return err
+		}
+		t.partial = ""
+	}
+
+	// At the end of the file for now. A file replaced at the path was rotated and a file shorter
+	// than what was read was truncated; either way, continue from the start of the current file.
+	openInfo, err := t.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat nginx log %s: %w", t.path, err)
+	}
+	pathInfo, err := os.Stat(t.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat nginx log %s: %w", t.path, err)
+	}
+	if pathInfo == nil || !os.SameFile(openInfo, pathInfo) {
+		t.close()
+		return nil
+	}
+	if openInfo.Size() < t.offset {
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind nginx log %s: %w", t.path, err)
+		}
+		t.reader.Reset(t.file)
+		t.offset, t.partial = 0, ""
+	}
+	return nil
+}
+
+func (t *logTail) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+// readLastLines returns the last n complete lines of file, reading it backwards in blocks so
+// large logs are not read in full, and the offset just past the last complete line.
+func readLastLines(file *os.File, n int) ([]string, int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	pos := info.Size()
+	var buf []byte
+	for pos > 0 && bytes.Count(buf, []byte{'\n'}) <= n {
+		readSize := min(int64(logTailBlockSize), pos)
+		pos -= readSize
+		chunk := make([]byte, readSize)
+		if _, err := file.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		buf = append(chunk, buf...)
+	}
+
+	end := bytes.LastIndexByte(buf, '\n') + 1
+	if end == 0 || n <= 0 {
+		return nil, pos + int64(end), nil
+	}
+	lines := strings.Split(string(buf[:end-1]), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, pos + int64(end), nil
+}
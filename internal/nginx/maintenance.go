@@ -0,0 +1,192 @@
+package nginx
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"text/template"
+)
+
+// Maintenance configs are named so they sort before every project's site config. Nginx includes
+// conf.d/*.conf in alphabetical order and, for a server_name defined twice on the same port, uses
+// the first server block, so the maintenance page replaces the site while both files exist.
+const maintenanceConfPrefix = "00-maintenance."
+
+const nginxMaintenanceTemplateContent = `
+{{- define "maintenance"}}
+    error_page 503 /{{.PageFileName}};
+
+    location = /{{.PageFileName}} {
+        root ` + config.NginxMaintenanceContainerPath + `;
+        add_header Retry-After 300 always;
+        add_header Cache-Control "no-store" always;
+        internal;
+    }
+
+    location / {
+        return 503;
+    }
+{{- end}}
+# Maintenance mode for {{.ProjectName}} - {{.Env}}
+# Takes precedence over {{.ProjectName}}.{{.Env}}.conf until 'reflow project maintenance' turns it off.
+server {
+    listen 80;
+    listen [::]:80;
+
+    server_name {{.Domain}};
+
+    # ACME HTTP-01 challenges, so certificates can still be renewed
+    location /.well-known/acme-challenge/ {
+        root ` + config.NginxAcmeWebrootContainerPath + `;
+    }
+{{template "maintenance" .}}
+
+    access_log /var/log/nginx/{{.ProjectName}}.{{.Env}}.access.log;
+    error_log /var/log/nginx/{{.ProjectName}}.{{.Env}}.error.log;
+}
+{{- if .TLS}}
+
+server {
+    listen 443 ssl;
+    listen [::]:443 ssl;
+    http2 on;
+
+    server_name {{.Domain}};
+
+    ssl_certificate ` + config.NginxCertsContainerPath + `/live/{{.Domain}}/fullchain.pem;
+    ssl_certificate_key ` + config.NginxCertsContainerPath + `/live/{{.Domain}}/privkey.pem;
+    ssl_protocols TLSv1.2 TLSv1.3;
+    ssl_session_cache shared:SSL:10m;
+{{template "maintenance" .}}
+
+    access_log /var/log/nginx/{{.ProjectName}}.{{.Env}}.access.log;
+    error_log /var/log/nginx/{{.ProjectName}}.{{.Env}}.error.log;
+}
+{{- end}}
+`
+
+// Built-in page served in maintenance mode when the project sets no maintenancePage.
+const defaultMaintenancePageContent = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Down for maintenance</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f5f5f5; color: #333; margin: 0; }
+  main { max-width: 36rem; margin: 20vh auto 0; padding: 0 1.5rem; text-align: center; }
+  h1 { font-size: 1.75rem; margin-bottom: 0.5rem; }
+  p { line-height: 1.5; color: #666; }
+</style>
+</head>
+<body>
+<main>
+  <h1>We'll be back shortly</h1>
+  <p>{{.Domain}} is down for scheduled maintenance. Please try again in a few minutes.</p>
+</main>
+</body>
+</html>
+`
+
+// MaintenanceTemplateData holds the data for rendering a maintenance mode config and page.
+type MaintenanceTemplateData struct {
+	ProjectName  string
+	Env          string
+	Domain       string
+	TLS          bool   // Also serve the page over HTTPS, using the certificate under the certs dir
+	PageFileName string // Set by WriteMaintenanceConfig
+}
+
+// GenerateMaintenanceConfig generates the Nginx configuration serving the maintenance page.
+func GenerateMaintenanceConfig(data MaintenanceTemplateData) (string, error) {
+	tmpl, err := template.New("nginx-maintenance").Parse(nginxMaintenanceTemplateContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse nginx maintenance template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute nginx maintenance template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// GenerateDefaultMaintenancePage renders the built-in maintenance page.
+func GenerateDefaultMaintenancePage(data MaintenanceTemplateData) ([]byte, error) {
+	tmpl, err := htmltemplate.New("maintenance-page").Parse(defaultMaintenancePageContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance page template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute maintenance page template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// maintenancePaths returns the maintenance config and page paths of a project environment.
+func maintenancePaths(reflowBasePath, projectName, env string) (confPath, pagePath string) {
+	confDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName)
+	confPath = filepath.Join(confDir, fmt.Sprintf("%s%s.%s.conf", maintenanceConfPrefix, projectName, env))
+	pagePath = filepath.Join(confDir, config.NginxMaintenanceDirName, fmt.Sprintf("%s.%s.html", projectName, env))
+	return confPath, pagePath
+}
+
+// MaintenanceEnabled reports whether a project environment has a maintenance config in place.
+func MaintenanceEnabled(reflowBasePath, projectName, env string) bool {
+	confPath, _ := maintenancePaths(reflowBasePath, projectName, env)
+	_, err := os.Stat(confPath)
+	return err == nil
+}
+
+// SnapshotMaintenanceConfig records the current maintenance config and page of a project environment.
+func SnapshotMaintenanceConfig(reflowBasePath, projectName, env string) (*ConfigSnapshot, error) {
+	confPath, pagePath := maintenancePaths(reflowBasePath, projectName, env)
+	return snapshotFiles([]string{confPath, pagePath})
+}
+
+// WriteMaintenanceConfig writes the maintenance page and the config serving it for a project
+// environment. It does not reload Nginx.
+func WriteMaintenanceConfig(reflowBasePath string, data MaintenanceTemplateData, page []byte) error {
+	confPath, pagePath := maintenancePaths(reflowBasePath, data.ProjectName, data.Env)
+	data.PageFileName = filepath.Base(pagePath)
+
+	// Only project names starting with "00-" followed by a character before 'm' sort first.
+	siteConfName := fmt.Sprintf("%s.%s.conf", data.ProjectName, data.Env)
+	if filepath.Base(confPath) >= siteConfName {
+		return fmt.Errorf("maintenance config %s would not take precedence over %s; rename the project to use maintenance mode", filepath.Base(confPath), siteConfName)
+	}
+
+	content, err := GenerateMaintenanceConfig(data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(pagePath), 0755); err != nil {
+		return fmt.Errorf("failed to ensure nginx maintenance dir %s exists: %w", filepath.Dir(pagePath), err)
+	}
+	if err := os.WriteFile(pagePath, page, 0644); err != nil {
+		return fmt.Errorf("failed to write maintenance page %s: %w", pagePath, err)
+	}
+	util.Log.Debugf("Writing Nginx maintenance config to: %s", confPath)
+	if err := os.WriteFile(confPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write nginx maintenance config %s: %w", confPath, err)
+	}
+	util.Log.Infof("Updated Nginx maintenance config file: %s", confPath)
+	return nil
+}
+
+// RemoveMaintenanceConfig deletes the maintenance config and page of a project environment, if
+// present. It does not reload Nginx.
+func RemoveMaintenanceConfig(reflowBasePath, projectName, env string) error {
+	confPath, pagePath := maintenancePaths(reflowBasePath, projectName, env)
+	for _, path := range []string{confPath, pagePath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove nginx maintenance file %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package nginx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/util"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// Reflow-generated configs name their upstreams reflow_<...>_upstream; anything else is left alone.
+	managedUpstreamPattern = regexp.MustCompile(`(?s)upstream\s+reflow_\S+_upstream\s*\{(.*?)\}`)
+	upstreamServerPattern  = regexp.MustCompile(`(?m)^\s*server\s+([^\s:;]+)`)
+)
+
+// ManagedConfig is a Reflow-generated Nginx site config and the containers it proxies to.
+type ManagedConfig struct {
+	FileName  string
+	Path      string
+	Upstreams []string // Container names from the config's upstream blocks
+}
+
+// ListManagedConfigs parses the conf.d directory and returns the configs generated by Reflow
+// for projects and plugins, identified by their reflow_*_upstream blocks.
+func ListManagedConfigs(reflowBasePath string) ([]ManagedConfig, error) {
+	confDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName)
+	entries, err := os.ReadDir(confDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read nginx conf dir %s: %w", confDir, err)
+	}
+
+	var managed []ManagedConfig
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".conf") {
+			continue
+		}
+		confPath := filepath.Join(confDir, entry.Name())
+		content, err := os.ReadFile(confPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read nginx config %s: %w", confPath, err)
+		}
+
+		blocks := managedUpstreamPattern.FindAllStringSubmatch(string(content), -1)
+		if len(blocks) == 0 {
+			continue
+		}
+		conf := ManagedConfig{FileName: entry.Name(), Path: confPath}
+		for _, block := range blocks {
+			for _, server := range upstreamServerPattern.FindAllStringSubmatch(block[1], -1) {
+				conf.Upstreams = append(conf.Upstreams, server[1])
+			}
+		}
+		managed = append(managed, conf)
+	}
+	return managed, nil
+}
+
+// FindOrphanedConfigs returns the managed configs none of whose upstream containers exist.
+// Stopped containers count as present, since 'project start' brings them back without
+// rewriting the config. projectName limits the check to that project's configs; empty checks all.
+func FindOrphanedConfigs(ctx context.Context, reflowBasePath, projectName string) ([]ManagedConfig, error) {
+	managed, err := ListManagedConfigs(reflowBasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := docker.FindContainersByLabels(ctx, map[string]string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed containers: %w", err)
+	}
+	existing := make(map[string]bool)
+	for _, c := range containers {
+		for _, name := range c.Names {
+			existing[strings.TrimPrefix(name, "/")] = true
+		}
+	}
+
+	var orphaned []ManagedConfig
+	for _, conf := range managed {
+		if projectName != "" && conf.FileName != projectName+".test.conf" && conf.FileName != projectName+".prod.conf" {
+			continue
+		}
+		alive := false
+		for _, upstream := range conf.Upstreams {
+			if existing[upstream] {
+				alive = true
+				break
+			}
+		}
+		if !alive {
+			orphaned = append(orphaned, conf)
+		}
+	}
+	sort.Slice(orphaned, func(i, j int) bool { return orphaned[i].FileName < orphaned[j].FileName })
+	return orphaned, nil
+}
+
+// PruneOrphanedConfigs removes the configs returned by FindOrphanedConfigs and reloads Nginx
+// if any were removed. With dryRun set, nothing is changed. Returns the orphaned configs.
+func PruneOrphanedConfigs(ctx context.Context, reflowBasePath, projectName string, dryRun bool) ([]ManagedConfig, error) {
+	orphaned, err := FindOrphanedConfigs(ctx, reflowBasePath, projectName)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || len(orphaned) == 0 {
+		return orphaned, nil
+	}
+
+	for _, conf := range orphaned {
+		if err := os.Remove(conf.Path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove orphaned nginx config %s: %w", conf.Path, err)
+		}
+		util.Log.Infof("Removed orphaned Nginx config %s (upstreams: %s)", conf.FileName, strings.Join(conf.Upstreams, ", "))
+	}
+	if err := ReloadNginx(ctx); err != nil {
+		return orphaned, fmt.Errorf("removed orphaned configs but failed to reload nginx: %w", err)
+	}
+	return orphaned, nil
+}
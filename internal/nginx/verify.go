@@ -0,0 +1,113 @@
+package nginx
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/util"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitHeader is the response header an app can set (e.g. from its REFLOW_COMMIT env var) so
+// VerifySite can confirm Nginx routes to the deployed commit.
+const CommitHeader = "X-Reflow-Commit"
+
+const (
+	verifyAttempts = 5
+	verifyInterval = 2 * time.Second
+	verifyTimeout  = 5 * time.Second // Per request
+	probeTimeout   = 2 * time.Second
+)
+
+var (
+	responseStatusPattern = regexp.MustCompile(`HTTP/\d(?:\.\d)?\s+(\d{3})`)
+	commitHeaderPattern   = regexp.MustCompile(`(?im)^\s*` + regexp.QuoteMeta(CommitHeader) + `:\s*(\S+)`)
+)
+
+// VerifySite requests a site through the Nginx container, as a client of domain would, and
+// checks that it answers without a 5xx status. If the response carries CommitHeader, it must
+// name expectedCommit (a short hash is enough). Nginx may briefly keep serving old workers after
+// a reload, so the request is retried a few times before giving up.
+func VerifySite(ctx context.Context, domain string, tls bool, expectedCommit string) error {
+	url := "http://localhost/"
+	if tls {
+		url = "https://localhost/"
+	}
+	// Busybox wget in the Alpine image prints the response headers with -S, also for error statuses.
+	cmd := []string{"wget", "-S", "-q", "-O", "/dev/null", "-T", strconv.Itoa(int(verifyTimeout.Seconds())), "--no-check-certificate", "--header", "Host: " + domain, url}
+
+	var lastErr error
+	for attempt := 1; attempt <= verifyAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("site verification cancelled: %w", ctx.Err())
+			case <-time.After(verifyInterval):
+			}
+		}
+
+		execCtx, cancel := context.WithTimeout(ctx, verifyTimeout+5*time.Second)
+		output, _, err := docker.ExecInContainer(execCtx, config.ReflowNginxContainerName, cmd, nil)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to request %s through nginx: %w", domain, err)
+		}
+		lastErr = checkSiteResponse(output, expectedCommit)
+		if lastErr == nil {
+			util.Log.Infof("Verified %s is served through Nginx.", domain)
+			return nil
+		}
+		util.Log.Debugf("Site verification attempt %d/%d for %s failed: %v", attempt, verifyAttempts, domain, lastErr)
+	}
+	return fmt.Errorf("%s is not served correctly through nginx: %w", domain, lastErr)
+}
+
+// checkSiteResponse checks the headers wget printed for a request.
+func checkSiteResponse(output, expectedCommit string) error {
+	statuses := responseStatusPattern.FindAllStringSubmatch(output, -1)
+	if len(statuses) == 0 {
+		return fmt.Errorf("no response: %s", strings.TrimSpace(output))
+	}
+	status, _ := strconv.Atoi(statuses[len(statuses)-1][1])
+	if status >= 500 {
+		return fmt.Errorf("got HTTP %d", status)
+	}
+
+	match := commitHeaderPattern.FindStringSubmatch(output)
+	if match == nil || expectedCommit == "" {
+		return nil
+	}
+	served := strings.ToLower(match[1])
+	if len(served) < 7 || !strings.HasPrefix(strings.ToLower(expectedCommit), served) {
+		return fmt.Errorf("%s is %s, expected %s", CommitHeader, match[1], expectedCommit[:min(7, len(expectedCommit))])
+	}
+	return nil
+}
+
+// ProbeSite makes a single request for domain through the Nginx container, bounded by a short
+// timeout, and reports whether it was answered without a 5xx status. Unlike VerifySite it does
+// not retry, so it suits listing many sites at once.
+func ProbeSite(ctx context.Context, domain string, tls bool) bool {
+	url := "http://localhost/"
+	if tls {
+		url = "https://localhost/"
+	}
+	cmd := []string{"wget", "-S", "-q", "--spider", "-T", strconv.Itoa(int(probeTimeout.Seconds())), "--no-check-certificate", "--header", "Host: " + domain, url}
+
+	execCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	output, _, err := docker.ExecInContainer(execCtx, config.ReflowNginxContainerName, cmd, nil)
+	if err != nil {
+		util.Log.Debugf("Failed to probe %s through nginx: %v", domain, err)
+		return false
+	}
+	if err := checkSiteResponse(output, ""); err != nil {
+		util.Log.Debugf("%s is not reachable through nginx: %v", domain, err)
+		return false
+	}
+	return true
+}
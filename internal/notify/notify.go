@@ -0,0 +1,290 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TypeSlack   = "slack"
+	TypeDiscord = "discord"
+	TypeGeneric = "generic"
+
+	sendTimeout     = 10 * time.Second
+	retryDelay      = 2 * time.Second  // A failed notification is retried once after this delay
+	dispatchTimeout = 15 * time.Second // Limit for all webhooks of one event together
+
+	colorSuccess   = 0x2EB67D
+	colorFailure   = 0xE01E5A
+	maxErrorLength = 1000 // Discord embed fields hold at most 1024 characters
+)
+
+// Notifier delivers a notification for a completed deployment event. deploymentURL may be empty.
+type Notifier interface {
+	Notify(ctx context.Context, event *config.DeploymentEvent, deploymentURL string) error
+}
+
+// SlackNotifier posts to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+}
+
+// DiscordNotifier posts to a Discord webhook.
+type DiscordNotifier struct {
+	WebhookURL string
+}
+
+// GenericNotifier posts the raw deployment event JSON to a webhook.
+type GenericNotifier struct {
+	WebhookURL string
+}
+
+// Dispatch sends a notification for a completed deployment event to every configured webhook
+// whose event and project filters match. The project's own notifications list is used instead
+// of the global one when it has one. deploymentURL is linked in the message when set.
+// Webhooks are sent concurrently and each gets one retry, all within dispatchTimeout, so slow
+// endpoints delay the caller by at most that long. Errors are logged and never returned, so a
+// failed notification cannot fail a deployment.
+func Dispatch(reflowBasePath string, event *config.DeploymentEvent, deploymentURL string) {
+	notifications := projectNotifications(reflowBasePath, event.ProjectName)
+	if notifications == nil {
+		globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+		if err != nil {
+			util.Log.Debugf("Could not load global config for notifications: %v", err)
+			return
+		}
+		notifications = globalCfg.Notifications
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dispatchTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	eventName := EventName(event)
+	for _, notifyCfg := range notifications {
+		if notifyCfg.WebhookURL == "" || !matchesFilter(notifyCfg.Events, eventName) || !matchesFilter(notifyCfg.ProjectFilter, event.ProjectName) {
+			continue
+		}
+		// Webhook URLs embed their access token, so keep them out of all log output.
+		util.RegisterSecret(notifyCfg.WebhookURL)
+		notifier, err := NewNotifier(notifyCfg)
+		if err != nil {
+			util.Log.Warnf("Skipping deployment notification: %v", err)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := notifyWithRetry(ctx, notifier, event, deploymentURL); err != nil {
+				util.Log.Warnf("Failed to send %s deployment notification to %s: %v", notifyCfg.Type, webhookHost(notifyCfg.WebhookURL), err)
+			} else {
+				util.Log.Debugf("Sent %s deployment notification '%s' for project '%s' to %s", notifyCfg.Type, eventName, event.ProjectName, webhookHost(notifyCfg.WebhookURL))
+			}
+		}()
+	}
+}
+
+// projectNotifications returns the project's own notifications list, or nil if it has none.
+func projectNotifications(reflowBasePath, projectName string) []config.NotificationConfig {
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		util.Log.Debugf("Could not load project config for notifications, using global settings: %v", err)
+		return nil
+	}
+	if len(projCfg.Notifications) == 0 {
+		return nil
+	}
+	return projCfg.Notifications
+}
+
+// notifyWithRetry sends a notification, retrying once after retryDelay if it fails. Each
+// attempt has its own sendTimeout, and neither outlasts ctx.
+func notifyWithRetry(ctx context.Context, notifier Notifier, event *config.DeploymentEvent, deploymentURL string) error {
+	var err error
+	for attempt := 1; attempt <= 2; attempt++ {
+		if attempt > 1 {
+			util.Log.Debugf("Retrying deployment notification after error: %v", err)
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(retryDelay):
+			}
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err = notifier.Notify(attemptCtx, event, deploymentURL)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// webhookHost returns the host of a webhook URL for log messages, without its secret path.
+func webhookHost(webhookURL string) string {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Host == "" {
+		return "webhook"
+	}
+	return parsed.Host
+}
+
+// NewNotifier returns the Notifier for a notification config entry.
+func NewNotifier(notifyCfg config.NotificationConfig) (Notifier, error) {
+	switch strings.ToLower(notifyCfg.Type) {
+	case TypeSlack:
+		return &SlackNotifier{WebhookURL: notifyCfg.WebhookURL}, nil
+	case TypeDiscord:
+		return &DiscordNotifier{WebhookURL: notifyCfg.WebhookURL}, nil
+	case TypeGeneric, "":
+		return &GenericNotifier{WebhookURL: notifyCfg.WebhookURL}, nil
+	default:
+		return nil, fmt.Errorf("unsupported notification type '%s' (expected slack, discord or generic)", notifyCfg.Type)
+	}
+}
+
+// EventName returns the name notification filters match against, e.g. "deploy.success".
+func EventName(event *config.DeploymentEvent) string {
+	return fmt.Sprintf("%s.%s", event.EventType, event.Outcome)
+}
+
+// matchesFilter reports whether value is in filter; an empty filter matches everything.
+func matchesFilter(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if strings.EqualFold(strings.TrimSpace(f), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify implements Notifier.
+func (n *SlackNotifier) Notify(ctx context.Context, event *config.DeploymentEvent, deploymentURL string) error {
+	fields := []map[string]any{
+		{"title": "Project", "value": event.ProjectName, "short": true},
+		{"title": "Environment", "value": event.Environment, "short": true},
+		{"title": "Commit", "value": shortCommit(event), "short": true},
+		{"title": "Duration", "value": formatDuration(event).String(), "short": true},
+	}
+	if deploymentURL != "" {
+		fields = append(fields, map[string]any{"title": "URL", "value": fmt.Sprintf("<%s|%s>", deploymentURL, deploymentURL), "short": false})
+	}
+	if event.Outcome != "success" && event.ErrorMessage != "" {
+		fields = append(fields, map[string]any{"title": "Error", "value": errorDetail(event), "short": false})
+	}
+
+	color := "good"
+	if event.Outcome != "success" {
+		color = "danger"
+	}
+	return postJSON(ctx, n.WebhookURL, map[string]any{
+		"text": formatMessage(event),
+		"attachments": []map[string]any{{
+			"color":  color,
+			"fields": fields,
+		}},
+	})
+}
+
+// Notify implements Notifier.
+func (n *DiscordNotifier) Notify(ctx context.Context, event *config.DeploymentEvent, deploymentURL string) error {
+	fields := []map[string]any{
+		{"name": "Project", "value": event.ProjectName, "inline": true},
+		{"name": "Environment", "value": event.Environment, "inline": true},
+		{"name": "Commit", "value": shortCommit(event), "inline": true},
+		{"name": "Duration", "value": formatDuration(event).String(), "inline": true},
+	}
+	if event.Outcome != "success" && event.ErrorMessage != "" {
+		fields = append(fields, map[string]any{"name": "Error", "value": errorDetail(event)})
+	}
+
+	color := colorSuccess
+	if event.Outcome != "success" {
+		color = colorFailure
+	}
+	embed := map[string]any{
+		"title":  formatMessage(event),
+		"color":  color,
+		"fields": fields,
+	}
+	if deploymentURL != "" {
+		embed["url"] = deploymentURL
+	}
+	return postJSON(ctx, n.WebhookURL, map[string]any{"embeds": []map[string]any{embed}})
+}
+
+// Notify implements Notifier.
+func (n *GenericNotifier) Notify(ctx context.Context, event *config.DeploymentEvent, _ string) error {
+	return postJSON(ctx, n.WebhookURL, event)
+}
+
+// postJSON posts payload as JSON and treats any non-2xx response as an error.
+func postJSON(ctx context.Context, webhookURL string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to build notification payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// formatMessage builds a short human-readable summary of a deployment event.
+func formatMessage(event *config.DeploymentEvent) string {
+	action := "Deployment"
+	if event.EventType == "approve" {
+		action = "Promotion"
+	}
+
+	if event.Outcome == "success" {
+		return fmt.Sprintf("✅ %s of '%s' to %s succeeded", action, event.ProjectName, event.Environment)
+	}
+	return fmt.Sprintf("❌ %s of '%s' to %s failed", action, event.ProjectName, event.Environment)
+}
+
+// errorDetail returns the event's error message, truncated to fit chat message field limits.
+func errorDetail(event *config.DeploymentEvent) string {
+	if len(event.ErrorMessage) <= maxErrorLength {
+		return event.ErrorMessage
+	}
+	return event.ErrorMessage[:maxErrorLength] + "…"
+}
+
+func shortCommit(event *config.DeploymentEvent) string {
+	if len(event.CommitSHA) >= 7 {
+		return event.CommitSHA[:7]
+	}
+	return "unknown commit"
+}
+
+func formatDuration(event *config.DeploymentEvent) time.Duration {
+	return time.Duration(event.DurationMs) * time.Millisecond
+}
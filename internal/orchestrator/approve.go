@@ -4,20 +4,50 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
-	"reflow/internal/app"
 	"reflow/internal/config"
 	"reflow/internal/deployment"
 	"reflow/internal/docker"
+	internalGit "reflow/internal/git"
 	"reflow/internal/nginx"
+	"reflow/internal/notify"
 	"reflow/internal/util"
 	"strings"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
-// ApproveProd promotes a project from 'test' to 'prod' environment.
-func ApproveProd(ctx context.Context, reflowBasePath, projectName string) (err error) {
+// ApproveProd promotes a project from 'test' to 'prod' environment. If commitIsh is set, the
+// approval is refused unless it resolves to the commit currently active in 'test', so a newer
+// deployment that landed on test in the meantime cannot reach prod unnoticed.
+func ApproveProd(ctx context.Context, reflowBasePath, projectName, commitIsh string) (err error) {
+	// Notifications go out once the lock is released, so slow webhooks never hold up the next
+	// deployment of the project.
+	var notifyEvent *config.DeploymentEvent
+	var notifyURL string
+	defer func() {
+		if notifyEvent != nil {
+			notify.Dispatch(reflowBasePath, notifyEvent, notifyURL)
+		}
+	}()
+
+	unlock, err := lockProject(projectName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var progress *deployment.Progress
+	if !IsDryRun(ctx) {
+		progress = deployment.StartProgress(reflowBasePath, projectName, fmt.Sprintf("approval of project '%s' to 'prod'", projectName))
+		progress.AddFields(logrus.Fields{"project": projectName, "env": "prod"})
+		defer func() { progress.Finish(err) }()
+	}
+
 	startTime := time.Now()
 	var approvedCommitHash string
+	var deploymentURL string
+	var prodActiveSlot, prodInactiveSlot string
 
 	initialEvent := &config.DeploymentEvent{
 		Timestamp:   startTime,
@@ -25,10 +55,14 @@ func ApproveProd(ctx context.Context, reflowBasePath, projectName string) (err e
 		ProjectName: projectName,
 		Environment: "prod",
 		Outcome:     "started",
-		TriggeredBy: "cli/api",
+		TriggeredBy: triggeredBy(ctx),
 	}
 
+	dryRun := IsDryRun(ctx)
 	defer func() {
+		if dryRun {
+			return // A dry run leaves no trace in the history and sends no notifications
+		}
 		duration := time.Since(startTime)
 		outcome := "success"
 		errMsg := ""
@@ -42,38 +76,33 @@ func ApproveProd(ctx context.Context, reflowBasePath, projectName string) (err e
 			ProjectName:  projectName,
 			Environment:  "prod",
 			CommitSHA:    approvedCommitHash,
+			Slot:         prodInactiveSlot,
 			Outcome:      outcome,
 			ErrorMessage: errMsg,
 			DurationMs:   duration.Milliseconds(),
-			TriggeredBy:  "cli/api",
+			TriggeredBy:  triggeredBy(ctx),
 		}
 		deployment.LogEvent(reflowBasePath, projectName, finalEvent)
+		notifyEvent, notifyURL = finalEvent, deploymentURL
 	}()
 
-	util.Log.Infof("Starting approval process for project '%s' to 'prod' environment...", projectName)
-	projectBasePath := config.GetProjectBasePath(reflowBasePath, projectName)
-	repoPath := filepath.Join(projectBasePath, config.RepoDirName)
+	if dryRun {
+		util.Log.Infof("Starting DRY RUN of approval for project '%s' to 'prod' environment (no changes will be made)...", projectName)
+	} else {
+		progress.Infof("Starting approval process for project '%s' to 'prod' environment...", projectName)
+	}
 
 	var projCfg *config.ProjectConfig
 	var projState *config.ProjectState
 	var globalCfg *config.GlobalConfig
 	var imageTag string
-	var prodActiveSlot, prodInactiveSlot string
-	var newContainerID string
-	var containerName string
+	var newContainerIDs []string
+	var containerNames []string
 
 	defer func() {
-		if err != nil && newContainerID != "" {
+		if err != nil && len(newContainerIDs) > 0 {
 			util.Log.Errorf("Approval failed: %v", err)
-			util.Log.Warnf("Attempting simple rollback: stopping and removing newly started container %s...", newContainerID[:12])
-			cleanupCtx := context.Background()
-			_ = docker.StopContainer(cleanupCtx, newContainerID, nil)
-			rmErr := docker.RemoveContainer(cleanupCtx, newContainerID)
-			if rmErr != nil {
-				util.Log.Errorf("Rollback cleanup failed: Could not remove container %s: %v", newContainerID[:12], rmErr)
-			} else {
-				util.Log.Infof("Rollback cleanup: Removed container %s", newContainerID[:12])
-			}
+			rollbackContainers(newContainerIDs)
 		}
 	}()
 
@@ -89,7 +118,7 @@ func ApproveProd(ctx context.Context, reflowBasePath, projectName string) (err e
 	}
 	globalCfg, err = config.LoadGlobalConfig(reflowBasePath)
 	if err != nil {
-		util.Log.Warnf("Could not load global config: %v", err)
+		progress.Warnf("Could not load global config: %v", err)
 		globalCfg = &config.GlobalConfig{}
 	}
 
@@ -99,11 +128,27 @@ func ApproveProd(ctx context.Context, reflowBasePath, projectName string) (err e
 		return fmt.Errorf("no active deployment found in 'test' environment for project '%s' to approve", projectName)
 	}
 
+	repoPath := filepath.Join(config.GetProjectBasePath(reflowBasePath, projectName), config.RepoDirName)
+	if commitIsh != "" {
+		requestedHash, resolveErr := resolveApprovalCommit(projCfg, repoPath, commitIsh, projState.Test.ActiveCommit)
+		if resolveErr != nil {
+			return resolveErr
+		}
+		if requestedHash != projState.Test.ActiveCommit {
+			return fmt.Errorf("test now runs %s, you asked to approve %s (%s); deploy it to test first or approve the current commit", shortCommit(projState.Test.ActiveCommit), shortCommit(requestedHash), commitIsh)
+		}
+	}
 	approvedCommitHash = projState.Test.ActiveCommit
-	util.Log.Infof("Approving commit %s currently active in 'test' (slot: %s)", approvedCommitHash[:7], projState.Test.ActiveSlot)
+	progress.AddFields(logrus.Fields{"commit": approvedCommitHash})
+	progress.Infof("Approving commit %s currently active in 'test' (slot: %s)", approvedCommitHash[:7], projState.Test.ActiveSlot)
+	if err = checkRequiredEnv(reflowBasePath, projCfg, "prod", approvedCommitHash); err != nil {
+		return err
+	}
 
-	initialEvent.CommitSHA = approvedCommitHash
-	deployment.LogEvent(reflowBasePath, projectName, initialEvent)
+	if !dryRun {
+		initialEvent.CommitSHA = approvedCommitHash
+		deployment.LogEvent(reflowBasePath, projectName, initialEvent)
+	}
 
 	// --- 3. Identify Prod Slots ---
 	util.Log.Debug("Identifying prod deployment slots...")
@@ -115,11 +160,11 @@ func ApproveProd(ctx context.Context, reflowBasePath, projectName string) (err e
 		prodInactiveSlot = "blue"
 	}
 
-	util.Log.Infof("Targeting prod inactive slot: %s (Active slot: %s)", prodInactiveSlot, prodActiveSlot)
+	progress.Infof("Targeting prod inactive slot: %s (Active slot: %s)", prodInactiveSlot, prodActiveSlot)
 
 	// --- 4. Find Docker Image ---
 	imageTag = fmt.Sprintf("%s:%s", strings.ToLower(projectName), approvedCommitHash)
-	util.Log.Infof("Verifying required image exists: %s", imageTag)
+	progress.Infof("Verifying required image exists: %s", imageTag)
 
 	existingImage, err := docker.FindImage(ctx, imageTag)
 	if err != nil {
@@ -127,12 +172,28 @@ func ApproveProd(ctx context.Context, reflowBasePath, projectName string) (err e
 	}
 
 	if existingImage == nil {
-		return fmt.Errorf("approved image %s not found locally. Was the 'test' deployment successful", imageTag)
+		if globalCfg.Registry.URL == "" {
+			return fmt.Errorf("approved image %s not found locally. Was the 'test' deployment successful", imageTag)
+		}
+		registryImage := docker.RegistryImageName(globalCfg.Registry.URL, projectName, approvedCommitHash)
+		if dryRun {
+			util.Log.Infof("[dry run] Approved image %s not found locally; would pull %s from the registry", imageTag, registryImage)
+		} else {
+			progress.Infof("Approved image %s not found locally, pulling %s from the registry...", imageTag, registryImage)
+			if err = pullImageFromRegistry(ctx, globalCfg, projectName, imageTag, approvedCommitHash); err != nil {
+				return fmt.Errorf("approved image %s not found locally and could not be pulled from the registry: %w", imageTag, err)
+			}
+		}
+	} else {
+		util.Log.Debugf("Found approved image %s (ID: %s)", imageTag, existingImage.ID)
+	}
+
+	if dryRun {
+		return dryRunSwitchSlot(ctx, reflowBasePath, projCfg, globalCfg, "prod", projState.Prod, prodInactiveSlot, imageTag, approvedCommitHash)
 	}
-	util.Log.Debugf("Found approved image %s (ID: %s)", imageTag, existingImage.ID)
 
 	// --- 5. Stop/Remove Old Inactive Prod Container ---
-	util.Log.Infof("Cleaning up previous prod inactive slot '%s' container if exists...", prodInactiveSlot)
+	progress.Infof("Cleaning up previous prod inactive slot '%s' container if exists...", prodInactiveSlot)
 	oldProdLabels := map[string]string{docker.LabelProject: projectName, docker.LabelEnvironment: "prod", docker.LabelSlot: prodInactiveSlot}
 	oldProdContainers, findErr := docker.FindContainersByLabels(ctx, oldProdLabels)
 	if findErr != nil {
@@ -145,111 +206,63 @@ func ApproveProd(ctx context.Context, reflowBasePath, projectName string) (err e
 		}
 	}
 
-	// --- 6. Start New Prod Container ---
-	containerName = fmt.Sprintf("%s-prod-%s-%s", strings.ToLower(projectName), prodInactiveSlot, approvedCommitHash[:7])
-	util.Log.Infof("Starting new prod container '%s' for slot '%s'...", containerName, prodInactiveSlot)
-	envFilePath := ""
-	if projCfg.Environments["prod"].EnvFile != "" {
-		envFilePath = filepath.Join(repoPath, projCfg.Environments["prod"].EnvFile)
-	}
-
-	util.Log.Debugf("Loading environment variables from file: %s", envFilePath)
-	envVars, err := util.LoadEnvFile(envFilePath)
+	// --- 6. Start New Prod Containers ---
+	replicas := config.EffectiveReplicas(projCfg, "prod")
+	progress.Infof("Starting %d new prod container(s) for slot '%s'...", replicas, prodInactiveSlot)
+	runOptions, envFile, err := slotRunOptions(reflowBasePath, imageTag, projCfg, "prod", prodInactiveSlot, approvedCommitHash)
 	if err != nil {
-		return fmt.Errorf("failed to load prod environment variables: %w", err)
-	}
-
-	envVars = append(envVars, fmt.Sprintf("PORT=%d", projCfg.AppPort))
-	newProdLabels := map[string]string{
-		docker.LabelManaged:     "true",
-		docker.LabelProject:     projectName,
-		docker.LabelEnvironment: "prod",
-		docker.LabelSlot:        prodInactiveSlot,
-		docker.LabelCommit:      approvedCommitHash,
-	}
-
-	runOptions := docker.ContainerRunOptions{
-		ImageName:     imageTag,
-		ContainerName: containerName,
-		NetworkName:   config.ReflowNetworkName,
-		Labels:        newProdLabels,
-		EnvVars:       envVars,
-		AppPort:       projCfg.AppPort,
-		RestartPolicy: "unless-stopped",
+		return err
 	}
 
-	newContainerID, err = docker.RunContainer(ctx, runOptions)
+	containerNames, err = startReplicas(ctx, runOptions, projCfg, "prod", prodInactiveSlot, approvedCommitHash, replicas, &newContainerIDs)
 	if err != nil {
 		return fmt.Errorf("failed to run new prod container: %w", err)
 	}
 
-	util.Log.Infof("New prod container started: %s (ID: %s)", containerName, newContainerID[:12])
-
 	// --- 7. Health Check ---
-	healthTimeout := 60 * time.Second
-	healthInterval := 5 * time.Second
-	healthCheckStartTime := time.Now()
-	isHealthy := false
-
-	util.Log.Infof("Performing health check via TCP connection from Nginx container (timeout %v)...", healthTimeout)
-
-	for time.Since(healthCheckStartTime) < healthTimeout {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("health check cancelled: %w", ctx.Err())
-		default:
-		}
-
-		util.Log.Debugf("Polling health for %s...", containerName)
-		healthy, checkErr := app.CheckTcpHealthFromNginx(ctx, containerName, projCfg.AppPort)
-
-		if checkErr != nil {
-			util.Log.Warnf("Health check poll failed for %s: %v", containerName, checkErr)
-		} else if healthy {
-			isHealthy = true
-			util.Log.Infof("Prod container '%s' passed health check after %v.", containerName, time.Since(healthCheckStartTime))
-			break
-		} else {
-			util.Log.Debugf("Prod container '%s' not healthy yet, retrying in %v...", containerName, healthInterval)
-		}
-
-		select {
-		case <-time.After(healthInterval):
-		case <-ctx.Done():
-			return fmt.Errorf("health check cancelled while waiting for interval: %w", ctx.Err())
+	for _, containerName := range containerNames {
+		if err = waitForHealthy(ctx, containerName, projCfg.AppPort, progress); err != nil {
+			return err
 		}
 	}
-
-	if !isHealthy {
-		err = fmt.Errorf("prod container '%s' failed health check: timed out after %v", containerName, healthTimeout)
+	if err = runSwitchHooks(ctx, "preSwitch", projCfg.Hooks.PreSwitch, repoPath, projCfg, "prod", approvedCommitHash, containerNames); err != nil {
 		return err
 	}
 
 	// --- 8. Update Nginx for Prod ---
-	util.Log.Info("Updating Nginx configuration for prod environment...")
+	progress.Info("Updating Nginx configuration for prod environment...")
 	prodDomain, err := config.GetEffectiveDomain(globalCfg, projCfg, "prod")
 	if err != nil {
 		return fmt.Errorf("failed to determine prod domain for nginx config: %w", err)
 	}
-	nginxData := nginx.TemplateData{ProjectName: projectName, Env: "prod", Slot: prodInactiveSlot, ContainerName: containerName, Domain: prodDomain, AppPort: projCfg.AppPort} // Uses projCfg correctly
+	nginxData := nginx.TemplateData{ProjectName: projectName, Env: "prod", Slot: prodInactiveSlot, ContainerNames: containerNames, Domain: prodDomain, AppPort: projCfg.AppPort, TLS: tlsEnabled(reflowBasePath, projCfg, "prod", prodDomain), RateLimit: config.EffectiveRateLimit(projCfg, "prod"), ExtraLocations: projCfg.Environments["prod"].ExtraLocations}
 	nginxConfContent, err := nginx.GenerateNginxConfig(nginxData)
 	if err != nil {
 		return fmt.Errorf("failed to generate prod nginx config: %w", err)
 	}
-	err = nginx.WriteNginxConfig(reflowBasePath, projectName, "prod", nginxConfContent)
-	if err != nil {
-		return fmt.Errorf("failed to write prod nginx config: %w", err)
+	if err = applyNginxConfig(ctx, reflowBasePath, projCfg, "prod", nginxConfContent, &siteCheck{Domain: prodDomain, TLS: nginxData.TLS, Commit: approvedCommitHash}); err != nil {
+		return fmt.Errorf("failed to update nginx for prod deployment: %w", err)
 	}
-	if err = nginx.ReloadNginx(ctx); err != nil {
-		return fmt.Errorf("failed to reload nginx for prod deployment: %w", err)
+	progress.Info("Nginx reloaded, prod traffic switched to new container(s).")
+	if hookErr := runSwitchHooks(ctx, "postSwitch", projCfg.Hooks.PostSwitch, repoPath, projCfg, "prod", approvedCommitHash, containerNames); hookErr != nil {
+		progress.Warnf("Post-switch hook failed, the deployment stays live: %v", hookErr)
+	}
+
+	if projCfg.AutoTLS {
+		progress.Infof("Ensuring TLS certificate for %s (autoTLS)...", prodDomain)
+		if tlsErr := provisionTLS(ctx, reflowBasePath, nginxData); tlsErr != nil {
+			progress.Warnf("Could not provision TLS certificate, prod stays on its current protocol: %v", tlsErr)
+		}
 	}
-	util.Log.Info("Nginx reloaded, prod traffic switched to new container.")
 
 	// --- 9. Update State for Prod ---
-	util.Log.Info("Updating deployment state for prod...")
+	progress.Info("Updating deployment state for prod...")
 	projState.Prod.ActiveSlot = prodInactiveSlot
 	projState.Prod.ActiveCommit = approvedCommitHash
 	projState.Prod.PendingCommit = ""
+	projState.Prod.LastImageTag = imageTag
+	projState.Prod.EnvFileHash = envFile.Hash
+	projState.Prod.Replicas = len(containerNames)
 	if prodInactiveSlot == "blue" {
 		projState.Prod.InactiveSlot = "green"
 	} else {
@@ -259,24 +272,47 @@ func ApproveProd(ctx context.Context, reflowBasePath, projectName string) (err e
 		return fmt.Errorf("CRITICAL: Promotion successful, but failed to save updated prod state: %w", err)
 	}
 
-	util.Log.Info("-----------------------------------------------------")
-	util.Log.Infof("✅ Promotion of project '%s' to 'prod' environment successful!", projectName)
-	util.Log.Infof("   Commit:  %s (%s)", approvedCommitHash, approvedCommitHash[:7])
-	util.Log.Infof("   Slot:    %s", prodInactiveSlot)
+	// --- 10. Prune Old Images ---
+	autoPruneImages(ctx, globalCfg, projCfg, projState)
+
+	progress.Info("-----------------------------------------------------")
+	progress.Infof("✅ Promotion of project '%s' to 'prod' environment successful!", projectName)
+	progress.Infof("   Commit:  %s (%s)", approvedCommitHash, approvedCommitHash[:7])
+	progress.Infof("   Slot:    %s", prodInactiveSlot)
+	progress.Infof("   Replicas: %d", len(containerNames))
+	progress.Infof("   Env File: %s", envFile)
 
 	prodDomain, domainErr := config.GetEffectiveDomain(globalCfg, projCfg, "prod")
 	if domainErr == nil {
-		accessURL := fmt.Sprintf("%s", prodDomain)
-		util.Log.Infof("   URL:     %s (Ensure DNS points to server IP!)", accessURL)
+		deploymentURL = accessURL(reflowBasePath, projCfg, "prod", prodDomain)
+		progress.Infof("   URL:     %s (Ensure DNS points to server IP!)", deploymentURL)
 	} else {
-		util.Log.Warnf("   URL:     Could not determine URL: %v", domainErr)
+		progress.Warnf("   URL:     Could not determine URL: %v", domainErr)
 	}
 
-	util.Log.Info(" ")
-	util.Log.Info("Next steps:")
-	util.Log.Infof("  - Check status:  ./t project status %s", projectName)
-	util.Log.Infof("  - View logs:     ./t project logs %s --env prod -f", projectName)
-	util.Log.Info("-----------------------------------------------------")
+	progress.Info(" ")
+	progress.Info("Next steps:")
+	progress.Infof("  - Check status:  ./t project status %s", projectName)
+	progress.Infof("  - View logs:     ./t project logs %s --env prod -f", projectName)
+	progress.Info("-----------------------------------------------------")
 
 	return nil
 }
+
+// resolveApprovalCommit resolves the commit-ish passed to ApproveProd against the project's
+// clone, without fetching. A prefix of the commit active in test (e.g. an abbreviated hash, or a
+// content hash of a local-source project) matches it directly.
+func resolveApprovalCommit(projCfg *config.ProjectConfig, repoPath, commitIsh, testCommit string) (string, error) {
+	if len(commitIsh) >= 4 && strings.HasPrefix(testCommit, strings.ToLower(commitIsh)) {
+		return testCommit, nil
+	}
+	if projCfg.SourceType == config.SourceTypeLocal {
+		return commitIsh, nil // Local sources have no refs to resolve
+	}
+
+	hash, err := internalGit.ResolveRevision(repoPath, commitIsh)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve '%s' to a commit: %w", commitIsh, err)
+	}
+	return hash.String(), nil
+}
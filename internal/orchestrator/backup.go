@@ -0,0 +1,304 @@
+package orchestrator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/nginx"
+	"reflow/internal/project"
+	"reflow/internal/util"
+	"slices"
+	"strings"
+	"time"
+)
+
+// backupManifestName is the manifest entry of a full backup archive.
+const backupManifestName = "backup.json"
+
+// BackupManifest describes a full backup archive written by BackupAll. Repositories are not
+// archived; each project's entry records where it is deployed from.
+type BackupManifest struct {
+	CreatedAt time.Time                `json:"createdAt"`
+	Projects  []project.BackupManifest `json:"projects"`
+}
+
+// BackupAll writes a tar.gz archive of the Reflow configuration to outputPath: the global
+// config.yaml and plugins.json, each project's config.yaml, state.json and deployments.log, and
+// the Nginx conf.d directory. Files keep their path relative to the base directory. Project
+// repositories, env files, secrets, credentials and data are not included.
+func BackupAll(ctx context.Context, reflowBasePath, outputPath string) error {
+	manifest := BackupManifest{CreatedAt: time.Now().UTC(), Projects: []project.BackupManifest{}}
+	var entries []util.ArchiveEntry
+
+	// --- 1. Global Files ---
+	for _, name := range []string{config.GlobalConfigFileName, config.PluginStateFileName} {
+		content, err := os.ReadFile(filepath.Join(reflowBasePath, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				util.Log.Warnf("%s does not exist, not backing it up.", name)
+				continue
+			}
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		entries = append(entries, util.ArchiveEntry{Name: name, Content: content})
+	}
+
+	// --- 2. Projects ---
+	summaries, err := project.ListProjects(reflowBasePath)
+	if err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+	for _, summary := range summaries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		projManifest, projEntries, err := project.CollectProjectBackup(reflowBasePath, summary.Name, false)
+		if err != nil {
+			return err
+		}
+		for _, entry := range projEntries {
+			entry.Name = path.Join(config.AppsDirName, summary.Name, entry.Name)
+			entries = append(entries, entry)
+		}
+		manifest.Projects = append(manifest.Projects, projManifest)
+	}
+
+	// --- 3. Nginx Configs ---
+	confDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName)
+	err = filepath.WalkDir(confDir, func(filePath string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if os.IsNotExist(walkErr) && filePath == confDir {
+				return filepath.SkipDir
+			}
+			return walkErr
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(reflowBasePath, filePath)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, util.ArchiveEntry{Name: filepath.ToSlash(relPath), Content: content})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read nginx configs in %s: %w", confDir, err)
+	}
+
+	// --- 4. Write Archive ---
+	manifestContent, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+	entries = append([]util.ArchiveEntry{{Name: backupManifestName, Content: manifestContent}}, entries...)
+	if err := util.WriteTarGz(outputPath, entries, manifest.CreatedAt); err != nil {
+		return err
+	}
+	util.Log.Infof("✅ Backed up the Reflow configuration and %d project(s) to %s.", len(manifest.Projects), outputPath)
+	return nil
+}
+
+// RestoreAll restores a full backup written by BackupAll into reflowBasePath. It asks before
+// overwriting the global config or plugin state, before restoring each project, and before
+// adding the archived Nginx configs, unless assumeYes is set. Projects that already exist are
+// skipped, and Nginx files that exist or belong to a project that was not restored are left
+// alone. Nothing is deployed and Nginx is not reloaded.
+func RestoreAll(ctx context.Context, reflowBasePath, archivePath string, assumeYes bool) error {
+	files, err := util.ReadTarGz(archivePath, project.MaxBackupEntrySize)
+	if err != nil {
+		return err
+	}
+	var manifest BackupManifest
+	if err := json.Unmarshal(files[backupManifestName], &manifest); err != nil || manifest.Projects == nil {
+		return fmt.Errorf("%s is not a full reflow backup (project backups are restored with 'reflow project restore')", archivePath)
+	}
+	util.Log.Infof("Restoring from %s (backed up %s, %d project(s))...", archivePath, manifest.CreatedAt.Local().Format(time.RFC1123), len(manifest.Projects))
+
+	reader := bufio.NewReader(os.Stdin)
+	confirm := func(question string) bool {
+		if assumeYes {
+			return true
+		}
+		fmt.Printf("%s [y/N]: ", question)
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			return false
+		}
+		answer := strings.TrimSpace(strings.ToLower(input))
+		return answer == "y" || answer == "yes"
+	}
+
+	// --- 1. Global Config and Plugin State ---
+	if content, ok := files[config.GlobalConfigFileName]; ok {
+		previous, restored, err := restoreBaseFile(reflowBasePath, config.GlobalConfigFileName, content, confirm)
+		if err != nil {
+			return err
+		}
+		if restored {
+			if _, err := config.ReloadGlobalConfig(reflowBasePath); err != nil {
+				if previous != nil {
+					_ = os.WriteFile(filepath.Join(reflowBasePath, config.GlobalConfigFileName), previous, 0644)
+				}
+				return fmt.Errorf("archived global config is invalid: %w", err)
+			}
+		}
+	}
+	if content, ok := files[config.PluginStateFileName]; ok {
+		_, restored, err := restoreBaseFile(reflowBasePath, config.PluginStateFileName, content, confirm)
+		if err != nil {
+			return err
+		}
+		if restored {
+			warnMissingPlugins(reflowBasePath)
+		}
+	}
+
+	// --- 2. Projects ---
+	var restoredProjects, skippedProjects []string
+	var failed int
+	for i := range manifest.Projects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		projManifest := &manifest.Projects[i]
+		projectName := projManifest.ProjectName
+		if err := project.CheckProjectNameAvailable(reflowBasePath, projectName); err != nil {
+			util.Log.Warnf("Skipping project '%s': %v", projectName, err)
+			skippedProjects = append(skippedProjects, projectName)
+			continue
+		}
+		if !confirm(fmt.Sprintf("Restore project '%s' (%s)?", projectName, util.RedactURL(projManifest.Source))) {
+			skippedProjects = append(skippedProjects, projectName)
+			continue
+		}
+
+		prefix := path.Join(config.AppsDirName, projectName) + "/"
+		projFiles := make(map[string][]byte)
+		for name, content := range files {
+			if rest, ok := strings.CutPrefix(name, prefix); ok {
+				projFiles[rest] = content
+			}
+		}
+		if err := project.RestoreProjectFiles(reflowBasePath, projManifest, projFiles); err != nil {
+			util.Log.Errorf("Failed to restore project '%s': %v", projectName, err)
+			skippedProjects = append(skippedProjects, projectName)
+			failed++
+			continue
+		}
+		restoredProjects = append(restoredProjects, projectName)
+	}
+
+	// --- 3. Nginx Configs ---
+	var skippedNginxFiles []string
+	for _, projectName := range skippedProjects {
+		for _, filePath := range nginx.ProjectConfigFiles(reflowBasePath, projectName) {
+			relPath, _ := filepath.Rel(reflowBasePath, filePath)
+			skippedNginxFiles = append(skippedNginxFiles, filepath.ToSlash(relPath))
+		}
+	}
+	confDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName)
+	confPrefix := path.Join(config.NginxDirName, config.NginxConfDirName) + "/"
+	nginxFiles := make(map[string]string) // Archive name -> destination path
+	for name := range files {
+		if !strings.HasPrefix(name, confPrefix) || slices.Contains(skippedNginxFiles, name) {
+			continue
+		}
+		filePath, ok := confFilePath(reflowBasePath, confDir, name)
+		if !ok {
+			util.Log.Warnf("Skipping archived file '%s': it is not inside %s.", name, confDir)
+			continue
+		}
+		if _, err := os.Stat(filePath); err == nil {
+			continue
+		}
+		nginxFiles[name] = filePath
+	}
+	if len(nginxFiles) > 0 && confirm(fmt.Sprintf("Add %d archived Nginx config file(s) missing here? Nginx will not reload while they proxy to containers that are not running.", len(nginxFiles))) {
+		for _, name := range slices.Sorted(maps.Keys(nginxFiles)) {
+			filePath := nginxFiles[name]
+			if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(filePath), err)
+			}
+			if err := os.WriteFile(filePath, files[name], 0644); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", name, err)
+			}
+		}
+		util.Log.Infof("Restored %d Nginx config file(s).", len(nginxFiles))
+	}
+
+	util.Log.Infof("Restored %d of %d project(s).", len(restoredProjects), len(manifest.Projects))
+	if failed > 0 {
+		return fmt.Errorf("%d project(s) failed to restore", failed)
+	}
+	return nil
+}
+
+// confFilePath returns where an archived Nginx file is restored to. Names that are absolute,
+// contain "..", or otherwise resolve outside confDir are refused.
+func confFilePath(reflowBasePath, confDir, name string) (string, bool) {
+	cleanName := path.Clean(name)
+	if path.IsAbs(cleanName) || filepath.IsAbs(filepath.FromSlash(name)) || slices.Contains(strings.Split(name, "/"), "..") {
+		return "", false
+	}
+	filePath := filepath.Join(reflowBasePath, filepath.FromSlash(cleanName))
+	relPath, err := filepath.Rel(confDir, filePath)
+	if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filePath, true
+}
+
+// restoreBaseFile writes an archived file of the base directory, asking first if a different
+// version exists. It returns the previous content (nil if there was none) and whether the file
+// was written.
+func restoreBaseFile(reflowBasePath, name string, content []byte, confirm func(string) bool) ([]byte, bool, error) {
+	filePath := filepath.Join(reflowBasePath, name)
+	previous, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, false, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	if previous != nil && bytes.Equal(previous, content) {
+		util.Log.Debugf("%s is unchanged, not restoring it.", name)
+		return previous, false, nil
+	}
+	if previous != nil && !confirm(fmt.Sprintf("Overwrite %s with the archived version?", filePath)) {
+		util.Log.Infof("Keeping the current %s.", name)
+		return previous, false, nil
+	}
+	if err := os.MkdirAll(reflowBasePath, 0755); err != nil {
+		return nil, false, fmt.Errorf("failed to create directory %s: %w", reflowBasePath, err)
+	}
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return nil, false, fmt.Errorf("failed to restore %s: %w", filePath, err)
+	}
+	util.Log.Infof("Restored %s.", name)
+	return previous, true, nil
+}
+
+// warnMissingPlugins warns about plugins in the restored plugin state whose install directory is
+// missing, as plugin files are not part of a backup.
+func warnMissingPlugins(reflowBasePath string) {
+	pluginState, err := config.LoadGlobalPluginState(reflowBasePath)
+	if err != nil {
+		util.Log.Warnf("Failed to load the restored plugin state: %v", err)
+		return
+	}
+	for name, plugin := range pluginState.InstalledPlugins {
+		if _, err := os.Stat(plugin.InstallPath); os.IsNotExist(err) {
+			util.Log.Warnf("Plugin '%s' is not installed here; uninstall it and install it again from %s.", name, util.RedactURL(plugin.RepoURL))
+		}
+	}
+}
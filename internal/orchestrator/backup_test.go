@@ -0,0 +1,28 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestConfFilePath(t *testing.T) {
+	base := t.TempDir()
+	confDir := filepath.Join(base, "nginx", "conf.d")
+	tests := []struct {
+		name string
+		want string // Empty if the name must be refused
+	}{
+		{"nginx/conf.d/app.test.conf", filepath.Join(confDir, "app.test.conf")},
+		{"nginx/conf.d/limits/ratelimit-app.conf", filepath.Join(confDir, "limits", "ratelimit-app.conf")},
+		{"nginx/conf.d/../../../../etc/cron.d/x", ""},
+		{"nginx/conf.d/../config.yaml", ""},
+		{"nginx/conf.d", ""},
+		{"/nginx/conf.d/app.test.conf", ""},
+	}
+	for _, tt := range tests {
+		got, ok := confFilePath(base, confDir, tt.name)
+		if ok != (tt.want != "") || got != tt.want {
+			t.Errorf("confFilePath(%q) = %q, %v; want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
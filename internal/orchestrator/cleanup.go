@@ -4,18 +4,46 @@ import (
 	"context"
 	"fmt"
 	"reflow/internal/config"
+	"reflow/internal/deployment"
 	"reflow/internal/docker"
+	"reflow/internal/project"
 	"reflow/internal/util"
 	"strings"
-
-	"github.com/docker/docker/api/types/image"
+	"time"
 )
 
+// autoPruneImages removes a project's old images after a successful deployment when
+// autoPruneImages is enabled in the global config. The images of the commits active in projState
+// are kept besides the newest ones. Failures are only logged.
+func autoPruneImages(ctx context.Context, globalCfg *config.GlobalConfig, projCfg *config.ProjectConfig, projState *config.ProjectState) {
+	if !globalCfg.AutoPruneImages {
+		return
+	}
+	projectName := projCfg.ProjectName
+	keepImages := config.EffectiveKeepImages(globalCfg, projCfg)
+	util.Log.Infof("Pruning old images for project '%s' (keeping %d)...", projectName, keepImages)
+	pruned, err := docker.PruneOldProjectImages(ctx, projectName, keepImages, projState.Test.ActiveCommit, projState.Prod.ActiveCommit)
+	if err != nil {
+		util.Log.Warnf("Automatic image pruning failed: %v", err)
+		return
+	}
+	util.Log.Infof("Pruned %d old image(s) for project '%s'.", pruned, projectName)
+}
+
 // CleanupProjectEnv cleans up inactive containers for a given project and environment.
-func CleanupProjectEnv(ctx context.Context, reflowBasePath, projectName, env string) (cleanedCount int, err error) {
+// Containers get stopTimeout to exit after SIGTERM before being killed; zero uses the project's
+// stopTimeout setting (or the default). Forced kills are logged to the project's deployment history.
+func CleanupProjectEnv(ctx context.Context, reflowBasePath, projectName, env string, stopTimeout time.Duration) (cleanedCount int, err error) {
 	util.Log.Infof("Starting cleanup for project '%s', environment '%s'...", projectName, env)
 	cleanedCount = 0
 
+	if stopTimeout <= 0 {
+		stopTimeout = config.DefaultStopTimeoutSeconds * time.Second
+		if projCfg, cfgErr := config.LoadProjectConfig(reflowBasePath, projectName); cfgErr == nil && projCfg.StopTimeout > 0 {
+			stopTimeout = time.Duration(projCfg.StopTimeout) * time.Second
+		}
+	}
+
 	projState, err := config.LoadProjectState(reflowBasePath, projectName)
 	if err != nil {
 		return 0, fmt.Errorf("failed to load project state for '%s': %w", projectName, err)
@@ -71,15 +99,28 @@ func CleanupProjectEnv(ctx context.Context, reflowBasePath, projectName, env str
 			util.Log.Warnf("Found inactive container: %s (ID: %s, Slot: %s, Commit: %s). Stopping and removing.",
 				containerName, containerID, slotLabel, commitLabel[:7])
 
-			stopErr := docker.StopContainer(ctx, c.ID, nil)
+			forced, stopErr := docker.StopContainerGracefully(ctx, c.ID, stopTimeout)
 			if stopErr != nil {
 				util.Log.Debugf("Ignoring error stopping potentially already stopped container %s: %v", containerID, stopErr)
 			}
+			if forced {
+				util.Log.Warnf("Container %s (%s) ignored SIGTERM and was killed after %v. The app may not handle graceful shutdown.", containerName, containerID, stopTimeout)
+				deployment.LogEvent(reflowBasePath, projectName, &config.DeploymentEvent{
+					Timestamp:    time.Now(),
+					EventType:    "stop-timeout",
+					ProjectName:  projectName,
+					Environment:  env,
+					CommitSHA:    commitLabel,
+					Outcome:      "failure",
+					ErrorMessage: fmt.Sprintf("container %s did not exit within %v of SIGTERM and was killed with SIGKILL", strings.TrimPrefix(containerName, "/"), stopTimeout),
+					TriggeredBy:  "cleanup",
+				})
+			}
 
 			removeErr := docker.RemoveContainer(ctx, c.ID)
 			if removeErr != nil {
 				errMsg := fmt.Sprintf("failed to remove inactive container %s (%s): %v", containerName, containerID, removeErr)
-				util.Log.Errorf(errMsg)
+				util.Log.Error(errMsg)
 				cleanupErrors = append(cleanupErrors, errMsg)
 			} else {
 				util.Log.Infof("Removed inactive container %s (%s)", containerName, containerID)
@@ -99,92 +140,133 @@ func CleanupProjectEnv(ctx context.Context, reflowBasePath, projectName, env str
 	return cleanedCount, nil
 }
 
-// PruneProjectImages removes Docker images associated with inactive commits for a project.
+// PruneProjectImages removes Docker images associated with inactive commits for a project,
+// keeping its keepImages most recently built images for rollbacks and any image a container
+// still uses.
 func PruneProjectImages(ctx context.Context, reflowBasePath, projectName string) (prunedCount int, err error) {
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load global config: %w", err)
+	}
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load project config for '%s': %w", projectName, err)
+	}
+	keepImages := config.EffectiveKeepImages(globalCfg, projCfg)
+
 	util.Log.Warn("--- Starting Image Pruning ---")
 	util.Log.Warn("This will remove Docker images tagged for this project that do not match")
-	util.Log.Warn("the currently active commit in EITHER the 'test' OR 'prod' environment.")
+	util.Log.Warn("the currently active commit in EITHER the 'test' OR 'prod' environment,")
+	util.Log.Warnf("except for the %d most recently built ones.", keepImages)
 	util.Log.Warn("Ensure you want to remove these images, as it might affect rollbacks.")
-	prunedCount = 0
 
 	projState, err := config.LoadProjectState(reflowBasePath, projectName)
 	if err != nil {
 		return 0, fmt.Errorf("failed to load project state for '%s' during image prune: %w", projectName, err)
 	}
+	if projState.Test.ActiveCommit == "" && projState.Prod.ActiveCommit == "" {
+		util.Log.Infof("No active deployments found for project '%s'. Skipping image prune.", projectName)
+		return 0, nil
+	}
 
-	activeCommits := make(map[string]bool)
-	if projState.Test.ActiveCommit != "" {
-		activeCommits[projState.Test.ActiveCommit] = true
+	images, err := docker.ListProjectImages(ctx, projectName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list images for pruning: %w", err)
 	}
-	if projState.Prod.ActiveCommit != "" {
-		activeCommits[projState.Prod.ActiveCommit] = true
+	util.Log.Infof("Found %d image(s) for project '%s'. Checking for prunable images...", len(images), projectName)
+
+	var pruneErrors []string
+	for _, img := range docker.PrunableProjectImages(images, keepImages, projState.Test.ActiveCommit, projState.Prod.ActiveCommit) {
+		util.Log.Warnf("Found prunable image: %s (ID: %s)", img.Tag, strings.TrimPrefix(img.ID, "sha256:")[:12])
+		if err := docker.RemoveImage(ctx, img.Tag); err != nil {
+			pruneErrors = append(pruneErrors, fmt.Sprintf("failed to prune image %s: %v", img.Tag, err))
+			continue
+		}
+		prunedCount++
 	}
 
-	if len(activeCommits) == 0 {
-		util.Log.Info("No active deployments found for project '%s'. Skipping image prune.", projectName)
-		return 0, nil
+	util.Log.Infof("Image pruning complete for project '%s'. Removed %d image(s).", projectName, prunedCount)
+	util.Log.Warn("--- Finished Image Pruning ---")
+
+	if len(pruneErrors) > 0 {
+		return prunedCount, fmt.Errorf("encountered errors during image pruning:\n - %s", strings.Join(pruneErrors, "\n - "))
 	}
-	util.Log.Debugf("Active commits for project '%s': %v", projectName, activeCommits)
 
-	cli, err := docker.GetClient()
+	return prunedCount, nil
+}
+
+// ImagePruneResult summarizes a PruneAllProjectImages run.
+type ImagePruneResult struct {
+	Projects       int   // Projects whose images were checked
+	PrunedImages   int   // Images removed
+	ReclaimedBytes int64 // Sum of the removed images' sizes; layers shared with kept images may not have been freed
+}
+
+// PruneAllProjectImages removes, for every project, the images tagged <project>:<commit> whose
+// commit is active in neither the project's test nor its prod environment, keeping the keepLast
+// most recently built ones (a negative keepLast uses each project's keepImages setting). As with
+// PruneProjectImages, projects with nothing deployed are skipped, and images a container still
+// uses are kept. Removal failures are collected and returned after all projects were processed.
+func PruneAllProjectImages(ctx context.Context, reflowBasePath string, keepLast int) (ImagePruneResult, error) {
+	var result ImagePruneResult
+
+	projects, err := project.ListProjects(reflowBasePath)
 	if err != nil {
-		return 0, err
+		return result, fmt.Errorf("failed to list projects: %w", err)
 	}
-
-	images, err := cli.ImageList(ctx, image.ListOptions{})
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to list images for pruning: %w", err)
+		return result, fmt.Errorf("failed to load global config: %w", err)
 	}
 
-	util.Log.Infof("Found %d total images. Checking for prunable images for project '%s'...", len(images), projectName)
-
 	var pruneErrors []string
-	imagePrefix := strings.ToLower(projectName) + ":"
-
-	for _, img := range images {
-		isProjectImage := false
-		commitHash := ""
-		repoTags := img.RepoTags
-
-		if repoTags == nil {
+	for _, summary := range projects {
+		// --- 1. Active Commits ---
+		projState, err := config.LoadProjectState(reflowBasePath, summary.Name)
+		if err != nil {
+			return result, fmt.Errorf("failed to load project state for '%s' during image prune: %w", summary.Name, err)
+		}
+		if projState.Test.ActiveCommit == "" && projState.Prod.ActiveCommit == "" {
+			util.Log.Infof("No active deployments found for project '%s'. Skipping its images.", summary.Name)
 			continue
 		}
-
-		for _, tag := range repoTags {
-			if strings.HasPrefix(tag, imagePrefix) {
-				isProjectImage = true
-				commitHash = strings.TrimPrefix(tag, imagePrefix)
-				break
+		keepCount := keepLast
+		if keepCount < 0 {
+			projCfg, err := config.LoadProjectConfig(reflowBasePath, summary.Name)
+			if err != nil {
+				return result, fmt.Errorf("failed to load project config for '%s' during image prune: %w", summary.Name, err)
 			}
+			keepCount = config.EffectiveKeepImages(globalCfg, projCfg)
 		}
+		result.Projects++
 
-		if !isProjectImage || commitHash == "" {
-			continue
+		// --- 2. Prune ---
+		images, err := docker.ListProjectImages(ctx, summary.Name)
+		if err != nil {
+			return result, fmt.Errorf("failed to list images for pruning: %w", err)
 		}
-
-		if _, isActive := activeCommits[commitHash]; !isActive {
-			util.Log.Warnf("Found prunable image: %s (ID: %s, Commit: %s)", repoTags, img.ID[:12], commitHash[:7])
-
-			err := docker.RemoveImage(ctx, img.ID)
-			if err != nil {
-				errMsg := fmt.Sprintf("failed to prune image %s (%s): %v", img.ID[:12], repoTags, err)
-				util.Log.Errorf(errMsg)
-				pruneErrors = append(pruneErrors, errMsg)
-			} else {
-				util.Log.Infof("Pruned image %s (%s)", img.ID[:12], repoTags)
-				prunedCount++
+		for _, img := range docker.PrunableProjectImages(images, keepCount, projState.Test.ActiveCommit, projState.Prod.ActiveCommit) {
+			util.Log.Warnf("Found prunable image: %s (ID: %s)", img.Tag, strings.TrimPrefix(img.ID, "sha256:")[:12])
+			// Remove by tag, so tags of other repositories on the same image survive; Docker
+			// deletes the image once its last tag is gone.
+			if err := docker.RemoveImage(ctx, img.Tag); err != nil {
+				pruneErrors = append(pruneErrors, fmt.Sprintf("failed to prune image %s: %v", img.Tag, err))
+				continue
+			}
+			result.PrunedImages++
+			if img.OtherTags == 0 {
+				result.ReclaimedBytes += img.Size
 			}
-		} else {
-			util.Log.Debugf("Skipping active image: %s (Commit: %s)", repoTags, commitHash[:7])
 		}
 	}
+	if result.Projects == 0 {
+		util.Log.Info("No projects with active deployments found. Nothing to prune.")
+		return result, nil
+	}
 
-	util.Log.Infof("Image pruning complete for project '%s'. Removed %d image(s).", projectName, prunedCount)
-	util.Log.Warn("--- Finished Image Pruning ---")
-
+	util.Log.Infof("Image pruning complete across %d project(s). Removed %d image(s), reclaiming about %.1fMiB.", result.Projects, result.PrunedImages, float64(result.ReclaimedBytes)/(1024*1024))
 	if len(pruneErrors) > 0 {
-		return prunedCount, fmt.Errorf("encountered errors during image pruning:\n - %s", strings.Join(pruneErrors, "\n - "))
+		return result, fmt.Errorf("encountered errors during image pruning:\n - %s", strings.Join(pruneErrors, "\n - "))
 	}
-
-	return prunedCount, nil
+	return result, nil
 }
@@ -5,38 +5,111 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"reflow/internal/app"
 	"reflow/internal/config"
 	"reflow/internal/deployment"
 	"reflow/internal/docker"
 	internalGit "reflow/internal/git"
 	"reflow/internal/nginx"
+	"reflow/internal/notify"
 	"reflow/internal/util"
 	"strings"
 	"time"
 
-	gogit "github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sirupsen/logrus"
 )
 
-const defaultCommit = "HEAD"
+const (
+	defaultCommit        = "HEAD"
+	reflowDockerfileName = ".reflow-dockerfile" // Generated into the repo for the build, removed afterwards
+)
+
+// DeployTest orchestrates the deployment process to the 'test' environment. It returns the full
+// hash of the commit commitIsh resolved to, which is set even if the deployment failed later on,
+// and empty if it could not be resolved.
+func DeployTest(ctx context.Context, reflowBasePath, projectName, commitIsh string) (string, error) {
+	var resolvedCommit string
+	err := deployCommit(ctx, reflowBasePath, projectName, "test", commitIsh, func(commitHash string) error {
+		resolvedCommit = commitHash
+		return nil
+	})
+	return resolvedCommit, err
+}
+
+// DeployProd builds and deploys a commit straight to the 'prod' environment, bypassing the usual
+// test-then-approve flow, e.g. to hotfix prod while test is busy with something else. Unless
+// skipTestCheck is set, the commit must have been deployed to 'test' successfully before.
+// The event is logged as a "deploy" to "prod", so history tells it apart from approvals.
+// Like DeployTest, it returns the commit commitIsh resolved to.
+func DeployProd(ctx context.Context, reflowBasePath, projectName, commitIsh string, skipTestCheck bool) (string, error) {
+	if skipTestCheck {
+		util.Log.Warn("Skipping the check that the commit was deployed to 'test' first.")
+	}
+	var resolvedCommit string
+	err := deployCommit(ctx, reflowBasePath, projectName, "prod", commitIsh, func(commitHash string) error {
+		resolvedCommit = commitHash
+		if skipTestCheck {
+			return nil
+		}
+		tested, err := deployment.HasSuccessfulDeployment(reflowBasePath, projectName, "test", commitHash)
+		if err != nil {
+			return fmt.Errorf("failed to check deployment history for commit %s: %w", commitHash[:7], err)
+		}
+		if !tested {
+			return fmt.Errorf("commit %s has never been deployed to 'test' successfully; deploy it to test first or skip this check", commitHash[:7])
+		}
+		util.Log.Infof("Commit %s was previously deployed to 'test' successfully.", commitHash[:7])
+		return nil
+	})
+	return resolvedCommit, err
+}
+
+// deployCommit builds a commit and deploys it to the inactive slot of env, then switches traffic
+// to it once healthy. A non-nil verify is called with the resolved commit before anything is
+// built, and aborts the deployment if it returns an error.
+func deployCommit(ctx context.Context, reflowBasePath, projectName, env, commitIsh string, verify func(commitHash string) error) (err error) {
+	// Notifications go out once the lock is released, so slow webhooks never hold up the next
+	// deployment of the project.
+	var notifyEvent *config.DeploymentEvent
+	var notifyURL string
+	defer func() {
+		if notifyEvent != nil {
+			notify.Dispatch(reflowBasePath, notifyEvent, notifyURL)
+		}
+	}()
+
+	unlock, err := lockProject(projectName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var progress *deployment.Progress
+	if !IsDryRun(ctx) {
+		progress = deployment.StartProgress(reflowBasePath, projectName, fmt.Sprintf("deployment of project '%s' to '%s'", projectName, env), progressSinks(ctx)...)
+		progress.AddFields(logrus.Fields{"project": projectName, "env": env})
+		defer func() { progress.Finish(err) }()
+	}
 
-// DeployTest orchestrates the deployment process to the 'test' environment.
-func DeployTest(ctx context.Context, reflowBasePath, projectName, commitIsh string) (err error) {
 	startTime := time.Now()
 	var finalCommitHash string
+	var deploymentURL string
+	var activeSlot, inactiveSlot string
 
 	initialEvent := &config.DeploymentEvent{
 		Timestamp:   startTime,
 		EventType:   "deploy",
 		ProjectName: projectName,
-		Environment: "test",
+		Environment: env,
 
 		Outcome:     "started",
-		TriggeredBy: "cli/api",
+		TriggeredBy: triggeredBy(ctx),
 	}
 
+	dryRun := IsDryRun(ctx)
 	defer func() {
+		if dryRun {
+			return // A dry run leaves no trace in the history and sends no notifications
+		}
 		duration := time.Since(startTime)
 		outcome := "success"
 		errMsg := ""
@@ -48,44 +121,39 @@ func DeployTest(ctx context.Context, reflowBasePath, projectName, commitIsh stri
 			Timestamp:    time.Now(),
 			EventType:    "deploy",
 			ProjectName:  projectName,
-			Environment:  "test",
+			Environment:  env,
 			CommitSHA:    finalCommitHash,
+			Slot:         inactiveSlot,
 			Outcome:      outcome,
 			ErrorMessage: errMsg,
 			DurationMs:   duration.Milliseconds(),
-			TriggeredBy:  "cli/api",
+			TriggeredBy:  triggeredBy(ctx),
 		}
 		deployment.LogEvent(reflowBasePath, projectName, finalEvent)
+		notifyEvent, notifyURL = finalEvent, deploymentURL
 	}()
 
-	util.Log.Infof("Starting deployment for project '%s' to 'test' environment...", projectName)
+	if dryRun {
+		util.Log.Infof("Starting DRY RUN of deployment for project '%s' to '%s' environment (no changes will be made)...", projectName, env)
+	} else {
+		progress.Infof("Starting deployment for project '%s' to '%s' environment...", projectName, env)
+	}
 	projectBasePath := config.GetProjectBasePath(reflowBasePath, projectName)
 	repoPath := filepath.Join(projectBasePath, config.RepoDirName)
 
 	var projCfg *config.ProjectConfig
 	var projState *config.ProjectState
 	var globalCfg *config.GlobalConfig
-	var repo *gogit.Repository
-	var resolvedHash *plumbing.Hash
 	var commitHash string
-	var activeSlot, inactiveSlot string
 	var imageTag string
 	var dockerfilePath string
-	var newContainerID string
-	var containerName string
+	var newContainerIDs []string
+	var containerNames []string
 
-	func() {
-		if err != nil && newContainerID != "" {
+	defer func() {
+		if err != nil && len(newContainerIDs) > 0 {
 			util.Log.Errorf("Deployment failed: %v", err)
-			util.Log.Warnf("Attempting simple rollback: stopping and removing newly started container %s...", newContainerID[:12])
-			cleanupCtx := context.Background()
-			_ = docker.StopContainer(cleanupCtx, newContainerID, nil)
-			rmErr := docker.RemoveContainer(cleanupCtx, newContainerID)
-			if rmErr != nil {
-				util.Log.Errorf("Rollback cleanup failed: Could not remove container %s: %v", newContainerID[:12], rmErr)
-			} else {
-				util.Log.Infof("Rollback cleanup: Removed container %s", newContainerID[:12])
-			}
+			rollbackContainers(newContainerIDs)
 		}
 		if dockerfilePath != "" {
 			_ = os.Remove(dockerfilePath)
@@ -101,64 +169,93 @@ func DeployTest(ctx context.Context, reflowBasePath, projectName, commitIsh stri
 
 	projState, err = config.LoadProjectState(reflowBasePath, projectName)
 	if err != nil {
-		util.Log.Warnf("Could not load project state, assuming first deployment: %v", err)
+		progress.Warnf("Could not load project state, assuming first deployment: %v", err)
 		projState = &config.ProjectState{}
 	}
 
 	globalCfg, err = config.LoadGlobalConfig(reflowBasePath)
 	if err != nil {
-		util.Log.Warnf("Could not load global config: %v", err)
+		progress.Warnf("Could not load global config: %v", err)
 		globalCfg = &config.GlobalConfig{}
 	}
+	push := isPush(ctx) || globalCfg.Registry.Push
+	if push && globalCfg.Registry.URL == "" {
+		return fmt.Errorf("cannot push the image: no registry.url is set in the global config")
+	}
 
 	// --- 2. Determine Target Commit ---
 	util.Log.Debug("Determining target commit...")
 	targetCommitIsh := commitIsh
-	if targetCommitIsh == "" {
+	if projCfg.SourceType == config.SourceTypeLocal {
+		if commitIsh != "" {
+			return fmt.Errorf("project '%s' is deployed from a local directory, so a commit cannot be selected", projectName)
+		}
+	} else if targetCommitIsh == "" {
 		targetCommitIsh = defaultCommit
-		util.Log.Infof("No commit specified, defaulting to %s", defaultCommit)
+		progress.Infof("No commit specified, defaulting to %s", defaultCommit)
 	}
 
-	// --- 3. Update & Checkout Repo ---
-	util.Log.Info("Updating repository...")
-	if err = internalGit.FetchUpdates(repoPath); err != nil {
-		return fmt.Errorf("failed to fetch repository updates: %w", err)
+	// --- 3. Update & Checkout Source ---
+	if dryRun {
+		commitHash, err = dryRunResolveCommit(projCfg, repoPath, targetCommitIsh)
+	} else if projCfg.SourceType == config.SourceTypeLocal {
+		progress.StartStep("copying source")
+		progress.Info("Updating local source...")
+		commitHash, err = syncLocalSource(projCfg, repoPath)
+	} else {
+		progress.StartStep("fetching repo")
+		progress.Info("Updating repository...")
+		commitHash, err = resolveGitCommit(reflowBasePath, projCfg, globalCfg, repoPath, targetCommitIsh)
 	}
-
-	repo, err = gogit.PlainOpen(repoPath)
 	if err != nil {
-		return fmt.Errorf("failed to open repository at %s: %w", repoPath, err)
+		return err
 	}
-	resolvedHash, err = repo.ResolveRevision(plumbing.Revision(targetCommitIsh))
-	if err != nil {
-		return fmt.Errorf("failed to resolve revision '%s': %w", targetCommitIsh, err)
+	finalCommitHash = commitHash
+	progress.AddFields(logrus.Fields{"commit": commitHash})
+	if targetCommitIsh != "" && !strings.HasPrefix(commitHash, strings.ToLower(targetCommitIsh)) {
+		progress.Infof("Deploying '%s' at commit %s", targetCommitIsh, commitHash[:7])
 	}
-	commitHash = resolvedHash.String()
-	util.Log.Infof("Resolved '%s' to commit: %s", targetCommitIsh, commitHash)
 
-	initialEvent.CommitSHA = commitHash
-	deployment.LogEvent(reflowBasePath, projectName, initialEvent)
+	if verify != nil {
+		if err = verify(commitHash); err != nil {
+			return err
+		}
+	}
+	if err = checkRequiredEnv(reflowBasePath, projCfg, env, commitHash); err != nil {
+		return err
+	}
 
-	util.Log.Infof("Checking out commit %s...", commitHash[:7])
-	if err = internalGit.CheckoutCommit(repoPath, commitHash); err != nil {
-		return fmt.Errorf("failed to checkout commit %s: %w", commitHash, err)
+	if !dryRun {
+		initialEvent.CommitSHA = commitHash
+		deployment.LogEvent(reflowBasePath, projectName, initialEvent)
+	}
+
+	if !dryRun && projCfg.SourceType != config.SourceTypeLocal {
+		progress.Infof("Checking out commit %s...", commitHash[:7])
+		if err = internalGit.CheckoutCommit(repoPath, commitHash); err != nil {
+			return fmt.Errorf("failed to checkout commit %s: %w", commitHash, err)
+		}
 	}
 
 	// --- 4. Identify Slots ---
 	util.Log.Debug("Identifying deployment slots...")
 
-	activeSlot = projState.Test.ActiveSlot
+	envState := &projState.Test
+	if env == "prod" {
+		envState = &projState.Prod
+	}
+	activeSlot = envState.ActiveSlot
 	if activeSlot == "blue" {
 		inactiveSlot = "green"
 	} else {
 		inactiveSlot = "blue"
 	}
 
-	util.Log.Infof("Targeting inactive slot: %s (Active slot: %s)", inactiveSlot, activeSlot)
+	progress.Infof("Targeting inactive slot: %s (Active slot: %s)", inactiveSlot, activeSlot)
 
 	// --- 5. Build Docker Image ---
 	imageTag = fmt.Sprintf("%s:%s", strings.ToLower(projectName), commitHash)
-	util.Log.Infof("Preparing to build image: %s", imageTag)
+	progress.Infof("Preparing to build image: %s", imageTag)
 	dockerfileData := docker.DockerfileData{
 		NodeVersion: projCfg.NodeVersion,
 		AppPort:     projCfg.AppPort,
@@ -168,23 +265,46 @@ func DeployTest(ctx context.Context, reflowBasePath, projectName, commitIsh stri
 		return fmt.Errorf("failed to generate dockerfile content: %w", err)
 	}
 
-	dockerfilePath = filepath.Join(repoPath, ".reflow-dockerfile")
+	if dryRun {
+		printDryRunArtifact("Dockerfile", dockerfileContent)
+		if isNoCache(ctx) {
+			util.Log.Infof("[dry run] Would build image %s from %s without the build cache", imageTag, repoPath)
+		} else {
+			util.Log.Infof("[dry run] Would build image %s from %s", imageTag, repoPath)
+		}
+		if push {
+			util.Log.Infof("[dry run] Would push the image to the registry as %s", docker.RegistryImageName(globalCfg.Registry.URL, projectName, commitHash))
+		}
+		return dryRunSwitchSlot(ctx, reflowBasePath, projCfg, globalCfg, env, *envState, inactiveSlot, imageTag, commitHash)
+	}
+
+	progress.StartStep("building image")
+	dockerfilePath = filepath.Join(repoPath, reflowDockerfileName)
 	if err = os.WriteFile(dockerfilePath, []byte(dockerfileContent), 0644); err != nil {
 		return fmt.Errorf("failed to write temporary dockerfile: %w", err)
 	}
 
 	buildArgs := map[string]*string{"NODE_VERSION": &projCfg.NodeVersion}
-	err = docker.BuildImage(ctx, dockerfilePath, repoPath, imageTag, buildArgs)
-	if err != nil {
-		return fmt.Errorf("docker image build failed: %w", err)
+	buildOpts := docker.BuildOptions{BuildKit: docker.UseBuildKit(ctx, globalCfg.UseBuildKit), NoCache: isNoCache(ctx)}
+	if cacheImage := previousImageTag(projectName, *envState); cacheImage != "" && !buildOpts.NoCache {
+		buildOpts.CacheFrom = []string{cacheImage}
+	}
+	if err = buildImageWithRetry(ctx, progress, projCfg, dockerfilePath, repoPath, imageTag, buildArgs, buildOpts); err != nil {
+		return err
+	}
+	progress.Infof("Image build successful: %s", imageTag)
+	if push {
+		progress.StartStep("pushing image")
+		if err = pushImageToRegistry(ctx, progress, globalCfg, projectName, imageTag, commitHash); err != nil {
+			return err
+		}
 	}
-	util.Log.Infof("Image build successful: %s", imageTag)
 
 	// --- 6. Stop/Remove Old Inactive Container ---
-	util.Log.Infof("Cleaning up previous inactive slot '%s' container if exists...", inactiveSlot)
+	progress.Infof("Cleaning up previous inactive slot '%s' container if exists...", inactiveSlot)
 	oldLabels := map[string]string{
 		docker.LabelProject:     projectName,
-		docker.LabelEnvironment: "test",
+		docker.LabelEnvironment: env,
 		docker.LabelSlot:        inactiveSlot,
 	}
 
@@ -193,145 +313,204 @@ func DeployTest(ctx context.Context, reflowBasePath, projectName, commitIsh stri
 		return fmt.Errorf("failed to check for old inactive containers: %w", findErr)
 	}
 	for _, oldC := range oldContainers {
-		util.Log.Warnf("Found old container %s (%s) in inactive slot. Stopping and removing.", oldC.ID[:12], strings.Join(oldC.Names, ","))
+		progress.Warnf("Found old container %s (%s) in inactive slot. Stopping and removing.", oldC.ID[:12], strings.Join(oldC.Names, ","))
 		_ = docker.StopContainer(ctx, oldC.ID, nil)
 		if rmErr := docker.RemoveContainer(ctx, oldC.ID); rmErr != nil {
 			util.Log.Errorf("Failed to remove old container %s: %v", oldC.ID[:12], rmErr)
 		}
 	}
 
-	// --- 7. Start New Container ---
-	containerName = fmt.Sprintf("%s-test-%s-%s", strings.ToLower(projectName), inactiveSlot, commitHash[:7])
-	util.Log.Infof("Starting new container '%s' for slot '%s'...", containerName, inactiveSlot)
-	envFilePath := ""
-	if projCfg.Environments["test"].EnvFile != "" {
-		envFilePath = filepath.Join(repoPath, projCfg.Environments["test"].EnvFile)
-	}
-
-	envVars, err := util.LoadEnvFile(envFilePath)
+	// --- 7. Start New Containers ---
+	progress.StartStep("starting container")
+	replicas := config.EffectiveReplicas(projCfg, env)
+	progress.Infof("Starting %d new container(s) for slot '%s'...", replicas, inactiveSlot)
+	runOptions, envFile, err := slotRunOptions(reflowBasePath, imageTag, projCfg, env, inactiveSlot, commitHash)
 	if err != nil {
-		return fmt.Errorf("failed to load environment variables: %w", err)
-	}
-
-	envVars = append(envVars, fmt.Sprintf("PORT=%d", projCfg.AppPort))
-	newLabels := map[string]string{
-		docker.LabelManaged:     "true",
-		docker.LabelProject:     projectName,
-		docker.LabelEnvironment: "test",
-		docker.LabelSlot:        inactiveSlot,
-		docker.LabelCommit:      commitHash,
-	}
-
-	runOptions := docker.ContainerRunOptions{
-		ImageName:     imageTag,
-		ContainerName: containerName,
-		NetworkName:   config.ReflowNetworkName,
-		Labels:        newLabels,
-		EnvVars:       envVars,
-		AppPort:       projCfg.AppPort,
-		RestartPolicy: "unless-stopped",
+		return err
 	}
 
-	newContainerID, err = docker.RunContainer(ctx, runOptions)
+	containerNames, err = startReplicas(ctx, runOptions, projCfg, env, inactiveSlot, commitHash, replicas, &newContainerIDs)
 	if err != nil {
 		return fmt.Errorf("failed to run new container: %w", err)
 	}
-	util.Log.Infof("New container started: %s (ID: %s)", containerName, newContainerID[:12])
 
 	// --- 8. Health Check ---
-	healthTimeout := 60 * time.Second
-	healthInterval := 5 * time.Second
-	healthCheckStartTime := time.Now()
-	isHealthy := false
-
-	util.Log.Infof("Performing health check via TCP connection from Nginx container (timeout %v)...", healthTimeout)
-
-	for time.Since(healthCheckStartTime) < healthTimeout {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("health check cancelled: %w", ctx.Err())
-		default:
-		}
-
-		util.Log.Debugf("Polling health for %s...", containerName)
-		healthy, checkErr := app.CheckTcpHealthFromNginx(ctx, containerName, projCfg.AppPort)
-
-		if checkErr != nil {
-			util.Log.Warnf("Health check poll failed for %s: %v", containerName, checkErr)
-		} else if healthy {
-			isHealthy = true
-			util.Log.Infof("Container '%s' passed health check after %v.", containerName, time.Since(healthCheckStartTime))
-			break
-		} else {
-			util.Log.Debugf("Container '%s' not healthy yet, retrying in %v...", containerName, healthInterval)
-		}
-
-		select {
-		case <-time.After(healthInterval):
-		case <-ctx.Done():
-			return fmt.Errorf("health check cancelled while waiting for interval: %w", ctx.Err())
+	progress.StartStep("health check")
+	for _, containerName := range containerNames {
+		if err = waitForHealthy(ctx, containerName, projCfg.AppPort, progress); err != nil {
+			return err
 		}
 	}
-
-	if !isHealthy {
-		err = fmt.Errorf("container '%s' failed health check: timed out after %v", containerName, healthTimeout)
+	if err = runSwitchHooks(ctx, "preSwitch", projCfg.Hooks.PreSwitch, repoPath, projCfg, env, commitHash, containerNames); err != nil {
 		return err
 	}
 
 	// --- 9. Update Nginx ---
-	util.Log.Info("Updating Nginx configuration...")
-	domain, err := config.GetEffectiveDomain(globalCfg, projCfg, "test")
+	progress.StartStep("switching nginx")
+	progress.Info("Updating Nginx configuration...")
+	domain, err := config.GetEffectiveDomain(globalCfg, projCfg, env)
 	if err != nil {
 		return fmt.Errorf("failed to determine domain for nginx config: %w", err)
 	}
-	nginxData := nginx.TemplateData{ProjectName: projectName, Env: "test", Slot: inactiveSlot, ContainerName: containerName, Domain: domain, AppPort: projCfg.AppPort}
+	nginxData := nginx.TemplateData{ProjectName: projectName, Env: env, Slot: inactiveSlot, ContainerNames: containerNames, Domain: domain, AppPort: projCfg.AppPort, TLS: env == "prod" && tlsEnabled(reflowBasePath, projCfg, env, domain), RateLimit: config.EffectiveRateLimit(projCfg, env), ExtraLocations: projCfg.Environments[env].ExtraLocations}
 	nginxConfContent, err := nginx.GenerateNginxConfig(nginxData)
 	if err != nil {
 		return fmt.Errorf("failed to generate nginx config: %w", err)
 	}
-	err = nginx.WriteNginxConfig(reflowBasePath, projectName, "test", nginxConfContent)
-	if err != nil {
-		return fmt.Errorf("failed to write nginx config: %w", err)
+	if err = applyNginxConfig(ctx, reflowBasePath, projCfg, env, nginxConfContent, &siteCheck{Domain: domain, TLS: nginxData.TLS, Commit: commitHash}); err != nil {
+		return err
 	}
-	if err = nginx.ReloadNginx(ctx); err != nil {
-		return fmt.Errorf("failed to reload nginx: %w", err)
+	progress.Info("Nginx reloaded, traffic switched to new container(s).")
+	if hookErr := runSwitchHooks(ctx, "postSwitch", projCfg.Hooks.PostSwitch, repoPath, projCfg, env, commitHash, containerNames); hookErr != nil {
+		progress.Warnf("Post-switch hook failed, the deployment stays live: %v", hookErr)
+	}
+
+	if env == "prod" && projCfg.AutoTLS {
+		progress.Infof("Ensuring TLS certificate for %s (autoTLS)...", domain)
+		if tlsErr := provisionTLS(ctx, reflowBasePath, nginxData); tlsErr != nil {
+			progress.Warnf("Could not provision TLS certificate, prod stays on its current protocol: %v", tlsErr)
+		}
 	}
-	util.Log.Info("Nginx reloaded, traffic switched to new container.")
 
 	// --- 10. Update State ---
-	util.Log.Info("Updating deployment state...")
-	projState.Test.ActiveSlot = inactiveSlot
-	projState.Test.ActiveCommit = commitHash
-	projState.Test.PendingCommit = ""
+	progress.StartStep("updating state")
+	progress.Info("Updating deployment state...")
+	envState.ActiveSlot = inactiveSlot
+	envState.ActiveCommit = commitHash
+	envState.PendingCommit = ""
+	envState.LastImageTag = imageTag
+	envState.EnvFileHash = envFile.Hash
+	envState.Replicas = len(containerNames)
 	if inactiveSlot == "blue" {
-		projState.Test.InactiveSlot = "green"
+		envState.InactiveSlot = "green"
 	} else {
-		projState.Test.InactiveSlot = "blue"
+		envState.InactiveSlot = "blue"
 	}
 
 	if err = config.SaveProjectState(reflowBasePath, projectName, projState); err != nil {
 		return fmt.Errorf("CRITICAL: Deployment successful, but failed to save updated state: %w", err)
 	}
 
-	util.Log.Info("-----------------------------------------------------")
-	util.Log.Infof("✅ Deployment to 'test' environment for project '%s' successful!", projectName)
-	util.Log.Infof("   Commit:  %s (%s)", commitHash, commitHash[:7])
-	util.Log.Infof("   Slot:    %s", inactiveSlot)
+	// --- 11. Prune Old Images ---
+	autoPruneImages(ctx, globalCfg, projCfg, projState)
+
+	progress.Info("-----------------------------------------------------")
+	progress.Infof("✅ Deployment to '%s' environment for project '%s' successful!", env, projectName)
+	progress.Infof("   Commit:  %s (%s)", commitHash, commitHash[:7])
+	progress.Infof("   Slot:    %s", inactiveSlot)
+	progress.Infof("   Replicas: %d", len(containerNames))
+	progress.Infof("   Env File: %s", envFile)
 
-	domain, domainErr := config.GetEffectiveDomain(globalCfg, projCfg, "test")
+	domain, domainErr := config.GetEffectiveDomain(globalCfg, projCfg, env)
 	if domainErr == nil {
-		accessURL := fmt.Sprintf("%s", domain)
-		util.Log.Infof("   URL:     %s (Ensure DNS points to server IP!)", accessURL)
+		deploymentURL = accessURL(reflowBasePath, projCfg, env, domain)
+		progress.Infof("   URL:     %s (Ensure DNS points to server IP!)", deploymentURL)
 	} else {
-		util.Log.Warnf("   URL:     Could not determine URL: %v", domainErr)
+		progress.Warnf("   URL:     Could not determine URL: %v", domainErr)
 	}
 
-	util.Log.Info(" ")
-	util.Log.Info("Next steps:")
-	util.Log.Infof("  - Check status:  ./t project status %s", projectName)
-	util.Log.Infof("  - View logs:     ./t project logs %s --env test -f", projectName)
-	util.Log.Infof("  - Approve (Prod):./t approve %s", projectName)
-	util.Log.Info("-----------------------------------------------------")
+	progress.Info(" ")
+	progress.Info("Next steps:")
+	progress.Infof("  - Check status:  ./t project status %s", projectName)
+	progress.Infof("  - View logs:     ./t project logs %s --env %s -f", projectName, env)
+	if env == "test" {
+		progress.Infof("  - Approve (Prod):./t approve %s", projectName)
+	}
+	progress.Info("-----------------------------------------------------")
 
 	return nil
 }
+
+// buildImageWithRetry builds the image, retrying up to projCfg.BuildRetries times with
+// exponential backoff. Only the build is retried; later steps fail the deployment directly.
+func buildImageWithRetry(ctx context.Context, progress *deployment.Progress, projCfg *config.ProjectConfig, dockerfilePath, repoPath, imageTag string, buildArgs map[string]*string, buildOpts docker.BuildOptions) error {
+	backoff := time.Duration(projCfg.BuildRetryBackoff) * time.Second
+	if backoff <= 0 {
+		backoff = config.DefaultBuildRetryBackoffSeconds * time.Second
+	}
+	attempts := max(projCfg.BuildRetries, 0) + 1
+	if progress.Reporting() {
+		buildOpts.Progress = func(detail string) { progress.StepDetail("%s", detail) }
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := docker.BuildImage(ctx, dockerfilePath, repoPath, imageTag, buildArgs, buildOpts)
+		if err == nil {
+			return nil
+		}
+		if attempt >= attempts {
+			if attempts > 1 {
+				return fmt.Errorf("docker image build failed after %d attempts: %w", attempts, err)
+			}
+			return fmt.Errorf("docker image build failed: %w", err)
+		}
+
+		progress.Warnf("Image build attempt %d/%d failed: %v", attempt, attempts, err)
+		progress.Infof("Retrying image build in %v...", backoff)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("docker image build cancelled while waiting to retry: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// previousImageTag returns the image of the environment's active deployment, to be used as build
+// cache. States saved before lastImageTag was recorded fall back to the active commit's tag.
+func previousImageTag(projectName string, envState config.EnvironmentState) string {
+	if envState.LastImageTag != "" {
+		return envState.LastImageTag
+	}
+	if envState.ActiveCommit != "" {
+		return fmt.Sprintf("%s:%s", strings.ToLower(projectName), envState.ActiveCommit)
+	}
+	return ""
+}
+
+// resolveGitCommit fetches a git project's repository and resolves commitIsh to a full commit
+// hash. For shallow clones the requested revision is fetched directly at the clone depth first,
+// and the full history is only fetched if that is not enough.
+func resolveGitCommit(reflowBasePath string, projCfg *config.ProjectConfig, globalCfg *config.GlobalConfig, repoPath, commitIsh string) (string, error) {
+	gitAuth, err := internalGit.AuthConfigFromGlobal(globalCfg).
+		WithProjectSSHKey(projCfg.ProjectName, config.ProjectSSHKeyPath(reflowBasePath, projCfg), projCfg.SSHKeyPassphrase).
+		WithHTTPCredentials(reflowBasePath, projCfg.ProjectName, projCfg.GithubRepo)
+	if err != nil {
+		return "", fmt.Errorf("failed to load git credentials: %w", err)
+	}
+	if err = internalGit.FetchUpdates(repoPath, gitAuth); err != nil {
+		return "", fmt.Errorf("failed to fetch repository updates: %w", err)
+	}
+
+	shallow, shallowErr := internalGit.IsShallow(repoPath)
+	if shallowErr != nil {
+		util.Log.Warnf("Could not determine whether the repository is a shallow clone: %v", shallowErr)
+	}
+	if shallow && commitIsh != defaultCommit {
+		depth := projCfg.Clone.Depth
+		if depth <= 0 {
+			depth = 1 // Shallow clones made before clone.depth was recorded used depth 1
+		}
+		if err = internalGit.FetchRevision(repoPath, commitIsh, depth, gitAuth); err != nil {
+			util.Log.Warnf("Could not fetch '%s' directly: %v", commitIsh, err)
+		}
+	}
+
+	resolvedHash, err := internalGit.ResolveRevision(repoPath, commitIsh)
+	if err != nil {
+		if !shallow {
+			return "", fmt.Errorf("failed to resolve revision '%s': %w", commitIsh, err)
+		}
+
+		util.Log.Warnf("Revision '%s' not found in shallow clone history, fetching full history...", commitIsh)
+		if err = internalGit.Unshallow(repoPath, gitAuth); err != nil {
+			return "", fmt.Errorf("revision '%s' is not in the shallow clone history and the full history could not be fetched: %w (re-create the project without --shallow to deploy older commits)", commitIsh, err)
+		}
+		resolvedHash, err = internalGit.ResolveRevision(repoPath, commitIsh)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve revision '%s' even after deepening the shallow clone: %w (check that it exists upstream; if it does, re-create the project without --shallow)", commitIsh, err)
+		}
+	}
+	util.Log.Infof("Resolved '%s' to commit: %s", commitIsh, resolvedHash.String())
+	return resolvedHash.String(), nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflow/internal/config"
 	"reflow/internal/docker"
 	"reflow/internal/plugin" // Import plugin manager
@@ -16,13 +17,19 @@ import (
 )
 
 // DestroyReflow stops and removes all Reflow managed containers, network, and deletes the base directory.
-func DestroyReflow(ctx context.Context, reflowBasePath string, force bool) error {
+// Persistent data (named volumes, project and plugin data directories) is kept unless removeData is set.
+func DestroyReflow(ctx context.Context, reflowBasePath string, force bool, removeData bool) error {
 	util.Log.Warn("--- Starting Reflow Destruction ---")
 	util.Log.Warnf("This will stop and remove ALL Reflow managed containers (projects + nginx),")
-	util.Log.Warnf("remove the '%s' Docker network,", config.ReflowNetworkName)
+	util.Log.Warnf("remove the '%s' Docker network and any project or plugin networks,", config.ReflowNetworkName)
 	util.Log.Warnf("and IRREVERSIBLY DELETE the entire Reflow base directory:")
 	util.Log.Warnf("  %s", reflowBasePath)
 	util.Log.Warn("This includes all configurations, states, and cloned repositories.")
+	if removeData {
+		util.Log.Warn("Persistent data (Reflow managed volumes and data directories) will ALSO be deleted.")
+	} else {
+		util.Log.Warnf("Persistent data is kept: Reflow managed volumes, '%s/*/%s' and '%s/'.", config.AppsDirName, config.DataDirName, config.PluginDataDirName)
+	}
 
 	if !force {
 		fmt.Printf("Are you absolutely sure you want to proceed? (Type 'yes' to confirm): ")
@@ -117,29 +124,52 @@ func DestroyReflow(ctx context.Context, reflowBasePath string, force bool) error
 		errMsg := fmt.Sprintf("failed to remove nginx container %s: %v", config.ReflowNginxContainerName, rmErr)
 		util.Log.Error(errMsg)
 		if finalErr == nil {
-			finalErr = fmt.Errorf(errMsg)
+			finalErr = errors.New(errMsg)
 		}
 	}
 
-	// --- Remove Network ---
+	// --- Remove Networks ---
+	// Project and plugin networks from network policies first; the main network has no labels.
+	if netErr := docker.RemoveNetworksByLabels(ctx, map[string]string{docker.LabelManaged: "true"}); netErr != nil {
+		util.Log.Error(netErr.Error())
+		if finalErr == nil {
+			finalErr = netErr
+		}
+	}
 	util.Log.Infof("Removing Docker network '%s'...", config.ReflowNetworkName)
 	err = cli.NetworkRemove(ctx, config.ReflowNetworkName)
 	if err != nil && !strings.Contains(err.Error(), "not found") {
 		errMsg := fmt.Sprintf("failed to remove network %s: %v", config.ReflowNetworkName, err)
 		util.Log.Error(errMsg)
 		if finalErr == nil {
-			finalErr = fmt.Errorf(errMsg)
+			finalErr = errors.New(errMsg)
+		}
+	}
+
+	// --- Remove Volumes ---
+	if removeData {
+		util.Log.Info("Removing Reflow managed volumes...")
+		if volErr := docker.RemoveVolumesByLabels(ctx, map[string]string{docker.LabelManaged: "true"}); volErr != nil {
+			util.Log.Error(volErr.Error())
+			if finalErr == nil {
+				finalErr = volErr
+			}
 		}
 	}
 
 	// --- Delete Base Directory ---
-	util.Log.Warnf("DELETING Reflow base directory: %s", reflowBasePath)
-	err = os.RemoveAll(reflowBasePath)
+	if removeData {
+		util.Log.Warnf("DELETING Reflow base directory: %s", reflowBasePath)
+		err = os.RemoveAll(reflowBasePath)
+	} else {
+		util.Log.Warnf("DELETING Reflow base directory (except data directories): %s", reflowBasePath)
+		err = removeAllExceptData(reflowBasePath)
+	}
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to delete base directory %s: %v", reflowBasePath, err)
 		util.Log.Error(errMsg)
 		if finalErr == nil {
-			finalErr = fmt.Errorf(errMsg)
+			finalErr = errors.New(errMsg)
 		} else {
 			finalErr = fmt.Errorf("%w; %s", finalErr, errMsg)
 		}
@@ -154,3 +184,64 @@ func DestroyReflow(ctx context.Context, reflowBasePath string, force bool) error
 	util.Log.Warn("--- Reflow Destruction Complete ---")
 	return nil
 }
+
+// removeAllExceptData deletes the Reflow base directory but keeps project data directories
+// (apps/<name>/data) and plugin data (plugin-data/). Directories left empty are removed.
+func removeAllExceptData(reflowBasePath string) error {
+	entries, err := os.ReadDir(reflowBasePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(reflowBasePath, entry.Name())
+		switch {
+		case entry.Name() == config.PluginDataDirName:
+			continue
+		case entry.Name() == config.AppsDirName && entry.IsDir():
+			apps, readErr := os.ReadDir(entryPath)
+			if readErr != nil {
+				return readErr
+			}
+			for _, app := range apps {
+				appPath := filepath.Join(entryPath, app.Name())
+				if err := removeAllExcept(appPath, config.DataDirName); err != nil {
+					return err
+				}
+			}
+			_ = os.Remove(entryPath) // Only succeeds if no project data was kept
+		default:
+			if err := os.RemoveAll(entryPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	_ = os.Remove(reflowBasePath) // Only succeeds if nothing was kept
+	return nil
+}
+
+// removeAllExcept deletes everything in dir except the named entry, then removes dir if it is empty.
+func removeAllExcept(dir, keep string) error {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return os.RemoveAll(dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == keep {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	_ = os.Remove(dir)
+	return nil
+}
@@ -0,0 +1,227 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflow/internal/config"
+	"reflow/internal/deployment"
+	"reflow/internal/docker"
+	internalGit "reflow/internal/git"
+	"reflow/internal/nginx"
+	"reflow/internal/util"
+	"strings"
+)
+
+// dryRunKey marks a context whose deployments only report what they would do.
+type dryRunKey struct{}
+
+// WithDryRun returns a context that puts DeployTest, DeployProd and ApproveProd in dry-run mode:
+// the Dockerfile and Nginx config are generated and printed, and the planned container and
+// state changes are logged, but nothing is built, started, written or recorded. ImportProject
+// only logs its planned actions.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx was created by WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// noCacheKey marks a context whose deployments build the image without the build cache.
+type noCacheKey struct{}
+
+// WithNoCache returns a context that makes DeployTest and DeployProd build the image from
+// scratch, without reusing cached layers from earlier builds.
+func WithNoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// isNoCache reports whether ctx was created by WithNoCache.
+func isNoCache(ctx context.Context) bool {
+	noCache, _ := ctx.Value(noCacheKey{}).(bool)
+	return noCache
+}
+
+// pushKey marks a context whose deployments push the built image to the registry.
+type pushKey struct{}
+
+// WithPush returns a context that makes DeployTest and DeployProd push the built image to the
+// registry of the global config as <registry>/<project>:<commit>, so that other hosts can pull
+// it on approve.
+func WithPush(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pushKey{}, true)
+}
+
+// isPush reports whether ctx was created by WithPush.
+func isPush(ctx context.Context) bool {
+	push, _ := ctx.Value(pushKey{}).(bool)
+	return push
+}
+
+// progressSinkKey carries the sink a context's deployments report their steps to.
+type progressSinkKey struct{}
+
+// WithProgressSink returns a context that makes DeployTest and DeployProd report each step
+// (fetching the repository, building the image, starting containers, health checks, switching
+// Nginx) to sink, along with progress within the step such as build percentages.
+func WithProgressSink(ctx context.Context, sink deployment.ProgressSink) context.Context {
+	return context.WithValue(ctx, progressSinkKey{}, sink)
+}
+
+// progressSinks returns the sink attached to ctx by WithProgressSink, if any.
+func progressSinks(ctx context.Context) []deployment.ProgressSink {
+	if sink, ok := ctx.Value(progressSinkKey{}).(deployment.ProgressSink); ok && sink != nil {
+		return []deployment.ProgressSink{sink}
+	}
+	return nil
+}
+
+// triggerKey carries what started a context's deployments, for the deployment history.
+type triggerKey struct{}
+
+// defaultTrigger is recorded for deployments started from the CLI or the API.
+const defaultTrigger = "cli/api"
+
+// WithTrigger returns a context whose deployments, approvals and scaling are recorded in the
+// deployment history as triggered by trigger, e.g. "scheduler", instead of "cli/api".
+func WithTrigger(ctx context.Context, trigger string) context.Context {
+	return context.WithValue(ctx, triggerKey{}, trigger)
+}
+
+// triggeredBy returns the trigger attached to ctx by WithTrigger, or defaultTrigger.
+func triggeredBy(ctx context.Context) string {
+	if trigger, ok := ctx.Value(triggerKey{}).(string); ok && trigger != "" {
+		return trigger
+	}
+	return defaultTrigger
+}
+
+// printDryRunArtifact writes generated content to stdout between clearly marked delimiters.
+func printDryRunArtifact(title, content string) {
+	fmt.Printf("----- BEGIN %s (dry run) -----\n", title)
+	fmt.Print(content)
+	if !strings.HasSuffix(content, "\n") {
+		fmt.Println()
+	}
+	fmt.Printf("----- END %s -----\n", title)
+}
+
+// dryRunResolveCommit resolves the commit a deployment would use without fetching, checking out
+// or copying anything, so the repository is left untouched.
+func dryRunResolveCommit(projCfg *config.ProjectConfig, repoPath, commitIsh string) (string, error) {
+	if projCfg.SourceType == config.SourceTypeLocal {
+		sourcePath := repoPath
+		if projCfg.LocalPath != "" {
+			if _, err := os.Stat(projCfg.LocalPath); err == nil {
+				sourcePath = projCfg.LocalPath
+			}
+		}
+		hash, err := util.HashDir(sourcePath, ".git", reflowDockerfileName)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash local source: %w", err)
+		}
+		util.Log.Infof("[dry run] Would copy local source '%s'; content hash: %s", projCfg.LocalPath, hash)
+		return hash, nil
+	}
+
+	util.Log.Info("[dry run] Skipping fetch; resolving against the refs already in the local clone.")
+	resolvedHash, err := internalGit.ResolveRevision(repoPath, commitIsh)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve revision '%s' (a real deploy would fetch first): %w", commitIsh, err)
+	}
+	util.Log.Infof("[dry run] Resolved '%s' to commit: %s", commitIsh, resolvedHash.String())
+	return resolvedHash.String(), nil
+}
+
+// logDryRunContainerRemovals logs the containers a deployment would remove from a slot.
+func logDryRunContainerRemovals(ctx context.Context, projectName, env, slot string) {
+	containers, err := docker.FindContainersByLabels(ctx, map[string]string{
+		docker.LabelProject:     projectName,
+		docker.LabelEnvironment: env,
+		docker.LabelSlot:        slot,
+	})
+	if err != nil {
+		util.Log.Warnf("[dry run] Could not list containers in slot '%s': %v", slot, err)
+		return
+	}
+	if len(containers) == 0 {
+		util.Log.Infof("[dry run] No old containers in slot '%s' to remove.", slot)
+	}
+	for _, c := range containers {
+		util.Log.Infof("[dry run] Would stop and remove old container %s (%s)", c.ID[:12], strings.Join(c.Names, ","))
+	}
+}
+
+// dryRunContainerNames returns the names the replicas of a slot would get.
+func dryRunContainerNames(projectName, env, slot, commitHash string, replicas int) []string {
+	names := make([]string, 0, replicas)
+	for i := 1; i <= replicas; i++ {
+		names = append(names, replicaContainerName(projectName, env, slot, commitHash, i))
+	}
+	return names
+}
+
+// dryRunSwitchSlot reports the rest of a deployment once its image is known: the containers that
+// would be replaced and started, the Nginx config that would be applied (printed in full) and
+// the state that would be saved.
+func dryRunSwitchSlot(ctx context.Context, reflowBasePath string, projCfg *config.ProjectConfig, globalCfg *config.GlobalConfig, env string, envState config.EnvironmentState, inactiveSlot, imageTag, commitHash string) error {
+	projectName := projCfg.ProjectName
+	logDryRunContainerRemovals(ctx, projectName, env, inactiveSlot)
+
+	_, envFile, err := slotRunOptions(reflowBasePath, imageTag, projCfg, env, inactiveSlot, commitHash)
+	if err != nil {
+		return err
+	}
+	containerNames := dryRunContainerNames(projectName, env, inactiveSlot, commitHash, config.EffectiveReplicas(projCfg, env))
+	util.Log.Infof("[dry run] Would start %d container(s) from %s in slot '%s': %s", len(containerNames), imageTag, inactiveSlot, strings.Join(containerNames, ", "))
+	if projCfg.NetworkPolicy != nil {
+		util.Log.Infof("[dry run] Would also attach them to network '%s', joined by: %s", config.ProjectNetworkName(projCfg.ProjectName), strings.Join(projCfg.NetworkPolicy.AllowedContainers, ", "))
+	}
+	util.Log.Infof("[dry run] Env file: %s", envFile)
+	if len(projCfg.PostStartHooks) > 0 {
+		util.Log.Infof("[dry run] Would run %d post-start hook(s) in each container", len(projCfg.PostStartHooks))
+	}
+	util.Log.Infof("[dry run] Would health check each container on port %d", projCfg.AppPort)
+	if projCfg.Hooks.PreSwitch != "" {
+		util.Log.Infof("[dry run] Would run preSwitch hook for each container: %s", projCfg.Hooks.PreSwitch)
+	}
+
+	domain, err := config.GetEffectiveDomain(globalCfg, projCfg, env)
+	if err != nil {
+		return fmt.Errorf("failed to determine domain for nginx config: %w", err)
+	}
+	nginxData := nginx.TemplateData{ProjectName: projectName, Env: env, Slot: inactiveSlot, ContainerNames: containerNames, Domain: domain, AppPort: projCfg.AppPort, TLS: env == "prod" && tlsEnabled(reflowBasePath, projCfg, env, domain), RateLimit: config.EffectiveRateLimit(projCfg, env), ExtraLocations: projCfg.Environments[env].ExtraLocations}
+	nginxConfContent, err := nginx.GenerateNginxConfig(nginxData)
+	if err != nil {
+		return fmt.Errorf("failed to generate nginx config: %w", err)
+	}
+	printDryRunArtifact(fmt.Sprintf("Nginx config %s.%s.conf", projectName, env), nginxConfContent)
+	util.Log.Info("[dry run] Would write the Nginx config above, reload Nginx and verify the site is served through it")
+	if projCfg.Hooks.PostSwitch != "" {
+		util.Log.Infof("[dry run] Would run postSwitch hook for each container: %s", projCfg.Hooks.PostSwitch)
+	}
+	if env == "prod" && projCfg.AutoTLS {
+		util.Log.Infof("[dry run] Would ensure a TLS certificate for %s (autoTLS)", domain)
+	}
+
+	util.Log.Infof("[dry run] Would update '%s' state: active slot '%s' -> '%s', active commit '%s' -> '%s'", env, envState.ActiveSlot, inactiveSlot, shortCommit(envState.ActiveCommit), shortCommit(commitHash))
+	if globalCfg.AutoPruneImages {
+		util.Log.Infof("[dry run] Would prune old images for project '%s' (keeping %d)", projectName, config.EffectiveKeepImages(globalCfg, projCfg))
+	}
+
+	util.Log.Info("-----------------------------------------------------")
+	util.Log.Infof("Dry run for project '%s' to '%s' complete. No changes were made.", projectName, env)
+	util.Log.Info("-----------------------------------------------------")
+	return nil
+}
+
+// shortCommit abbreviates a commit hash for log output, or returns "none" if it is empty.
+func shortCommit(commitHash string) string {
+	if commitHash == "" {
+		return "none"
+	}
+	return commitHash[:min(7, len(commitHash))]
+}
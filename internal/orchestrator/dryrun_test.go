@@ -0,0 +1,16 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTriggeredBy(t *testing.T) {
+	ctx := context.Background()
+	if got := triggeredBy(ctx); got != "cli/api" {
+		t.Errorf("triggeredBy without a trigger = %q, want %q", got, "cli/api")
+	}
+	if got := triggeredBy(WithTrigger(WithDryRun(ctx), "scheduler")); got != "scheduler" {
+		t.Errorf("triggeredBy = %q, want %q", got, "scheduler")
+	}
+}
@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"fmt"
+	"reflow/internal/config"
+	"strings"
+)
+
+// CheckRequiredEnv checks a project environment's env file, as currently in its repository
+// directory, and its secrets against the environment's requiredEnv. It returns a description of
+// each required variable that is missing, empty or malformed; none means the check passed.
+func CheckRequiredEnv(reflowBasePath, projectName, env string) ([]string, error) {
+	if env != "test" && env != "prod" {
+		return nil, fmt.Errorf("invalid environment specified: %s", env)
+	}
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project config for '%s': %w", projectName, err)
+	}
+	return requiredEnvProblems(reflowBasePath, projCfg, env, "")
+}
+
+// checkRequiredEnv fails if the variables a deployment of commitHash to env would get lack any
+// of the environment's requiredEnv, so a deployment can stop before anything is built or started.
+func checkRequiredEnv(reflowBasePath string, projCfg *config.ProjectConfig, env, commitHash string) error {
+	problems, err := requiredEnvProblems(reflowBasePath, projCfg, env, commitHash)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("required environment variables for '%s' are not set correctly: %s (check the env file and 'reflow project secrets')", env, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// requiredEnvProblems loads an environment's variables for commitHash (the working tree if
+// empty) and checks them against its requiredEnv.
+func requiredEnvProblems(reflowBasePath string, projCfg *config.ProjectConfig, env, commitHash string) ([]string, error) {
+	entries := projCfg.Environments[env].RequiredEnv
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	required, err := config.ParseRequiredEnv(fmt.Sprintf("environments.%s.requiredEnv", env), entries)
+	if err != nil {
+		return nil, err
+	}
+	envVars, _, err := loadEnvironmentVars(reflowBasePath, projCfg, env, "", commitHash)
+	if err != nil {
+		return nil, err
+	}
+	return config.CheckRequiredEnv(required, envVars), nil
+}
@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/util"
+	"strings"
+	"time"
+)
+
+// switchHookRun describes one run of a hooks.preSwitch or hooks.postSwitch script.
+type switchHookRun struct {
+	Name          string // "preSwitch" or "postSwitch", for messages
+	Script        string // As configured, possibly prefixed with config.SwitchHookContainerPrefix
+	RepoPath      string // Host scripts are resolved against and run from the project's repository
+	ProjectName   string
+	Env           string
+	CommitHash    string
+	ContainerName string
+}
+
+// runSwitchHooks runs a switch hook once for each new container. It stops at the first failure
+// and returns an error that includes the script's error output.
+func runSwitchHooks(ctx context.Context, name, script, repoPath string, projCfg *config.ProjectConfig, env, commitHash string, containerNames []string) error {
+	if script == "" {
+		return nil
+	}
+	for _, containerName := range containerNames {
+		run := switchHookRun{
+			Name:          name,
+			Script:        script,
+			RepoPath:      repoPath,
+			ProjectName:   projCfg.ProjectName,
+			Env:           env,
+			CommitHash:    commitHash,
+			ContainerName: containerName,
+		}
+		if err := run.execute(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// environment returns the REFLOW_* variables passed to the script.
+func (r switchHookRun) environment() []string {
+	return []string{
+		"REFLOW_PROJECT=" + r.ProjectName,
+		"REFLOW_ENV=" + r.Env,
+		"REFLOW_CONTAINER_NAME=" + r.ContainerName,
+		"REFLOW_COMMIT=" + r.CommitHash,
+	}
+}
+
+func (r switchHookRun) execute(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, config.SwitchHookTimeoutSeconds*time.Second)
+	defer cancel()
+
+	if containerPath, inContainer := strings.CutPrefix(r.Script, config.SwitchHookContainerPrefix); inContainer {
+		util.Log.Infof("Running %s hook in container %s: %s", r.Name, r.ContainerName, containerPath)
+		output, exitCode, err := docker.ExecInContainer(ctx, r.ContainerName, []string{"sh", containerPath}, r.environment())
+		output = strings.TrimSpace(output)
+		if output != "" {
+			util.Log.Debugf("%s hook output:\n%s", r.Name, output)
+		}
+		if err != nil {
+			return fmt.Errorf("%s hook '%s' failed in container %s: %w", r.Name, containerPath, r.ContainerName, err)
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("%s hook '%s' exited with code %d in container %s: %s", r.Name, containerPath, exitCode, r.ContainerName, output)
+		}
+		return nil
+	}
+
+	scriptPath, err := hostScriptPath(r.RepoPath, r.Script)
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", r.Name, err)
+	}
+	util.Log.Infof("Running %s hook for container %s: %s", r.Name, r.ContainerName, scriptPath)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, scriptPath)
+	cmd.Dir = r.RepoPath
+	cmd.Env = append(os.Environ(), r.environment()...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if output := strings.TrimSpace(stdout.String()); output != "" {
+		util.Log.Debugf("%s hook output:\n%s", r.Name, output)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %ds", config.SwitchHookTimeoutSeconds)
+		}
+		return fmt.Errorf("%s hook '%s' failed for container %s: %v: %s", r.Name, r.Script, r.ContainerName, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// hostScriptPath resolves a host hook script inside the repository, following symlinks so a link
+// in the repository cannot point the hook at an executable elsewhere on the host. The config is
+// validated on load, but is checked again here before anything runs.
+func hostScriptPath(repoPath, script string) (string, error) {
+	scriptPath, err := config.SwitchHookScriptPath(repoPath, script)
+	if err != nil {
+		return "", err
+	}
+	realRepoPath, err := filepath.EvalSymlinks(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve repository path %s: %w", repoPath, err)
+	}
+	realScriptPath, err := filepath.EvalSymlinks(scriptPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve script '%s': %w", script, err)
+	}
+	relPath, err := filepath.Rel(realRepoPath, realScriptPath)
+	if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("'%s' resolves outside the repository", script)
+	}
+	return scriptPath, nil
+}
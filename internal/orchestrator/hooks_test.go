@@ -0,0 +1,29 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHostScriptPath(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoPath, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "scripts", "migrate.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/bin/sh", filepath.Join(repoPath, "scripts", "shell")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := hostScriptPath(repoPath, "scripts/migrate.sh"); err != nil || got != filepath.Join(repoPath, "scripts", "migrate.sh") {
+		t.Errorf("hostScriptPath(scripts/migrate.sh) = %q, %v", got, err)
+	}
+	for _, script := range []string{"/bin/sh", "../outside.sh", "scripts/shell"} {
+		if _, err := hostScriptPath(repoPath, script); err == nil {
+			t.Errorf("hostScriptPath(%q) succeeded, want an error", script)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/deployment"
+	"reflow/internal/docker"
+	"reflow/internal/nginx"
+	"reflow/internal/project"
+	"reflow/internal/util"
+	"strings"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// importSlot is the slot an adopted container becomes the active deployment of.
+const importSlot = "blue"
+
+// Container labels that may record the commit its image was built from, in order of preference.
+var commitLabels = []string{docker.LabelCommit, "org.opencontainers.image.revision"}
+
+// ImportProject creates a project for an app that was deployed by hand and adopts its running
+// container as the active deployment of env, instead of building and deploying from scratch.
+//
+// The project is created and its repository cloned as by 'project create'. The container's
+// image is tagged <project>:<commit>, using the commit recorded in the container's labels if it
+// resolves in the repository and the cloned HEAD otherwise. Since Docker cannot add labels to a
+// container, the container is then recreated from its own config with reflow's labels and name,
+// attached to the reflow network, and health-checked. Finally the project state records it as
+// the active 'blue' slot and the environment's Nginx config is written and loaded.
+//
+// With a dry-run context (see WithDryRun) the container is inspected and the planned actions are
+// logged, but nothing is created or changed.
+func ImportProject(ctx context.Context, reflowBasePath string, args config.CreateProjectArgs, env, containerRef string) (err error) {
+	dryRun := IsDryRun(ctx)
+	startTime := time.Now()
+
+	// --- 1. Validate ---
+	if env != "test" && env != "prod" {
+		return fmt.Errorf("invalid environment '%s': must be 'test' or 'prod'", env)
+	}
+	if args.SourceType != "" && args.SourceType != config.SourceTypeGit {
+		return fmt.Errorf("project import requires a git repository URL")
+	}
+	if args.ProjectName == "" || args.RepoURL == "" {
+		return fmt.Errorf("project name and repository URL are required")
+	}
+	if err := project.ValidateProjectName(args.ProjectName); err != nil {
+		return err
+	}
+	if err := project.CheckProjectNameAvailable(reflowBasePath, args.ProjectName); err != nil {
+		return err
+	}
+	projectBasePath := config.GetProjectBasePath(reflowBasePath, args.ProjectName)
+	if _, statErr := os.Stat(projectBasePath); statErr == nil {
+		return fmt.Errorf("project '%s' already exists at %s", args.ProjectName, projectBasePath)
+	}
+
+	// --- 2. Inspect the Container ---
+	original, err := docker.InspectContainer(ctx, containerRef)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container '%s': %w", containerRef, err)
+	}
+	originalName := strings.TrimPrefix(original.Name, "/")
+	if original.State == nil || !original.State.Running {
+		return fmt.Errorf("container '%s' is not running; start it before importing", originalName)
+	}
+	if original.Config.Labels[docker.LabelManaged] == "true" {
+		return fmt.Errorf("container '%s' is already managed by reflow (project '%s')", originalName, original.Config.Labels[docker.LabelProject])
+	}
+	if args.AppPort <= 0 {
+		if args.AppPort, err = exposedAppPort(original.Config.ExposedPorts); err != nil {
+			return fmt.Errorf("container '%s': %w; set the app port explicitly", originalName, err)
+		}
+	}
+	labelCommit, commitLabel := "", ""
+	for _, label := range commitLabels {
+		if value := original.Config.Labels[label]; value != "" {
+			labelCommit, commitLabel = value, label
+			break
+		}
+	}
+
+	projCfg := project.NewProjectConfig(args)
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		util.Log.Warnf("Could not load global config: %v", err)
+		globalCfg = &config.GlobalConfig{}
+	}
+	domain, err := config.GetEffectiveDomain(globalCfg, &projCfg, env)
+	if err != nil {
+		return fmt.Errorf("failed to determine domain for nginx config: %w", err)
+	}
+
+	if dryRun {
+		util.Log.Infof("[dry run] Would create project '%s' in %s and clone %s", args.ProjectName, projectBasePath, util.RedactURL(args.RepoURL))
+		if labelCommit != "" {
+			util.Log.Infof("[dry run] Would use commit '%s' from the container's '%s' label", labelCommit, commitLabel)
+		} else {
+			util.Log.Info("[dry run] Container has no commit label; would use the cloned repository's HEAD commit")
+		}
+		util.Log.Infof("[dry run] Would tag image %s as %s:<commit>", original.Config.Image, strings.ToLower(args.ProjectName))
+		util.Log.Infof("[dry run] Would stop container '%s' (%s) and recreate it as %s-%s-%s-<commit>-1 with reflow labels on network '%s'", originalName, original.ID[:12], strings.ToLower(args.ProjectName), env, importSlot, config.ReflowNetworkName)
+		util.Log.Infof("[dry run] Would health-check the new container on port %d and remove '%s' once it passes", args.AppPort, originalName)
+		util.Log.Infof("[dry run] Would record slot '%s' as active in the '%s' environment state", importSlot, env)
+		util.Log.Infof("[dry run] Would write and load the '%s' Nginx config for %s", env, domain)
+		return nil
+	}
+
+	// --- 3. Create the Project ---
+	if err = project.CreateProject(reflowBasePath, args); err != nil {
+		return err
+	}
+	adopted := false
+	defer func() {
+		if err != nil && !adopted {
+			util.Log.Warnf("Removing project directory %s after the failed import.", projectBasePath)
+			_ = os.RemoveAll(projectBasePath)
+		}
+	}()
+
+	// --- 4. Resolve the Commit and Tag the Image ---
+	repoPath := filepath.Join(projectBasePath, config.RepoDirName)
+	commitHash, err := resolveImportCommit(repoPath, labelCommit)
+	if err != nil {
+		return err
+	}
+	imageTag := fmt.Sprintf("%s:%s", strings.ToLower(args.ProjectName), commitHash)
+	if err = docker.TagImage(ctx, original.Image, imageTag); err != nil {
+		return err
+	}
+
+	// --- 5. Recreate the Container with Reflow's Labels ---
+	containerName := replicaContainerName(args.ProjectName, env, importSlot, commitHash, 1)
+	labels := map[string]string{
+		docker.LabelManaged:     "true",
+		docker.LabelProject:     args.ProjectName,
+		docker.LabelEnvironment: env,
+		docker.LabelSlot:        importSlot,
+		docker.LabelCommit:      commitHash,
+		docker.LabelReplica:     "1",
+	}
+	newID, err := docker.RecreateWithLabels(ctx, original.ID, containerName, labels, config.ReflowNetworkName)
+	if err != nil {
+		return fmt.Errorf("failed to adopt container '%s': %w", originalName, err)
+	}
+	if err = waitForHealthy(ctx, containerName, projCfg.AppPort, nil); err != nil {
+		util.Log.Warnf("Adopted container failed its health check; restoring '%s'.", originalName)
+		_ = docker.StopContainer(context.Background(), newID, nil)
+		_ = docker.RemoveContainer(context.Background(), newID)
+		if startErr := docker.StartContainer(context.Background(), original.ID); startErr != nil {
+			util.Log.Errorf("Failed to restart original container '%s': %v", originalName, startErr)
+		}
+		return err
+	}
+	adopted = true
+
+	// --- 6. Record the Deployment ---
+	projState := &config.ProjectState{}
+	envState := config.EnvironmentState{ActiveSlot: importSlot, InactiveSlot: "green", ActiveCommit: commitHash, LastImageTag: imageTag}
+	if env == "test" {
+		projState.Test = envState
+	} else {
+		projState.Prod = envState
+	}
+	if err = config.SaveProjectState(reflowBasePath, args.ProjectName, projState); err != nil {
+		return fmt.Errorf("container adopted as '%s', but failed to save project state: %w", containerName, err)
+	}
+	deployment.LogEvent(reflowBasePath, args.ProjectName, &config.DeploymentEvent{
+		Timestamp:   time.Now(),
+		EventType:   "import",
+		ProjectName: args.ProjectName,
+		Environment: env,
+		CommitSHA:   commitHash,
+		Slot:        importSlot,
+		Outcome:     "success",
+		DurationMs:  time.Since(startTime).Milliseconds(),
+		TriggeredBy: "cli",
+	})
+
+	// --- 7. Update Nginx ---
+	nginxData := nginx.TemplateData{ProjectName: args.ProjectName, Env: env, Slot: importSlot, ContainerNames: []string{containerName}, Domain: domain, AppPort: projCfg.AppPort, TLS: tlsEnabled(reflowBasePath, &projCfg, env, domain), RateLimit: config.EffectiveRateLimit(&projCfg, env)}
+	nginxConfContent, err := nginx.GenerateNginxConfig(nginxData)
+	if err != nil {
+		return fmt.Errorf("container adopted, but failed to generate nginx config: %w", err)
+	}
+	if err = applyNginxConfig(ctx, reflowBasePath, &projCfg, env, nginxConfContent, nil); err != nil {
+		return fmt.Errorf("container adopted, but the nginx config could not be applied (original container '%s' is kept, stopped): %w", originalName, err)
+	}
+
+	if rmErr := docker.RemoveContainer(ctx, original.ID); rmErr != nil {
+		util.Log.Warnf("Could not remove the original container '%s': %v", originalName, rmErr)
+	}
+
+	util.Log.Info("-----------------------------------------------------")
+	util.Log.Infof("✅ Project '%s' imported; container '%s' adopted as the active '%s' deployment.", args.ProjectName, originalName, env)
+	util.Log.Infof("   Commit:    %s", commitHash)
+	util.Log.Infof("   Container: %s", containerName)
+	util.Log.Infof("   URL:       http://%s", domain)
+	util.Log.Info("-----------------------------------------------------")
+	return nil
+}
+
+// exposedAppPort returns the container's only exposed TCP port.
+func exposedAppPort(exposed map[nat.Port]struct{}) (int, error) {
+	var ports []int
+	for port := range exposed {
+		if port.Proto() == "tcp" {
+			ports = append(ports, port.Int())
+		}
+	}
+	switch len(ports) {
+	case 0:
+		return 0, fmt.Errorf("no exposed TCP port to route traffic to")
+	case 1:
+		return ports[0], nil
+	default:
+		return 0, fmt.Errorf("%d exposed TCP ports, cannot tell which one is the app port", len(ports))
+	}
+}
+
+// resolveImportCommit resolves the commit recorded on an adopted container to a full hash in the
+// cloned repository, falling back to the repository's HEAD.
+func resolveImportCommit(repoPath, labelCommit string) (string, error) {
+	repo, err := gogit.PlainOpen(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository at %s: %w", repoPath, err)
+	}
+	if labelCommit != "" {
+		if hash, resolveErr := repo.ResolveRevision(plumbing.Revision(labelCommit)); resolveErr == nil {
+			return hash.String(), nil
+		}
+		util.Log.Warnf("Commit '%s' from the container's labels was not found in the repository; using HEAD instead.", labelCommit)
+	} else {
+		util.Log.Warn("Container has no commit label; recording the repository's HEAD commit as deployed.")
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD of %s: %w", repoPath, err)
+	}
+	return head.Hash().String(), nil
+}
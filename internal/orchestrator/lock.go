@@ -0,0 +1,30 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrDeploymentInProgress is returned when a project already has a deployment or approval
+// running in this process.
+var ErrDeploymentInProgress = errors.New("deployment already in progress")
+
+// projectLocks holds a *sync.Mutex per project name, guarding its state during deployments.
+var projectLocks sync.Map
+
+// lockProject claims the project's deployment lock without waiting, so a second deployment fails
+// fast instead of queueing behind the first. The returned function releases the lock.
+//
+// The lock only covers this process: deployments, approvals and scaling started through one
+// 'reflow server' (API, scheduler and webhooks) exclude each other, but a CLI command such as
+// 'reflow deploy' or 'reflow project rename' run at the same time is not stopped and can race
+// with it on the project's state.json.
+func lockProject(projectName string) (func(), error) {
+	value, _ := projectLocks.LoadOrStore(projectName, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	if !mu.TryLock() {
+		return nil, fmt.Errorf("project '%s': %w", projectName, ErrDeploymentInProgress)
+	}
+	return mu.Unlock, nil
+}
@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestLockProject(t *testing.T) {
+	unlock, err := lockProject("lock-test")
+	if err != nil {
+		t.Fatalf("first lock failed: %v", err)
+	}
+
+	if _, err := lockProject("lock-test"); !errors.Is(err, ErrDeploymentInProgress) {
+		t.Errorf("second lock error = %v, want ErrDeploymentInProgress", err)
+	}
+	otherUnlock, err := lockProject("lock-test-other")
+	if err != nil {
+		t.Errorf("lock of another project failed: %v", err)
+	} else {
+		otherUnlock()
+	}
+
+	unlock()
+	unlock, err = lockProject("lock-test")
+	if err != nil {
+		t.Fatalf("lock after unlock failed: %v", err)
+	}
+	unlock()
+}
+
+func TestScaleProjectEnvDuringDeployment(t *testing.T) {
+	unlock, err := lockProject("scale-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	err = ScaleProjectEnv(context.Background(), t.TempDir(), "scale-test", "test", 2)
+	if !errors.Is(err, ErrDeploymentInProgress) {
+		t.Errorf("ScaleProjectEnv during a deployment: error = %v, want ErrDeploymentInProgress", err)
+	}
+}
+
+func TestDeploymentsDuringDeployment(t *testing.T) {
+	unlock, err := lockProject("deploy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	base := t.TempDir()
+	if _, err := DeployTest(context.Background(), base, "deploy-test", "main"); !errors.Is(err, ErrDeploymentInProgress) {
+		t.Errorf("DeployTest during a deployment: error = %v, want ErrDeploymentInProgress", err)
+	}
+	if err := ApproveProd(context.Background(), base, "deploy-test", ""); !errors.Is(err, ErrDeploymentInProgress) {
+		t.Errorf("ApproveProd during a deployment: error = %v, want ErrDeploymentInProgress", err)
+	}
+}
+
+func TestLockProjectConcurrent(t *testing.T) {
+	const attempts = 10
+	var wg sync.WaitGroup
+	results := make(chan error, attempts)
+	unlocks := make(chan func(), attempts)
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := lockProject("concurrent-test")
+			if err == nil {
+				unlocks <- unlock
+			}
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+	close(unlocks)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+		} else if !errors.Is(err, ErrDeploymentInProgress) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d of %d concurrent locks succeeded, want 1", succeeded, attempts)
+	}
+	for unlock := range unlocks {
+		unlock()
+	}
+}
@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/nginx"
+	"reflow/internal/util"
+)
+
+// SetMaintenanceMode turns maintenance mode on or off for a project environment. While on, Nginx
+// answers every request for the environment's domain with a 503 and the project's maintenance
+// page; the site config itself is left alone, so deployments can run underneath and their config
+// takes over again once maintenance mode is turned off.
+//
+// The project is not locked, so maintenance mode can be switched while a deployment is running.
+func SetMaintenanceMode(ctx context.Context, reflowBasePath, projectName, env string, enabled bool) error {
+	if env != "test" && env != "prod" {
+		return fmt.Errorf("invalid environment '%s': must be 'test' or 'prod'", env)
+	}
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to load project config for '%s': %w", projectName, err)
+	}
+
+	snapshot, err := nginx.SnapshotMaintenanceConfig(reflowBasePath, projectName, env)
+	if err != nil {
+		return fmt.Errorf("failed to back up current maintenance config: %w", err)
+	}
+
+	if !enabled {
+		if !nginx.MaintenanceEnabled(reflowBasePath, projectName, env) {
+			util.Log.Infof("Project '%s' env '%s' is not in maintenance mode.", projectName, env)
+			return nginx.RemoveMaintenanceConfig(reflowBasePath, projectName, env)
+		}
+		if err := nginx.RemoveMaintenanceConfig(reflowBasePath, projectName, env); err != nil {
+			return err
+		}
+		if err := nginx.ReloadNginx(ctx); err != nil {
+			rollbackNginxConfig(ctx, snapshot)
+			return fmt.Errorf("failed to reload nginx: %w", err)
+		}
+		util.Log.Infof("Maintenance mode disabled for project '%s' env '%s'.", projectName, env)
+		return nil
+	}
+
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+	domain, err := config.GetEffectiveDomain(globalCfg, projCfg, env)
+	if err != nil {
+		return fmt.Errorf("failed to determine domain for env '%s': %w", env, err)
+	}
+	data := nginx.MaintenanceTemplateData{
+		ProjectName: projectName,
+		Env:         env,
+		Domain:      domain,
+		TLS:         tlsEnabled(reflowBasePath, projCfg, env, domain),
+	}
+
+	page, err := maintenancePage(reflowBasePath, projCfg, data)
+	if err != nil {
+		return err
+	}
+	if err := nginx.WriteMaintenanceConfig(reflowBasePath, data, page); err != nil {
+		return err
+	}
+	if err := nginx.ReloadNginx(ctx); err != nil {
+		rollbackNginxConfig(ctx, snapshot)
+		return fmt.Errorf("failed to reload nginx: %w", err)
+	}
+	util.Log.Infof("Maintenance mode enabled for project '%s' env '%s': %s now serves a 503 maintenance page.", projectName, env, domain)
+	return nil
+}
+
+// maintenancePage returns the project's maintenancePage file, or the built-in page if none is set.
+func maintenancePage(reflowBasePath string, projCfg *config.ProjectConfig, data nginx.MaintenanceTemplateData) ([]byte, error) {
+	if projCfg.MaintenancePage == "" {
+		return nginx.GenerateDefaultMaintenancePage(data)
+	}
+	pagePath := projCfg.MaintenancePage
+	if !filepath.IsAbs(pagePath) {
+		pagePath = filepath.Join(config.GetProjectBasePath(reflowBasePath, projCfg.ProjectName), pagePath)
+	}
+	page, err := os.ReadFile(pagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance page %s (check maintenancePage): %w", pagePath, err)
+	}
+	return page, nil
+}
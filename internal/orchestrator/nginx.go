@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/config"
+	"reflow/internal/nginx"
+	"reflow/internal/util"
+)
+
+// reloadNginx reloads the Nginx container. Tests replace it to simulate Nginx rejecting a config.
+var reloadNginx = nginx.ReloadNginx
+
+// siteCheck describes what applyNginxConfig verifies after a reload: that Domain is served
+// through Nginx without a 5xx, by Commit if the app reports it in nginx.CommitHeader.
+type siteCheck struct {
+	Domain string
+	TLS    bool
+	Commit string
+}
+
+// applyNginxConfig writes a project environment's site config and the project's rate limit
+// config, then reloads Nginx. If the reload fails, the previous files are restored and Nginx
+// is reloaded again, so a rejected config never stays on disk to break later reloads. With a
+// check, the site is then requested through Nginx, and the previous config is restored in the
+// same way if it is not served correctly. The check is skipped while the environment is in
+// maintenance mode, as Nginx then answers with the maintenance page.
+func applyNginxConfig(ctx context.Context, reflowBasePath string, projCfg *config.ProjectConfig, env, content string, check *siteCheck) error {
+	snapshot, err := nginx.SnapshotProjectConfig(reflowBasePath, projCfg.ProjectName, env)
+	if err != nil {
+		return fmt.Errorf("failed to back up current nginx config: %w", err)
+	}
+
+	if err := nginx.WriteNginxConfig(reflowBasePath, projCfg.ProjectName, env, content); err != nil {
+		return fmt.Errorf("failed to write nginx config: %w", err)
+	}
+	if err := nginx.WriteRateLimitConfig(reflowBasePath, projCfg); err != nil {
+		rollbackNginxConfig(ctx, snapshot)
+		return fmt.Errorf("failed to write nginx rate limit config: %w", err)
+	}
+	if err := reloadNginx(ctx); err != nil {
+		rollbackNginxConfig(ctx, snapshot)
+		return fmt.Errorf("failed to reload nginx: %w", err)
+	}
+
+	if check != nil && !nginx.MaintenanceEnabled(reflowBasePath, projCfg.ProjectName, env) {
+		util.Log.Infof("Verifying %s is served through Nginx...", check.Domain)
+		if err := nginx.VerifySite(ctx, check.Domain, check.TLS, check.Commit); err != nil {
+			rollbackNginxConfig(ctx, snapshot)
+			return fmt.Errorf("nginx switch verification failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// rollbackNginxConfig restores a config snapshot and reloads Nginx with it. Failures are only
+// logged, since the caller is already returning the error that triggered the rollback.
+func rollbackNginxConfig(ctx context.Context, snapshot *nginx.ConfigSnapshot) {
+	util.Log.Warn("Restoring previous Nginx configuration...")
+	if err := snapshot.Restore(); err != nil {
+		util.Log.Errorf("Failed to restore previous Nginx configuration: %v", err)
+		return
+	}
+	if err := reloadNginx(ctx); err != nil {
+		util.Log.Errorf("Failed to reload Nginx with the restored configuration: %v", err)
+		return
+	}
+	util.Log.Info("Previous Nginx configuration restored.")
+}
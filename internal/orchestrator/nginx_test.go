@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"testing"
+)
+
+// failFirstReload makes the next reload fail, as when Nginx rejects a config, and lets the
+// rollback reload succeed. It returns a pointer to the number of reloads.
+func failFirstReload(t *testing.T) *int {
+	t.Helper()
+	reloads := 0
+	previous := reloadNginx
+	reloadNginx = func(ctx context.Context) error {
+		reloads++
+		if reloads == 1 {
+			return errors.New("nginx: [emerg] invalid config")
+		}
+		return nil
+	}
+	t.Cleanup(func() { reloadNginx = previous })
+	return &reloads
+}
+
+func TestApplyNginxConfigRestoresPreviousConfig(t *testing.T) {
+	base := t.TempDir()
+	confPath := filepath.Join(base, config.NginxDirName, config.NginxConfDirName, "app.test.conf")
+	if err := os.MkdirAll(filepath.Dir(confPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(confPath, []byte("previous config"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reloads := failFirstReload(t)
+
+	err := applyNginxConfig(context.Background(), base, &config.ProjectConfig{ProjectName: "app"}, "test", "broken config", nil)
+	if err == nil {
+		t.Fatal("applyNginxConfig succeeded although the reload failed")
+	}
+	content, readErr := os.ReadFile(confPath)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if string(content) != "previous config" {
+		t.Errorf("config after rollback = %q, want the previous config", content)
+	}
+	if *reloads != 2 {
+		t.Errorf("nginx reloaded %d times, want 2 (the failed reload and the rollback)", *reloads)
+	}
+}
+
+func TestApplyNginxConfigRemovesNewConfig(t *testing.T) {
+	base := t.TempDir()
+	confPath := filepath.Join(base, config.NginxDirName, config.NginxConfDirName, "app.prod.conf")
+	failFirstReload(t)
+
+	err := applyNginxConfig(context.Background(), base, &config.ProjectConfig{ProjectName: "app"}, "prod", "broken config", nil)
+	if err == nil {
+		t.Fatal("applyNginxConfig succeeded although the reload failed")
+	}
+	if _, statErr := os.Stat(confPath); !os.IsNotExist(statErr) {
+		t.Errorf("new config %s was not removed: %v", confPath, statErr)
+	}
+}
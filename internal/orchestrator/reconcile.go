@@ -0,0 +1,288 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/nginx"
+	"reflow/internal/util"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// Kinds of discrepancy reported by ReconcileProject.
+const (
+	DiscrepancyStateMismatch    = "state-mismatch"    // State names another slot/commit than the one serving traffic
+	DiscrepancyUntracked        = "untracked"         // A deployment is serving traffic but state records none
+	DiscrepancyMissingContainer = "missing-container" // State names an active deployment that has no running container
+	DiscrepancyOrphanContainer  = "orphan-container"  // A container the active deployment does not account for
+)
+
+// Discrepancy is a difference between a project's recorded state and its Docker containers.
+type Discrepancy struct {
+	Environment string
+	Kind        string
+	Message     string
+	Remediation string
+	Fixed       bool
+}
+
+// slotCommit identifies the containers of one deployment.
+type slotCommit struct {
+	Slot   string
+	Commit string
+}
+
+// ReconcileProject compares a project's state.json with the containers labelled for it in each
+// environment. The deployment that is actually live is taken from the running, healthy
+// containers, narrowed to those the environment's Nginx config proxies to when it exists.
+//
+// Containers in the inactive slot are the previous deployment, kept for rollback until the next
+// deployment or 'project cleanup', and are not reported. With fix set, state is updated to the
+// live deployment when it can be determined unambiguously, and orphaned containers are stopped
+// and removed.
+func ReconcileProject(ctx context.Context, reflowBasePath, projectName string, fix bool) ([]Discrepancy, error) {
+	if fix {
+		unlock, err := lockProject(projectName)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	projState, err := config.LoadProjectState(reflowBasePath, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project state for '%s': %w", projectName, err)
+	}
+
+	stopTimeout := config.DefaultStopTimeoutSeconds * time.Second
+	if projCfg, cfgErr := config.LoadProjectConfig(reflowBasePath, projectName); cfgErr == nil && projCfg.StopTimeout > 0 {
+		stopTimeout = time.Duration(projCfg.StopTimeout) * time.Second
+	}
+
+	var discrepancies []Discrepancy
+	stateChanged := false
+	for _, env := range []string{"test", "prod"} {
+		envState := &projState.Test
+		if env == "prod" {
+			envState = &projState.Prod
+		}
+		found, changed, err := reconcileEnv(ctx, reflowBasePath, projectName, env, envState, fix, stopTimeout)
+		if err != nil {
+			return discrepancies, err
+		}
+		discrepancies = append(discrepancies, found...)
+		stateChanged = stateChanged || changed
+	}
+
+	if stateChanged {
+		if err := config.SaveProjectState(reflowBasePath, projectName, projState); err != nil {
+			return discrepancies, fmt.Errorf("failed to save reconciled state for '%s': %w", projectName, err)
+		}
+		util.Log.Infof("Updated state for project '%s' to match its running containers.", projectName)
+	}
+	return discrepancies, nil
+}
+
+// reconcileEnv checks one environment. Returns whether envState was modified.
+func reconcileEnv(ctx context.Context, reflowBasePath, projectName, env string, envState *config.EnvironmentState, fix bool, stopTimeout time.Duration) ([]Discrepancy, bool, error) {
+	containers, err := docker.FindContainersByLabels(ctx, map[string]string{
+		docker.LabelProject:     projectName,
+		docker.LabelEnvironment: env,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to find containers for project '%s' env '%s': %w", projectName, env, err)
+	}
+	served, err := servedContainerNames(reflowBasePath, projectName, env)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var discrepancies []Discrepancy
+	stateChanged := false
+	recorded := slotCommit{Slot: envState.ActiveSlot, Commit: envState.ActiveCommit}
+	live := liveDeployment(containers, served)
+
+	// --- 1. Active Deployment ---
+	switch {
+	case recorded.Commit == "" && live != nil:
+		d := Discrepancy{
+			Environment: env,
+			Kind:        DiscrepancyUntracked,
+			Message:     fmt.Sprintf("state records no active deployment, but slot '%s' is serving commit %s", live.Slot, shortCommit(live.Commit)),
+			Remediation: "Run with --fix to record it as the active deployment",
+		}
+		if fix {
+			adoptDeployment(envState, *live)
+			d.Fixed, stateChanged = true, true
+		}
+		discrepancies = append(discrepancies, d)
+	case recorded.Commit != "" && live != nil && *live != recorded:
+		d := Discrepancy{
+			Environment: env,
+			Kind:        DiscrepancyStateMismatch,
+			Message: fmt.Sprintf("state says slot '%s' runs commit %s, but slot '%s' is serving commit %s",
+				recorded.Slot, shortCommit(recorded.Commit), live.Slot, shortCommit(live.Commit)),
+			Remediation: "Run with --fix to update state to the serving deployment",
+		}
+		if fix {
+			adoptDeployment(envState, *live)
+			d.Fixed, stateChanged = true, true
+		}
+		discrepancies = append(discrepancies, d)
+	case recorded.Commit != "" && live == nil && !hasRunningContainer(containers, recorded):
+		d := Discrepancy{
+			Environment: env,
+			Kind:        DiscrepancyMissingContainer,
+			Message:     fmt.Sprintf("state says slot '%s' runs commit %s, but no container for it is running", recorded.Slot, shortCommit(recorded.Commit)),
+		}
+		if countContainers(containers, recorded) > 0 {
+			d.Remediation = fmt.Sprintf("Start it with 'reflow project start %s --env %s'", projectName, env)
+		} else if env == "test" {
+			d.Remediation = fmt.Sprintf("Redeploy with 'reflow deploy %s'", projectName)
+		} else {
+			d.Remediation = fmt.Sprintf("Redeploy with 'reflow approve %s'", projectName)
+		}
+		discrepancies = append(discrepancies, d)
+	}
+
+	// --- 2. Orphaned Containers ---
+	// Judge containers against the deployment that is really live, even when state was not fixed.
+	active := recorded
+	if live != nil {
+		active = *live
+	}
+	for _, c := range containers {
+		owner := slotCommit{Slot: c.Labels[docker.LabelSlot], Commit: c.Labels[docker.LabelCommit]}
+		if owner == active || (active.Commit != "" && owner.Slot != active.Slot) {
+			continue
+		}
+		containerName := strings.TrimPrefix(strings.Join(c.Names, ", "), "/")
+		d := Discrepancy{Environment: env, Kind: DiscrepancyOrphanContainer}
+		if active.Commit == "" {
+			d.Message = fmt.Sprintf("container %s (slot '%s', commit %s, %s) belongs to no recorded or serving deployment", containerName, owner.Slot, shortCommit(owner.Commit), c.State)
+		} else {
+			d.Message = fmt.Sprintf("container %s in active slot '%s' runs commit %s instead of %s (%s)", containerName, owner.Slot, shortCommit(owner.Commit), shortCommit(active.Commit), c.State)
+		}
+		d.Remediation = "Run with --fix to stop and remove it"
+		if active.Commit == "" && c.State == "running" {
+			// Several deployments are running and none is recorded or served, so there is no
+			// telling which one should stay.
+			d.Remediation = fmt.Sprintf("Redeploy, or remove it with 'docker rm -f %s' if it is not needed", containerName)
+			discrepancies = append(discrepancies, d)
+			continue
+		}
+		if fix {
+			if err := removeOrphanContainer(ctx, c, stopTimeout); err != nil {
+				d.Message += fmt.Sprintf(" (removal failed: %v)", err)
+			} else {
+				d.Fixed = true
+			}
+		}
+		discrepancies = append(discrepancies, d)
+	}
+
+	return discrepancies, stateChanged, nil
+}
+
+// servedContainerNames returns the upstream containers of the environment's Nginx config, or
+// an empty set if it has none.
+func servedContainerNames(reflowBasePath, projectName, env string) (map[string]bool, error) {
+	managed, err := nginx.ListManagedConfigs(reflowBasePath)
+	if err != nil {
+		return nil, err
+	}
+	served := make(map[string]bool)
+	confFileName := fmt.Sprintf("%s.%s.conf", projectName, env)
+	for _, conf := range managed {
+		if conf.FileName != confFileName {
+			continue
+		}
+		for _, upstream := range conf.Upstreams {
+			served[upstream] = true
+		}
+	}
+	return served, nil
+}
+
+// liveDeployment returns the deployment the running, healthy containers belong to, considering
+// only served containers when Nginx proxies to any. Returns nil when there is none, or when
+// more than one deployment qualifies.
+func liveDeployment(containers []container.Summary, served map[string]bool) *slotCommit {
+	deployments := make(map[slotCommit]bool)
+	for _, c := range containers {
+		if c.State != "running" || strings.Contains(c.Status, "(unhealthy)") {
+			continue
+		}
+		if len(served) > 0 && !isServed(c, served) {
+			continue
+		}
+		deployments[slotCommit{Slot: c.Labels[docker.LabelSlot], Commit: c.Labels[docker.LabelCommit]}] = true
+	}
+	if len(deployments) != 1 {
+		return nil
+	}
+	for deployment := range deployments {
+		if deployment.Slot == "" || deployment.Commit == "" {
+			return nil
+		}
+		return &deployment
+	}
+	return nil
+}
+
+func isServed(c container.Summary, served map[string]bool) bool {
+	for _, name := range c.Names {
+		if served[strings.TrimPrefix(name, "/")] {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRunningContainer(containers []container.Summary, deployment slotCommit) bool {
+	for _, c := range containers {
+		if c.State == "running" && c.Labels[docker.LabelSlot] == deployment.Slot && c.Labels[docker.LabelCommit] == deployment.Commit {
+			return true
+		}
+	}
+	return false
+}
+
+func countContainers(containers []container.Summary, deployment slotCommit) int {
+	count := 0
+	for _, c := range containers {
+		if c.Labels[docker.LabelSlot] == deployment.Slot && c.Labels[docker.LabelCommit] == deployment.Commit {
+			count++
+		}
+	}
+	return count
+}
+
+// adoptDeployment records deployment as the environment's active one.
+func adoptDeployment(envState *config.EnvironmentState, deployment slotCommit) {
+	envState.ActiveSlot = deployment.Slot
+	envState.ActiveCommit = deployment.Commit
+	envState.PendingCommit = ""
+	if deployment.Slot == "blue" {
+		envState.InactiveSlot = "green"
+	} else {
+		envState.InactiveSlot = "blue"
+	}
+}
+
+func removeOrphanContainer(ctx context.Context, c container.Summary, stopTimeout time.Duration) error {
+	if c.State == "running" {
+		if _, err := docker.StopContainerGracefully(ctx, c.ID, stopTimeout); err != nil {
+			util.Log.Debugf("Ignoring error stopping orphaned container %s: %v", c.ID[:12], err)
+		}
+	}
+	if err := docker.RemoveContainer(ctx, c.ID); err != nil {
+		return err
+	}
+	util.Log.Infof("Removed orphaned container %s (%s)", strings.Join(c.Names, ", "), c.ID[:12])
+	return nil
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/config"
+	"reflow/internal/deployment"
+	"reflow/internal/docker"
+	"reflow/internal/util"
+)
+
+// pushImageToRegistry tags a project image for the configured registry as
+// <registry>/<project>:<commit> and pushes it. The registry tag is removed locally afterwards,
+// so image pruning, which only looks at <project>:<commit> tags, can still remove the image.
+func pushImageToRegistry(ctx context.Context, progress *deployment.Progress, globalCfg *config.GlobalConfig, projectName, imageTag, commitHash string) error {
+	registryAuth, err := docker.ImageRegistryAuth(globalCfg.Registry)
+	if err != nil {
+		return err
+	}
+	registryImage := docker.RegistryImageName(globalCfg.Registry.URL, projectName, commitHash)
+	if err := docker.TagImage(ctx, imageTag, registryImage); err != nil {
+		return err
+	}
+	defer func() {
+		if untagErr := docker.UntagImage(context.Background(), registryImage); untagErr != nil {
+			util.Log.Debugf("Could not remove local registry tag: %v", untagErr)
+		}
+	}()
+
+	progress.Infof("Pushing image to registry as %s...", registryImage)
+	if err := docker.PushImage(ctx, registryImage, registryAuth); err != nil {
+		return fmt.Errorf("image was built but could not be pushed: %w", err)
+	}
+	return nil
+}
+
+// pullImageFromRegistry pulls a commit's image from the configured registry and tags it as the
+// local imageTag (<project>:<commit>), for hosts that did not build it themselves.
+func pullImageFromRegistry(ctx context.Context, globalCfg *config.GlobalConfig, projectName, imageTag, commitHash string) error {
+	registryAuth, err := docker.ImageRegistryAuth(globalCfg.Registry)
+	if err != nil {
+		return err
+	}
+	registryImage := docker.RegistryImageName(globalCfg.Registry.URL, projectName, commitHash)
+	if err := docker.PullImage(ctx, registryImage, registryAuth); err != nil {
+		return err
+	}
+	if err := docker.TagImage(ctx, registryImage, imageTag); err != nil {
+		return err
+	}
+	if err := docker.UntagImage(ctx, registryImage); err != nil {
+		util.Log.Debugf("Could not remove local registry tag: %v", err)
+	}
+	return nil
+}
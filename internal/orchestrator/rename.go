@@ -0,0 +1,248 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/deployment"
+	"reflow/internal/docker"
+	"reflow/internal/nginx"
+	"reflow/internal/project"
+	"reflow/internal/util"
+	"strings"
+	"time"
+)
+
+// RenameProject renames a project and redeploys its active environments under the new name.
+// Container names, labels, image tags and Nginx config filenames all embed the project name,
+// so the active containers are recreated in their current slots from retagged images.
+func RenameProject(ctx context.Context, reflowBasePath, oldName, newName string) error {
+	util.Log.Infof("Renaming project '%s' to '%s'...", oldName, newName)
+
+	// --- 1. Validate ---
+	if oldName == newName {
+		return fmt.Errorf("new project name is the same as the current name '%s'", oldName)
+	}
+	if err := project.ValidateProjectName(newName); err != nil {
+		return err
+	}
+	oldProjectPath := config.GetProjectBasePath(reflowBasePath, oldName)
+	newProjectPath := config.GetProjectBasePath(reflowBasePath, newName)
+	if _, err := os.Stat(newProjectPath); err == nil {
+		return fmt.Errorf("project '%s' already exists", newName)
+	}
+	if !strings.EqualFold(oldName, newName) {
+		if err := project.CheckProjectNameAvailable(reflowBasePath, newName); err != nil {
+			return err
+		}
+	}
+
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, oldName)
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+	// Docker volumes can't be renamed, and named volumes are prefixed with the project name.
+	for _, v := range projCfg.Volumes {
+		if !config.IsHostPathVolume(v.Source) {
+			return fmt.Errorf("project '%s' uses named volume '%s', which cannot be renamed; move its data to a host path volume under %s first", oldName, v.Source, config.GetProjectDataPath(reflowBasePath, oldName))
+		}
+	}
+
+	projState, err := config.LoadProjectState(reflowBasePath, oldName)
+	if err != nil {
+		return fmt.Errorf("failed to load project state: %w", err)
+	}
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		util.Log.Warnf("Could not load global config: %v", err)
+		globalCfg = &config.GlobalConfig{}
+	}
+
+	activeEnvs := map[string]config.EnvironmentState{}
+	if projState.Test.ActiveCommit != "" && projState.Test.ActiveSlot != "" {
+		activeEnvs["test"] = projState.Test
+	}
+	if projState.Prod.ActiveCommit != "" && projState.Prod.ActiveSlot != "" {
+		activeEnvs["prod"] = projState.Prod
+	}
+
+	// --- 2. Retag Images For Active Commits ---
+	for env, envState := range activeEnvs {
+		oldTag := fmt.Sprintf("%s:%s", strings.ToLower(oldName), envState.ActiveCommit)
+		newTag := fmt.Sprintf("%s:%s", strings.ToLower(newName), envState.ActiveCommit)
+		existingImage, findErr := docker.FindImage(ctx, oldTag)
+		if findErr != nil {
+			return fmt.Errorf("error checking for image %s: %w", oldTag, findErr)
+		}
+		if existingImage == nil {
+			return fmt.Errorf("image %s for active '%s' deployment not found locally; cannot redeploy after rename", oldTag, env)
+		}
+		if err := docker.TagImage(ctx, oldTag, newTag); err != nil {
+			return err
+		}
+	}
+
+	// --- 3. Stop/Remove Old Containers ---
+	util.Log.Info("Stopping and removing containers for the old project name...")
+	oldContainers, err := docker.FindContainersByLabels(ctx, map[string]string{docker.LabelProject: oldName})
+	if err != nil {
+		return fmt.Errorf("failed to find containers for project '%s': %w", oldName, err)
+	}
+	for _, c := range oldContainers {
+		_ = docker.StopContainer(ctx, c.ID, nil)
+		if rmErr := docker.RemoveContainer(ctx, c.ID); rmErr != nil {
+			return fmt.Errorf("failed to remove container %s: %w", c.ID[:12], rmErr)
+		}
+	}
+
+	if projCfg.NetworkPolicy != nil && !strings.EqualFold(oldName, newName) {
+		if err := docker.RemoveNetwork(ctx, config.ProjectNetworkName(oldName)); err != nil {
+			util.Log.Warnf("Could not remove the network of the old project name: %v", err)
+		}
+	}
+
+	// --- 4. Move Project Directory & Rewrite Config ---
+	if err := os.Rename(oldProjectPath, newProjectPath); err != nil {
+		return fmt.Errorf("failed to rename project directory %s to %s: %w", oldProjectPath, newProjectPath, err)
+	}
+	projCfg.ProjectName = newName
+	if err := config.SaveProjectConfig(reflowBasePath, projCfg); err != nil {
+		return fmt.Errorf("failed to save renamed project config: %w", err)
+	}
+
+	// --- 5. Remove Old Nginx Configs ---
+	confDir := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName)
+	for _, env := range []string{"test", "prod"} {
+		oldConfPath := filepath.Join(confDir, fmt.Sprintf("%s.%s.conf", oldName, env))
+		if rmErr := os.Remove(oldConfPath); rmErr != nil && !os.IsNotExist(rmErr) {
+			util.Log.Warnf("Failed to remove old Nginx config %s: %v", oldConfPath, rmErr)
+		}
+		if rmErr := nginx.RemoveMaintenanceConfig(reflowBasePath, oldName, env); rmErr != nil {
+			util.Log.Warnf("%v", rmErr)
+		}
+	}
+	if rmErr := nginx.RemoveRateLimitConfig(reflowBasePath, oldName); rmErr != nil {
+		util.Log.Warnf("%v", rmErr)
+	}
+
+	// --- 6. Update Schedules Referencing The Old Name ---
+	if renameScheduleEntries(globalCfg, oldName, newName) {
+		if err := config.SaveGlobalConfig(reflowBasePath, globalCfg); err != nil {
+			util.Log.Warnf("Failed to update deploy schedules for renamed project: %v", err)
+		} else {
+			util.Log.Infof("Deploy schedules now target '%s'. Restart or reload a running 'reflow server' so its scheduler picks them up.", newName)
+		}
+	}
+
+	// --- 7. Redeploy Active Environments ---
+	var redeployErrs []string
+	for _, env := range []string{"test", "prod"} {
+		envState, ok := activeEnvs[env]
+		if !ok {
+			continue
+		}
+		startTime := time.Now()
+		redeployErr := redeployEnv(ctx, reflowBasePath, projCfg, globalCfg, env, envState)
+
+		event := &config.DeploymentEvent{
+			Timestamp:   time.Now(),
+			EventType:   "rename",
+			ProjectName: newName,
+			Environment: env,
+			CommitSHA:   envState.ActiveCommit,
+			Outcome:     "success",
+			DurationMs:  time.Since(startTime).Milliseconds(),
+			TriggeredBy: "cli/api",
+		}
+		if redeployErr != nil {
+			event.Outcome = "failure"
+			event.ErrorMessage = redeployErr.Error()
+			redeployErrs = append(redeployErrs, fmt.Sprintf("%s: %v", env, redeployErr))
+		}
+		deployment.LogEvent(reflowBasePath, newName, event)
+	}
+
+	// Each redeployed environment reloads Nginx, which also drops the old configs. Without one,
+	// reload here so the removed configs stop being served.
+	if len(activeEnvs) == 0 {
+		if err := reloadNginx(ctx); err != nil {
+			redeployErrs = append(redeployErrs, fmt.Sprintf("nginx reload: %v", err))
+		}
+	}
+
+	// --- 8. Drop Old Image Tags ---
+	// Image tags are lowercased, so a case-only rename reuses the same tag and must keep it.
+	if !strings.EqualFold(oldName, newName) {
+		for _, envState := range activeEnvs {
+			oldTag := fmt.Sprintf("%s:%s", strings.ToLower(oldName), envState.ActiveCommit)
+			if rmErr := docker.RemoveImage(ctx, oldTag); rmErr != nil {
+				util.Log.Warnf("Failed to remove old image tag %s: %v", oldTag, rmErr)
+			}
+		}
+	}
+
+	if len(redeployErrs) > 0 {
+		return errors.New("project renamed, but redeploy failed for: " + strings.Join(redeployErrs, "; "))
+	}
+
+	util.Log.Infof("✅ Project '%s' renamed to '%s'.", oldName, newName)
+	if len(activeEnvs) > 0 {
+		util.Log.Infof("   Redeployed %d active environment(s) under the new name.", len(activeEnvs))
+	}
+	return nil
+}
+
+// redeployEnv recreates an environment's active containers in its current slot and applies its
+// Nginx config, restoring the previous one if Nginx rejects it.
+func redeployEnv(ctx context.Context, reflowBasePath string, projCfg *config.ProjectConfig, globalCfg *config.GlobalConfig, env string, envState config.EnvironmentState) (err error) {
+	util.Log.Infof("Redeploying '%s' environment (slot: %s, commit: %s)...", env, envState.ActiveSlot, envState.ActiveCommit[:7])
+
+	var newContainerIDs []string
+	defer func() {
+		if err != nil && len(newContainerIDs) > 0 {
+			rollbackContainers(newContainerIDs)
+		}
+	}()
+
+	imageTag := fmt.Sprintf("%s:%s", strings.ToLower(projCfg.ProjectName), envState.ActiveCommit)
+	runOptions, _, err := slotRunOptions(reflowBasePath, imageTag, projCfg, env, envState.ActiveSlot, envState.ActiveCommit)
+	if err != nil {
+		return err
+	}
+
+	containerNames, err := startReplicas(ctx, runOptions, projCfg, env, envState.ActiveSlot, envState.ActiveCommit, config.EffectiveReplicas(projCfg, env), &newContainerIDs)
+	if err != nil {
+		return err
+	}
+	for _, containerName := range containerNames {
+		if err = waitForHealthy(ctx, containerName, projCfg.AppPort, nil); err != nil {
+			return err
+		}
+	}
+
+	domain, err := config.GetEffectiveDomain(globalCfg, projCfg, env)
+	if err != nil {
+		return fmt.Errorf("failed to determine domain for nginx config: %w", err)
+	}
+	nginxData := nginx.TemplateData{ProjectName: projCfg.ProjectName, Env: env, Slot: envState.ActiveSlot, ContainerNames: containerNames, Domain: domain, AppPort: projCfg.AppPort, TLS: tlsEnabled(reflowBasePath, projCfg, env, domain), RateLimit: config.EffectiveRateLimit(projCfg, env), ExtraLocations: projCfg.Environments[env].ExtraLocations}
+	nginxConfContent, err := nginx.GenerateNginxConfig(nginxData)
+	if err != nil {
+		return fmt.Errorf("failed to generate nginx config: %w", err)
+	}
+	return applyNginxConfig(ctx, reflowBasePath, projCfg, env, nginxConfContent, nil)
+}
+
+// renameScheduleEntries points deploy schedules at the new project name. Returns true if any changed.
+func renameScheduleEntries(globalCfg *config.GlobalConfig, oldName, newName string) bool {
+	changed := false
+	for i := range globalCfg.DeploySchedules {
+		if globalCfg.DeploySchedules[i].ProjectName == oldName {
+			globalCfg.DeploySchedules[i].ProjectName = newName
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,401 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/app"
+	"reflow/internal/config"
+	"reflow/internal/deployment"
+	"reflow/internal/docker"
+	internalGit "reflow/internal/git"
+	"reflow/internal/project"
+	"reflow/internal/util"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	healthTimeout  = 60 * time.Second
+	healthInterval = 5 * time.Second
+)
+
+// replicaContainerName builds the container name for a single replica, e.g. myapp-test-blue-abc1234-1.
+func replicaContainerName(projectName, env, slot, commitHash string, index int) string {
+	return fmt.Sprintf("%s-%s-%s-%s-%d", strings.ToLower(projectName), env, slot, commitHash[:7], index)
+}
+
+// envFileSummary describes the env file used for a deployment, for the deploy summary.
+type envFileSummary struct {
+	Path     string // Resolved path, empty if the environment has no env file
+	Commit   string // Commit the file was read from; empty if it was read from the working tree
+	Hash     string // Short content hash (see project.EnvFileHash); empty if the file wasn't found
+	Found    bool
+	VarCount int
+}
+
+// String formats the summary as shown in the deploy summary.
+func (s envFileSummary) String() string {
+	switch {
+	case s.Path == "":
+		return "none configured"
+	case !s.Found:
+		return fmt.Sprintf("%s (not found, 0 vars loaded)", s.Path)
+	case s.Commit != "":
+		return fmt.Sprintf("%s at commit %s (%d vars loaded)", s.Path, s.Commit[:7], s.VarCount)
+	default:
+		return fmt.Sprintf("%s (%d vars loaded)", s.Path, s.VarCount)
+	}
+}
+
+// loadEnvironmentFile loads the variables from an environment's env file. With requireEnvFile
+// set, a missing or unconfigured env file is an error instead of a warning.
+//
+// For git projects an env file tracked in the repository is read as stored at commitHash rather
+// than from the working tree, which a concurrent deployment of the other environment may have
+// checked out at a different commit. Untracked env files (e.g. gitignored secrets placed on the
+// server) and local-source projects are read from the working tree.
+func loadEnvironmentFile(reflowBasePath string, projCfg *config.ProjectConfig, env, commitHash string) ([]string, envFileSummary, error) {
+	envCfg := projCfg.Environments[env]
+	var summary envFileSummary
+	if envCfg.EnvFile == "" {
+		if envCfg.RequireEnvFile {
+			return nil, summary, fmt.Errorf("environments.%s.requireEnvFile is set but no envFile is configured", env)
+		}
+		return nil, summary, nil
+	}
+
+	repoPath := filepath.Join(config.GetProjectBasePath(reflowBasePath, projCfg.ProjectName), config.RepoDirName)
+	summary.Path = filepath.Join(repoPath, envCfg.EnvFile)
+
+	if projCfg.SourceType != config.SourceTypeLocal && commitHash != "" {
+		content, tracked, err := loadTrackedEnvFile(repoPath, envCfg.EnvFile, commitHash)
+		if err != nil {
+			return nil, summary, fmt.Errorf("failed to load %s environment variables: %w", env, err)
+		}
+		if tracked {
+			summary.Found = content != nil
+			if !summary.Found && envCfg.RequireEnvFile {
+				return nil, summary, fmt.Errorf("required env file for '%s' not found at commit %s (check environments.%s.envFile)", env, commitHash[:7], env)
+			}
+			if !summary.Found {
+				util.Log.Warnf("Environment file %s does not exist at commit %s, continuing without it.", envCfg.EnvFile, commitHash[:7])
+				return nil, summary, nil
+			}
+			util.Log.Debugf("Loading environment variables from %s at commit %s", envCfg.EnvFile, commitHash[:7])
+			envVars, err := util.ParseEnvVars(bytes.NewReader(content), fmt.Sprintf("%s@%s", envCfg.EnvFile, commitHash[:7]))
+			if err != nil {
+				return nil, summary, fmt.Errorf("failed to load %s environment variables: %w", env, err)
+			}
+			if envVars == nil {
+				envVars = []string{}
+			}
+			summary.Commit = commitHash
+			summary.Hash = project.EnvFileHash(content)
+			summary.VarCount = len(envVars)
+			return envVars, summary, nil
+		}
+	}
+
+	content, err := os.ReadFile(summary.Path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, summary, fmt.Errorf("failed to read env file %s: %w", summary.Path, err)
+		}
+		if envCfg.RequireEnvFile {
+			return nil, summary, fmt.Errorf("required env file for '%s' not found at %s (check environments.%s.envFile)", env, summary.Path, env)
+		}
+		util.Log.Warnf("Environment file not found at %s, continuing without it.", summary.Path)
+		return nil, summary, nil
+	}
+	summary.Found = true
+	summary.Hash = project.EnvFileHash(content)
+
+	util.Log.Debugf("Loading environment variables from file: %s", summary.Path)
+	envVars, err := util.ParseEnvVars(bytes.NewReader(content), summary.Path)
+	if err != nil {
+		return nil, summary, fmt.Errorf("failed to load %s environment variables: %w", env, err)
+	}
+	summary.VarCount = len(envVars)
+	return envVars, summary, nil
+}
+
+// loadTrackedEnvFile reads an env file as stored at commitHash. tracked is false if git does not
+// manage the file, i.e. it is in neither commitHash nor the checked-out commit, in which case the
+// caller reads the working tree. A file tracked at HEAD but missing at commitHash is reported as
+// tracked with nil content, since the working tree copy belongs to another commit.
+func loadTrackedEnvFile(repoPath, envFile, commitHash string) (content []byte, tracked bool, err error) {
+	content, err = internalGit.ReadFileAtCommit(repoPath, commitHash, envFile)
+	if err == nil {
+		if content == nil {
+			content = []byte{}
+		}
+		return content, true, nil
+	}
+	if !errors.Is(err, internalGit.ErrFileNotInCommit) {
+		return nil, false, err
+	}
+
+	if _, headErr := internalGit.ReadFileAtCommit(repoPath, "HEAD", envFile); headErr == nil {
+		return nil, true, nil
+	}
+	return nil, false, nil
+}
+
+// loadEnvironmentVars loads an environment's variables from its env file (see
+// loadEnvironmentFile) with the environment's secrets merged over them. With interpolate set for
+// the environment, ${NAME} references in the env file are expanded from, in order, earlier lines
+// of the file, the environment's secrets and the built-ins PROJECT, ENV, DOMAIN and SLOT (empty
+// when slot is not known yet). Otherwise values are passed on as written.
+func loadEnvironmentVars(reflowBasePath string, projCfg *config.ProjectConfig, env, slot, commitHash string) ([]string, envFileSummary, error) {
+	envVars, envFile, err := loadEnvironmentFile(reflowBasePath, projCfg, env, commitHash)
+	if err != nil {
+		return nil, envFile, err
+	}
+	secrets, err := config.ReadSecrets(reflowBasePath, projCfg.ProjectName, env)
+	if err != nil {
+		return nil, envFile, fmt.Errorf("failed to load %s secrets: %w", env, err)
+	}
+
+	if projCfg.Environments[env].Interpolate {
+		references := envBuiltins(reflowBasePath, projCfg, env, slot)
+		for _, secret := range secrets {
+			key, value, _ := strings.Cut(secret, "=")
+			references[key] = value
+		}
+		if envVars, err = util.ExpandEnvVars(envVars, references, envFile.Path); err != nil {
+			return nil, envFile, err
+		}
+	}
+
+	if len(secrets) > 0 {
+		util.Log.Debugf("Merging %d secret(s) for environment '%s' over the env file values", len(secrets), env)
+		envVars = util.MergeEnvVars(envVars, secrets)
+	}
+	return envVars, envFile, nil
+}
+
+// envBuiltins returns the values reflow provides for ${NAME} references in env files. DOMAIN is
+// left out if the environment's domain cannot be determined, so referencing it fails.
+func envBuiltins(reflowBasePath string, projCfg *config.ProjectConfig, env, slot string) map[string]string {
+	builtins := map[string]string{
+		"PROJECT": projCfg.ProjectName,
+		"ENV":     env,
+		"SLOT":    slot,
+	}
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		util.Log.Debugf("Could not load global config for env file references: %v", err)
+		globalCfg = &config.GlobalConfig{}
+	}
+	if domain, err := config.GetEffectiveDomain(globalCfg, projCfg, env); err == nil {
+		builtins["DOMAIN"] = domain
+	} else {
+		util.Log.Debugf("DOMAIN is not available to env file references: %v", err)
+	}
+	return builtins
+}
+
+// slotRunOptions builds the container run options shared by all replicas of a deployment slot,
+// along with a summary of the env file that was loaded.
+func slotRunOptions(reflowBasePath, imageTag string, projCfg *config.ProjectConfig, env, slot, commitHash string) (docker.ContainerRunOptions, envFileSummary, error) {
+	envVars, envFile, err := loadEnvironmentVars(reflowBasePath, projCfg, env, slot, commitHash)
+	if err != nil {
+		return docker.ContainerRunOptions{}, envFile, err
+	}
+	envVars = append(envVars, fmt.Sprintf("PORT=%d", projCfg.AppPort), "REFLOW_COMMIT="+commitHash)
+
+	limits, err := config.ParseResources(fmt.Sprintf("environments.%s.resources", env), config.EffectiveResources(projCfg, env))
+	if err != nil {
+		return docker.ContainerRunOptions{}, envFile, err
+	}
+
+	restartPolicy, err := config.ParseRestartPolicy(fmt.Sprintf("environments.%s.restartPolicy", env), config.EffectiveRestartPolicy(projCfg, env))
+	if err != nil {
+		return docker.ContainerRunOptions{}, envFile, err
+	}
+
+	volumes, err := config.ResolveVolumes("volumes", config.GetProjectDataPath(reflowBasePath, projCfg.ProjectName), config.ProjectVolumePrefix(projCfg.ProjectName), projCfg.Volumes)
+	if err != nil {
+		return docker.ContainerRunOptions{}, envFile, err
+	}
+
+	var extraNetworks []string
+	if projCfg.NetworkPolicy != nil {
+		extraNetworks = []string{config.ProjectNetworkName(projCfg.ProjectName)}
+	}
+
+	return docker.ContainerRunOptions{
+		ImageName:     imageTag,
+		NetworkName:   config.ReflowNetworkName,
+		ExtraNetworks: extraNetworks,
+		Labels: map[string]string{
+			docker.LabelManaged:     "true",
+			docker.LabelProject:     projCfg.ProjectName,
+			docker.LabelEnvironment: env,
+			docker.LabelSlot:        slot,
+			docker.LabelCommit:      commitHash,
+		},
+		EnvVars:           envVars,
+		AppPort:           projCfg.AppPort,
+		RestartPolicy:     restartPolicy.Mode,
+		MaxRetries:        restartPolicy.MaxRetries,
+		MemoryLimit:       limits.MemoryBytes,
+		MemoryReservation: limits.MemoryReservationBytes,
+		NanoCPUs:          limits.NanoCPUs,
+		Volumes:           toVolumeMounts(volumes),
+		VolumeLabels: map[string]string{
+			docker.LabelManaged: "true",
+			docker.LabelProject: projCfg.ProjectName,
+		},
+		HostPathRoot: reflowBasePath,
+	}, envFile, nil
+}
+
+// ensureProjectNetwork creates the network of a project with a network policy and connects the
+// policy's allowed containers to it. Allowed containers that cannot be connected, e.g. because
+// they are not running yet, are only warned about; they are connected on the next deployment.
+func ensureProjectNetwork(ctx context.Context, projCfg *config.ProjectConfig) error {
+	if projCfg.NetworkPolicy == nil {
+		return nil
+	}
+	networkName := config.ProjectNetworkName(projCfg.ProjectName)
+	if err := docker.CreateNetworkIfNotExists(ctx, networkName, map[string]string{
+		docker.LabelManaged: "true",
+		docker.LabelProject: projCfg.ProjectName,
+	}); err != nil {
+		return err
+	}
+	for _, allowed := range projCfg.NetworkPolicy.AllowedContainers {
+		if err := docker.ConnectContainerToNetwork(ctx, networkName, allowed); err != nil {
+			util.Log.Warnf("Could not give container '%s' access to project '%s': %v", allowed, projCfg.ProjectName, err)
+		}
+	}
+	return nil
+}
+
+// toVolumeMounts converts resolved volume config entries into Docker volume mounts.
+func toVolumeMounts(volumes []config.VolumeConfig) []docker.VolumeMount {
+	mounts := make([]docker.VolumeMount, 0, len(volumes))
+	for _, v := range volumes {
+		mounts = append(mounts, docker.VolumeMount{Source: v.Source, Target: v.Target, ReadOnly: v.ReadOnly})
+	}
+	return mounts
+}
+
+// startReplicas starts 'replicas' containers for a slot from the given base run options, waits
+// for each to report running and runs the project's post-start hooks in it.
+// IDs of started containers are appended to startedIDs as they come up, so the caller can roll
+// back on failure even if a later replica fails to start.
+func startReplicas(ctx context.Context, baseOptions docker.ContainerRunOptions, projCfg *config.ProjectConfig, env, slot, commitHash string, replicas int, startedIDs *[]string) ([]string, error) {
+	indexes := make([]int, replicas)
+	for i := range indexes {
+		indexes[i] = i + 1
+	}
+	return startReplicaIndexes(ctx, baseOptions, projCfg, env, slot, commitHash, indexes, replicas, startedIDs)
+}
+
+// startReplicaIndexes is startReplicas for only the given replica numbers (1-based) out of
+// 'replicas', e.g. to add replicas to a running slot.
+func startReplicaIndexes(ctx context.Context, baseOptions docker.ContainerRunOptions, projCfg *config.ProjectConfig, env, slot, commitHash string, indexes []int, replicas int, startedIDs *[]string) ([]string, error) {
+	projectName := projCfg.ProjectName
+	startTimeout := config.DefaultStartTimeoutSeconds * time.Second
+	if projCfg.StartTimeout > 0 {
+		startTimeout = time.Duration(projCfg.StartTimeout) * time.Second
+	}
+	if len(indexes) > 0 {
+		if err := ensureProjectNetwork(ctx, projCfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var containerNames []string
+	for _, i := range indexes {
+		containerName := replicaContainerName(projectName, env, slot, commitHash, i)
+		if len(containerName) > config.MaxContainerNameLength {
+			return containerNames, fmt.Errorf("container name '%s' exceeds %d characters; shorten the project name or reduce replicas", containerName, config.MaxContainerNameLength)
+		}
+
+		labels := make(map[string]string, len(baseOptions.Labels)+1)
+		for k, v := range baseOptions.Labels {
+			labels[k] = v
+		}
+		labels[docker.LabelReplica] = strconv.Itoa(i)
+
+		runOptions := baseOptions
+		runOptions.ContainerName = containerName
+		runOptions.Labels = labels
+
+		util.Log.Infof("Starting container '%s' (replica %d/%d) for slot '%s'...", containerName, i, replicas, slot)
+		containerID, err := docker.RunContainer(ctx, runOptions)
+		if err != nil {
+			return containerNames, fmt.Errorf("failed to run container '%s': %w", containerName, err)
+		}
+		*startedIDs = append(*startedIDs, containerID)
+		if err := docker.WaitForContainerRunning(ctx, containerID, startTimeout); err != nil {
+			return containerNames, fmt.Errorf("container '%s' did not start: %w", containerName, err)
+		}
+		if err := docker.RunPostStartHooks(ctx, containerID, projCfg.PostStartHooks, config.PostStartHookTimeoutSeconds*time.Second); err != nil {
+			return containerNames, fmt.Errorf("container '%s': %w", containerName, err)
+		}
+		containerNames = append(containerNames, containerName)
+		util.Log.Infof("Container started: %s (ID: %s)", containerName, containerID[:12])
+	}
+	return containerNames, nil
+}
+
+// waitForHealthy polls a container's app port from the Nginx container until it accepts connections or times out.
+// Each poll is reported to progress, if non-nil, as "<container> 3/12".
+func waitForHealthy(ctx context.Context, containerName string, appPort int, progress *deployment.Progress) error {
+	healthCheckStartTime := time.Now()
+	maxPolls := int(healthTimeout / healthInterval)
+
+	util.Log.Infof("Performing health check for '%s' via TCP connection from Nginx container (timeout %v)...", containerName, healthTimeout)
+
+	for poll := 1; time.Since(healthCheckStartTime) < healthTimeout; poll++ {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("health check cancelled: %w", ctx.Err())
+		default:
+		}
+		progress.StepDetail("%s %d/%d", containerName, poll, maxPolls)
+
+		util.Log.Debugf("Polling health for %s...", containerName)
+		healthy, checkErr := app.CheckTcpHealthFromNginx(ctx, containerName, appPort)
+
+		if checkErr != nil {
+			util.Log.Warnf("Health check poll failed for %s: %v", containerName, checkErr)
+		} else if healthy {
+			util.Log.Infof("Container '%s' passed health check after %v.", containerName, time.Since(healthCheckStartTime))
+			return nil
+		} else {
+			util.Log.Debugf("Container '%s' not healthy yet, retrying in %v...", containerName, healthInterval)
+		}
+
+		select {
+		case <-time.After(healthInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("health check cancelled while waiting for interval: %w", ctx.Err())
+		}
+	}
+
+	return fmt.Errorf("container '%s' failed health check: timed out after %v", containerName, healthTimeout)
+}
+
+// rollbackContainers stops and removes containers started during a failed deployment.
+func rollbackContainers(containerIDs []string) {
+	cleanupCtx := context.Background()
+	for _, containerID := range containerIDs {
+		util.Log.Warnf("Attempting simple rollback: stopping and removing newly started container %s...", containerID[:12])
+		_ = docker.StopContainer(cleanupCtx, containerID, nil)
+		if rmErr := docker.RemoveContainer(cleanupCtx, containerID); rmErr != nil {
+			util.Log.Errorf("Rollback cleanup failed: Could not remove container %s: %v", containerID[:12], rmErr)
+		} else {
+			util.Log.Infof("Rollback cleanup: Removed container %s", containerID[:12])
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/config"
+	"reflow/internal/deployment"
+	"reflow/internal/docker"
+	"reflow/internal/nginx"
+	"reflow/internal/util"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// ScaleProjectEnv sets the number of replicas of a project environment and applies it to the
+// active deployment without rebuilding: missing replicas are started in the active slot and
+// health checked before Nginx routes to them, and surplus replicas are removed after Nginx
+// stopped routing to them. An environment with nothing deployed only gets its config updated.
+// Like a deployment, it holds the project's lock, so it fails while one is running.
+func ScaleProjectEnv(ctx context.Context, reflowBasePath, projectName, env string, replicas int) (err error) {
+	if env != "test" && env != "prod" {
+		return fmt.Errorf("invalid environment specified: %s", env)
+	}
+	if replicas < 1 {
+		return fmt.Errorf("invalid replicas value %d: must be at least 1", replicas)
+	}
+	unlock, err := lockProject(projectName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// --- 1. Load Config and State ---
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to load project config for '%s': %w", projectName, err)
+	}
+	projState, err := config.LoadProjectState(reflowBasePath, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to load project state for '%s': %w", projectName, err)
+	}
+	envState := &projState.Test
+	if env == "prod" {
+		envState = &projState.Prod
+	}
+
+	// --- 2. Save Replicas ---
+	if projCfg.Environments == nil {
+		projCfg.Environments = make(map[string]config.ProjectEnvConfig)
+	}
+	envCfg := projCfg.Environments[env]
+	envCfg.Replicas = replicas
+	projCfg.Environments[env] = envCfg
+	if err := config.SaveProjectConfig(reflowBasePath, projCfg); err != nil {
+		return err
+	}
+
+	if envState.ActiveCommit == "" || envState.ActiveSlot == "" {
+		util.Log.Infof("No active deployment for project '%s' env '%s'. %d replica(s) will be started on the next deploy.", projectName, env, replicas)
+		return nil
+	}
+	slot, commitHash := envState.ActiveSlot, envState.ActiveCommit
+
+	// --- 3. Current Replicas ---
+	containers, err := docker.FindContainersByLabels(ctx, map[string]string{
+		docker.LabelProject:     projectName,
+		docker.LabelEnvironment: env,
+		docker.LabelSlot:        slot,
+		docker.LabelCommit:      commitHash,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find containers for project '%s' env '%s': %w", projectName, env, err)
+	}
+	existing := make(map[int]container.Summary, len(containers))
+	for _, c := range containers {
+		// Containers from before replicas were labelled count as replica 1.
+		index := 1
+		if label := c.Labels[docker.LabelReplica]; label != "" {
+			parsed, parseErr := strconv.Atoi(label)
+			if parseErr != nil {
+				util.Log.Warnf("Ignoring container %s with invalid replica label '%s'.", c.ID[:12], label)
+				continue
+			}
+			index = parsed
+		}
+		existing[index] = c
+	}
+	util.Log.Infof("Scaling project '%s' env '%s' (slot %s, commit %s) from %d to %d replica(s)...", projectName, env, slot, commitHash[:7], len(existing), replicas)
+
+	// --- 4. Start Missing Replicas ---
+	var newContainerIDs []string
+	defer func() {
+		if err != nil && len(newContainerIDs) > 0 {
+			rollbackContainers(newContainerIDs)
+		}
+	}()
+
+	imageTag := fmt.Sprintf("%s:%s", strings.ToLower(projectName), commitHash)
+	runOptions, _, err := slotRunOptions(reflowBasePath, imageTag, projCfg, env, slot, commitHash)
+	if err != nil {
+		return err
+	}
+	var missing []int
+	containerNames := make([]string, 0, replicas)
+	for i := 1; i <= replicas; i++ {
+		if c, exists := existing[i]; exists && len(c.Names) > 0 {
+			containerNames = append(containerNames, strings.TrimPrefix(c.Names[0], "/"))
+			continue
+		}
+		missing = append(missing, i)
+		containerNames = append(containerNames, replicaContainerName(projectName, env, slot, commitHash, i))
+	}
+	started, err := startReplicaIndexes(ctx, runOptions, projCfg, env, slot, commitHash, missing, replicas, &newContainerIDs)
+	if err != nil {
+		return err
+	}
+	for _, containerName := range started {
+		if err = waitForHealthy(ctx, containerName, projCfg.AppPort, nil); err != nil {
+			return err
+		}
+	}
+
+	// --- 5. Update Nginx ---
+	domain, err := config.GetEffectiveDomain(globalCfg, projCfg, env)
+	if err != nil {
+		return fmt.Errorf("failed to determine domain for nginx config: %w", err)
+	}
+	nginxData := nginx.TemplateData{ProjectName: projectName, Env: env, Slot: slot, ContainerNames: containerNames, Domain: domain, AppPort: projCfg.AppPort, TLS: env == "prod" && tlsEnabled(reflowBasePath, projCfg, env, domain), RateLimit: config.EffectiveRateLimit(projCfg, env), ExtraLocations: projCfg.Environments[env].ExtraLocations}
+	nginxConfContent, err := nginx.GenerateNginxConfig(nginxData)
+	if err != nil {
+		return fmt.Errorf("failed to generate nginx config: %w", err)
+	}
+	if err = applyNginxConfig(ctx, reflowBasePath, projCfg, env, nginxConfContent, &siteCheck{Domain: domain, TLS: nginxData.TLS, Commit: commitHash}); err != nil {
+		return err
+	}
+
+	// --- 6. Remove Surplus Replicas ---
+	// Nginx no longer routes to them, so failures here leave idle containers behind for
+	// 'project cleanup' rather than failing the scale.
+	stopTimeout := config.DefaultStopTimeoutSeconds * time.Second
+	if projCfg.StopTimeout > 0 {
+		stopTimeout = time.Duration(projCfg.StopTimeout) * time.Second
+	}
+	removed := 0
+	for index, c := range existing {
+		if index <= replicas {
+			continue
+		}
+		containerID := c.ID
+		util.Log.Infof("Removing replica %d (%s)...", index, containerID[:12])
+		if _, stopErr := docker.StopContainerGracefully(ctx, containerID, stopTimeout); stopErr != nil {
+			util.Log.Debugf("Ignoring error stopping container %s: %v", containerID[:12], stopErr)
+		}
+		if rmErr := docker.RemoveContainer(ctx, containerID); rmErr != nil {
+			util.Log.Warnf("Failed to remove replica %d (%s): %v", index, containerID[:12], rmErr)
+			continue
+		}
+		removed++
+	}
+
+	// --- 7. Update State ---
+	envState.Replicas = replicas
+	if err = config.SaveProjectState(reflowBasePath, projectName, projState); err != nil {
+		return fmt.Errorf("scaled, but failed to save updated state: %w", err)
+	}
+	deployment.LogEvent(reflowBasePath, projectName, &config.DeploymentEvent{
+		Timestamp:   time.Now(),
+		EventType:   "scale",
+		ProjectName: projectName,
+		Environment: env,
+		CommitSHA:   commitHash,
+		Outcome:     "success",
+		TriggeredBy: triggeredBy(ctx),
+	})
+
+	util.Log.Infof("✅ Project '%s' env '%s' now runs %d replica(s) (started %d, removed %d).", projectName, env, replicas, len(newContainerIDs), removed)
+	return nil
+}
@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"reflow/internal/config"
+	"reflow/internal/util"
+)
+
+// syncLocalSource refreshes a local project's copy in repoPath from its source directory and
+// returns a pseudo-commit hash of the copied content, used in place of a git commit for image
+// tags and deployment state. If the source directory is gone, the existing copy is used as-is.
+func syncLocalSource(projCfg *config.ProjectConfig, repoPath string) (string, error) {
+	sourceAvailable := false
+	if projCfg.LocalPath != "" {
+		if _, err := os.Stat(projCfg.LocalPath); err == nil {
+			sourceAvailable = true
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to access local source %s: %w", projCfg.LocalPath, err)
+		}
+	}
+
+	if sourceAvailable {
+		util.Log.Infof("Copying local source '%s'...", projCfg.LocalPath)
+		// Copy next to the current copy first, so a failed copy leaves the last good one in place.
+		stagingPath := repoPath + ".sync"
+		_ = os.RemoveAll(stagingPath)
+		if err := util.CopyDir(projCfg.LocalPath, stagingPath); err != nil {
+			_ = os.RemoveAll(stagingPath)
+			return "", fmt.Errorf("failed to copy local source %s: %w", projCfg.LocalPath, err)
+		}
+		if err := os.RemoveAll(repoPath); err != nil {
+			_ = os.RemoveAll(stagingPath)
+			return "", fmt.Errorf("failed to remove previous source copy %s: %w", repoPath, err)
+		}
+		if err := os.Rename(stagingPath, repoPath); err != nil {
+			return "", fmt.Errorf("failed to replace source copy %s: %w", repoPath, err)
+		}
+	} else {
+		util.Log.Warnf("Local source '%s' is not available, deploying the existing copy in %s", projCfg.LocalPath, repoPath)
+	}
+
+	hash, err := util.HashDir(repoPath, ".git", reflowDockerfileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash local source: %w", err)
+	}
+	util.Log.Infof("Local source content hash: %s", hash)
+	return hash, nil
+}
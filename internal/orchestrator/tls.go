@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/acme"
+	"reflow/internal/config"
+	"reflow/internal/nginx"
+	"reflow/internal/util"
+)
+
+// tlsEnabled reports whether an environment's Nginx config should serve HTTPS.
+// Only 'prod' of autoTLS projects gets certificates, and only once one has been obtained.
+func tlsEnabled(reflowBasePath string, projCfg *config.ProjectConfig, env, domain string) bool {
+	return env == "prod" && projCfg.AutoTLS && acme.HasCertificate(reflowBasePath, domain)
+}
+
+// accessURL returns the public URL of an environment, using HTTPS when it is served over TLS.
+func accessURL(reflowBasePath string, projCfg *config.ProjectConfig, env, domain string) string {
+	if tlsEnabled(reflowBasePath, projCfg, env, domain) {
+		return fmt.Sprintf("https://%s", domain)
+	}
+	return fmt.Sprintf("http://%s", domain)
+}
+
+// provisionTLS obtains or renews the certificate for an autoTLS environment whose HTTP config is
+// already live, then switches its Nginx config to HTTPS. Failures leave the site on plain HTTP.
+func provisionTLS(ctx context.Context, reflowBasePath string, nginxData nginx.TemplateData) error {
+	changed, err := acme.EnsureCertificate(ctx, reflowBasePath, nginxData.Domain)
+	if err != nil {
+		return err
+	}
+	if !changed && nginxData.TLS {
+		return nil
+	}
+
+	nginxData.TLS = true
+	nginxConfContent, err := nginx.GenerateNginxConfig(nginxData)
+	if err != nil {
+		return fmt.Errorf("failed to generate TLS nginx config: %w", err)
+	}
+	snapshot, err := nginx.SnapshotProjectConfig(reflowBasePath, nginxData.ProjectName, nginxData.Env)
+	if err != nil {
+		return fmt.Errorf("failed to back up current nginx config: %w", err)
+	}
+	if err := nginx.WriteNginxConfig(reflowBasePath, nginxData.ProjectName, nginxData.Env, nginxConfContent); err != nil {
+		return fmt.Errorf("failed to write TLS nginx config: %w", err)
+	}
+	if err := nginx.ReloadNginx(ctx); err != nil {
+		rollbackNginxConfig(ctx, snapshot)
+		return fmt.Errorf("failed to reload nginx with TLS config: %w", err)
+	}
+	util.Log.Infof("HTTPS enabled for %s.", nginxData.Domain)
+	return nil
+}
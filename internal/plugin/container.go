@@ -0,0 +1,188 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/util"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// loadContainerPlugin returns an installed plugin that runs a container, failing with a clear
+// error for unknown, CLI-type and disabled plugins.
+func loadContainerPlugin(globalState *config.GlobalPluginState, pluginName string) (*config.PluginInstanceConfig, error) {
+	pluginConf, exists := globalState.InstalledPlugins[pluginName]
+	if !exists {
+		return nil, fmt.Errorf("plugin '%s' not found", pluginName)
+	}
+	if pluginConf.Type != config.PluginTypeContainer {
+		return nil, fmt.Errorf("plugin '%s' is a %s plugin and has no container", pluginName, pluginConf.Type)
+	}
+	if !pluginConf.Enabled {
+		return nil, fmt.Errorf("plugin '%s' is disabled; enable it first with 'reflow plugin enable %s'", pluginName, pluginName)
+	}
+	return pluginConf, nil
+}
+
+// findPluginContainer returns the ID of a plugin's container and its state (e.g. "running").
+// The stored ContainerID is tried first; when it is empty or stale the container is looked up by
+// its reflow-plugin-<name> name and then by its reflow.plugin.name label. Returns "" if the
+// container does not exist.
+func findPluginContainer(ctx context.Context, pluginConf *config.PluginInstanceConfig) (string, string, error) {
+	candidates := []string{fmt.Sprintf("reflow-plugin-%s", pluginConf.PluginName)}
+	if pluginConf.ContainerID != "" {
+		candidates = append([]string{pluginConf.ContainerID}, candidates...)
+	}
+	for _, candidate := range candidates {
+		inspect, err := docker.InspectContainer(ctx, candidate)
+		if err == nil {
+			return inspect.ID, inspect.State.Status, nil
+		}
+		if !docker.IsErrNotFound(err) {
+			return "", "", fmt.Errorf("failed to inspect container for plugin '%s': %w", pluginConf.PluginName, err)
+		}
+	}
+
+	containers, err := docker.FindContainersByLabels(ctx, map[string]string{docker.LabelPluginName: pluginConf.PluginName})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find container for plugin '%s': %w", pluginConf.PluginName, err)
+	}
+	if len(containers) == 0 {
+		return "", "", nil
+	}
+	latest := containers[0]
+	for _, c := range containers[1:] {
+		if (c.State == "running" && latest.State != "running") || (c.State == latest.State && c.Created > latest.Created) {
+			latest = c
+		}
+	}
+	return latest.ID, latest.State, nil
+}
+
+// GetPluginLogs returns the logs of a container plugin's container as Docker's multiplexed
+// stdout/stderr stream (demultiplex it with stdcopy.StdCopy). With follow set the stream stays
+// open until ctx is cancelled; following a stopped container is an error, while without it the
+// stopped container's last logs are returned. The caller must close the reader.
+func GetPluginLogs(ctx context.Context, reflowBasePath, pluginName string, follow bool, tail string) (io.ReadCloser, error) {
+	globalState, err := config.LoadGlobalPluginState(reflowBasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load global plugin state: %w", err)
+	}
+	pluginConf, err := loadContainerPlugin(globalState, pluginName)
+	if err != nil {
+		return nil, err
+	}
+
+	containerID, state, err := findPluginContainer(ctx, pluginConf)
+	if err != nil {
+		return nil, err
+	}
+	if containerID == "" {
+		return nil, fmt.Errorf("container for plugin '%s' not found; run 'reflow plugin restart %s' to recreate it", pluginName, pluginName)
+	}
+	if state != "running" {
+		if follow {
+			return nil, fmt.Errorf("cannot follow logs: container for plugin '%s' is not running (%s); run 'reflow plugin restart %s' to start it", pluginName, state, pluginName)
+		}
+		util.Log.Warnf("Container for plugin '%s' is not running (%s). Showing its last logs.", pluginName, state)
+	}
+	util.Log.Debugf("Fetching logs for plugin '%s' container %s...", pluginName, containerID[:12])
+
+	logReader, err := docker.GetContainerLogs(ctx, containerID, follow, tail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve logs for plugin '%s': %w", pluginName, err)
+	}
+	return logReader, nil
+}
+
+// StreamPluginLogs writes the logs of a container plugin to w. With follow set it keeps
+// streaming until ctx is cancelled.
+func StreamPluginLogs(ctx context.Context, reflowBasePath, pluginName string, follow bool, tail string, w io.Writer) error {
+	logReader, err := GetPluginLogs(ctx, reflowBasePath, pluginName, follow, tail)
+	if err != nil {
+		return err
+	}
+	defer logReader.Close()
+
+	if _, err := stdcopy.StdCopy(w, w, logReader); err != nil && !errors.Is(err, io.EOF) {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("error streaming logs for plugin '%s': %w", pluginName, err)
+	}
+	return nil
+}
+
+// RestartPlugin stops and starts a container plugin's container. A container that no longer
+// exists is recreated from the plugin's saved config values, and the new container ID is
+// recorded in the plugin state.
+func RestartPlugin(reflowBasePath, pluginName string) error {
+	ctx := context.Background()
+
+	globalState, err := config.LoadGlobalPluginState(reflowBasePath)
+	if err != nil {
+		return fmt.Errorf("failed to load global plugin state: %w", err)
+	}
+	pluginConf, err := loadContainerPlugin(globalState, pluginName)
+	if err != nil {
+		return err
+	}
+
+	containerID, _, err := findPluginContainer(ctx, pluginConf)
+	if err != nil {
+		return err
+	}
+
+	if containerID != "" {
+		// --- Restart Existing Container ---
+		util.Log.Infof("Restarting container for plugin '%s'...", pluginName)
+		if err := docker.StopContainer(ctx, containerID, nil); err != nil && !docker.IsErrNotFound(err) {
+			return fmt.Errorf("failed to stop container for plugin '%s': %w", pluginName, err)
+		}
+		if err := docker.StartContainer(ctx, containerID); err != nil {
+			return fmt.Errorf("failed to start container for plugin '%s': %w", pluginName, err)
+		}
+		if err := docker.WaitForContainerRunning(ctx, containerID, config.DefaultStartTimeoutSeconds*time.Second); err != nil {
+			return fmt.Errorf("plugin '%s' container did not come back up: %w", pluginName, err)
+		}
+	} else {
+		// --- Recreate Missing Container ---
+		util.Log.Warnf("Container for plugin '%s' not found. Recreating it from the saved config...", pluginName)
+		configValues, loadErr := config.LoadPluginInstanceConfig(pluginConf.ConfigPath)
+		if loadErr != nil {
+			util.Log.Warnf("Failed to load current config values from '%s': %v. Using the values stored in global state.", pluginConf.ConfigPath, loadErr)
+			configValues = pluginConf.ConfigValues
+		} else {
+			pluginConf.ConfigValues = configValues
+		}
+		metadata, parseErr := ParsePluginMetadata(filepath.Join(pluginConf.InstallPath, config.PluginMetadataFileName))
+		if parseErr != nil {
+			return fmt.Errorf("could not parse metadata for plugin '%s': %w", pluginName, parseErr)
+		}
+		pluginConf.Metadata = metadata
+
+		containerID, err = startPluginContainer(ctx, reflowBasePath, pluginConf, configValues)
+		if err != nil {
+			return fmt.Errorf("failed to recreate container for plugin '%s': %w", pluginName, err)
+		}
+	}
+
+	// --- Update State ---
+	if pluginConf.ContainerID != containerID {
+		pluginConf.ContainerID = containerID
+		pluginConf.Metadata = nil // Don't save full metadata in state file
+		globalState.InstalledPlugins[pluginName] = pluginConf
+		if err := config.SaveGlobalPluginState(reflowBasePath, globalState); err != nil {
+			return fmt.Errorf("plugin '%s' restarted, but failed to save its new container ID: %w", pluginName, err)
+		}
+	}
+
+	util.Log.Infof("✅ Plugin '%s' restarted (container %s).", pluginName, containerID[:12])
+	return nil
+}
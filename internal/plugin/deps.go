@@ -0,0 +1,166 @@
+package plugin
+
+import (
+	"fmt"
+	"path"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"slices"
+	"sort"
+	"strings"
+
+	hversion "github.com/hashicorp/go-version"
+)
+
+// unsatisfiedDependency is a declared dependency that no installed plugin fulfils.
+type unsatisfiedDependency struct {
+	Dependency config.PluginDependency
+	Installed  bool // A matching plugin is installed, but its version is too old
+	Reason     string
+}
+
+func (u unsatisfiedDependency) String() string {
+	return fmt.Sprintf("%s (%s)", u.Dependency.Name, u.Reason)
+}
+
+// checkDependencies returns the dependencies not fulfilled by an installed plugin. A plugin
+// fulfils a dependency when its name matches the dependency's name pattern and, if a minimum
+// version is set, its version is at least that.
+func checkDependencies(globalState *config.GlobalPluginState, deps []config.PluginDependency) ([]unsatisfiedDependency, error) {
+	var unsatisfied []unsatisfiedDependency
+	for _, dep := range deps {
+		matches, err := matchingPlugins(globalState, dep)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			unsatisfied = append(unsatisfied, unsatisfiedDependency{Dependency: dep, Reason: "not installed"})
+			continue
+		}
+		if dep.MinVersion == "" {
+			continue
+		}
+		minVersion, err := hversion.NewVersion(strings.TrimPrefix(dep.MinVersion, "v"))
+		if err != nil {
+			return nil, fmt.Errorf("dependency '%s' has an invalid minVersion '%s': %w", dep.Name, dep.MinVersion, err)
+		}
+		var versions []string
+		satisfied := false
+		for _, installed := range matches {
+			versions = append(versions, fmt.Sprintf("%s %s", installed.PluginName, installed.Version))
+			installedVersion, err := hversion.NewVersion(strings.TrimPrefix(installed.Version, "v"))
+			if err == nil && !installedVersion.LessThan(minVersion) {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			unsatisfied = append(unsatisfied, unsatisfiedDependency{
+				Dependency: dep,
+				Installed:  true,
+				Reason:     fmt.Sprintf("requires version %s or later, installed: %s", dep.MinVersion, strings.Join(versions, ", ")),
+			})
+		}
+	}
+	return unsatisfied, nil
+}
+
+// matchingPlugins returns the installed plugins whose names match a dependency, sorted by name.
+func matchingPlugins(globalState *config.GlobalPluginState, dep config.PluginDependency) ([]*config.PluginInstanceConfig, error) {
+	var matches []*config.PluginInstanceConfig
+	for name, pluginConf := range globalState.InstalledPlugins {
+		matched, err := path.Match(dep.Name, name)
+		if err != nil {
+			return nil, fmt.Errorf("dependency name '%s' is not a valid pattern: %w", dep.Name, err)
+		}
+		if matched {
+			matches = append(matches, pluginConf)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].PluginName < matches[j].PluginName })
+	return matches, nil
+}
+
+// resolveDependencies fails if any of a plugin's dependencies is unsatisfied, listing them all.
+// With opts.WithDeps set, missing dependencies are installed first and the refreshed plugin state is
+// returned. installing lists the plugins whose dependencies are being installed, outermost first,
+// to stop dependency cycles.
+func resolveDependencies(reflowBasePath string, globalState *config.GlobalPluginState, pluginName string, deps []config.PluginDependency, opts InstallOptions, installing []string) (*config.GlobalPluginState, error) {
+	unsatisfied, err := checkDependencies(globalState, deps)
+	if err != nil {
+		return nil, err
+	}
+	if len(unsatisfied) > 0 && opts.WithDeps {
+		if err := installDependencies(reflowBasePath, pluginName, unsatisfied, opts, append(installing, pluginName)); err != nil {
+			return nil, err
+		}
+		if globalState, err = config.LoadGlobalPluginState(reflowBasePath); err != nil {
+			return nil, fmt.Errorf("failed to reload global plugin state: %w", err)
+		}
+		if unsatisfied, err = checkDependencies(globalState, deps); err != nil {
+			return nil, err
+		}
+	}
+	if len(unsatisfied) > 0 {
+		missing := make([]string, 0, len(unsatisfied))
+		for _, u := range unsatisfied {
+			missing = append(missing, u.String())
+		}
+		return nil, fmt.Errorf("unsatisfied dependencies: %s; install them first, or use --with-deps", strings.Join(missing, ", "))
+	}
+	return globalState, nil
+}
+
+// installDependencies installs missing dependencies with the same options as the plugin that
+// needs them, minus its setup values.
+func installDependencies(reflowBasePath, pluginName string, unsatisfied []unsatisfiedDependency, opts InstallOptions, installing []string) error {
+	opts.Values = nil
+	for _, u := range unsatisfied {
+		if u.Installed {
+			return fmt.Errorf("dependency %s of plugin '%s' is installed but too old; upgrade it first", u, pluginName)
+		}
+		if u.Dependency.Repo == "" {
+			return fmt.Errorf("dependency '%s' of plugin '%s' declares no repo to install it from; install it manually", u.Dependency.Name, pluginName)
+		}
+		util.Log.Infof("Installing dependency '%s' of plugin '%s' from %s...", u.Dependency.Name, pluginName, util.RedactURL(u.Dependency.Repo))
+		if _, err := installPlugin(reflowBasePath, u.Dependency.Repo, opts, installing); err != nil {
+			return fmt.Errorf("failed to install dependency '%s' of plugin '%s': %w", u.Dependency.Name, pluginName, err)
+		}
+	}
+	return nil
+}
+
+// recordDependents adds pluginName to the RequiredBy list of every installed plugin that
+// fulfils one of its dependencies.
+func recordDependents(globalState *config.GlobalPluginState, pluginName string, deps []config.PluginDependency) {
+	for _, dep := range deps {
+		matches, err := matchingPlugins(globalState, dep)
+		if err != nil {
+			continue // Already rejected by checkDependencies
+		}
+		for _, dependency := range matches {
+			if !slices.Contains(dependency.RequiredBy, pluginName) {
+				dependency.RequiredBy = append(dependency.RequiredBy, pluginName)
+				sort.Strings(dependency.RequiredBy)
+			}
+		}
+	}
+}
+
+// forgetDependent removes pluginName from the RequiredBy list of every installed plugin.
+func forgetDependent(globalState *config.GlobalPluginState, pluginName string) {
+	for _, pluginConf := range globalState.InstalledPlugins {
+		pluginConf.RequiredBy = slices.DeleteFunc(pluginConf.RequiredBy, func(name string) bool { return name == pluginName })
+	}
+}
+
+// installedDependents returns the plugins in RequiredBy that are still installed.
+func installedDependents(globalState *config.GlobalPluginState, pluginConf *config.PluginInstanceConfig) []string {
+	var dependents []string
+	for _, name := range pluginConf.RequiredBy {
+		if _, exists := globalState.InstalledPlugins[name]; exists {
+			dependents = append(dependents, name)
+		}
+	}
+	return dependents
+}
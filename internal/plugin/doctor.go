@@ -0,0 +1,196 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/util"
+	"strings"
+)
+
+// DoctorCheck is the result of a single plugin diagnostic check.
+type DoctorCheck struct {
+	Name        string
+	OK          bool
+	Message     string
+	Remediation string
+	Fixed       bool
+}
+
+// DiagnosePlugin inspects an installed plugin for inconsistent state. When fix is true, it
+// restores a missing config file, restarts a stopped or missing container and regenerates
+// the Nginx config where those checks fail.
+func DiagnosePlugin(reflowBasePath, pluginName string, fix bool) ([]DoctorCheck, error) {
+	ctx := context.Background()
+
+	globalState, err := config.LoadGlobalPluginState(reflowBasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load global plugin state: %w", err)
+	}
+	pluginConf, exists := globalState.InstalledPlugins[pluginName]
+	if !exists {
+		return nil, fmt.Errorf("plugin '%s' not found", pluginName)
+	}
+
+	var checks []DoctorCheck
+	stateChanged := false
+
+	// --- 1. Install Directory ---
+	if info, statErr := os.Stat(pluginConf.InstallPath); statErr != nil || !info.IsDir() {
+		checks = append(checks, DoctorCheck{
+			Name:        "Install directory",
+			Message:     fmt.Sprintf("'%s' is missing", pluginConf.InstallPath),
+			Remediation: fmt.Sprintf("Reinstall: reflow plugin uninstall %s && reflow plugin install %s", pluginName, pluginConf.RepoURL),
+		})
+		return checks, nil
+	}
+	checks = append(checks, DoctorCheck{Name: "Install directory", OK: true, Message: pluginConf.InstallPath})
+
+	// --- 2. Metadata ---
+	metadataPath := filepath.Join(pluginConf.InstallPath, config.PluginMetadataFileName)
+	metadata, parseErr := ParsePluginMetadata(metadataPath)
+	if parseErr != nil {
+		checks = append(checks, DoctorCheck{
+			Name:        "Metadata",
+			Message:     parseErr.Error(),
+			Remediation: fmt.Sprintf("Fix %s in the plugin repository, or reinstall the plugin", config.PluginMetadataFileName),
+		})
+		return checks, nil
+	}
+	pluginConf.Metadata = metadata
+	checks = append(checks, DoctorCheck{Name: "Metadata", OK: true, Message: fmt.Sprintf("%s v%s (%s)", metadata.Name, metadata.Version, metadata.Type)})
+
+	// --- 3. Config File ---
+	configCheck := DoctorCheck{Name: "Config file"}
+	if _, statErr := os.Stat(pluginConf.ConfigPath); statErr == nil {
+		configCheck.OK = true
+		configCheck.Message = pluginConf.ConfigPath
+		if values, loadErr := config.LoadPluginInstanceConfig(pluginConf.ConfigPath); loadErr == nil {
+			pluginConf.ConfigValues = values
+		}
+	} else {
+		configCheck.Message = fmt.Sprintf("'%s' is missing", pluginConf.ConfigPath)
+		configCheck.Remediation = "Run with --fix to restore it from the saved plugin state, then review with 'reflow plugin config edit'"
+		if fix {
+			if saveErr := config.SavePluginInstanceConfig(pluginConf.ConfigPath, pluginConf.ConfigValues); saveErr != nil {
+				configCheck.Message += fmt.Sprintf(" (restore failed: %v)", saveErr)
+			} else {
+				configCheck.OK, configCheck.Fixed = true, true
+			}
+		}
+	}
+	checks = append(checks, configCheck)
+
+	if pluginConf.Type != config.PluginTypeContainer {
+		return checks, nil
+	}
+	if !pluginConf.Enabled {
+		checks = append(checks, DoctorCheck{Name: "Container", OK: true, Message: "plugin is disabled, skipping container and Nginx checks"})
+		return checks, nil
+	}
+
+	// --- 4. Container ---
+	containerName := fmt.Sprintf("reflow-plugin-%s", pluginName)
+	containerCheck := DoctorCheck{Name: "Container"}
+	inspect, inspectErr := docker.InspectContainer(ctx, containerName)
+	switch {
+	case inspectErr == nil && inspect.State != nil && inspect.State.Running:
+		containerCheck.OK = true
+		containerCheck.Message = fmt.Sprintf("'%s' is running", containerName)
+		if pluginConf.ContainerID != inspect.ID {
+			pluginConf.ContainerID = inspect.ID
+			stateChanged = true
+		}
+	case inspectErr == nil:
+		containerCheck.Message = fmt.Sprintf("'%s' exists but is not running", containerName)
+		containerCheck.Remediation = fmt.Sprintf("Run with --fix, or 'reflow plugin disable %s && reflow plugin enable %s'", pluginName, pluginName)
+		if fix {
+			if startErr := docker.StartContainer(ctx, inspect.ID); startErr != nil {
+				containerCheck.Message += fmt.Sprintf(" (start failed: %v)", startErr)
+			} else {
+				containerCheck.OK, containerCheck.Fixed = true, true
+				pluginConf.ContainerID = inspect.ID
+				stateChanged = true
+			}
+		}
+	case docker.IsErrNotFound(inspectErr):
+		containerCheck.Message = fmt.Sprintf("'%s' not found, but plugin is enabled", containerName)
+		containerCheck.Remediation = fmt.Sprintf("Run with --fix, or 'reflow plugin disable %s && reflow plugin enable %s'", pluginName, pluginName)
+		if fix {
+			containerID, startErr := startPluginContainer(ctx, reflowBasePath, pluginConf, pluginConf.ConfigValues)
+			if startErr != nil {
+				containerCheck.Message += fmt.Sprintf(" (recreate failed: %v)", startErr)
+			} else {
+				containerCheck.OK, containerCheck.Fixed = true, true
+				pluginConf.ContainerID = containerID
+				stateChanged = true
+			}
+		}
+	default:
+		containerCheck.Message = fmt.Sprintf("failed to inspect '%s': %v", containerName, inspectErr)
+		containerCheck.Remediation = "Ensure the Docker daemon is running and accessible"
+	}
+	checks = append(checks, containerCheck)
+
+	// --- 5. Nginx Config ---
+	if metadata.Nginx != nil {
+		nginxCheck := checkPluginNginxConfig(reflowBasePath, pluginConf, containerName)
+		if !nginxCheck.OK && fix {
+			if nginxErr := configurePluginNginx(ctx, reflowBasePath, pluginConf); nginxErr != nil {
+				nginxCheck.Message += fmt.Sprintf(" (regenerate failed: %v)", nginxErr)
+				pluginConf.NginxConfigOk = false
+			} else {
+				nginxCheck.OK, nginxCheck.Fixed = true, true
+				pluginConf.NginxConfigOk = true
+			}
+			stateChanged = true
+		}
+		checks = append(checks, nginxCheck)
+	}
+
+	if stateChanged {
+		globalState.InstalledPlugins[pluginName] = pluginConf
+		if saveErr := config.SaveGlobalPluginState(reflowBasePath, globalState); saveErr != nil {
+			util.Log.Errorf("Failed to save plugin state after doctor fixes for '%s': %v", pluginName, saveErr)
+		}
+	}
+
+	return checks, nil
+}
+
+// checkPluginNginxConfig verifies the plugin's Nginx config exists and proxies to the expected container and port.
+func checkPluginNginxConfig(reflowBasePath string, pluginConf *config.PluginInstanceConfig, containerName string) DoctorCheck {
+	check := DoctorCheck{
+		Name:        "Nginx config",
+		Remediation: fmt.Sprintf("Run with --fix to regenerate it, or 'reflow plugin disable %s && reflow plugin enable %s'", pluginConf.PluginName, pluginConf.PluginName),
+	}
+
+	confFileName := fmt.Sprintf("plugin.%s.conf", pluginConf.PluginName)
+	nginxConfPath := filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName, confFileName)
+	content, err := os.ReadFile(nginxConfPath)
+	if err != nil {
+		check.Message = fmt.Sprintf("'%s' is missing or unreadable", nginxConfPath)
+		return check
+	}
+
+	containerPort := nginxContainerPort(pluginConf)
+	if containerPort == 0 {
+		check.Message = "could not determine the plugin container port"
+		check.Remediation = "Set 'nginx.containerPort' in the plugin metadata or 'containerPort' in the plugin config"
+		return check
+	}
+
+	expectedUpstream := fmt.Sprintf("server %s:%d;", containerName, containerPort)
+	if !strings.Contains(string(content), expectedUpstream) {
+		check.Message = fmt.Sprintf("'%s' does not proxy to %s:%d", nginxConfPath, containerName, containerPort)
+		return check
+	}
+
+	check.OK = true
+	check.Remediation = ""
+	check.Message = fmt.Sprintf("%s -> %s:%d", nginxConfPath, containerName, containerPort)
+	return check
+}
@@ -1,34 +1,45 @@
 package plugin
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflow/internal/config"
 	"reflow/internal/docker"
 	"reflow/internal/git"
 	"reflow/internal/nginx"
 	"reflow/internal/util"
+	"slices"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
-// InstallPlugin installs a plugin from a Git repository.
-func InstallPlugin(reflowBasePath, repoURL string) error {
+// InstallPlugin installs a plugin from a Git repository. The plugin's declared dependencies must
+// already be installed, unless opts.WithDeps is set, in which case missing ones are installed
+// first from the repositories they declare.
+func InstallPlugin(reflowBasePath, repoURL string, opts InstallOptions) error {
+	_, err := installPlugin(reflowBasePath, repoURL, opts, nil)
+	return err
+}
+
+// installPlugin implements InstallPlugin and returns the installed plugin's name. installing
+// lists the plugins whose dependencies are being installed, outermost first.
+func installPlugin(reflowBasePath, repoURL string, opts InstallOptions, installing []string) (string, error) {
 	util.Log.Infof("Attempting to install plugin from repository: %s", repoURL)
 	ctx := context.Background() // Use background context for install operations
 
 	// --- 1. Determine Plugin Name and Install Path ---
 	pluginName, err := DerivePluginName(repoURL)
 	if err != nil {
-		return fmt.Errorf("could not determine plugin name: %w", err)
+		return "", fmt.Errorf("could not determine plugin name: %w", err)
+	}
+	if slices.Contains(installing, pluginName) {
+		return "", fmt.Errorf("plugin dependency cycle: %s -> %s", strings.Join(installing, " -> "), pluginName)
 	}
 	installPath := config.GetPluginInstallPath(reflowBasePath, pluginName)
 	util.Log.Debugf("Derived plugin name: %s", pluginName)
@@ -37,21 +48,30 @@ func InstallPlugin(reflowBasePath, repoURL string) error {
 	// --- 2. Check if Already Installed ---
 	globalState, err := config.LoadGlobalPluginState(reflowBasePath)
 	if err != nil {
-		return fmt.Errorf("failed to load global plugin state: %w", err)
+		return "", fmt.Errorf("failed to load global plugin state: %w", err)
 	}
 	if _, exists := globalState.InstalledPlugins[pluginName]; exists {
-		return fmt.Errorf("plugin '%s' is already installed. Uninstall first if you want to reinstall", pluginName)
+		return "", fmt.Errorf("plugin '%s' is already installed. Uninstall first if you want to reinstall", pluginName)
 	}
 
 	// --- 3. Clone Repository ---
 	pluginsBasePath := config.GetPluginsBasePath(reflowBasePath)
 	if err := os.MkdirAll(pluginsBasePath, 0755); err != nil {
-		return fmt.Errorf("failed to create plugins directory %s: %w", pluginsBasePath, err)
+		return "", fmt.Errorf("failed to create plugins directory %s: %w", pluginsBasePath, err)
 	}
 
-	if err := git.CloneRepo(repoURL, installPath); err != nil {
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		util.Log.Warnf("Could not load global config, using default git authentication: %v", err)
+		globalCfg = &config.GlobalConfig{}
+	}
+	gitAuth, err := git.AuthConfigFromGlobal(globalCfg).WithHTTPCredentials(reflowBasePath, "", repoURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to load git credentials: %w", err)
+	}
+	if err := git.CloneRepo(repoURL, installPath, 0, gitAuth); err != nil {
 		_ = os.RemoveAll(installPath)
-		return fmt.Errorf("failed to clone plugin repository '%s': %w", repoURL, err)
+		return "", fmt.Errorf("failed to clone plugin repository '%s': %w", util.RedactURL(repoURL), err)
 	}
 
 	// --- 4. Parse Plugin Metadata ---
@@ -59,62 +79,43 @@ func InstallPlugin(reflowBasePath, repoURL string) error {
 	metadata, err := ParsePluginMetadata(metadataPath)
 	if err != nil {
 		_ = os.RemoveAll(installPath)
-		return fmt.Errorf("failed to parse plugin metadata file (%s): %w", config.PluginMetadataFileName, err)
+		return "", fmt.Errorf("failed to parse plugin metadata file (%s): %w", config.PluginMetadataFileName, err)
 	}
 	util.Log.Infof("Loaded metadata for plugin '%s' (Type: %s, Version: %s)", metadata.Name, metadata.Type, metadata.Version)
 
+	// --- 4a. Check Dependencies ---
+	globalState, err = resolveDependencies(reflowBasePath, globalState, pluginName, metadata.Dependencies, opts, installing)
+	if err != nil {
+		_ = os.RemoveAll(installPath)
+		return "", fmt.Errorf("plugin '%s' cannot be installed: %w", pluginName, err)
+	}
+
 	// --- 5. Run Setup Prompts and Collect Config ---
-	configValues := make(map[string]string)
 	if len(metadata.Setup) > 0 {
 		util.Log.Info("Running plugin setup configuration...")
-		reader := bufio.NewReader(os.Stdin)
-		for _, prompt := range metadata.Setup {
-			// Generate default value dynamically if needed (e.g., domain)
-			defaultValue := prompt.Default
-			if prompt.Key == "domain" && defaultValue == "" {
-				// Attempt to calculate a default plugin domain
-				globalCfg, _ := config.LoadGlobalConfig(reflowBasePath) // Ignore error, GetEffectivePluginDomain handles nil
-				calculatedDomain, domainErr := GetEffectivePluginDomain(globalCfg, pluginName, "plugin")
-				if domainErr == nil {
-					defaultValue = calculatedDomain
-				}
-			}
-
-			fmt.Printf("  - %s", prompt.Prompt)
-			if defaultValue != "" {
-				fmt.Printf(" [%s]", defaultValue)
-			}
-			if prompt.Description != "" {
-				fmt.Printf("\n    (%s)", prompt.Description)
-			}
-			fmt.Print(": ")
-
-			input, _ := reader.ReadString('\n')
-			value := strings.TrimSpace(input)
-
-			if value == "" && defaultValue != "" {
-				value = defaultValue
-			}
-
-			if value == "" && prompt.Required {
-				fmt.Println("  This field is required.")
-				fmt.Printf("  - %s: ", prompt.Prompt)
-				input, _ = reader.ReadString('\n')
-				value = strings.TrimSpace(input)
-				if value == "" {
-					_ = os.RemoveAll(installPath)
-					return fmt.Errorf("required configuration value '%s' was not provided", prompt.Key)
-				}
+	}
+	defaultFor := func(prompt config.PluginSetupPrompt) string {
+		// Generate default value dynamically if needed (e.g., domain)
+		if prompt.Key == "domain" && prompt.Default == "" {
+			// Attempt to calculate a default plugin domain
+			globalCfg, _ := config.LoadGlobalConfig(reflowBasePath) // Ignore error, GetEffectivePluginDomain handles nil
+			if calculatedDomain, domainErr := GetEffectivePluginDomain(globalCfg, pluginName, "plugin"); domainErr == nil {
+				return calculatedDomain
 			}
-			configValues[prompt.Key] = value
 		}
+		return prompt.Default
+	}
+	configValues, err := collectSetupValues(metadata.Setup, defaultFor, opts)
+	if err != nil {
+		_ = os.RemoveAll(installPath)
+		return "", fmt.Errorf("plugin '%s' setup failed: %w", pluginName, err)
 	}
 
 	// --- 6. Save Instance Configuration ---
 	instanceConfigPath := config.GetPluginConfigPath(reflowBasePath, pluginName)
 	if err := config.SavePluginInstanceConfig(instanceConfigPath, configValues); err != nil {
 		_ = os.RemoveAll(installPath)
-		return fmt.Errorf("failed to save plugin instance configuration: %w", err)
+		return "", fmt.Errorf("failed to save plugin instance configuration: %w", err)
 	}
 
 	// --- 7. Prepare Global State Entry ---
@@ -140,7 +141,7 @@ func InstallPlugin(reflowBasePath, repoURL string) error {
 		containerID, startErr := startPluginContainer(ctx, reflowBasePath, instanceConfig, configValues)
 		if startErr != nil {
 			_ = os.RemoveAll(installPath) // Cleanup install path on container start failure
-			return fmt.Errorf("failed to start plugin container: %w", startErr)
+			return "", fmt.Errorf("failed to start plugin container: %w", startErr)
 		}
 		instanceConfig.ContainerID = containerID
 		util.Log.Infof("Plugin container started successfully (ID: %s)", containerID[:12])
@@ -157,7 +158,7 @@ func InstallPlugin(reflowBasePath, repoURL string) error {
 				_ = docker.RemoveContainer(ctx, containerID)
 				util.Log.Warnf("Attempting rollback: removing installation directory %s...", installPath)
 				_ = os.RemoveAll(installPath)
-				return fmt.Errorf("failed to configure Nginx for plugin: %w", nginxErr)
+				return "", fmt.Errorf("failed to configure Nginx for plugin: %w", nginxErr)
 			}
 			instanceConfig.NginxConfigOk = true
 			util.Log.Info("Nginx configured successfully for plugin.")
@@ -169,6 +170,7 @@ func InstallPlugin(reflowBasePath, repoURL string) error {
 	// --- 9. Save Final Global Plugin State ---
 	instanceConfig.Metadata = nil // Don't save full metadata in state file
 	globalState.InstalledPlugins[pluginName] = instanceConfig
+	recordDependents(globalState, pluginName, metadata.Dependencies)
 	if err := config.SaveGlobalPluginState(reflowBasePath, globalState); err != nil {
 		// This is still problematic, but less critical now as container/nginx might be running
 		util.Log.Errorf("CRITICAL: Plugin installed and setup completed, but failed to save final global plugin state: %v", err)
@@ -184,11 +186,12 @@ func InstallPlugin(reflowBasePath, repoURL string) error {
 			util.Log.Warnf("   Could not determine access URL: %v", domainErr)
 		}
 	}
-	return nil
+	return pluginName, nil
 }
 
-// UninstallPlugin removes an installed plugin.
-func UninstallPlugin(reflowBasePath, pluginName string) error {
+// UninstallPlugin removes an installed plugin. Its persistent data (named volumes and the plugin
+// data directory) is only deleted when removeData is set.
+func UninstallPlugin(reflowBasePath, pluginName string, removeData bool) error {
 	util.Log.Warnf("Attempting to uninstall plugin '%s'...", pluginName)
 	ctx := context.Background()
 
@@ -202,6 +205,15 @@ func UninstallPlugin(reflowBasePath, pluginName string) error {
 	if !exists {
 		return fmt.Errorf("plugin '%s' is not installed", pluginName)
 	}
+	if dependents := installedDependents(globalState, pluginConfig); len(dependents) > 0 {
+		util.Log.Warnf("Plugin(s) %s depend on '%s' and may stop working once it is removed.", strings.Join(dependents, ", "), pluginName)
+	}
+
+	// Metadata isn't kept in state; read it before the install directory is deleted.
+	declaresVolumes := false
+	if metadata, metaErr := ParsePluginMetadata(filepath.Join(pluginConfig.InstallPath, config.PluginMetadataFileName)); metaErr == nil && metadata.Container != nil {
+		declaresVolumes = len(metadata.Container.Volumes) > 0
+	}
 
 	// --- 2. Stop Container (if applicable) ---
 	if pluginConfig.Type == config.PluginTypeContainer && pluginConfig.ContainerID != "" {
@@ -230,6 +242,12 @@ func UninstallPlugin(reflowBasePath, pluginName string) error {
 		}
 	}
 
+	if pluginConfig.Type == config.PluginTypeContainer {
+		if err := docker.RemoveNetwork(ctx, config.PluginNetworkName(pluginName)); err != nil {
+			util.Log.Errorf("Failed to remove network of plugin '%s': %v. Continuing cleanup.", pluginName, err)
+		}
+	}
+
 	// --- 5. Remove Installation Directory ---
 	util.Log.Infof("Removing installation directory: %s", pluginConfig.InstallPath)
 	if err := os.RemoveAll(pluginConfig.InstallPath); err != nil {
@@ -237,8 +255,23 @@ func UninstallPlugin(reflowBasePath, pluginName string) error {
 		util.Log.Errorf("Failed to remove installation directory %s: %v. Continuing cleanup.", pluginConfig.InstallPath, err)
 	}
 
-	// --- 6. Update Global State ---
+	// --- 6. Remove or Keep Persistent Data ---
+	pluginDataPath := config.GetPluginDataPath(reflowBasePath, pluginName)
+	if removeData {
+		util.Log.Infof("Removing persistent data for plugin '%s'...", pluginName)
+		if err := docker.RemoveVolumesByLabels(ctx, map[string]string{docker.LabelPluginName: pluginName}); err != nil {
+			util.Log.Errorf("Failed to remove volumes for plugin '%s': %v. Continuing cleanup.", pluginName, err)
+		}
+		if err := os.RemoveAll(pluginDataPath); err != nil {
+			util.Log.Errorf("Failed to remove data directory %s: %v. Continuing cleanup.", pluginDataPath, err)
+		}
+	} else if declaresVolumes {
+		util.Log.Infof("Keeping persistent data for plugin '%s' (volumes prefixed '%s', host data in %s). Use --remove-data to delete it.", pluginName, config.PluginVolumePrefix(pluginName), pluginDataPath)
+	}
+
+	// --- 7. Update Global State ---
 	delete(globalState.InstalledPlugins, pluginName)
+	forgetDependent(globalState, pluginName)
 	if err := config.SaveGlobalPluginState(reflowBasePath, globalState); err != nil {
 		// This is bad, state is inconsistent with filesystem
 		return fmt.Errorf("failed to save updated global plugin state after uninstalling '%s': %w. Manual cleanup of plugins.json may be required", pluginName, err)
@@ -270,14 +303,33 @@ func startPluginContainer(ctx context.Context, reflowBasePath string, pluginConf
 			buildArgs[key] = &v
 		}
 
-		err := docker.BuildImage(ctx, dockerfilePath, contextPath, imageTag, buildArgs)
+		var buildOpts docker.BuildOptions
+		if globalCfg, cfgErr := config.LoadGlobalConfig(reflowBasePath); cfgErr == nil {
+			buildOpts.BuildKit = docker.UseBuildKit(ctx, globalCfg.UseBuildKit)
+		} else {
+			util.Log.Warnf("Could not load global config, using the default builder: %v", cfgErr)
+			buildOpts.BuildKit = docker.UseBuildKit(ctx, nil)
+		}
+
+		err := docker.BuildImage(ctx, dockerfilePath, contextPath, imageTag, buildArgs, buildOpts)
 		if err != nil {
 			return "", fmt.Errorf("docker image build failed for plugin '%s': %w", pluginConf.PluginName, err)
 		}
 		finalImageName = imageTag
 	} else if imageName != "" {
 		util.Log.Infof("Pulling image '%s' for plugin '%s'...", imageName, pluginConf.DisplayName)
-		err := docker.PullImage(ctx, imageName)
+		globalCfg, cfgErr := config.LoadGlobalConfig(reflowBasePath)
+		if cfgErr != nil {
+			util.Log.Warnf("Could not load global config for registry credentials, pulling anonymously: %v", cfgErr)
+		}
+		registryAuth, err := docker.RegistryAuthFromGlobal(globalCfg, imageName)
+		if err != nil {
+			return "", err
+		}
+		if registryAuth != "" {
+			util.Log.Debugf("Using configured registry credentials to pull '%s'", imageName)
+		}
+		err = docker.PullImage(ctx, imageName, registryAuth)
 		if err != nil {
 			return "", fmt.Errorf("failed to pull image '%s': %w", imageName, err)
 		}
@@ -314,19 +366,50 @@ func startPluginContainer(ctx context.Context, reflowBasePath string, pluginConf
 	envVars = append(envVars, fmt.Sprintf("PORT=%d", appPort))
 
 	labels := map[string]string{
-		docker.LabelManaged:  "true",
-		"reflow.type":        "plugin",
-		"reflow.plugin.name": pluginConf.PluginName,
+		docker.LabelManaged:    "true",
+		"reflow.type":          "plugin",
+		docker.LabelPluginName: pluginConf.PluginName,
+	}
+
+	limits, err := config.ParseResources("container.resources", pluginConf.Metadata.Container.Resources)
+	if err != nil {
+		return "", fmt.Errorf("plugin '%s' metadata is invalid: %w", pluginConf.PluginName, err)
+	}
+
+	volumes, err := config.ResolveVolumes("container.volumes", config.GetPluginDataPath(reflowBasePath, pluginConf.PluginName), config.PluginVolumePrefix(pluginConf.PluginName), containerMeta.Volumes)
+	if err != nil {
+		return "", fmt.Errorf("plugin '%s' metadata is invalid: %w", pluginConf.PluginName, err)
+	}
+	volumeMounts := make([]docker.VolumeMount, 0, len(volumes))
+	for _, v := range volumes {
+		volumeMounts = append(volumeMounts, docker.VolumeMount{Source: v.Source, Target: v.Target, ReadOnly: v.ReadOnly})
+	}
+
+	networkName := config.ReflowNetworkName
+	if containerMeta.NetworkPolicy != nil {
+		networkName = config.PluginNetworkName(pluginConf.PluginName)
+		if err := setupPluginNetwork(ctx, pluginConf, containerMeta.NetworkPolicy); err != nil {
+			return "", err
+		}
 	}
 
 	runOptions := docker.ContainerRunOptions{
-		ImageName:     finalImageName,
-		ContainerName: containerName,
-		NetworkName:   config.ReflowNetworkName,
-		Labels:        labels,
-		EnvVars:       envVars,
-		AppPort:       appPort,
-		RestartPolicy: "unless-stopped",
+		ImageName:         finalImageName,
+		ContainerName:     containerName,
+		NetworkName:       networkName,
+		Labels:            labels,
+		EnvVars:           envVars,
+		AppPort:           appPort,
+		RestartPolicy:     config.DefaultRestartPolicy,
+		MemoryLimit:       limits.MemoryBytes,
+		MemoryReservation: limits.MemoryReservationBytes,
+		NanoCPUs:          limits.NanoCPUs,
+		Volumes:           volumeMounts,
+		VolumeLabels: map[string]string{
+			docker.LabelManaged:    "true",
+			docker.LabelPluginName: pluginConf.PluginName,
+		},
+		HostPathRoot: reflowBasePath,
 	}
 
 	cli, _ := docker.GetClient()
@@ -346,7 +429,22 @@ func startPluginContainer(ctx context.Context, reflowBasePath string, pluginConf
 		return "", fmt.Errorf("failed to run container %s: %w", containerName, err)
 	}
 
-	time.Sleep(5 * time.Second)
+	startTimeout := config.DefaultStartTimeoutSeconds * time.Second
+	if containerMeta.StartTimeout > 0 {
+		startTimeout = time.Duration(containerMeta.StartTimeout) * time.Second
+	}
+	startErr := docker.WaitForContainerRunning(ctx, containerID, startTimeout)
+	if startErr == nil {
+		startErr = docker.RunPostStartHooks(ctx, containerID, containerMeta.PostStartHooks, config.PostStartHookTimeoutSeconds*time.Second)
+	}
+	if startErr != nil {
+		util.Log.Warnf("Removing plugin container %s after failed startup...", containerName)
+		_ = docker.StopContainer(ctx, containerID, nil)
+		if rmErr := docker.RemoveContainer(ctx, containerID); rmErr != nil {
+			util.Log.Errorf("Failed to remove plugin container %s: %v", containerName, rmErr)
+		}
+		return "", fmt.Errorf("plugin container %s failed to start: %w", containerName, startErr)
+	}
 
 	return containerID, nil
 }
@@ -369,6 +467,30 @@ func stopPluginContainer(ctx context.Context, reflowBasePath string, pluginConf
 	return nil
 }
 
+// setupPluginNetwork creates the network of a plugin with a network policy and connects Nginx,
+// if the plugin is served through it, and the policy's allowed containers to it. Containers that
+// cannot be connected, e.g. because they don't exist yet, are only warned about.
+func setupPluginNetwork(ctx context.Context, pluginConf *config.PluginInstanceConfig, policy *config.NetworkPolicyConfig) error {
+	networkName := config.PluginNetworkName(pluginConf.PluginName)
+	if err := docker.CreateNetworkIfNotExists(ctx, networkName, map[string]string{
+		docker.LabelManaged:    "true",
+		docker.LabelPluginName: pluginConf.PluginName,
+	}); err != nil {
+		return err
+	}
+
+	allowed := policy.AllowedContainers
+	if pluginConf.Metadata.Nginx != nil {
+		allowed = append([]string{config.ReflowNginxContainerName}, allowed...)
+	}
+	for _, containerName := range allowed {
+		if err := docker.ConnectContainerToNetwork(ctx, networkName, containerName); err != nil {
+			util.Log.Warnf("Could not give container '%s' access to plugin '%s': %v", containerName, pluginConf.PluginName, err)
+		}
+	}
+	return nil
+}
+
 // configurePluginNginx generates/writes Nginx config for a plugin and reloads Nginx.
 func configurePluginNginx(ctx context.Context, reflowBasePath string, pluginConf *config.PluginInstanceConfig) error {
 	if pluginConf.Metadata == nil || pluginConf.Metadata.Nginx == nil {
@@ -381,12 +503,7 @@ func configurePluginNginx(ctx context.Context, reflowBasePath string, pluginConf
 		return fmt.Errorf("failed to determine domain for plugin '%s': %w", pluginConf.PluginName, err)
 	}
 
-	containerPort := nginxMeta.ContainerPort
-	if containerPort == 0 {
-		if portStr, ok := pluginConf.ConfigValues["containerPort"]; ok {
-			fmt.Sscan(portStr, &containerPort)
-		}
-	}
+	containerPort := nginxContainerPort(pluginConf)
 	if containerPort == 0 {
 		return fmt.Errorf("could not determine container port for plugin '%s' Nginx config", pluginConf.PluginName)
 	}
@@ -433,6 +550,20 @@ func configurePluginNginx(ctx context.Context, reflowBasePath string, pluginConf
 	return nil
 }
 
+// nginxContainerPort returns the port Nginx should proxy to for a plugin, or 0 if unknown.
+func nginxContainerPort(pluginConf *config.PluginInstanceConfig) int {
+	containerPort := 0
+	if pluginConf.Metadata != nil && pluginConf.Metadata.Nginx != nil {
+		containerPort = pluginConf.Metadata.Nginx.ContainerPort
+	}
+	if containerPort == 0 {
+		if portStr, ok := pluginConf.ConfigValues["containerPort"]; ok {
+			fmt.Sscan(portStr, &containerPort)
+		}
+	}
+	return containerPort
+}
+
 // RemovePluginNginx removes the Nginx config file for a plugin and reloads Nginx.
 func RemovePluginNginx(ctx context.Context, reflowBasePath string, pluginConf *config.PluginInstanceConfig) error {
 	confFileName := fmt.Sprintf("plugin.%s.conf", pluginConf.PluginName)
@@ -488,6 +619,13 @@ func ParsePluginMetadata(filePath string) (*config.PluginMetadata, error) {
 		if metadata.Container.Dockerfile == "" && metadata.Container.Image == "" {
 			return nil, errors.New("container plugin metadata must specify either 'container.dockerfile' or 'container.image'")
 		}
+		if _, err := config.ParseResources("container.resources", metadata.Container.Resources); err != nil {
+			return nil, fmt.Errorf("plugin metadata is invalid: %w", err)
+		}
+		// The real data path depends on the plugin name; only the entries' validity matters here.
+		if _, err := config.ResolveVolumes("container.volumes", filepath.Join(filepath.Dir(filePath), config.DataDirName), "", metadata.Container.Volumes); err != nil {
+			return nil, fmt.Errorf("plugin metadata is invalid: %w", err)
+		}
 	}
 	if metadata.Type == config.PluginTypeCLI && metadata.Commands == nil {
 		return nil, errors.New("cli plugin metadata must include a 'commands' section")
@@ -574,7 +712,10 @@ func GetEffectivePluginDomainFromConfig(reflowBasePath string, pluginConf *confi
 // GetEffectivePluginDomain calculates the domain name for a plugin.
 // env parameter is usually "plugin" but could be adapted if plugins have envs later.
 func GetEffectivePluginDomain(globalCfg *config.GlobalConfig, pluginName, env string) (string, error) {
-	if globalCfg == nil || globalCfg.DefaultDomain == "" || globalCfg.DefaultDomain == "localhost" || globalCfg.DefaultDomain == "yourdomain.com" {
+	if globalCfg == nil {
+		return "", fmt.Errorf("cannot calculate default domain for plugin %s: global config is not available", pluginName)
+	}
+	if globalCfg.DefaultDomain == "" || globalCfg.DefaultDomain == "localhost" || globalCfg.DefaultDomain == "yourdomain.com" {
 		return "", fmt.Errorf("cannot calculate default domain for plugin %s: global defaultDomain is not set or invalid ('%s')", pluginName, globalCfg.DefaultDomain)
 	}
 	// Simple structure: plugin-<name>.<defaultDomain>
@@ -640,17 +781,16 @@ func LoadCliPlugins(reflowBasePath string, rootCommand *cobra.Command) error {
 						util.Log.Debugf(" Plugin Executable: %s", executablePath)
 						util.Log.Debugf(" Arguments: %v", args)
 
-						execCmd := exec.Command(executablePath, args...)
+						execCmd, cleanup, err := pluginCommand(reflowBasePath, pluginConf, executablePath, args)
+						if err != nil {
+							return err
+						}
+						defer cleanup()
 						execCmd.Stdin = os.Stdin
 						execCmd.Stdout = os.Stdout
 						execCmd.Stderr = os.Stderr
-						execCmd.Env = append(os.Environ(),
-							fmt.Sprintf("REFLOW_BASE_PATH=%s", reflowBasePath),
-							fmt.Sprintf("REFLOW_PLUGIN_CONFIG_PATH=%s", pluginConf.ConfigPath),
-							fmt.Sprintf("REFLOW_PLUGIN_INSTALL_PATH=%s", pluginConf.InstallPath),
-						)
 
-						err := execCmd.Run()
+						err = execCmd.Run()
 						if err != nil {
 							// Don't wrap the error here, let Cobra handle the exit code from the plugin
 							util.Log.Debugf("Plugin command '%s' execution finished with error: %v", cmdName, err)
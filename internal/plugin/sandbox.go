@@ -0,0 +1,41 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"reflow/internal/config"
+	"reflow/internal/util"
+)
+
+// SandboxHelperCommand is the hidden first argument with which reflow re-executes itself to set
+// up the sandbox of a CLI plugin before running the plugin's executable. See RunSandboxHelper.
+const SandboxHelperCommand = "__plugin-sandbox"
+
+// pluginCommand builds the command that runs a CLI plugin's executable. With pluginSandbox set
+// in the global config the plugin runs sandboxed: it can read the filesystem, including its
+// install path and config, but can only write to a temporary directory of its own (exported as
+// TMPDIR and REFLOW_PLUGIN_TMP_DIR), and the Docker socket is hidden. The sandbox does not
+// isolate the network or other Unix sockets the user can connect to, and the plugin keeps the
+// user's supplementary groups. The returned cleanup function removes the temp directory.
+func pluginCommand(reflowBasePath string, pluginConf *config.PluginInstanceConfig, executablePath string, args []string) (*exec.Cmd, func(), error) {
+	env := append(os.Environ(),
+		fmt.Sprintf("REFLOW_BASE_PATH=%s", reflowBasePath),
+		fmt.Sprintf("REFLOW_PLUGIN_CONFIG_PATH=%s", pluginConf.ConfigPath),
+		fmt.Sprintf("REFLOW_PLUGIN_INSTALL_PATH=%s", pluginConf.InstallPath),
+	)
+
+	sandboxed := false
+	if globalCfg, err := config.LoadGlobalConfig(reflowBasePath); err != nil {
+		util.Log.Warnf("Could not load global config to check pluginSandbox: %v. Running plugin '%s' without a sandbox.", err, pluginConf.PluginName)
+	} else {
+		sandboxed = globalCfg.PluginSandbox
+	}
+
+	if !sandboxed {
+		execCmd := exec.Command(executablePath, args...)
+		execCmd.Env = env
+		return execCmd, func() {}, nil
+	}
+	return sandboxedCommand(pluginConf.PluginName, executablePath, args, env)
+}
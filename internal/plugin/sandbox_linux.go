@@ -0,0 +1,241 @@
+//go:build linux
+
+package plugin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflow/internal/util"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Securebits from linux/securebits.h, which x/sys/unix does not define.
+const (
+	secbitNoRoot              = 1 << 0
+	secbitNoRootLocked        = 1 << 1
+	secbitNoSetuidFixup       = 1 << 2
+	secbitNoSetuidFixupLocked = 1 << 3
+)
+
+// Per-mount options from /proc/self/mountinfo that have to be kept when remounting read-only;
+// the kernel refuses to clear them on mounts inherited into a user namespace.
+var preservedMountFlags = map[string]uintptr{
+	"nosuid":      unix.MS_NOSUID,
+	"nodev":       unix.MS_NODEV,
+	"noexec":      unix.MS_NOEXEC,
+	"noatime":     unix.MS_NOATIME,
+	"nodiratime":  unix.MS_NODIRATIME,
+	"relatime":    unix.MS_RELATIME,
+	"strictatime": unix.MS_STRICTATIME,
+}
+
+// sandboxedCommand starts the plugin through reflow's sandbox helper in a new user and mount
+// namespace. The helper runs as root inside the namespace, which maps to the invoking user, so
+// it can remount the filesystem read-only before dropping its capabilities and running the
+// plugin.
+func sandboxedCommand(pluginName, executablePath string, args, env []string) (*exec.Cmd, func(), error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to locate the reflow executable for the plugin sandbox: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("reflow-plugin-%s-", pluginName))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temp dir for plugin '%s': %w", pluginName, err)
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			util.Log.Warnf("Failed to remove temp dir %s of plugin '%s': %v", tmpDir, pluginName, err)
+		}
+	}
+
+	helperArgs := append([]string{SandboxHelperCommand, tmpDir, executablePath}, args...)
+	execCmd := exec.Command(self, helperArgs...)
+	execCmd.Env = append(env, "TMPDIR="+tmpDir, "REFLOW_PLUGIN_TMP_DIR="+tmpDir)
+	execCmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+	}
+	util.Log.Debugf("Running plugin '%s' sandboxed, writable temp dir: %s", pluginName, tmpDir)
+	return execCmd, cleanup, nil
+}
+
+// RunSandboxHelper sets up the sandbox and replaces the current process with the plugin. It is
+// called by main when reflow is started with SandboxHelperCommand, with the arguments
+// <tmp-dir> <executable> [plugin args...], and never returns.
+func RunSandboxHelper(args []string) {
+	if len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "usage: reflow %s <tmp-dir> <executable> [args...]\n", SandboxHelperCommand)
+		os.Exit(2)
+	}
+	tmpDir, executablePath := args[0], args[1]
+
+	// Capabilities are per thread, so they must be dropped on the thread that execs the plugin.
+	runtime.LockOSThread()
+
+	if err := restrictFilesystem(tmpDir); err != nil {
+		fmt.Fprintf(os.Stderr, "reflow: failed to set up plugin sandbox: %v\n", err)
+		os.Exit(1)
+	}
+	if err := dropCapabilities(); err != nil {
+		fmt.Fprintf(os.Stderr, "reflow: failed to drop capabilities in plugin sandbox: %v\n", err)
+		os.Exit(1)
+	}
+
+	err := syscall.Exec(executablePath, append([]string{executablePath}, args[2:]...), os.Environ())
+	fmt.Fprintf(os.Stderr, "reflow: failed to run plugin executable %s: %v\n", executablePath, err)
+	os.Exit(126)
+}
+
+// restrictFilesystem makes every mount read-only except a bind mount of tmpDir, and hides the
+// Docker socket. Mount changes stay private to the sandbox's mount namespace.
+func restrictFilesystem(tmpDir string) error {
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+	tmpDir, err := filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve temp dir %s: %w", tmpDir, err)
+	}
+	if err := unix.Mount(tmpDir, tmpDir, "", unix.MS_BIND, ""); err != nil {
+		return fmt.Errorf("failed to bind-mount temp dir %s: %w", tmpDir, err)
+	}
+	if err := hideDockerSockets(); err != nil {
+		return err
+	}
+
+	mounts, err := readMountInfo()
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		if m.mountPoint == tmpDir {
+			continue
+		}
+		flags := uintptr(unix.MS_BIND | unix.MS_REMOUNT | unix.MS_RDONLY)
+		for _, option := range strings.Split(m.options, ",") {
+			flags |= preservedMountFlags[option]
+		}
+		if err := unix.Mount("", m.mountPoint, "", flags, ""); err != nil {
+			// Mount points hidden by a later mount or deleted since can't be reached by path.
+			if errors.Is(err, unix.ENOENT) || errors.Is(err, unix.EACCES) {
+				continue
+			}
+			return fmt.Errorf("failed to remount %s read-only: %w", m.mountPoint, err)
+		}
+	}
+	return nil
+}
+
+// hideDockerSockets bind-mounts /dev/null over the Docker daemon sockets the user could reach.
+// A read-only mount does not stop connecting to a Unix socket, and the plugin keeps the user's
+// supplementary groups (e.g. docker), so the socket would otherwise give it control of the
+// host. The bind mounts are made read-only with the rest of the filesystem.
+func hideDockerSockets() error {
+	paths := []string{"/var/run/docker.sock", "/run/docker.sock"}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		paths = append(paths, filepath.Join(runtimeDir, "docker.sock")) // Rootless Docker
+	}
+	if socketPath, ok := strings.CutPrefix(os.Getenv("DOCKER_HOST"), "unix://"); ok && socketPath != "" {
+		paths = append(paths, socketPath)
+	}
+
+	hidden := make(map[string]bool)
+	for _, socketPath := range paths {
+		resolved, err := filepath.EvalSymlinks(socketPath)
+		if err != nil || hidden[resolved] {
+			continue
+		}
+		info, err := os.Stat(resolved)
+		if err != nil || info.Mode().Type() != os.ModeSocket {
+			continue
+		}
+		if err := unix.Mount("/dev/null", resolved, "", unix.MS_BIND, ""); err != nil {
+			return fmt.Errorf("failed to hide docker socket %s: %w", resolved, err)
+		}
+		hidden[resolved] = true
+	}
+	return nil
+}
+
+type mountInfo struct {
+	mountPoint string
+	options    string
+}
+
+// readMountInfo lists the mounts of the current mount namespace from /proc/self/mountinfo.
+func readMountInfo() ([]mountInfo, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mount table: %w", err)
+	}
+	defer file.Close()
+
+	var mounts []mountInfo
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Format: id parent major:minor root mount-point options [optional...] - fstype source super-options
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		mounts = append(mounts, mountInfo{mountPoint: unescapeMountPath(fields[4]), options: fields[5]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mount table: %w", err)
+	}
+	return mounts, nil
+}
+
+// unescapeMountPath decodes the octal escapes (e.g. \040 for a space) used in mountinfo paths.
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if code, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+// dropCapabilities makes sure the plugin gets no capabilities in the namespace when exec'd, so
+// it cannot undo the read-only remounts: root loses its special treatment on exec, the bounding
+// and ambient sets are emptied, and no_new_privs stops setuid binaries from regaining any.
+func dropCapabilities() error {
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to clear ambient capabilities: %w", err)
+	}
+	securebits := secbitNoRoot | secbitNoRootLocked | secbitNoSetuidFixup | secbitNoSetuidFixupLocked
+	if err := unix.Prctl(unix.PR_SET_SECUREBITS, uintptr(securebits), 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set securebits: %w", err)
+	}
+	for capability := 0; ; capability++ {
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(capability), 0, 0, 0); err != nil {
+			if errors.Is(err, unix.EINVAL) {
+				break // Past the last capability the kernel knows
+			}
+			return fmt.Errorf("failed to drop capability %d from the bounding set: %w", capability, err)
+		}
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set no_new_privs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+//go:build linux
+
+package plugin
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the test binary act as the sandbox helper, as main does for reflow.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == SandboxHelperCommand {
+		RunSandboxHelper(os.Args[2:])
+	}
+	os.Exit(m.Run())
+}
+
+// runSandboxed runs a shell script in the plugin sandbox and returns its combined output.
+func runSandboxed(t *testing.T, script string, env ...string) (string, error) {
+	t.Helper()
+	execCmd, cleanup, err := sandboxedCommand("test", "/bin/sh", []string{"-c", script}, append(os.Environ(), env...))
+	if err != nil {
+		t.Fatalf("sandboxedCommand: %v", err)
+	}
+	defer cleanup()
+	output, err := execCmd.CombinedOutput()
+	return string(output), err
+}
+
+// requireSandbox skips the test when the sandbox cannot be set up here, e.g. because user
+// namespaces are disabled.
+func requireSandbox(t *testing.T) {
+	t.Helper()
+	if output, err := runSandboxed(t, "true"); err != nil {
+		t.Skipf("plugin sandbox is unavailable: %v %s", err, output)
+	}
+}
+
+func TestSandboxReadsConfig(t *testing.T) {
+	requireSandbox(t)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("greeting: hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runSandboxed(t, `cat "$REFLOW_PLUGIN_CONFIG_PATH"`, "REFLOW_PLUGIN_CONFIG_PATH="+configPath)
+	if err != nil {
+		t.Fatalf("reading config failed: %v %s", err, output)
+	}
+	if output != "greeting: hello\n" {
+		t.Errorf("got config %q", output)
+	}
+}
+
+func TestSandboxWritesOnlyTempDir(t *testing.T) {
+	requireSandbox(t)
+	outsideDir := t.TempDir()
+
+	output, err := runSandboxed(t, `echo data > "$OUTSIDE_DIR/file"`, "OUTSIDE_DIR="+outsideDir)
+	if err == nil {
+		t.Errorf("writing outside the temp dir succeeded: %s", output)
+	}
+	if _, statErr := os.Stat(filepath.Join(outsideDir, "file")); statErr == nil {
+		t.Errorf("file outside the temp dir was created")
+	}
+
+	output, err = runSandboxed(t, `echo data > "$REFLOW_PLUGIN_TMP_DIR/file" && cat "$TMPDIR/file"`)
+	if err != nil {
+		t.Fatalf("writing to the temp dir failed: %v %s", err, output)
+	}
+	if output != "data\n" {
+		t.Errorf("got %q from the temp dir", output)
+	}
+}
+
+func TestSandboxHidesDockerSocket(t *testing.T) {
+	requireSandbox(t)
+	socketPath := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	output, err := runSandboxed(t, `if [ -S "$SOCKET" ]; then echo socket; else echo hidden; fi`, "SOCKET="+socketPath, "DOCKER_HOST=unix://"+socketPath)
+	if err != nil {
+		t.Fatalf("sandboxed command failed: %v %s", err, output)
+	}
+	if strings.TrimSpace(output) != "hidden" {
+		t.Errorf("docker socket %s is reachable in the sandbox", socketPath)
+	}
+}
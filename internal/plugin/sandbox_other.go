@@ -0,0 +1,24 @@
+//go:build !linux
+
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"reflow/internal/util"
+)
+
+// sandboxedCommand runs the plugin unrestricted: the sandbox relies on Linux namespaces.
+func sandboxedCommand(pluginName, executablePath string, args, env []string) (*exec.Cmd, func(), error) {
+	util.Log.Warnf("pluginSandbox is enabled, but plugin sandboxing is only supported on Linux. Running plugin '%s' without a sandbox.", pluginName)
+	execCmd := exec.Command(executablePath, args...)
+	execCmd.Env = env
+	return execCmd, func() {}, nil
+}
+
+// RunSandboxHelper is only reached on Linux, where sandboxedCommand starts it.
+func RunSandboxHelper(args []string) {
+	fmt.Fprintln(os.Stderr, "reflow: plugin sandboxing is only supported on Linux")
+	os.Exit(1)
+}
@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InstallOptions controls how InstallPlugin installs a plugin.
+type InstallOptions struct {
+	// WithDeps installs missing dependencies first, from the repositories they declare.
+	WithDeps bool
+
+	// Values pre-fills answers to the plugin's setup prompts; prompts they cover are not asked.
+	// They apply to the named plugin only, not to dependencies installed with WithDeps.
+	Values map[string]string
+
+	// NonInteractive never reads answers from Input. Prompts without a value fall back to their
+	// default, and installation fails if a required prompt has neither.
+	NonInteractive bool
+
+	// Input is read for interactive answers. Defaults to os.Stdin.
+	Input io.Reader
+}
+
+// LoadSetupValuesFile reads pre-filled setup answers from a YAML file mapping prompt keys to values.
+func LoadSetupValuesFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values file %s: %w", path, err)
+	}
+	values := make(map[string]string)
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse values file %s (expected 'key: value' pairs): %w", path, err)
+	}
+	return values, nil
+}
+
+// SetupValues merges pre-filled setup answers from a values file (if valuesFile is set) and from
+// "key=value" entries, which take precedence over the file.
+func SetupValues(valuesFile string, setValues []string) (map[string]string, error) {
+	values := make(map[string]string)
+	if valuesFile != "" {
+		var err error
+		if values, err = LoadSetupValuesFile(valuesFile); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range setValues {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --set value '%s': expected key=value", entry)
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, nil
+}
+
+// collectSetupValues answers a plugin's setup prompts from opts.Values, then interactively or,
+// with opts.NonInteractive set, from each prompt's default. defaultFor returns a prompt's
+// default value. Both paths fail if a required prompt ends up without a value.
+func collectSetupValues(prompts []config.PluginSetupPrompt, defaultFor func(config.PluginSetupPrompt) string, opts InstallOptions) (map[string]string, error) {
+	configValues := make(map[string]string)
+	promptKeys := make(map[string]bool, len(prompts))
+	var reader *bufio.Reader
+	var missing []string
+
+	for _, prompt := range prompts {
+		promptKeys[prompt.Key] = true
+		defaultValue := defaultFor(prompt)
+
+		value, provided := opts.Values[prompt.Key]
+		switch {
+		case provided:
+			util.Log.Debugf("Using provided value for setup prompt '%s'", prompt.Key)
+		case opts.NonInteractive:
+			value = defaultValue
+		default:
+			if reader == nil {
+				input := opts.Input
+				if input == nil {
+					input = os.Stdin
+				}
+				reader = bufio.NewReader(input)
+			}
+			value = askSetupPrompt(reader, prompt, defaultValue)
+		}
+
+		value = strings.TrimSpace(value)
+		if value == "" {
+			value = defaultValue
+		}
+		if value == "" && prompt.Required {
+			missing = append(missing, prompt.Key)
+			continue
+		}
+		configValues[prompt.Key] = value
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("required configuration value(s) not provided: %s (use --set key=value or --values-file)", strings.Join(missing, ", "))
+	}
+
+	// Values for keys without a prompt are kept, e.g. 'containerPort' read by the Nginx setup.
+	var extraKeys []string
+	for key := range opts.Values {
+		if !promptKeys[key] {
+			extraKeys = append(extraKeys, key)
+			configValues[key] = opts.Values[key]
+		}
+	}
+	if len(extraKeys) > 0 {
+		sort.Strings(extraKeys)
+		util.Log.Warnf("Provided value(s) %s do not match a setup prompt of this plugin; saving them anyway.", strings.Join(extraKeys, ", "))
+	}
+	return configValues, nil
+}
+
+// askSetupPrompt asks for one setup value, asking once more if a required prompt without a
+// default gets an empty answer.
+func askSetupPrompt(reader *bufio.Reader, prompt config.PluginSetupPrompt, defaultValue string) string {
+	fmt.Printf("  - %s", prompt.Prompt)
+	if defaultValue != "" {
+		fmt.Printf(" [%s]", defaultValue)
+	}
+	if prompt.Description != "" {
+		fmt.Printf("\n    (%s)", prompt.Description)
+	}
+	fmt.Print(": ")
+
+	input, _ := reader.ReadString('\n')
+	value := strings.TrimSpace(input)
+
+	if value == "" && defaultValue == "" && prompt.Required {
+		fmt.Println("  This field is required.")
+		fmt.Printf("  - %s: ", prompt.Prompt)
+		input, _ = reader.ReadString('\n')
+		value = strings.TrimSpace(input)
+	}
+	return value
+}
@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"testing"
+)
+
+func TestCollectSetupValuesNonInteractive(t *testing.T) {
+	prompts := []config.PluginSetupPrompt{
+		{Key: "domain", Prompt: "Domain", Required: true},
+		{Key: "port", Prompt: "Port", Default: "8080"},
+	}
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("domain: file.example.com\nport: \"9000\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		valuesFile string
+		setValues  []string
+		want       map[string]string // nil if an error is expected
+	}{
+		{
+			name: "missing required value",
+		},
+		{
+			name:      "blank required value",
+			setValues: []string{"domain=  "},
+		},
+		{
+			name:      "default used when no value is given",
+			setValues: []string{"domain=app.example.com"},
+			want:      map[string]string{"domain": "app.example.com", "port": "8080"},
+		},
+		{
+			name:       "values file",
+			valuesFile: valuesFile,
+			want:       map[string]string{"domain": "file.example.com", "port": "9000"},
+		},
+		{
+			name:       "set overrides values file",
+			valuesFile: valuesFile,
+			setValues:  []string{"port=9100"},
+			want:       map[string]string{"domain": "file.example.com", "port": "9100"},
+		},
+		{
+			name:      "value without a prompt is kept",
+			setValues: []string{"domain=app.example.com", "containerPort=3000"},
+			want:      map[string]string{"domain": "app.example.com", "port": "8080", "containerPort": "3000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := SetupValues(tt.valuesFile, tt.setValues)
+			if err != nil {
+				t.Fatalf("SetupValues: %v", err)
+			}
+			opts := InstallOptions{Values: values, NonInteractive: true}
+			got, err := collectSetupValues(prompts, func(p config.PluginSetupPrompt) string { return p.Default }, opts)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("collectSetupValues = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("collectSetupValues: %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("collectSetupValues = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetupValuesInvalidEntry(t *testing.T) {
+	for _, entry := range []string{"domain", "=value", " =value"} {
+		if _, err := SetupValues("", []string{entry}); err == nil {
+			t.Errorf("SetupValues(%q) succeeded, want an error", entry)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/docker"
+	"reflow/internal/util"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sensitiveConfigKeyParts marks config values that are masked in status output.
+var sensitiveConfigKeyParts = []string{"token", "secret", "password"}
+
+// Status holds the live state of an installed plugin for 'plugin status' and the plugin status endpoint.
+type Status struct {
+	Name            string
+	DisplayName     string
+	Version         string
+	Type            config.PluginType
+	Enabled         bool
+	ContainerName   string
+	ContainerID     string
+	ContainerState  string // Docker state, e.g. "running" or "exited"; empty for CLI plugins or a missing container
+	ContainerHealth string // Health check status, if the image defines one
+	ContainerStatus string
+	StartedAt       *time.Time // When the running container was started
+	Uptime          string     // Time since StartedAt, e.g. "3h2m10s"
+	CPUPercent      float64    // CPU usage of the running container
+	MemoryMB        float64    // Memory usage of the running container
+	InstallPath     string
+	Commands        []string   // Commands a CLI plugin registers, sorted
+	NginxConfigOk   bool       // Whether reflow recorded a successful Nginx setup
+	NginxConfigPath string     // Expected plugin.<name>.conf path
+	NginxConfigFile bool       // Whether that file currently exists
+	NginxConfigTime *time.Time // Modification time of the file, if it exists
+	EffectiveDomain string
+	AccessURL       string
+	ConfigValues    map[string]string // Values of keys containing token/secret/password are masked
+}
+
+// GetPluginStatus reports an installed plugin's state, including a running container's uptime
+// and resource usage. Problems with the plugin's container or Nginx config are reported in the
+// returned status rather than as errors, so a plugin whose container was removed outside reflow
+// still shows up, degraded.
+func GetPluginStatus(ctx context.Context, reflowBasePath, pluginName string) (*Status, error) {
+	globalState, err := config.LoadGlobalPluginState(reflowBasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load global plugin state: %w", err)
+	}
+	pluginConf, exists := globalState.InstalledPlugins[pluginName]
+	if !exists {
+		return nil, fmt.Errorf("plugin '%s' not found", pluginName)
+	}
+
+	status := &Status{
+		Name:          pluginConf.PluginName,
+		DisplayName:   pluginConf.DisplayName,
+		Version:       pluginConf.Version,
+		Type:          pluginConf.Type,
+		Enabled:       pluginConf.Enabled,
+		InstallPath:   pluginConf.InstallPath,
+		NginxConfigOk: pluginConf.NginxConfigOk,
+	}
+
+	// --- 1. Config Values ---
+	// Prefer the config file, which 'plugin config set' may have changed since the state was saved.
+	configValues := pluginConf.ConfigValues
+	if pluginConf.ConfigPath != "" {
+		if loaded, loadErr := config.LoadPluginInstanceConfig(pluginConf.ConfigPath); loadErr == nil {
+			configValues = loaded
+		}
+	}
+	pluginConf.ConfigValues = configValues
+	status.ConfigValues = maskSensitiveConfigValues(configValues)
+
+	if pluginConf.Type != config.PluginTypeContainer {
+		// A CLI plugin's commands are only known from its metadata; a broken metadata file
+		// just leaves them out.
+		metadata, parseErr := ParsePluginMetadata(filepath.Join(pluginConf.InstallPath, config.PluginMetadataFileName))
+		if parseErr == nil && metadata.Commands != nil {
+			for cmdName := range metadata.Commands.Definitions {
+				status.Commands = append(status.Commands, cmdName)
+			}
+			sort.Strings(status.Commands)
+		}
+		return status, nil
+	}
+
+	// --- 2. Container ---
+	status.ContainerName = fmt.Sprintf("reflow-plugin-%s", pluginName)
+	containerID, _, findErr := findPluginContainer(ctx, pluginConf)
+	switch {
+	case findErr != nil:
+		status.ContainerStatus = fmt.Sprintf("Error querying Docker: %v", findErr)
+	case containerID == "":
+		if pluginConf.Enabled {
+			status.ContainerStatus = "Not Found (Expected based on state!)"
+		} else {
+			status.ContainerStatus = "Not Running (plugin disabled)"
+		}
+	default:
+		inspect, inspectErr := docker.InspectContainer(ctx, containerID)
+		if inspectErr != nil {
+			status.ContainerStatus = fmt.Sprintf("Error inspecting container: %v", inspectErr)
+			break
+		}
+		status.ContainerID = inspect.ID[:12]
+		if inspect.State != nil {
+			status.ContainerState = inspect.State.Status
+			status.ContainerStatus = inspect.State.Status
+			if inspect.State.Health != nil {
+				status.ContainerHealth = inspect.State.Health.Status
+				status.ContainerStatus = fmt.Sprintf("%s (%s)", inspect.State.Status, inspect.State.Health.Status)
+			}
+			if inspect.State.Status == "exited" {
+				status.ContainerStatus = fmt.Sprintf("exited (code %d)", inspect.State.ExitCode)
+			}
+			if inspect.State.Running {
+				if startedAt, parseErr := time.Parse(time.RFC3339Nano, inspect.State.StartedAt); parseErr == nil {
+					status.StartedAt = &startedAt
+					status.Uptime = time.Since(startedAt).Round(time.Second).String()
+				}
+				if stats, statsErr := docker.GetContainerStats(ctx, containerID); statsErr == nil {
+					status.CPUPercent = stats.CPUPercent
+					status.MemoryMB = stats.MemoryUsageMB
+				} else {
+					util.Log.Debugf("Could not get stats for plugin '%s' container: %v", pluginName, statsErr)
+				}
+			}
+		}
+	}
+
+	// --- 3. Nginx Config ---
+	confFileName := fmt.Sprintf("plugin.%s.conf", pluginName)
+	status.NginxConfigPath = filepath.Join(reflowBasePath, config.NginxDirName, config.NginxConfDirName, confFileName)
+	if info, statErr := os.Stat(status.NginxConfigPath); statErr == nil {
+		modTime := info.ModTime()
+		status.NginxConfigFile = true
+		status.NginxConfigTime = &modTime
+	}
+
+	// --- 4. Domain and Access URL ---
+	// A plugin without an Nginx setup may legitimately have no domain, so the error is only
+	// reported when reflow configured Nginx for it.
+	domain, domainErr := GetEffectivePluginDomainFromConfig(reflowBasePath, pluginConf)
+	switch {
+	case domainErr == nil:
+		status.EffectiveDomain = domain
+		if pluginConf.Enabled && pluginConf.NginxConfigOk && status.NginxConfigFile {
+			status.AccessURL = "http://" + domain
+		}
+	case pluginConf.NginxConfigOk:
+		status.EffectiveDomain = fmt.Sprintf("Error: %v", domainErr)
+	}
+
+	return status, nil
+}
+
+// maskSensitiveConfigValues returns a copy of values with sensitive entries masked.
+func maskSensitiveConfigValues(values map[string]string) map[string]string {
+	masked := make(map[string]string, len(values))
+	for key, value := range values {
+		lowerKey := strings.ToLower(key)
+		for _, part := range sensitiveConfigKeyParts {
+			if strings.Contains(lowerKey, part) {
+				value = config.MaskSecretValue(value)
+				break
+			}
+		}
+		masked[key] = value
+	}
+	return masked
+}
@@ -0,0 +1,217 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/util"
+	"time"
+)
+
+// Names of the entries in a project backup archive.
+const (
+	backupManifestName = "backup.json"
+	backupEnvDirName   = "env" // Holds <env>.env, the env file of each environment
+)
+
+// MaxBackupEntrySize bounds how much of a single archive entry a restore reads.
+const MaxBackupEntrySize = 64 << 20
+
+// BackupManifest describes a backed up project. The repository itself is not archived; it is
+// cloned again (or copied from its local source directory) on restore.
+type BackupManifest struct {
+	ProjectName string            `json:"projectName"`
+	Source      string            `json:"source"` // Repository URL, or "local:<path>"
+	TestCommit  string            `json:"testCommit,omitempty"`
+	ProdCommit  string            `json:"prodCommit,omitempty"`
+	EnvFiles    map[string]string `json:"envFiles,omitempty"` // Environment -> envFile path in the repository, for archived env files
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+// BackupProject writes a tar.gz archive of a project's config, state, deployment log and env
+// files to outputPath, with a manifest recording where the project is deployed from and its
+// active commits. The repository, secrets, git credential and deploy key are not included.
+func BackupProject(reflowBasePath, projectName, outputPath string) error {
+	manifest, entries, err := CollectProjectBackup(reflowBasePath, projectName, true)
+	if err != nil {
+		return err
+	}
+	manifestContent, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+	entries = append([]util.ArchiveEntry{{Name: backupManifestName, Content: manifestContent}}, entries...)
+
+	if err := util.WriteTarGz(outputPath, entries, manifest.CreatedAt); err != nil {
+		return err
+	}
+	util.Log.Infof("✅ Project '%s' backed up to %s.", projectName, outputPath)
+	return nil
+}
+
+// CollectProjectBackup reads the files of a project that a backup archives: config.yaml,
+// state.json, deployments.log if there is one and, with withEnvFiles, the env file of each
+// environment as env/<env>.env. Entry names are relative to the project.
+func CollectProjectBackup(reflowBasePath, projectName string, withEnvFiles bool) (BackupManifest, []util.ArchiveEntry, error) {
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		return BackupManifest{}, nil, fmt.Errorf("failed to load project config for '%s': %w", projectName, err)
+	}
+	projState, err := config.LoadProjectState(reflowBasePath, projectName)
+	if err != nil {
+		return BackupManifest{}, nil, fmt.Errorf("failed to load project state for '%s': %w", projectName, err)
+	}
+	projectBasePath := config.GetProjectBasePath(reflowBasePath, projectName)
+
+	manifest := BackupManifest{
+		ProjectName: projectName,
+		Source:      sourceLocation(projCfg),
+		TestCommit:  projState.Test.ActiveCommit,
+		ProdCommit:  projState.Prod.ActiveCommit,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	var entries []util.ArchiveEntry
+	for _, name := range []string{config.ProjectConfigFileName, config.ProjectStateFileName, config.DeploymentsLogFileName} {
+		content, err := os.ReadFile(filepath.Join(projectBasePath, name))
+		if err != nil {
+			if os.IsNotExist(err) && name == config.DeploymentsLogFileName {
+				continue
+			}
+			return BackupManifest{}, nil, fmt.Errorf("failed to read %s of project '%s': %w", name, projectName, err)
+		}
+		entries = append(entries, util.ArchiveEntry{Name: name, Content: content})
+	}
+	if !withEnvFiles {
+		return manifest, entries, nil
+	}
+
+	for _, env := range []string{"test", "prod"} {
+		if projCfg.Environments[env].EnvFile == "" {
+			continue
+		}
+		envFilePath, err := config.ProjectEnvFilePath(reflowBasePath, projCfg, env)
+		if err != nil {
+			return BackupManifest{}, nil, err
+		}
+		content, err := os.ReadFile(envFilePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				util.Log.Warnf("Env file %s of environment '%s' does not exist, not backing it up.", envFilePath, env)
+				continue
+			}
+			return BackupManifest{}, nil, fmt.Errorf("failed to read env file %s: %w", envFilePath, err)
+		}
+		entries = append(entries, util.ArchiveEntry{Name: path.Join(backupEnvDirName, env+".env"), Content: content})
+		if manifest.EnvFiles == nil {
+			manifest.EnvFiles = make(map[string]string)
+		}
+		manifest.EnvFiles[env] = projCfg.Environments[env].EnvFile
+	}
+	return manifest, entries, nil
+}
+
+// RestoreProject recreates a project from an archive written by BackupProject. See
+// RestoreProjectFiles.
+func RestoreProject(reflowBasePath, archivePath string) (*BackupManifest, error) {
+	files, err := util.ReadTarGz(archivePath, MaxBackupEntrySize)
+	if err != nil {
+		return nil, err
+	}
+	manifestContent, ok := files[backupManifestName]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a reflow project backup: %s is missing", archivePath, backupManifestName)
+	}
+	manifest := &BackupManifest{}
+	if err := json.Unmarshal(manifestContent, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse backup manifest: %w", err)
+	}
+	if manifest.ProjectName == "" {
+		return nil, fmt.Errorf("%s is not a reflow project backup (full backups are restored with 'reflow restore')", archivePath)
+	}
+	util.Log.Infof("Restoring project '%s' from %s (backed up %s)...", manifest.ProjectName, archivePath, manifest.CreatedAt.Local().Format(time.RFC1123))
+
+	if err := RestoreProjectFiles(reflowBasePath, manifest, files); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// RestoreProjectFiles recreates the project described by manifest from its backed up files, named
+// as by CollectProjectBackup. The config, state and deployment log are restored as archived, the
+// repository is cloned again (or copied from the local source directory) and the archived env
+// files are written into it. The project must not exist. Containers are not started; the
+// restored state still names the archived active commits.
+func RestoreProjectFiles(reflowBasePath string, manifest *BackupManifest, files map[string][]byte) (err error) {
+	// --- 1. Validate ---
+	projectName := manifest.ProjectName
+	for _, name := range []string{config.ProjectConfigFileName, config.ProjectStateFileName} {
+		if _, ok := files[name]; !ok {
+			return fmt.Errorf("backup of project '%s' is missing %s", projectName, name)
+		}
+	}
+	if err := ValidateProjectName(projectName); err != nil {
+		return err
+	}
+	if err := CheckProjectNameAvailable(reflowBasePath, projectName); err != nil {
+		return err
+	}
+	projectBasePath := config.GetProjectBasePath(reflowBasePath, projectName)
+	if _, err := os.Stat(projectBasePath); err == nil {
+		return fmt.Errorf("project '%s' already exists at %s", projectName, projectBasePath)
+	}
+
+	// --- 2. Restore Project Files ---
+	if err := os.MkdirAll(projectBasePath, 0755); err != nil {
+		return fmt.Errorf("failed to create project directory %s: %w", projectBasePath, err)
+	}
+	defer func() {
+		if err != nil {
+			util.Log.Warnf("Cleaning up project directory %s due to restore failure.", projectBasePath)
+			_ = os.RemoveAll(projectBasePath)
+		}
+	}()
+	for _, name := range []string{config.ProjectConfigFileName, config.ProjectStateFileName, config.DeploymentsLogFileName} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		if err = os.WriteFile(filepath.Join(projectBasePath, name), content, 0644); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+	}
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		return fmt.Errorf("restored project config is invalid: %w", err)
+	}
+
+	// --- 3. Clone Repository or Copy Local Source ---
+	if err = populateRepo(reflowBasePath, projCfg); err != nil {
+		return err
+	}
+
+	// --- 4. Restore Env Files ---
+	for env := range manifest.EnvFiles {
+		content, ok := files[path.Join(backupEnvDirName, env+".env")]
+		if !ok {
+			continue
+		}
+		envFilePath, pathErr := config.ProjectEnvFilePath(reflowBasePath, projCfg, env)
+		if pathErr != nil {
+			err = pathErr
+			return err
+		}
+		if err = os.MkdirAll(filepath.Dir(envFilePath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for env file %s: %w", envFilePath, err)
+		}
+		if err = os.WriteFile(envFilePath, content, 0644); err != nil {
+			return fmt.Errorf("failed to restore env file %s: %w", envFilePath, err)
+		}
+	}
+
+	util.Log.Infof("✅ Project '%s' restored.", projectName)
+	return nil
+}
@@ -0,0 +1,164 @@
+package project
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/git"
+	"reflow/internal/util"
+)
+
+// DuplicateProject creates project newName from the config of sourceName, with a fresh clone of
+// the same repository (or a fresh copy of its local source directory) and empty state. The
+// source's own git credential, deploy key and secrets are copied so the duplicate can fetch and
+// run like the source. Domains are not copied, since Nginx serves each domain for one project
+// only: 'test' gets testDomain (or the default domain if empty) and 'prod' the default domain.
+// A non-empty branch becomes the duplicate's deploy branch for push webhooks.
+func DuplicateProject(reflowBasePath, sourceName, newName, testDomain, branch string) (err error) {
+	// --- 1. Validate ---
+	if err := ValidateProjectName(newName); err != nil {
+		return err
+	}
+	if err := CheckProjectNameAvailable(reflowBasePath, newName); err != nil {
+		return err
+	}
+	srcCfg, err := config.LoadProjectConfig(reflowBasePath, sourceName)
+	if err != nil {
+		return fmt.Errorf("failed to load project config for '%s': %w", sourceName, err)
+	}
+	if branch != "" && srcCfg.SourceType == config.SourceTypeLocal {
+		return fmt.Errorf("project '%s' is deployed from a local directory, which has no branches", sourceName)
+	}
+	util.Log.Infof("Duplicating project '%s' as '%s'...", sourceName, newName)
+	for _, v := range srcCfg.Volumes {
+		// Named volumes are prefixed with the project name; host paths are not.
+		if config.IsHostPathVolume(v.Source) {
+			util.Log.Warnf("Host path volume '%s' is shared with project '%s'.", v.Source, sourceName)
+		}
+	}
+
+	// --- 2. Create Project Directory ---
+	srcProjectPath := config.GetProjectBasePath(reflowBasePath, sourceName)
+	projectBasePath := config.GetProjectBasePath(reflowBasePath, newName)
+	if _, err := os.Stat(projectBasePath); err == nil {
+		return fmt.Errorf("project '%s' already exists at %s", newName, projectBasePath)
+	}
+	if err := os.MkdirAll(projectBasePath, 0755); err != nil {
+		return fmt.Errorf("failed to create project directory %s: %w", projectBasePath, err)
+	}
+	defer func() {
+		if err != nil {
+			util.Log.Warnf("Cleaning up project directory %s due to duplication failure.", projectBasePath)
+			_ = os.RemoveAll(projectBasePath)
+		}
+	}()
+
+	// --- 3. Copy Credentials and Secrets ---
+	cred, err := config.LoadProjectGitCredential(reflowBasePath, sourceName)
+	if err != nil {
+		return fmt.Errorf("failed to load git credential of project '%s': %w", sourceName, err)
+	}
+	if cred != nil {
+		if err = config.SaveProjectGitCredential(reflowBasePath, newName, *cred); err != nil {
+			return fmt.Errorf("failed to store git credential for project '%s': %w", newName, err)
+		}
+	}
+	for _, dirName := range []string{config.SSHDirName, config.SecretsDirName} {
+		srcDir := filepath.Join(srcProjectPath, dirName)
+		if _, statErr := os.Stat(srcDir); os.IsNotExist(statErr) {
+			continue
+		}
+		if err = util.CopyDir(srcDir, filepath.Join(projectBasePath, dirName)); err != nil {
+			return fmt.Errorf("failed to copy %s of project '%s': %w", dirName, sourceName, err)
+		}
+	}
+
+	// --- 4. Create Project Config File ---
+	projCfg := duplicateProjectConfig(srcCfg, newName, testDomain, branch)
+	if err = config.SaveProjectConfig(reflowBasePath, &projCfg); err != nil {
+		return fmt.Errorf("failed to save project config for '%s': %w", newName, err)
+	}
+
+	// --- 5. Clone Repository or Copy Local Source ---
+	if err = populateRepo(reflowBasePath, &projCfg); err != nil {
+		return err
+	}
+
+	// --- 6. Create Initial State File ---
+	initialState := config.ProjectState{
+		Test: config.EnvironmentState{},
+		Prod: config.EnvironmentState{},
+	}
+	if err = config.SaveProjectState(reflowBasePath, newName, &initialState); err != nil {
+		return fmt.Errorf("failed to save initial project state for '%s': %w", newName, err)
+	}
+
+	util.Log.Infof("✅ Project '%s' created as a duplicate of '%s'.", newName, sourceName)
+	return nil
+}
+
+// populateRepo fills a new project's repository directory: a fresh clone of its repository, or a
+// copy of its local source directory.
+func populateRepo(reflowBasePath string, projCfg *config.ProjectConfig) error {
+	repoDestPath := filepath.Join(config.GetProjectBasePath(reflowBasePath, projCfg.ProjectName), config.RepoDirName)
+	if projCfg.SourceType == config.SourceTypeLocal {
+		util.Log.Infof("Copying '%s' into '%s'...", projCfg.LocalPath, repoDestPath)
+		if err := util.CopyDir(projCfg.LocalPath, repoDestPath); err != nil {
+			return fmt.Errorf("failed to copy local source for project '%s': %w", projCfg.ProjectName, err)
+		}
+		return nil
+	}
+
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		util.Log.Warnf("Could not load global config, using default git authentication: %v", err)
+		globalCfg = &config.GlobalConfig{}
+	}
+	gitAuth, err := git.AuthConfigFromGlobal(globalCfg).
+		WithProjectSSHKey(projCfg.ProjectName, config.ProjectSSHKeyPath(reflowBasePath, projCfg), projCfg.SSHKeyPassphrase).
+		WithHTTPCredentials(reflowBasePath, projCfg.ProjectName, projCfg.GithubRepo)
+	if err != nil {
+		return fmt.Errorf("failed to load git credentials: %w", err)
+	}
+	if err := git.CloneRepo(projCfg.GithubRepo, repoDestPath, projCfg.Clone.Depth, gitAuth); err != nil {
+		return fmt.Errorf("failed to clone repository for project '%s': %w", projCfg.ProjectName, err)
+	}
+	return nil
+}
+
+// duplicateProjectConfig returns a copy of srcCfg for project newName. Settings that must be
+// unique across projects, the domains and rate limit zone names, are left to their defaults.
+func duplicateProjectConfig(srcCfg *config.ProjectConfig, newName, testDomain, branch string) config.ProjectConfig {
+	projCfg := *srcCfg
+	projCfg.ProjectName = newName
+	projCfg.TestDomainOverride = ""
+	projCfg.ProdDomainOverride = ""
+	if branch != "" {
+		projCfg.DeployBranch = branch
+	}
+
+	projCfg.Environments = maps.Clone(srcCfg.Environments)
+	if projCfg.Environments == nil {
+		projCfg.Environments = make(map[string]config.ProjectEnvConfig)
+	}
+	for env, envCfg := range projCfg.Environments {
+		envCfg.Domain = ""
+		if envCfg.RateLimit != nil && envCfg.RateLimit.ZoneName != "" {
+			rateLimit := *envCfg.RateLimit
+			rateLimit.ZoneName = ""
+			envCfg.RateLimit = &rateLimit
+		}
+		projCfg.Environments[env] = envCfg
+	}
+	for _, env := range []string{"test", "prod"} {
+		if _, ok := projCfg.Environments[env]; !ok {
+			projCfg.Environments[env] = config.ProjectEnvConfig{}
+		}
+	}
+	testCfg := projCfg.Environments["test"]
+	testCfg.Domain = testDomain
+	projCfg.Environments["test"] = testCfg
+	return projCfg
+}
@@ -0,0 +1,46 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"reflow/internal/util"
+)
+
+// envFileHashLength is the number of hex digits kept of an env file's SHA-256 hash.
+const envFileHashLength = 12
+
+// EnvFileHash returns a short hash of an env file's content. Deployments record it in the
+// environment's state so 'status' can tell when the file has been edited since.
+func EnvFileHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:envFileHashLength]
+}
+
+// populateEnvFileStatus fills in the modification time and hash of an environment's env file as
+// it is in the working tree, and whether it differs from the content the active deployment
+// loaded. Deployments made before the hash was recorded are never reported as changed.
+func populateEnvFileStatus(reflowBasePath string, projCfg *config.ProjectConfig, envState config.EnvironmentState, details *EnvironmentDetails) {
+	envFile := projCfg.Environments[details.EnvironmentName].EnvFile
+	if envFile == "" {
+		return
+	}
+	envFilePath := filepath.Join(config.GetProjectBasePath(reflowBasePath, projCfg.ProjectName), config.RepoDirName, envFile)
+
+	content, err := os.ReadFile(envFilePath)
+	switch {
+	case err == nil:
+		details.EnvFileHash = EnvFileHash(content)
+		if info, statErr := os.Stat(envFilePath); statErr == nil {
+			details.EnvFileModTime = info.ModTime()
+		}
+	case !os.IsNotExist(err):
+		util.Log.Warnf("Could not read env file %s: %v", envFilePath, err)
+		return
+	}
+
+	details.DeployedEnvHash = envState.EnvFileHash
+	details.EnvFileChanged = details.IsActive && envState.EnvFileHash != "" && details.EnvFileHash != envState.EnvFileHash
+}
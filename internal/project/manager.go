@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/docker/docker/api/types/container"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"os"
@@ -12,16 +13,33 @@ import (
 	"reflow/internal/docker"
 	"reflow/internal/git"
 	"reflow/internal/util"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Summary ProjectSummary holds summarized information for the 'list' command.
 type Summary struct {
-	Name       string
-	RepoURL    string
-	TestStatus string // e.g., "Commit: abc1234" or "Not Deployed"
-	ProdStatus string // e.g., "Commit: def5678" or "Not Deployed"
+	Name          string
+	RepoURL       string
+	TestStatus    string // e.g., "Commit: abc1234" or "Not Deployed"
+	ProdStatus    string // e.g., "Commit: def5678" or "Not Deployed"
+	TestDomain    string // Effective domain; empty if it can't be determined
+	ProdDomain    string
+	TestReachable *bool // Set by CheckReachability for deployed environments; nil if not checked
+	ProdReachable *bool
 }
 
+// statsTimeout bounds each container stats call made by 'status'.
+const statsTimeout = 3 * time.Second
+
+// A container Docker has restarted at least crashLoopRestarts times, and that is restarting or
+// was last started within crashLoopWindow, is reported as crash-looping by 'status'.
+const (
+	crashLoopRestarts = 3
+	crashLoopWindow   = 10 * time.Minute
+)
+
 // EnvironmentDetails holds detailed status for one environment (test/prod).
 type EnvironmentDetails struct {
 	EnvironmentName string
@@ -30,10 +48,16 @@ type EnvironmentDetails struct {
 	ActiveSlot      string
 	EffectiveDomain string
 	EnvFilePath     string
+	EnvFileModTime  time.Time // Zero if the env file doesn't exist
+	EnvFileHash     string    // Short content hash of the env file (see EnvFileHash)
+	DeployedEnvHash string    // Env file hash recorded by the active deployment; empty if not recorded
+	EnvFileChanged  bool      // The env file differs from the one the active deployment loaded
 	AppPort         int
 	ContainerStatus string
 	ContainerID     string
 	ContainerNames  []string
+	CPUUsage        string // Summed across running replicas, or "n/a"
+	MemoryUsage     string // Summed usage / limit across running replicas, or "n/a"
 }
 
 // Details ProjectDetails holds comprehensive information for the 'status' command.
@@ -54,6 +78,12 @@ func ListProjects(reflowBasePath string) ([]Summary, error) {
 
 	var summaries []Summary
 
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		util.Log.Warnf("Could not load global config while listing projects: %v. Domains might be incomplete.", err)
+		globalCfg = &config.GlobalConfig{}
+	}
+
 	entries, err := os.ReadDir(appsPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -87,7 +117,13 @@ func ListProjects(reflowBasePath string) ([]Summary, error) {
 
 		summary := Summary{
 			Name:    projCfg.ProjectName,
-			RepoURL: projCfg.GithubRepo,
+			RepoURL: sourceLocation(projCfg),
+		}
+		if domain, err := config.GetEffectiveDomain(globalCfg, projCfg, "test"); err == nil {
+			summary.TestDomain = domain
+		}
+		if domain, err := config.GetEffectiveDomain(globalCfg, projCfg, "prod"); err == nil {
+			summary.ProdDomain = domain
 		}
 
 		if projState.Test.ActiveCommit != "" {
@@ -134,7 +170,7 @@ func GetProjectDetails(ctx context.Context, reflowBasePath, projectName string)
 
 	details := &Details{
 		Name:           projCfg.ProjectName,
-		RepoURL:        projCfg.GithubRepo,
+		RepoURL:        sourceLocation(projCfg),
 		ConfigFilePath: filepath.Join(projectBasePath, config.ProjectConfigFileName),
 		StateFilePath:  filepath.Join(projectBasePath, config.ProjectStateFileName),
 		LocalRepoPath:  filepath.Join(projectBasePath, config.RepoDirName),
@@ -145,6 +181,8 @@ func GetProjectDetails(ctx context.Context, reflowBasePath, projectName string)
 	// --- Populate Environment Details ---
 	populateEnvDetails(ctx, projCfg, projState.Test, &details.TestDetails, globalCfg)
 	populateEnvDetails(ctx, projCfg, projState.Prod, &details.ProdDetails, globalCfg)
+	populateEnvFileStatus(reflowBasePath, projCfg, projState.Test, &details.TestDetails)
+	populateEnvFileStatus(reflowBasePath, projCfg, projState.Prod, &details.ProdDetails)
 
 	return details, nil
 }
@@ -203,27 +241,166 @@ func populateEnvDetails(ctx context.Context, projCfg *config.ProjectConfig, envS
 	if len(foundContainers) == 0 {
 		details.ContainerStatus = "Not Found (Expected based on state!)"
 	} else if len(foundContainers) > 1 {
-		details.ContainerStatus = "Multiple Found (!)"
-		details.ContainerID = "Multiple"
+		runningCount := 0
+		var containerIDs []string
 		for _, c := range foundContainers {
+			if c.State == "running" {
+				runningCount++
+			}
+			containerIDs = append(containerIDs, c.ID[:12])
 			details.ContainerNames = append(details.ContainerNames, c.Names...)
 		}
-		util.Log.Warnf("Found multiple containers matching labels for %s/%s/%s: %v", projCfg.ProjectName, envName, envState.ActiveSlot, details.ContainerNames)
+		details.ContainerStatus = fmt.Sprintf("%d/%d replicas running", runningCount, len(foundContainers))
+		var problems []string
+		for _, c := range foundContainers {
+			if problem := containerProblem(ctx, c.ID); problem != "" {
+				problems = append(problems, fmt.Sprintf("%s: %s", c.ID[:12], problem))
+			}
+		}
+		if len(problems) > 0 {
+			details.ContainerStatus += " - " + strings.Join(problems, "; ")
+		}
+		details.ContainerID = strings.Join(containerIDs, ", ")
+		if replicas := config.EffectiveReplicas(projCfg, envName); len(foundContainers) != replicas {
+			util.Log.Warnf("Found %d containers for %s/%s/%s, but the environment is configured for %d replica(s): %v", len(foundContainers), projCfg.ProjectName, envName, envState.ActiveSlot, replicas, details.ContainerNames)
+		}
 	} else {
 		container := foundContainers[0]
 		details.ContainerStatus = docker.GetContainerStatusString(container)
+		if problem := containerProblem(ctx, container.ID); problem != "" {
+			details.ContainerStatus = problem
+		}
 		details.ContainerID = container.ID[:12]
 		details.ContainerNames = container.Names
 	}
+
+	details.CPUUsage, details.MemoryUsage = resourceUsage(ctx, foundContainers)
+}
+
+// resourceUsage sums CPU and memory usage over the running containers. Each stats call gets a
+// short timeout so a slow daemon can't stall 'status'; unavailable stats are reported as "n/a".
+func resourceUsage(ctx context.Context, containers []container.Summary) (cpu string, mem string) {
+	cpu, mem = "n/a", "n/a"
+
+	var running []string
+	for _, c := range containers {
+		if c.State == "running" {
+			running = append(running, c.ID)
+		}
+	}
+	if len(running) == 0 {
+		return cpu, mem
+	}
+
+	results := make([]*docker.ContainerStats, len(running))
+	var wg sync.WaitGroup
+	for i, id := range running {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			statsCtx, cancel := context.WithTimeout(ctx, statsTimeout)
+			defer cancel()
+			stats, err := docker.GetContainerStats(statsCtx, id)
+			if err != nil {
+				util.Log.Debugf("Stats unavailable for container %s: %v", id[:12], err)
+				return
+			}
+			results[i] = stats
+		}(i, id)
+	}
+	wg.Wait()
+
+	var cpuPercent, memUsage, memLimit float64
+	for _, stats := range results {
+		if stats == nil {
+			return cpu, mem
+		}
+		cpuPercent += stats.CPUPercent
+		memUsage += stats.MemoryUsageMB
+		memLimit += stats.MemoryLimitMB
+	}
+	return fmt.Sprintf("%.2f%%", cpuPercent), fmt.Sprintf("%.1fMiB / %.1fMiB", memUsage, memLimit)
+}
+
+// containerProblem inspects a container for problems its Docker status hides: a container in a
+// crash-restart loop shows as running between crashes. It returns "" if nothing is wrong or the
+// container can't be inspected.
+func containerProblem(ctx context.Context, containerID string) string {
+	inspectCtx, cancel := context.WithTimeout(ctx, statsTimeout)
+	defer cancel()
+	inspect, err := docker.InspectContainer(inspectCtx, containerID)
+	if err != nil || inspect.ContainerJSONBase == nil || inspect.State == nil {
+		util.Log.Debugf("Could not inspect container %s for crash detection: %v", containerID[:12], err)
+		return ""
+	}
+	state := inspect.State
+
+	if inspect.RestartCount >= crashLoopRestarts {
+		recentStart := false
+		if startedAt, err := time.Parse(time.RFC3339Nano, state.StartedAt); err == nil {
+			recentStart = time.Since(startedAt) < crashLoopWindow
+		}
+		if state.Restarting || recentStart {
+			// Docker resets the exit code when it starts the container again.
+			switch {
+			case state.OOMKilled:
+				return fmt.Sprintf("Crash-looping (restarted %d times, last killed out of memory)", inspect.RestartCount)
+			case !state.Running:
+				return fmt.Sprintf("Crash-looping (restarted %d times, last exit code %d)", inspect.RestartCount, state.ExitCode)
+			default:
+				return fmt.Sprintf("Crash-looping (restarted %d times)", inspect.RestartCount)
+			}
+		}
+	}
+	if !state.Running && state.OOMKilled {
+		return fmt.Sprintf("Exited (killed out of memory, exit code %d)", state.ExitCode)
+	}
+	return ""
+}
+
+// sourceLocation returns what a project is deployed from: its repository URL, or its source
+// directory for a local project.
+func sourceLocation(projCfg *config.ProjectConfig) string {
+	if projCfg.SourceType == config.SourceTypeLocal {
+		return "local:" + projCfg.LocalPath
+	}
+	return projCfg.GithubRepo
 }
 
 // CreateProject handles the core logic of creating a new project.
 func CreateProject(reflowBasePath string, args config.CreateProjectArgs) error {
-	if args.ProjectName == "" || args.RepoURL == "" {
-		return errors.New("project name and repository URL are required")
+	if args.SourceType == "" {
+		args.SourceType = config.SourceTypeGit
+	}
+	switch args.SourceType {
+	case config.SourceTypeGit:
+		if args.ProjectName == "" || args.RepoURL == "" {
+			return errors.New("project name and repository URL are required")
+		}
+	case config.SourceTypeLocal:
+		if args.ProjectName == "" || args.LocalPath == "" {
+			return errors.New("project name and local path are required")
+		}
+		absLocalPath, err := filepath.Abs(args.LocalPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve local path %s: %w", args.LocalPath, err)
+		}
+		args.LocalPath = absLocalPath
+	default:
+		return fmt.Errorf("invalid source type '%s': must be '%s' or '%s'", args.SourceType, config.SourceTypeGit, config.SourceTypeLocal)
+	}
+	if err := ValidateProjectName(args.ProjectName); err != nil {
+		return err
+	}
+	if err := CheckProjectNameAvailable(reflowBasePath, args.ProjectName); err != nil {
+		return err
 	}
 
-	util.Log.Infof("Creating new project '%s' from repo '%s'", args.ProjectName, args.RepoURL)
+	if args.SourceType == config.SourceTypeLocal {
+		util.Log.Infof("Creating new project '%s' from local directory '%s'", args.ProjectName, args.LocalPath)
+	} else {
+		util.Log.Infof("Creating new project '%s' from repo '%s'", args.ProjectName, util.RedactURL(args.RepoURL))
+	}
 
 	projectBasePath := config.GetProjectBasePath(reflowBasePath, args.ProjectName)
 	repoDestPath := filepath.Join(projectBasePath, config.RepoDirName)
@@ -249,48 +426,18 @@ func CreateProject(reflowBasePath string, args config.CreateProjectArgs) error {
 		}
 	}()
 
-	// --- 3. Clone Repository ---
-	if err := git.CloneRepo(args.RepoURL, repoDestPath); err != nil {
-		return fmt.Errorf("failed to clone repository for project '%s': %w", args.ProjectName, err)
+	// --- 3. Clone Repository or Copy Local Source ---
+	if args.SourceType == config.SourceTypeLocal {
+		util.Log.Infof("Copying '%s' into '%s'...", args.LocalPath, repoDestPath)
+		if err := util.CopyDir(args.LocalPath, repoDestPath); err != nil {
+			return fmt.Errorf("failed to copy local source for project '%s': %w", args.ProjectName, err)
+		}
+	} else if err := cloneProjectRepo(reflowBasePath, args, repoDestPath); err != nil {
+		return err
 	}
 
 	// --- 4. Create Project Config File ---
-	appPort := args.AppPort
-	if appPort <= 0 {
-		appPort = 3000
-	}
-	nodeVersion := args.NodeVersion
-	if nodeVersion == "" {
-		nodeVersion = "18-alpine"
-	}
-	testEnvFile := args.TestEnvFile
-	if testEnvFile == "" {
-		testEnvFile = ".env.development"
-	}
-	prodEnvFile := args.ProdEnvFile
-	if prodEnvFile == "" {
-		prodEnvFile = ".env.production"
-	}
-
-	projCfg := config.ProjectConfig{
-		ProjectName: args.ProjectName,
-		GithubRepo:  args.RepoURL,
-		AppPort:     appPort,
-		NodeVersion: nodeVersion,
-		Environments: map[string]config.ProjectEnvConfig{
-			"test": {
-				Domain:  args.TestDomain,
-				EnvFile: testEnvFile,
-			},
-			"prod": {
-				Domain:  args.ProdDomain,
-				EnvFile: prodEnvFile,
-			},
-		},
-		TestDomainOverride: args.TestDomain,
-		ProdDomainOverride: args.ProdDomain,
-	}
-
+	projCfg := NewProjectConfig(args)
 	if err := config.SaveProjectConfig(reflowBasePath, &projCfg); err != nil {
 		return fmt.Errorf("failed to save project config for '%s': %w", args.ProjectName, err)
 	}
@@ -317,6 +464,7 @@ func CreateProject(reflowBasePath string, args config.CreateProjectArgs) error {
 			globalCfg = &config.GlobalConfig{Debug: util.Log.GetLevel() == logrus.DebugLevel}
 		} else {
 			util.Log.Warnf("Could not load global config during project creation log: %v", gerr)
+			globalCfg = &config.GlobalConfig{}
 		}
 	}
 
@@ -331,7 +479,11 @@ func CreateProject(reflowBasePath string, args config.CreateProjectArgs) error {
 
 	util.Log.Info("-----------------------------------------------------")
 	util.Log.Infof("✅ Project '%s' created successfully!", args.ProjectName)
-	util.Log.Infof("   - Repo cloned to: %s", repoDestPath)
+	if args.SourceType == config.SourceTypeLocal {
+		util.Log.Infof("   - Source copied to: %s", repoDestPath)
+	} else {
+		util.Log.Infof("   - Repo cloned to: %s", repoDestPath)
+	}
 	util.Log.Infof("   - Config file: %s", configFilePath)
 	if errTest == nil {
 		util.Log.Infof("   - Test Env Domain: %s", testEffDomain)
@@ -345,3 +497,82 @@ func CreateProject(reflowBasePath string, args config.CreateProjectArgs) error {
 	success = true
 	return nil
 }
+
+// NewProjectConfig returns the config of a new project created from args, with defaults
+// applied for the settings args leaves empty.
+func NewProjectConfig(args config.CreateProjectArgs) config.ProjectConfig {
+	appPort := args.AppPort
+	if appPort <= 0 {
+		appPort = 3000
+	}
+	nodeVersion := args.NodeVersion
+	if nodeVersion == "" {
+		nodeVersion = "18-alpine"
+	}
+	testEnvFile := args.TestEnvFile
+	if testEnvFile == "" {
+		testEnvFile = ".env.development"
+	}
+	prodEnvFile := args.ProdEnvFile
+	if prodEnvFile == "" {
+		prodEnvFile = ".env.production"
+	}
+
+	return config.ProjectConfig{
+		ProjectName: args.ProjectName,
+		GithubRepo:  args.RepoURL,
+		SourceType:  args.SourceType,
+		LocalPath:   args.LocalPath,
+		Clone:       config.CloneConfig{Depth: cloneDepth(args)},
+		SSHKeyPath:  args.SSHKeyPath,
+		AppPort:     appPort,
+		NodeVersion: nodeVersion,
+		Replicas:    config.DefaultReplicas,
+		Environments: map[string]config.ProjectEnvConfig{
+			"test": {
+				Domain:  args.TestDomain,
+				EnvFile: testEnvFile,
+			},
+			"prod": {
+				Domain:  args.ProdDomain,
+				EnvFile: prodEnvFile,
+			},
+		},
+		TestDomainOverride: args.TestDomain,
+		ProdDomainOverride: args.ProdDomain,
+	}
+}
+
+// cloneDepth returns the clone depth for a new project: 1 with --shallow, else full history.
+func cloneDepth(args config.CreateProjectArgs) int {
+	if args.Shallow && args.SourceType != config.SourceTypeLocal {
+		return 1
+	}
+	return 0
+}
+
+// cloneProjectRepo clones a new git project's repository, storing its access token first if one
+// was given so the clone can use it.
+func cloneProjectRepo(reflowBasePath string, args config.CreateProjectArgs, repoDestPath string) error {
+	globalCfg, err := config.LoadGlobalConfig(reflowBasePath)
+	if err != nil {
+		util.Log.Warnf("Could not load global config, using default git authentication: %v", err)
+		globalCfg = &config.GlobalConfig{}
+	}
+	if args.GitToken != "" {
+		util.RegisterSecret(args.GitToken)
+		if err := config.SaveProjectGitCredential(reflowBasePath, args.ProjectName, config.GitCredential{Username: args.GitUsername, Token: args.GitToken}); err != nil {
+			return fmt.Errorf("failed to store git token for project '%s': %w", args.ProjectName, err)
+		}
+	}
+	gitAuth, err := git.AuthConfigFromGlobal(globalCfg).
+		WithProjectSSHKey(args.ProjectName, args.SSHKeyPath, "").
+		WithHTTPCredentials(reflowBasePath, args.ProjectName, args.RepoURL)
+	if err != nil {
+		return fmt.Errorf("failed to load git credentials: %w", err)
+	}
+	if err := git.CloneRepo(args.RepoURL, repoDestPath, cloneDepth(args), gitAuth); err != nil {
+		return fmt.Errorf("failed to clone repository for project '%s': %w", args.ProjectName, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflow/internal/config"
+	"strings"
+)
+
+// containerNameSuffixLength is the longest suffix appended to a project name when naming
+// containers: "-test-green-abc1234-99" (env, slot, short commit, replica index).
+const containerNameSuffixLength = len("-test-green-abc1234-99")
+
+// MaxProjectNameLength keeps generated container names within config.MaxContainerNameLength.
+const MaxProjectNameLength = config.MaxContainerNameLength - containerNameSuffixLength
+
+// SanitizeProjectName lowercases a name and strips characters outside a-z, 0-9, '-' and '_',
+// following the same rules used for plugin names.
+func SanitizeProjectName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			b.WriteRune(r)
+		} else if r == ' ' || r == '.' {
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), "-_")
+}
+
+// ValidateProjectName checks that a project name is safe to use in directory names, Docker
+// container/image names and Nginx upstream identifiers.
+func ValidateProjectName(name string) error {
+	if name == "" {
+		return fmt.Errorf("project name cannot be empty")
+	}
+
+	if sanitized := SanitizeProjectName(name); sanitized != name {
+		if sanitized == "" {
+			return fmt.Errorf("invalid project name '%s': only lowercase letters, digits, '-' and '_' are allowed, and it must start and end with a letter or digit", name)
+		}
+		return fmt.Errorf("invalid project name '%s': only lowercase letters, digits, '-' and '_' are allowed, and it must start and end with a letter or digit (try '%s')", name, sanitized)
+	}
+
+	if len(name) > MaxProjectNameLength {
+		return fmt.Errorf("project name '%s' is too long (%d characters): container names would exceed %d characters, use at most %d", name, len(name), config.MaxContainerNameLength, MaxProjectNameLength)
+	}
+	return nil
+}
+
+// CheckProjectNameAvailable rejects names that match an existing project case-insensitively,
+// since image names and container names are lowercased.
+func CheckProjectNameAvailable(reflowBasePath, name string) error {
+	appsPath := filepath.Join(reflowBasePath, config.AppsDirName)
+	entries, err := os.ReadDir(appsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read apps directory %s: %w", appsPath, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.EqualFold(entry.Name(), name) {
+			return fmt.Errorf("project '%s' already exists (conflicts with existing project '%s')", name, entry.Name())
+		}
+	}
+	return nil
+}
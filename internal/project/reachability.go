@@ -0,0 +1,51 @@
+package project
+
+import (
+	"context"
+	"reflow/internal/acme"
+	"reflow/internal/config"
+	"reflow/internal/nginx"
+	"sync"
+)
+
+// CheckReachability probes the domain of every deployed environment in summaries through the
+// Nginx container and sets TestReachable and ProdReachable. All probes run concurrently and each
+// is bounded by nginx.ProbeSite's timeout, so listing stays fast however many projects there are.
+func CheckReachability(ctx context.Context, reflowBasePath string, summaries []Summary) {
+	var wg sync.WaitGroup
+	probe := func(projectName, env, domain string, result **bool) {
+		if domain == "" {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reachable := nginx.ProbeSite(ctx, domain, serverTLS(reflowBasePath, projectName, env, domain))
+			*result = &reachable
+		}()
+	}
+
+	for i := range summaries {
+		s := &summaries[i]
+		if s.TestStatus != "Not Deployed" {
+			probe(s.Name, "test", s.TestDomain, &s.TestReachable)
+		}
+		if s.ProdStatus != "Not Deployed" {
+			probe(s.Name, "prod", s.ProdDomain, &s.ProdReachable)
+		}
+	}
+	wg.Wait()
+}
+
+// serverTLS reports whether Nginx serves an environment's domain over HTTPS, so the probe doesn't
+// stop at the HTTP redirect.
+func serverTLS(reflowBasePath, projectName, env, domain string) bool {
+	if env != "prod" {
+		return false
+	}
+	projCfg, err := config.LoadProjectConfig(reflowBasePath, projectName)
+	if err != nil {
+		return false
+	}
+	return projCfg.AutoTLS && acme.HasCertificate(reflowBasePath, domain)
+}
@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"reflow/internal/config"
+	"reflow/internal/orchestrator"
+	"reflow/internal/util"
+	"sync"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Scheduler runs cron-triggered test deployments and production promotions.
+type Scheduler struct {
+	basePath string
+	cron     *cron.Cron
+	ctx      context.Context
+
+	mu       sync.RWMutex
+	entries  []config.ScheduleEntry
+	entryIDs []cron.EntryID // Cron job of each entry, by index
+}
+
+// New creates a Scheduler for the given reflow base path. Call Start to begin running jobs.
+func New(basePath string) *Scheduler {
+	return &Scheduler{
+		basePath: basePath,
+		cron:     cron.New(),
+		ctx:      context.Background(),
+	}
+}
+
+// ValidateEntry checks that a schedule entry is well-formed and targets an existing project.
+func ValidateEntry(basePath string, entry config.ScheduleEntry) error {
+	if entry.ProjectName == "" {
+		return fmt.Errorf("projectName is required")
+	}
+	if entry.Env != "test" && entry.Env != "prod" {
+		return fmt.Errorf("invalid env '%s', must be 'test' or 'prod'", entry.Env)
+	}
+	if entry.Env == "prod" && entry.CommitIsh != "" {
+		return fmt.Errorf("commitIsh is not supported for 'prod' schedules; production promotes the current test deployment")
+	}
+	if _, err := cron.ParseStandard(entry.CronExpr); err != nil {
+		return fmt.Errorf("invalid cron expression '%s': %w", entry.CronExpr, err)
+	}
+	if _, err := config.LoadProjectConfig(basePath, entry.ProjectName); err != nil {
+		return fmt.Errorf("project '%s' not found or config invalid: %w", entry.ProjectName, err)
+	}
+	return nil
+}
+
+// LoadFromConfig registers all schedules defined in the global config.
+// Invalid entries are logged and skipped so one bad schedule does not block the others.
+func (s *Scheduler) LoadFromConfig() error {
+	globalCfg, err := config.LoadGlobalConfig(s.basePath)
+	if err != nil {
+		return fmt.Errorf("failed to load global config for schedules: %w", err)
+	}
+	for _, entry := range globalCfg.DeploySchedules {
+		if err := s.Add(entry); err != nil {
+			util.Log.Warnf("Skipping deploy schedule for project '%s' (%s): %v", entry.ProjectName, entry.Env, err)
+		}
+	}
+	return nil
+}
+
+// Reload replaces all registered schedules with the ones currently in the global config. Jobs
+// that are already running are not interrupted.
+func (s *Scheduler) Reload() error {
+	s.mu.Lock()
+	ids := s.entryIDs
+	s.entries, s.entryIDs = nil, nil
+	s.mu.Unlock()
+
+	for _, id := range ids {
+		s.cron.Remove(id)
+	}
+	return s.LoadFromConfig()
+}
+
+// Add validates and registers a schedule entry with the running scheduler.
+func (s *Scheduler) Add(entry config.ScheduleEntry) error {
+	if err := ValidateEntry(s.basePath, entry); err != nil {
+		return err
+	}
+
+	id, err := s.cron.AddFunc(entry.CronExpr, func() { s.run(entry) })
+	if err != nil {
+		return fmt.Errorf("failed to register schedule: %w", err)
+	}
+
+	s.mu.Lock()
+	s.entries = append(s.entries, entry)
+	s.entryIDs = append(s.entryIDs, id)
+	s.mu.Unlock()
+
+	util.Log.Infof("Registered deploy schedule: project '%s', env '%s', cron '%s'", entry.ProjectName, entry.Env, entry.CronExpr)
+	return nil
+}
+
+// Entries returns a copy of the registered schedule entries.
+func (s *Scheduler) Entries() []config.ScheduleEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := make([]config.ScheduleEntry, len(s.entries))
+	copy(entries, s.entries)
+	return entries
+}
+
+// Start begins running scheduled jobs. Jobs receive a context derived from ctx,
+// and the scheduler stops once ctx is cancelled, waiting for running jobs to finish.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.ctx = ctx
+	s.cron.Start()
+	util.Log.Infof("Deployment scheduler started with %d schedule(s).", len(s.Entries()))
+
+	go func() {
+		<-ctx.Done()
+		util.Log.Info("Stopping deployment scheduler...")
+		<-s.cron.Stop().Done()
+		util.Log.Info("Deployment scheduler stopped.")
+	}()
+}
+
+// run executes a single scheduled deployment or promotion.
+func (s *Scheduler) run(entry config.ScheduleEntry) {
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	util.Log.Infof("Scheduled %s triggered for project '%s' (cron '%s')", entry.Env, entry.ProjectName, entry.CronExpr)
+
+	// The deployment or approval records itself in the history, marked as triggered by the scheduler.
+	ctx := orchestrator.WithTrigger(s.ctx, "scheduler")
+	var err error
+	if entry.Env == "test" {
+		_, err = orchestrator.DeployTest(ctx, s.basePath, entry.ProjectName, entry.CommitIsh)
+	} else {
+		err = orchestrator.ApproveProd(ctx, s.basePath, entry.ProjectName, "")
+	}
+	if err != nil {
+		util.Log.Errorf("Scheduled %s for project '%s' failed: %v", entry.Env, entry.ProjectName, err)
+	} else {
+		util.Log.Infof("Scheduled %s for project '%s' completed successfully.", entry.Env, entry.ProjectName)
+	}
+}
@@ -0,0 +1,120 @@
+package util
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// ArchiveEntry is a regular file in a tar.gz archive written by WriteTarGz.
+type ArchiveEntry struct {
+	Name    string // Slash-separated path inside the archive
+	Content []byte
+}
+
+// WriteTarGz writes entries, in order, to a new tar.gz archive at archivePath, readable only by its
+// owner since archives may hold configuration with credentials. An existing file is never
+// overwritten, and a partially written archive is removed.
+func WriteTarGz(archivePath string, entries []ArchiveEntry, modTime time.Time) (err error) {
+	file, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("output file %s already exists", archivePath)
+		}
+		return fmt.Errorf("failed to create archive %s: %w", archivePath, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to write archive %s: %w", archivePath, closeErr)
+		}
+		if err != nil {
+			_ = os.Remove(archivePath)
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.Name, Mode: 0600, Size: int64(len(entry.Content)), ModTime: modTime, Typeflag: tar.TypeReg}
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s to archive: %w", entry.Name, err)
+		}
+		if _, err = tarWriter.Write(entry.Content); err != nil {
+			return fmt.Errorf("failed to write %s to archive: %w", entry.Name, err)
+		}
+	}
+	if err = tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write archive %s: %w", archivePath, err)
+	}
+	if err = gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write archive %s: %w", archivePath, err)
+	}
+	return nil
+}
+
+// ReadTarGz reads the regular files of a tar.gz archive into memory, keyed by their cleaned path
+// inside the archive. Archives with absolute paths or paths containing ".." are rejected, so
+// callers can join names onto a directory, and so are entries larger than maxEntrySize.
+func ReadTarGz(archivePath string, maxEntrySize int64) (map[string][]byte, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+	}
+	defer gzipReader.Close()
+
+	entries := make(map[string][]byte)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, err := cleanArchiveName(header.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", archivePath, err)
+		}
+		if header.Size > maxEntrySize {
+			return nil, fmt.Errorf("archive entry %s is too large (%d bytes)", name, header.Size)
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", name, err)
+		}
+		entries[name] = content
+	}
+	return entries, nil
+}
+
+// cleanArchiveName returns the cleaned form of an archive entry name, rejecting names that are
+// absolute or contain a ".." element and so could point outside the directory they are
+// extracted to.
+func cleanArchiveName(name string) (string, error) {
+	slashName := strings.ReplaceAll(name, "\\", "/")
+	if name == "" || path.IsAbs(slashName) || (len(slashName) > 1 && slashName[1] == ':') {
+		return "", fmt.Errorf("entry name '%s' is not a relative path", name)
+	}
+	for _, element := range strings.Split(slashName, "/") {
+		if element == ".." {
+			return "", fmt.Errorf("entry name '%s' contains '..'", name)
+		}
+	}
+	return path.Clean(slashName), nil
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeRawTarGz writes an archive with the given entry names, bypassing WriteTarGz so names
+// it would never produce can be tested.
+func writeRawTarGz(t *testing.T, names ...string) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "test.tar.gz")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range names {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: 1, Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func TestReadTarGzRoundTrip(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "backup.tar.gz")
+	entries := []ArchiveEntry{{Name: "config.yaml", Content: []byte("a: 1\n")}, {Name: "nginx/conf.d/app.test.conf", Content: []byte("server {}\n")}}
+	if err := WriteTarGz(archivePath, entries, time.Now()); err != nil {
+		t.Fatalf("WriteTarGz: %v", err)
+	}
+	if err := WriteTarGz(archivePath, entries, time.Now()); err == nil {
+		t.Fatal("WriteTarGz overwrote an existing file")
+	}
+
+	files, err := ReadTarGz(archivePath, 1<<20)
+	if err != nil {
+		t.Fatalf("ReadTarGz: %v", err)
+	}
+	for _, entry := range entries {
+		if got := string(files[entry.Name]); got != string(entry.Content) {
+			t.Errorf("%s = %q, want %q", entry.Name, got, entry.Content)
+		}
+	}
+}
+
+func TestReadTarGzRejectsUnsafeNames(t *testing.T) {
+	for _, name := range []string{
+		"nginx/conf.d/../../../../etc/cron.d/x",
+		"../outside",
+		"/etc/passwd",
+		`..\outside`,
+		`C:\windows\x`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ReadTarGz(writeRawTarGz(t, "config.yaml", name), 1<<20)
+			if err == nil {
+				t.Fatalf("ReadTarGz accepted entry %q", name)
+			}
+		})
+	}
+}
+
+func TestReadTarGzCleansNames(t *testing.T) {
+	files, err := ReadTarGz(writeRawTarGz(t, "./nginx//conf.d/./a..b.conf"), 1<<20)
+	if err != nil {
+		t.Fatalf("ReadTarGz: %v", err)
+	}
+	if _, ok := files["nginx/conf.d/a..b.conf"]; !ok {
+		t.Errorf("cleaned name missing, got %v", keys(files))
+	}
+}
+
+func keys(m map[string][]byte) string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
@@ -32,7 +33,14 @@ func LoadEnvFile(filePath string) ([]string, error) {
 		}
 	}(file)
 
-	scanner := bufio.NewScanner(file)
+	return ParseEnvVars(file, filePath)
+}
+
+// ParseEnvVars reads KEY=VALUE lines in env file format from r, skipping blank lines and '#'
+// comments. source names the input in log and error messages.
+func ParseEnvVars(r io.Reader, source string) ([]string, error) {
+	var vars []string
+	scanner := bufio.NewScanner(r)
 	lineNumber := 0
 	for scanner.Scan() {
 		lineNumber++
@@ -41,15 +49,142 @@ func LoadEnvFile(filePath string) ([]string, error) {
 			continue
 		}
 		if !strings.Contains(line, "=") {
-			Log.Warnf("Skipping invalid line %d in env file %s: Missing '='", lineNumber, filePath)
+			Log.Warnf("Skipping invalid line %d in env file %s: Missing '='", lineNumber, source)
 			continue
 		}
 		vars = append(vars, line)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading env file %s: %w", filePath, err)
+		return nil, fmt.Errorf("error reading env file %s: %w", source, err)
 	}
-	Log.Debugf("Loaded %d variables from %s", len(vars), filePath)
+	Log.Debugf("Loaded %d variables from %s", len(vars), source)
 	return vars, nil
 }
+
+// EnvLineError describes a line of env file content that ValidateEnvContent rejects.
+type EnvLineError struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// ValidateEnvContent checks env file content line by line with the rules ParseEnvVars applies:
+// blank lines and '#' comments are allowed, any other line must be KEY=VALUE. Lines that
+// ParseEnvVars would skip or misread are reported: a missing '=' or variable name, whitespace in
+// the name, a byte order mark, or a carriage return other than a CRLF line ending. It returns the
+// number of variables assigned and the rejected lines.
+func ValidateEnvContent(content []byte) (int, []EnvLineError) {
+	count := 0
+	var problems []EnvLineError
+	for i, line := range strings.Split(string(content), "\n") {
+		lineNumber := i + 1
+		if i == 0 && strings.HasPrefix(line, "\uFEFF") {
+			problems = append(problems, EnvLineError{Line: lineNumber, Reason: "starts with a UTF-8 byte order mark"})
+			continue
+		}
+		line = strings.TrimSuffix(line, "\r")
+		if strings.Contains(line, "\r") {
+			problems = append(problems, EnvLineError{Line: lineNumber, Reason: "contains a carriage return"})
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, ok := strings.Cut(line, "=")
+		switch {
+		case !ok:
+			problems = append(problems, EnvLineError{Line: lineNumber, Reason: "missing '='"})
+		case key == "":
+			problems = append(problems, EnvLineError{Line: lineNumber, Reason: "missing variable name"})
+		case strings.ContainsAny(key, " \t"):
+			problems = append(problems, EnvLineError{Line: lineNumber, Reason: fmt.Sprintf("variable name '%s' contains whitespace", key)})
+		default:
+			count++
+		}
+	}
+	return count, problems
+}
+
+// ExpandEnvVars expands ${NAME} references in the values of KEY=VALUE lines as returned by
+// ParseEnvVars. A reference resolves to the value of NAME on an earlier line (as expanded
+// itself), or else to fallback[NAME]. "$$" stands for a literal '$'; any other '$' not starting
+// a reference is kept as is. Referencing a name that is not defined at that point is an error;
+// this includes names only defined on a later line, so references can never be circular.
+// source names the input in error messages.
+func ExpandEnvVars(vars []string, fallback map[string]string, source string) ([]string, error) {
+	defined := make(map[string]string, len(vars))
+	expanded := make([]string, 0, len(vars))
+	for _, kv := range vars {
+		key, value, _ := strings.Cut(kv, "=")
+		var result strings.Builder
+		for i := 0; i < len(value); i++ {
+			if value[i] != '$' || i+1 == len(value) {
+				result.WriteByte(value[i])
+				continue
+			}
+			switch value[i+1] {
+			case '$':
+				result.WriteByte('$')
+				i++
+			case '{':
+				end := strings.IndexByte(value[i+2:], '}')
+				if end < 0 {
+					return nil, fmt.Errorf("invalid value for %s in env file %s: unterminated '${'", key, source)
+				}
+				name := value[i+2 : i+2+end]
+				if !isEnvVarName(name) {
+					return nil, fmt.Errorf("invalid value for %s in env file %s: '${%s}' is not a valid variable reference", key, source, name)
+				}
+				resolved, ok := defined[name]
+				if !ok {
+					resolved, ok = fallback[name]
+				}
+				if !ok {
+					return nil, fmt.Errorf("invalid value for %s in env file %s: ${%s} is not defined before it", key, source, name)
+				}
+				result.WriteString(resolved)
+				i += end + 2
+			default:
+				result.WriteByte('$')
+			}
+		}
+		defined[key] = result.String()
+		expanded = append(expanded, key+"="+result.String())
+	}
+	return expanded, nil
+}
+
+// isEnvVarName reports whether name is a valid environment variable name: letters, digits and
+// underscores, not starting with a digit.
+func isEnvVarName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, c := range name {
+		if c != '_' && (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeEnvVars combines two KEY=VALUE lists. Keys in overrides replace the same keys in base;
+// base order is kept and new keys are appended.
+func MergeEnvVars(base, overrides []string) []string {
+	overrideValues := make(map[string]string, len(overrides))
+	for _, kv := range overrides {
+		key, _, _ := strings.Cut(kv, "=")
+		overrideValues[key] = kv
+	}
+
+	merged := make([]string, 0, len(base)+len(overrides))
+	for _, kv := range base {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, ok := overrideValues[key]; ok {
+			continue
+		}
+		merged = append(merged, kv)
+	}
+	return append(merged, overrides...)
+}
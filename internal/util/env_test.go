@@ -0,0 +1,72 @@
+package util
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpandEnvVars(t *testing.T) {
+	fallback := map[string]string{"PROJECT": "app", "DB_PASSWORD": "s3cret"}
+	tests := []struct {
+		name string
+		vars []string
+		want []string // nil if an error is expected
+	}{
+		{
+			name: "earlier line and fallback",
+			vars: []string{"HOST=db", "URL=postgres://${PROJECT}:${DB_PASSWORD}@${HOST}/x"},
+			want: []string{"HOST=db", "URL=postgres://app:s3cret@db/x"},
+		},
+		{
+			name: "earlier line takes precedence over fallback",
+			vars: []string{"PROJECT=other", "NAME=${PROJECT}"},
+			want: []string{"PROJECT=other", "NAME=other"},
+		},
+		{
+			name: "escaped and bare dollars",
+			vars: []string{"PRICE=$$5", "REF=$${HOST}", "PLAIN=a$b$", "SHELL=$HOME"},
+			want: []string{"PRICE=$5", "REF=${HOST}", "PLAIN=a$b$", "SHELL=$HOME"},
+		},
+		{
+			name: "missing reference",
+			vars: []string{"URL=${MISSING}"},
+		},
+		{
+			name: "forward reference",
+			vars: []string{"A=${B}", "B=b"},
+		},
+		{
+			name: "circular reference",
+			vars: []string{"A=${B}", "B=${A}"},
+		},
+		{
+			name: "self reference",
+			vars: []string{"A=${A}"},
+		},
+		{
+			name: "default syntax is not supported",
+			vars: []string{"A=${FOO:-bar}"},
+		},
+		{
+			name: "unterminated reference",
+			vars: []string{"A=${B"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnvVars(tt.vars, fallback, ".env")
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("ExpandEnvVars = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandEnvVars: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExpandEnvVars = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
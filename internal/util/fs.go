@@ -0,0 +1,145 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// CopyDir recursively copies the contents of srcDir into dstDir, creating dstDir if needed.
+// File modes and symlinks are preserved; other special files are skipped.
+func CopyDir(srcDir, dstDir string) error {
+	info, err := os.Stat(srcDir)
+	if err != nil {
+		return fmt.Errorf("failed to access source directory %s: %w", srcDir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("source path %s is not a directory", srcDir)
+	}
+
+	return filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dstDir, relPath)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink %s: %w", path, err)
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			Log.Debugf("Skipping special file %s during copy", path)
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	return out.Close()
+}
+
+// HashDir returns a SHA256 hex digest of a directory tree: the sorted relative paths of its
+// files together with their contents and symlink targets. Entries whose base name is in ignore
+// (e.g. ".git") are left out, including everything below an ignored directory.
+func HashDir(dir string, ignore ...string) (string, error) {
+	ignored := make(map[string]bool, len(ignore))
+	for _, name := range ignore {
+		ignored[name] = true
+	}
+
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if path != dir && ignored[d.Name()] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk directory %s: %w", dir, err)
+	}
+	sort.Strings(paths)
+
+	hash := sha256.New()
+	for _, path := range paths {
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return "", err
+		}
+		// Separate entries with NUL bytes so different path/content splits cannot collide.
+		_, _ = fmt.Fprintf(hash, "%s\x00", filepath.ToSlash(relPath))
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to read symlink %s: %w", path, err)
+			}
+			_, _ = fmt.Fprintf(hash, "symlink:%s", link)
+		case info.Mode().IsRegular():
+			if err := hashFileContent(hash, path); err != nil {
+				return "", err
+			}
+		}
+		_, _ = hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func hashFileContent(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// rotatingFile is an append-only log file that is rolled over to <path>.1, <path>.2, ... once it
+// reaches maxBytes, keeping at most maxArchives old files.
+type rotatingFile struct {
+	mu          sync.Mutex
+	path        string
+	maxBytes    int64
+	maxArchives int
+	file        *os.File
+	size        int64
+}
+
+func openRotatingFile(path string, maxSizeMB, maxArchives int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	rf := &rotatingFile{path: path, maxBytes: int64(maxSizeMB) * 1024 * 1024, maxArchives: maxArchives}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", rf.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat log file %s: %w", rf.path, err)
+	}
+	rf.file, rf.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, rotating the file first if p would take it past maxBytes.
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.maxBytes > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxBytes {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the archives up by one, moves the current file to <path>.1 and reopens it.
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file %s: %w", rf.path, err)
+	}
+	archive := func(i int) string { return fmt.Sprintf("%s.%d", rf.path, i) }
+	_ = os.Remove(archive(rf.maxArchives))
+	for i := rf.maxArchives - 1; i >= 1; i-- {
+		_ = os.Rename(archive(i), archive(i+1))
+	}
+	if rf.maxArchives > 0 {
+		_ = os.Rename(rf.path, archive(1))
+	} else {
+		_ = os.Remove(rf.path)
+	}
+	return rf.open()
+}
+
+// fileHook writes every entry the logger emits to a file, with its own formatter so the file
+// never gets the terminal's colors.
+type fileHook struct {
+	writer    *rotatingFile
+	formatter logrus.Formatter
+}
+
+func (h *fileHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *fileHook) Fire(entry *logrus.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = h.writer.Write(line)
+	return err
+}
+
+var (
+	logFileHook *fileHook
+	logLevel    = logrus.InfoLevel
+)
+
+// SetLogFile additionally writes all log entries to path, in the current log format without
+// colors, rotating it once it reaches maxSizeMB and keeping maxArchives old files. An empty path
+// stops logging to a file. InitLogger keeps the file and updates its format.
+func SetLogFile(path string, maxSizeMB, maxArchives int) error {
+	var hook *fileHook
+	if path != "" {
+		writer, err := openRotatingFile(path, maxSizeMB, maxArchives)
+		if err != nil {
+			return err
+		}
+		hook = &fileHook{writer: writer, formatter: fileFormatter()}
+	}
+
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range Log.Hooks {
+		for _, h := range levelHooks {
+			if h != logrus.Hook(logFileHook) {
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	if logFileHook != nil {
+		logFileHook.writer.mu.Lock()
+		_ = logFileHook.writer.file.Close()
+		logFileHook.writer.mu.Unlock()
+	}
+	if hook != nil {
+		for _, level := range hook.Levels() {
+			hooks[level] = append(hooks[level], hook)
+		}
+	}
+	Log.ReplaceHooks(hooks)
+	logFileHook = hook
+	return nil
+}
+
+// SetLogLevel sets the level used by InitLogger when debug output is off: "debug", "info",
+// "warn" or "error". An empty level means "info".
+func SetLogLevel(level string) error {
+	if level == "" {
+		logLevel = logrus.InfoLevel
+		return nil
+	}
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level '%s': %w", level, err)
+	}
+	logLevel = parsed
+	return nil
+}
+
+// fileFormatter returns the formatter for the log file in the current log format.
+func fileFormatter() logrus.Formatter {
+	if logFormat == LogFormatJSON {
+		return &logrus.JSONFormatter{}
+	}
+	return &logrus.TextFormatter{FullTimestamp: true, DisableColors: true}
+}
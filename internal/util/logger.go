@@ -11,27 +11,61 @@ import (
 
 var Log = logrus.New()
 
-func InitLogger(debug bool) {
+// Log output formats accepted by InitLogger.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+var logFormat = LogFormatText
+
+// InitLogger sets the log level and output format. format is LogFormatText (colored, the
+// default) or LogFormatJSON, which writes one JSON object per entry for log collectors. Without
+// debug the level set with SetLogLevel applies (info by default). A log file set with
+// SetLogFile switches to the new format too.
+func InitLogger(debug bool, format string) {
 	Log.SetOutput(os.Stdout)
+	logFormat = LogFormatText
+	if format == LogFormatJSON {
+		logFormat = LogFormatJSON
+	}
+
 	if debug {
 		Log.SetLevel(logrus.DebugLevel)
-		Log.SetFormatter(&logrus.TextFormatter{
-			FullTimestamp: true,
-			ForceColors:   true,
-			CallerPrettyfier: func(f *runtime.Frame) (string, string) {
-				s := strings.Split(f.Function, ".")
-				funcname := s[len(s)-1]
-				filename := filepath.Base(f.File)
-				return funcname, " [" + filename + ":" + string(rune(f.Line)) + "]"
-			},
-		})
+		if logFormat == LogFormatJSON {
+			Log.SetFormatter(&logrus.JSONFormatter{})
+		} else {
+			Log.SetFormatter(&logrus.TextFormatter{
+				FullTimestamp: true,
+				ForceColors:   true,
+				CallerPrettyfier: func(f *runtime.Frame) (string, string) {
+					s := strings.Split(f.Function, ".")
+					funcname := s[len(s)-1]
+					filename := filepath.Base(f.File)
+					return funcname, " [" + filename + ":" + string(rune(f.Line)) + "]"
+				},
+			})
+		}
 		Log.SetReportCaller(true)
 		Log.Debug("Debug logging enabled")
 	} else {
-		Log.SetLevel(logrus.InfoLevel)
-		Log.SetFormatter(&logrus.TextFormatter{
-			FullTimestamp: true,
-			ForceColors:   true,
-		})
+		Log.SetLevel(logLevel)
+		if logFormat == LogFormatJSON {
+			Log.SetFormatter(&logrus.JSONFormatter{})
+		} else {
+			Log.SetFormatter(&logrus.TextFormatter{
+				FullTimestamp: true,
+				ForceColors:   true,
+			})
+		}
+		Log.SetReportCaller(false)
+	}
+	if logFileHook != nil {
+		logFileHook.formatter = fileFormatter()
 	}
 }
+
+// LogFormat returns the output format set by the last InitLogger call.
+func LogFormat() string {
+	return logFormat
+}
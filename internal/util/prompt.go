@@ -0,0 +1,32 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ReadSecretInput reads a secret such as an access token from stdin. On a terminal the prompt is
+// shown and the input is not echoed; otherwise the first line of stdin is used, so secrets can be
+// piped in and kept out of the shell history.
+func ReadSecretInput(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+		return strings.TrimSpace(string(value)), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input from stdin: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"os"
 	"reflow/cmd"
+	"reflow/internal/plugin"
 )
 
 func main() {
+	// Sandboxed CLI plugins are started through reflow itself, which sets up the sandbox first.
+	if len(os.Args) > 1 && os.Args[1] == plugin.SandboxHelperCommand {
+		plugin.RunSandboxHelper(os.Args[2:])
+	}
 	cmd.Execute()
 }